@@ -0,0 +1,894 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: cache.proto
+
+package cachepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	MapService_Set_FullMethodName           = "/cache.v1.MapService/Set"
+	MapService_Get_FullMethodName           = "/cache.v1.MapService/Get"
+	MapService_GetMany_FullMethodName       = "/cache.v1.MapService/GetMany"
+	MapService_Update_FullMethodName        = "/cache.v1.MapService/Update"
+	MapService_Delete_FullMethodName        = "/cache.v1.MapService/Delete"
+	MapService_GetTimeToLive_FullMethodName = "/cache.v1.MapService/GetTimeToLive"
+	MapService_SetTimeToLive_FullMethodName = "/cache.v1.MapService/SetTimeToLive"
+	MapService_List_FullMethodName          = "/cache.v1.MapService/List"
+	MapService_Scan_FullMethodName          = "/cache.v1.MapService/Scan"
+)
+
+// MapServiceClient is the client API for MapService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MapService mirrors the map routes of the HTTP API. Every request names the
+// namespace it applies to, an empty namespace is the default namespace.
+type MapServiceClient interface {
+	// Set sets the value of a key, a positive time_to_live expires it after that many seconds
+	Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Get returns the value of a key
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Entry, error)
+	// GetMany returns the entries found for the given keys
+	GetMany(ctx context.Context, in *GetManyRequest, opts ...grpc.CallOption) (*GetManyResponse, error)
+	// Update updates the value of an existing key
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Delete removes a key
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// GetTimeToLive returns the remaining time to live of a key
+	GetTimeToLive(ctx context.Context, in *GetTimeToLiveRequest, opts ...grpc.CallOption) (*TimeToLive, error)
+	// SetTimeToLive sets the time to live of an existing key, 0 removes the expiration
+	SetTimeToLive(ctx context.Context, in *SetTimeToLiveRequest, opts ...grpc.CallOption) (*TimeToLive, error)
+	// List streams the first n entries sorted by key, or by sort_by, all of them when n is 0
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (MapService_ListClient, error)
+	// Scan streams the entries whose key matches a Redis style glob pattern, in key order
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (MapService_ScanClient, error)
+}
+
+type mapServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMapServiceClient(cc grpc.ClientConnInterface) MapServiceClient {
+	return &mapServiceClient{cc}
+}
+
+func (c *mapServiceClient) Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, MapService_Set_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Entry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entry)
+	err := c.cc.Invoke(ctx, MapService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) GetMany(ctx context.Context, in *GetManyRequest, opts ...grpc.CallOption) (*GetManyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetManyResponse)
+	err := c.cc.Invoke(ctx, MapService_GetMany_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, MapService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, MapService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) GetTimeToLive(ctx context.Context, in *GetTimeToLiveRequest, opts ...grpc.CallOption) (*TimeToLive, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TimeToLive)
+	err := c.cc.Invoke(ctx, MapService_GetTimeToLive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) SetTimeToLive(ctx context.Context, in *SetTimeToLiveRequest, opts ...grpc.CallOption) (*TimeToLive, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TimeToLive)
+	err := c.cc.Invoke(ctx, MapService_SetTimeToLive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (MapService_ListClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MapService_ServiceDesc.Streams[0], MapService_List_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mapServiceListClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MapService_ListClient interface {
+	Recv() (*Entry, error)
+	grpc.ClientStream
+}
+
+type mapServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *mapServiceListClient) Recv() (*Entry, error) {
+	m := new(Entry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *mapServiceClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (MapService_ScanClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MapService_ServiceDesc.Streams[1], MapService_Scan_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mapServiceScanClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MapService_ScanClient interface {
+	Recv() (*Entry, error)
+	grpc.ClientStream
+}
+
+type mapServiceScanClient struct {
+	grpc.ClientStream
+}
+
+func (x *mapServiceScanClient) Recv() (*Entry, error) {
+	m := new(Entry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MapServiceServer is the server API for MapService service.
+// All implementations must embed UnimplementedMapServiceServer
+// for forward compatibility
+//
+// MapService mirrors the map routes of the HTTP API. Every request names the
+// namespace it applies to, an empty namespace is the default namespace.
+type MapServiceServer interface {
+	// Set sets the value of a key, a positive time_to_live expires it after that many seconds
+	Set(context.Context, *SetRequest) (*SetResponse, error)
+	// Get returns the value of a key
+	Get(context.Context, *GetRequest) (*Entry, error)
+	// GetMany returns the entries found for the given keys
+	GetMany(context.Context, *GetManyRequest) (*GetManyResponse, error)
+	// Update updates the value of an existing key
+	Update(context.Context, *UpdateRequest) (*SetResponse, error)
+	// Delete removes a key
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// GetTimeToLive returns the remaining time to live of a key
+	GetTimeToLive(context.Context, *GetTimeToLiveRequest) (*TimeToLive, error)
+	// SetTimeToLive sets the time to live of an existing key, 0 removes the expiration
+	SetTimeToLive(context.Context, *SetTimeToLiveRequest) (*TimeToLive, error)
+	// List streams the first n entries sorted by key, or by sort_by, all of them when n is 0
+	List(*ListRequest, MapService_ListServer) error
+	// Scan streams the entries whose key matches a Redis style glob pattern, in key order
+	Scan(*ScanRequest, MapService_ScanServer) error
+	mustEmbedUnimplementedMapServiceServer()
+}
+
+// UnimplementedMapServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMapServiceServer struct {
+}
+
+func (UnimplementedMapServiceServer) Set(context.Context, *SetRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+}
+func (UnimplementedMapServiceServer) Get(context.Context, *GetRequest) (*Entry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedMapServiceServer) GetMany(context.Context, *GetManyRequest) (*GetManyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMany not implemented")
+}
+func (UnimplementedMapServiceServer) Update(context.Context, *UpdateRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedMapServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedMapServiceServer) GetTimeToLive(context.Context, *GetTimeToLiveRequest) (*TimeToLive, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTimeToLive not implemented")
+}
+func (UnimplementedMapServiceServer) SetTimeToLive(context.Context, *SetTimeToLiveRequest) (*TimeToLive, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTimeToLive not implemented")
+}
+func (UnimplementedMapServiceServer) List(*ListRequest, MapService_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedMapServiceServer) Scan(*ScanRequest, MapService_ScanServer) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedMapServiceServer) mustEmbedUnimplementedMapServiceServer() {}
+
+// UnsafeMapServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MapServiceServer will
+// result in compilation errors.
+type UnsafeMapServiceServer interface {
+	mustEmbedUnimplementedMapServiceServer()
+}
+
+func RegisterMapServiceServer(s grpc.ServiceRegistrar, srv MapServiceServer) {
+	s.RegisterService(&MapService_ServiceDesc, srv)
+}
+
+func _MapService_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_Set_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).Set(ctx, req.(*SetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_GetMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetManyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).GetMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_GetMany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).GetMany(ctx, req.(*GetManyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_GetTimeToLive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimeToLiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).GetTimeToLive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_GetTimeToLive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).GetTimeToLive(ctx, req.(*GetTimeToLiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_SetTimeToLive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTimeToLiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapServiceServer).SetTimeToLive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapService_SetTimeToLive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapServiceServer).SetTimeToLive(ctx, req.(*SetTimeToLiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MapServiceServer).List(m, &mapServiceListServer{ServerStream: stream})
+}
+
+type MapService_ListServer interface {
+	Send(*Entry) error
+	grpc.ServerStream
+}
+
+type mapServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *mapServiceListServer) Send(m *Entry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MapService_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MapServiceServer).Scan(m, &mapServiceScanServer{ServerStream: stream})
+}
+
+type MapService_ScanServer interface {
+	Send(*Entry) error
+	grpc.ServerStream
+}
+
+type mapServiceScanServer struct {
+	grpc.ServerStream
+}
+
+func (x *mapServiceScanServer) Send(m *Entry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MapService_ServiceDesc is the grpc.ServiceDesc for MapService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MapService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cache.v1.MapService",
+	HandlerType: (*MapServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Set",
+			Handler:    _MapService_Set_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _MapService_Get_Handler,
+		},
+		{
+			MethodName: "GetMany",
+			Handler:    _MapService_GetMany_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _MapService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _MapService_Delete_Handler,
+		},
+		{
+			MethodName: "GetTimeToLive",
+			Handler:    _MapService_GetTimeToLive_Handler,
+		},
+		{
+			MethodName: "SetTimeToLive",
+			Handler:    _MapService_SetTimeToLive_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _MapService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Scan",
+			Handler:       _MapService_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cache.proto",
+}
+
+const (
+	QueueService_Push_FullMethodName    = "/cache.v1.QueueService/Push"
+	QueueService_Peek_FullMethodName    = "/cache.v1.QueueService/Peek"
+	QueueService_Pop_FullMethodName     = "/cache.v1.QueueService/Pop"
+	QueueService_Update_FullMethodName  = "/cache.v1.QueueService/Update"
+	QueueService_Delete_FullMethodName  = "/cache.v1.QueueService/Delete"
+	QueueService_List_FullMethodName    = "/cache.v1.QueueService/List"
+	QueueService_Consume_FullMethodName = "/cache.v1.QueueService/Consume"
+)
+
+// QueueServiceClient is the client API for QueueService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// QueueService mirrors the queue routes of the HTTP API
+type QueueServiceClient interface {
+	// Push adds a value to the back of the queue
+	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Peek returns the first entry of the queue without removing it
+	Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*Entry, error)
+	// Pop removes and returns the first entry of the queue
+	Pop(ctx context.Context, in *PopRequest, opts ...grpc.CallOption) (*Entry, error)
+	// Update updates the value of the entries with the given key
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Delete removes the entries with the given key
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// List streams the first n entries in queue order, or sorted by sort_by, all of them when n is 0
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (QueueService_ListClient, error)
+	// Consume pops entries as they become available. The first request names the
+	// namespace, every request grants the server credit to send that many more
+	// entries. Entries are removed from the queue when they are sent.
+	Consume(ctx context.Context, opts ...grpc.CallOption) (QueueService_ConsumeClient, error)
+}
+
+type queueServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQueueServiceClient(cc grpc.ClientConnInterface) QueueServiceClient {
+	return &queueServiceClient{cc}
+}
+
+func (c *queueServiceClient) Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, QueueService_Push_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueServiceClient) Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*Entry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entry)
+	err := c.cc.Invoke(ctx, QueueService_Peek_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueServiceClient) Pop(ctx context.Context, in *PopRequest, opts ...grpc.CallOption) (*Entry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entry)
+	err := c.cc.Invoke(ctx, QueueService_Pop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, QueueService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, QueueService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (QueueService_ListClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QueueService_ServiceDesc.Streams[0], QueueService_List_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queueServiceListClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QueueService_ListClient interface {
+	Recv() (*Entry, error)
+	grpc.ClientStream
+}
+
+type queueServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *queueServiceListClient) Recv() (*Entry, error) {
+	m := new(Entry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *queueServiceClient) Consume(ctx context.Context, opts ...grpc.CallOption) (QueueService_ConsumeClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QueueService_ServiceDesc.Streams[1], QueueService_Consume_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queueServiceConsumeClient{ClientStream: stream}
+	return x, nil
+}
+
+type QueueService_ConsumeClient interface {
+	Send(*ConsumeRequest) error
+	Recv() (*Entry, error)
+	grpc.ClientStream
+}
+
+type queueServiceConsumeClient struct {
+	grpc.ClientStream
+}
+
+func (x *queueServiceConsumeClient) Send(m *ConsumeRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *queueServiceConsumeClient) Recv() (*Entry, error) {
+	m := new(Entry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QueueServiceServer is the server API for QueueService service.
+// All implementations must embed UnimplementedQueueServiceServer
+// for forward compatibility
+//
+// QueueService mirrors the queue routes of the HTTP API
+type QueueServiceServer interface {
+	// Push adds a value to the back of the queue
+	Push(context.Context, *PushRequest) (*SetResponse, error)
+	// Peek returns the first entry of the queue without removing it
+	Peek(context.Context, *PeekRequest) (*Entry, error)
+	// Pop removes and returns the first entry of the queue
+	Pop(context.Context, *PopRequest) (*Entry, error)
+	// Update updates the value of the entries with the given key
+	Update(context.Context, *UpdateRequest) (*SetResponse, error)
+	// Delete removes the entries with the given key
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// List streams the first n entries in queue order, or sorted by sort_by, all of them when n is 0
+	List(*ListRequest, QueueService_ListServer) error
+	// Consume pops entries as they become available. The first request names the
+	// namespace, every request grants the server credit to send that many more
+	// entries. Entries are removed from the queue when they are sent.
+	Consume(QueueService_ConsumeServer) error
+	mustEmbedUnimplementedQueueServiceServer()
+}
+
+// UnimplementedQueueServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedQueueServiceServer struct {
+}
+
+func (UnimplementedQueueServiceServer) Push(context.Context, *PushRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedQueueServiceServer) Peek(context.Context, *PeekRequest) (*Entry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Peek not implemented")
+}
+func (UnimplementedQueueServiceServer) Pop(context.Context, *PopRequest) (*Entry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pop not implemented")
+}
+func (UnimplementedQueueServiceServer) Update(context.Context, *UpdateRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedQueueServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedQueueServiceServer) List(*ListRequest, QueueService_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedQueueServiceServer) Consume(QueueService_ConsumeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Consume not implemented")
+}
+func (UnimplementedQueueServiceServer) mustEmbedUnimplementedQueueServiceServer() {}
+
+// UnsafeQueueServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QueueServiceServer will
+// result in compilation errors.
+type UnsafeQueueServiceServer interface {
+	mustEmbedUnimplementedQueueServiceServer()
+}
+
+func RegisterQueueServiceServer(s grpc.ServiceRegistrar, srv QueueServiceServer) {
+	s.RegisterService(&QueueService_ServiceDesc, srv)
+}
+
+func _QueueService_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServiceServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueueService_Push_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServiceServer).Push(ctx, req.(*PushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueueService_Peek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeekRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServiceServer).Peek(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueueService_Peek_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServiceServer).Peek(ctx, req.(*PeekRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueueService_Pop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServiceServer).Pop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueueService_Pop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServiceServer).Pop(ctx, req.(*PopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueueService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueueService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueueService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueueService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueueService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueueServiceServer).List(m, &queueServiceListServer{ServerStream: stream})
+}
+
+type QueueService_ListServer interface {
+	Send(*Entry) error
+	grpc.ServerStream
+}
+
+type queueServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *queueServiceListServer) Send(m *Entry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _QueueService_Consume_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(QueueServiceServer).Consume(&queueServiceConsumeServer{ServerStream: stream})
+}
+
+type QueueService_ConsumeServer interface {
+	Send(*Entry) error
+	Recv() (*ConsumeRequest, error)
+	grpc.ServerStream
+}
+
+type queueServiceConsumeServer struct {
+	grpc.ServerStream
+}
+
+func (x *queueServiceConsumeServer) Send(m *Entry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *queueServiceConsumeServer) Recv() (*ConsumeRequest, error) {
+	m := new(ConsumeRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QueueService_ServiceDesc is the grpc.ServiceDesc for QueueService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QueueService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cache.v1.QueueService",
+	HandlerType: (*QueueServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Push",
+			Handler:    _QueueService_Push_Handler,
+		},
+		{
+			MethodName: "Peek",
+			Handler:    _QueueService_Peek_Handler,
+		},
+		{
+			MethodName: "Pop",
+			Handler:    _QueueService_Pop_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _QueueService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _QueueService_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _QueueService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Consume",
+			Handler:       _QueueService_Consume_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "cache.proto",
+}
@@ -1,52 +1,160 @@
 // Package apiSpec provides primitives to interact with the openapi HTTP API.
 //
-// Code generated by unknown module path version unknown version DO NOT EDIT.
+// Code generated by github.com/deepmap/oapi-codegen version (devel) DO NOT EDIT.
 package apiSpec
 
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
 
-	"github.com/deepmap/oapi-codegen/pkg/runtime"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
+	"github.com/oapi-codegen/runtime"
+	strictgin "github.com/oapi-codegen/runtime/strictmiddleware/gin"
 )
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Show the nodes of the sharded cluster
+	// (GET /admin/cluster)
+	GetCluster(c *gin.Context)
+	// Change the nodes of the sharded cluster
+	// (PUT /admin/cluster)
+	ChangeCluster(c *gin.Context)
+	// Show the clock of this node and the synchronization with its peers in active-active mode
+	// (GET /admin/crdt)
+	GetCRDTStatus(c *gin.Context)
+	// Check that the server is up
+	// (GET /admin/health)
+	GetHealth(c *gin.Context)
+	// List the most accessed keys of the maps and the queues, the hottest first
+	// (GET /admin/hotkeys)
+	GetHotKeys(c *gin.Context, params GetHotKeysParams)
+	// Summarize the uptime, configuration, sizes, memory, hit ratio and persistence and replication status of this node
+	// (GET /admin/info)
+	GetInfo(c *gin.Context)
+	// List the members of the gossip cluster known by this node and their states
+	// (GET /admin/members)
+	ListMembers(c *gin.Context)
+	// List the namespaces and their sizes
+	// (GET /admin/namespaces)
+	ListNamespaces(c *gin.Context)
+	// Flush and remove a namespace
+	// (DELETE /admin/namespaces/{namespace})
+	DeleteNamespace(c *gin.Context, namespace NamespaceName)
+	// Show the raft role, term and log positions of this node
+	// (GET /admin/raft)
+	GetRaftStatus(c *gin.Context)
+	// Show the replication role and, on a follower, its lag behind the leader
+	// (GET /admin/replication)
+	GetReplicationStatus(c *gin.Context)
+	// Empty the slow log
+	// (DELETE /admin/slowlog)
+	ResetSlowLog(c *gin.Context)
+	// List the operations that took longer than the slow log threshold, the most recent first
+	// (GET /admin/slowlog)
+	GetSlowLog(c *gin.Context, params GetSlowLogParams)
+	// Get all the entries of the cache
+	// (GET /cache)
+	GetAllMapValues(c *gin.Context, params GetAllMapValuesParams)
 	// Add an entry to the cache
 	// (POST /cache)
-	PostCache(c *gin.Context)
+	SetMapValue(c *gin.Context, params SetMapValueParams)
 	// Get value for list of keys
 	// (GET /cache/entries)
-	GetCacheEntries(c *gin.Context, params GetCacheEntriesParams)
+	GetListofMapValues(c *gin.Context, params GetListofMapValuesParams)
 	// Get a list of n entries from the cache
-	// (GET /cache/list/{n-entries})
-	GetCacheListNEntries(c *gin.Context, nEntries int)
-	// Get n sorted entries
-	// (GET /cache/list/{sort}/{n})
-	GetCacheListSortN(c *gin.Context, sort GetCacheListSortNParamsSort, n int)
+	// (GET /cache/list/{n})
+	GetMapEntryList(c *gin.Context, n N, params GetMapEntryListParams)
 	// Get metadata for all entries in cache
 	// (GET /cache/metadata)
-	GetCacheMetadata(c *gin.Context)
+	GetAllMapMetadata(c *gin.Context, params GetAllMapMetadataParams)
 	// Get metadata for specific entry in cache
 	// (GET /cache/metadata/{key})
-	GetCacheMetadataKey(c *gin.Context, key string)
+	GetMapMetadata(c *gin.Context, key Key, params GetMapMetadataParams)
+	// Get n entries from the cache sorted by key or value
+	// (GET /cache/sorted/{sort-by}/{n})
+	GetSortedMapEntries(c *gin.Context, sortBy GetSortedMapEntriesParamsSortBy, n N, params GetSortedMapEntriesParams)
+	// Delete an entry from the cache
+	// (DELETE /cache/{key})
+	DeleteMapEntry(c *gin.Context, key Key, params DeleteMapEntryParams)
 	// Get an entry from the cache
 	// (GET /cache/{key})
-	GetCacheKey(c *gin.Context, key string)
+	GetMapValue(c *gin.Context, key Key, params GetMapValueParams)
 	// Update an entry in cache
 	// (PUT /cache/{key})
-	PutCacheKey(c *gin.Context, key string)
-	// Post an entry with expiration time
-	// (POST /cache/{time-to-live})
-	PostCacheTimeToLive(c *gin.Context, timeToLive int)
+	UpdateMapEntry(c *gin.Context, key Key, params UpdateMapEntryParams)
+	// Get the remaining time to live of an entry
+	// (GET /cache/{key}/ttl)
+	GetMapTimeToLive(c *gin.Context, key Key, params GetMapTimeToLiveParams)
+	// Set the time to live of an entry, 0 removes the expiration
+	// (PUT /cache/{key}/ttl)
+	SetMapTimeToLive(c *gin.Context, key Key, params SetMapTimeToLiveParams)
+	// Get the value of a counter
+	// (GET /counters/{key})
+	GetCounter(c *gin.Context, key Key, params GetCounterParams)
+	// Add to a counter, concurrent additions on different nodes are all kept
+	// (POST /counters/{key})
+	IncrementCounter(c *gin.Context, key Key, params IncrementCounterParams)
+	// Get all the entries of the queue in order
+	// (GET /queue)
+	GetAllQueueValues(c *gin.Context, params GetAllQueueValuesParams)
+	// Push an entry to the back of the queue
+	// (POST /queue)
+	SetQueueValue(c *gin.Context, params SetQueueValueParams)
+	// Get the first n entries of the queue
+	// (GET /queue/list/{n})
+	GetQueueEntryList(c *gin.Context, n N, params GetQueueEntryListParams)
+	// Get metadata for all entries in the queue
+	// (GET /queue/metadata)
+	GetAllQueueMetadata(c *gin.Context, params GetAllQueueMetadataParams)
+	// Get metadata for the first entry with the given key in the queue
+	// (GET /queue/metadata/{key})
+	GetQueueMetadata(c *gin.Context, key Key, params GetQueueMetadataParams)
+	// Get the entry at the front of the queue without removing it
+	// (GET /queue/peek)
+	GetQueueValue(c *gin.Context, params GetQueueValueParams)
+	// Remove and return the entry at the front of the queue
+	// (POST /queue/pop)
+	PopQueueValue(c *gin.Context, params PopQueueValueParams)
+	// Get the first n entries of the queue sorted by key or value
+	// (GET /queue/sorted/{sort-by}/{n})
+	GetSortedQueueEntries(c *gin.Context, sortBy GetSortedQueueEntriesParamsSortBy, n N, params GetSortedQueueEntriesParams)
+	// Delete the entries with the given key from the queue
+	// (DELETE /queue/{key})
+	DeleteQueueValue(c *gin.Context, key Key, params DeleteQueueValueParams)
+	// Update the value of an entry in the queue
+	// (PUT /queue/{key})
+	UpdateQueueValue(c *gin.Context, key Key, params UpdateQueueValueParams)
+	// Get the elements of a set
+	// (GET /sets/{key})
+	GetSet(c *gin.Context, key Key, params GetSetParams)
+	// Add and remove elements of a set, an element added concurrently with its removal is kept
+	// (POST /sets/{key})
+	UpdateSet(c *gin.Context, key Key, params UpdateSetParams)
+	// List the topics and their subscriptions
+	// (GET /topics)
+	ListTopics(c *gin.Context)
+	// Publish a message to every subscription of the topic
+	// (POST /topics/{topic}/messages)
+	PublishMessage(c *gin.Context, topic Topic)
+	// List the subscriptions of a topic
+	// (GET /topics/{topic}/subscriptions)
+	ListSubscriptions(c *gin.Context, topic Topic)
+	// Subscribe the queue of a namespace to a topic
+	// (POST /topics/{topic}/subscriptions)
+	CreateSubscription(c *gin.Context, topic Topic)
+	// Remove a subscription from a topic
+	// (DELETE /topics/{topic}/subscriptions/{subscription})
+	DeleteSubscription(c *gin.Context, topic Topic, subscription SubscriptionName)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -58,8 +166,8 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
-// PostCache operation middleware
-func (siw *ServerInterfaceWrapper) PostCache(c *gin.Context) {
+// GetCluster operation middleware
+func (siw *ServerInterfaceWrapper) GetCluster(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -68,29 +176,215 @@ func (siw *ServerInterfaceWrapper) PostCache(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.PostCache(c)
+	siw.Handler.GetCluster(c)
 }
 
-// GetCacheEntries operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheEntries(c *gin.Context) {
+// ChangeCluster operation middleware
+func (siw *ServerInterfaceWrapper) ChangeCluster(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ChangeCluster(c)
+}
+
+// GetCRDTStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetCRDTStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCRDTStatus(c)
+}
+
+// GetHealth operation middleware
+func (siw *ServerInterfaceWrapper) GetHealth(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetHealth(c)
+}
+
+// GetHotKeys operation middleware
+func (siw *ServerInterfaceWrapper) GetHotKeys(c *gin.Context) {
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetCacheEntriesParams
+	var params GetHotKeysParams
 
-	// ------------- Required query parameter "key" -------------
+	// ------------- Optional query parameter "count" -------------
 
-	if paramValue := c.Query("key"); paramValue != "" {
+	err = runtime.BindQueryParameter("form", true, false, "count", c.Request.URL.Query(), &params.Count)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter count: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument key is required, but not found"), http.StatusBadRequest)
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetHotKeys(c, params)
+}
+
+// GetInfo operation middleware
+func (siw *ServerInterfaceWrapper) GetInfo(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetInfo(c)
+}
+
+// ListMembers operation middleware
+func (siw *ServerInterfaceWrapper) ListMembers(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListMembers(c)
+}
+
+// ListNamespaces operation middleware
+func (siw *ServerInterfaceWrapper) ListNamespaces(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListNamespaces(c)
+}
+
+// DeleteNamespace operation middleware
+func (siw *ServerInterfaceWrapper) DeleteNamespace(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace NamespaceName
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", c.Param("namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "key", c.Request.URL.Query(), &params.Key)
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteNamespace(c, namespace)
+}
+
+// GetRaftStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetRaftStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetRaftStatus(c)
+}
+
+// GetReplicationStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetReplicationStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetReplicationStatus(c)
+}
+
+// ResetSlowLog operation middleware
+func (siw *ServerInterfaceWrapper) ResetSlowLog(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ResetSlowLog(c)
+}
+
+// GetSlowLog operation middleware
+func (siw *ServerInterfaceWrapper) GetSlowLog(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSlowLogParams
+
+	// ------------- Optional query parameter "count" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "count", c.Request.URL.Query(), &params.Count)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter count: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSlowLog(c, params)
+}
+
+// GetAllMapValues operation middleware
+func (siw *ServerInterfaceWrapper) GetAllMapValues(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAllMapValuesParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -101,20 +395,22 @@ func (siw *ServerInterfaceWrapper) GetCacheEntries(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheEntries(c, params)
+	siw.Handler.GetAllMapValues(c, params)
 }
 
-// GetCacheListNEntries operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheListNEntries(c *gin.Context) {
+// SetMapValue operation middleware
+func (siw *ServerInterfaceWrapper) SetMapValue(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "n-entries" -------------
-	var nEntries int
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetMapValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
 
-	err = runtime.BindStyledParameter("simple", false, "n-entries", c.Param("n-entries"), &nEntries)
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n-entries: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -125,29 +421,72 @@ func (siw *ServerInterfaceWrapper) GetCacheListNEntries(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheListNEntries(c, nEntries)
+	siw.Handler.SetMapValue(c, params)
 }
 
-// GetCacheListSortN operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheListSortN(c *gin.Context) {
+// GetListofMapValues operation middleware
+func (siw *ServerInterfaceWrapper) GetListofMapValues(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "sort" -------------
-	var sort GetCacheListSortNParamsSort
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetListofMapValuesParams
+
+	// ------------- Required query parameter "key" -------------
+
+	if paramValue := c.Query("key"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument key is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "key", c.Request.URL.Query(), &params.Key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
 
-	err = runtime.BindStyledParameter("simple", false, "sort", c.Param("sort"), &sort)
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetListofMapValues(c, params)
+}
+
+// GetMapEntryList operation middleware
+func (siw *ServerInterfaceWrapper) GetMapEntryList(c *gin.Context) {
+
+	var err error
+
 	// ------------- Path parameter "n" -------------
-	var n int
+	var n N
 
 	err = runtime.BindStyledParameter("simple", false, "n", c.Param("n"), &n)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMapEntryListParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -158,11 +497,24 @@ func (siw *ServerInterfaceWrapper) GetCacheListSortN(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheListSortN(c, sort, n)
+	siw.Handler.GetMapEntryList(c, n, params)
 }
 
-// GetCacheMetadata operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheMetadata(c *gin.Context) {
+// GetAllMapMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetAllMapMetadata(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAllMapMetadataParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -171,20 +523,31 @@ func (siw *ServerInterfaceWrapper) GetCacheMetadata(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheMetadata(c)
+	siw.Handler.GetAllMapMetadata(c, params)
 }
 
-// GetCacheMetadataKey operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheMetadataKey(c *gin.Context) {
+// GetMapMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetMapMetadata(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "key" -------------
-	var key string
+	var key Key
 
 	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMapMetadataParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -195,20 +558,40 @@ func (siw *ServerInterfaceWrapper) GetCacheMetadataKey(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheMetadataKey(c, key)
+	siw.Handler.GetMapMetadata(c, key, params)
 }
 
-// GetCacheKey operation middleware
-func (siw *ServerInterfaceWrapper) GetCacheKey(c *gin.Context) {
+// GetSortedMapEntries operation middleware
+func (siw *ServerInterfaceWrapper) GetSortedMapEntries(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "key" -------------
-	var key string
+	// ------------- Path parameter "sort-by" -------------
+	var sortBy GetSortedMapEntriesParamsSortBy
 
-	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	err = runtime.BindStyledParameter("simple", false, "sort-by", c.Param("sort-by"), &sortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort-by: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "n" -------------
+	var n N
+
+	err = runtime.BindStyledParameter("simple", false, "n", c.Param("n"), &n)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSortedMapEntriesParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -219,20 +602,31 @@ func (siw *ServerInterfaceWrapper) GetCacheKey(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCacheKey(c, key)
+	siw.Handler.GetSortedMapEntries(c, sortBy, n, params)
 }
 
-// PutCacheKey operation middleware
-func (siw *ServerInterfaceWrapper) PutCacheKey(c *gin.Context) {
+// DeleteMapEntry operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMapEntry(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "key" -------------
-	var key string
+	var key Key
 
 	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteMapEntryParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -243,20 +637,31 @@ func (siw *ServerInterfaceWrapper) PutCacheKey(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.PutCacheKey(c, key)
+	siw.Handler.DeleteMapEntry(c, key, params)
 }
 
-// PostCacheTimeToLive operation middleware
-func (siw *ServerInterfaceWrapper) PostCacheTimeToLive(c *gin.Context) {
+// GetMapValue operation middleware
+func (siw *ServerInterfaceWrapper) GetMapValue(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "time-to-live" -------------
-	var timeToLive int
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	err = runtime.BindStyledParameter("simple", false, "time-to-live", c.Param("time-to-live"), &timeToLive)
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMapValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter time-to-live: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -267,66 +672,3687 @@ func (siw *ServerInterfaceWrapper) PostCacheTimeToLive(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.PostCacheTimeToLive(c, timeToLive)
+	siw.Handler.GetMapValue(c, key, params)
 }
 
-// GinServerOptions provides options for the Gin server.
-type GinServerOptions struct {
-	BaseURL      string
-	Middlewares  []MiddlewareFunc
-	ErrorHandler func(*gin.Context, error, int)
-}
+// UpdateMapEntry operation middleware
+func (siw *ServerInterfaceWrapper) UpdateMapEntry(c *gin.Context) {
 
-// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
-func RegisterHandlers(router gin.IRouter, si ServerInterface) {
-	RegisterHandlersWithOptions(router, si, GinServerOptions{})
-}
+	var err error
 
-// RegisterHandlersWithOptions creates http.Handler with additional options
-func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options GinServerOptions) {
-	errorHandler := options.ErrorHandler
-	if errorHandler == nil {
-		errorHandler = func(c *gin.Context, err error, statusCode int) {
-			c.JSON(statusCode, gin.H{"msg": err.Error()})
-		}
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
 	}
 
-	wrapper := ServerInterfaceWrapper{
-		Handler:            si,
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateMapEntryParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateMapEntry(c, key, params)
+}
+
+// GetMapTimeToLive operation middleware
+func (siw *ServerInterfaceWrapper) GetMapTimeToLive(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMapTimeToLiveParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMapTimeToLive(c, key, params)
+}
+
+// SetMapTimeToLive operation middleware
+func (siw *ServerInterfaceWrapper) SetMapTimeToLive(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetMapTimeToLiveParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetMapTimeToLive(c, key, params)
+}
+
+// GetCounter operation middleware
+func (siw *ServerInterfaceWrapper) GetCounter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCounterParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCounter(c, key, params)
+}
+
+// IncrementCounter operation middleware
+func (siw *ServerInterfaceWrapper) IncrementCounter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params IncrementCounterParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.IncrementCounter(c, key, params)
+}
+
+// GetAllQueueValues operation middleware
+func (siw *ServerInterfaceWrapper) GetAllQueueValues(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAllQueueValuesParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAllQueueValues(c, params)
+}
+
+// SetQueueValue operation middleware
+func (siw *ServerInterfaceWrapper) SetQueueValue(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetQueueValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetQueueValue(c, params)
+}
+
+// GetQueueEntryList operation middleware
+func (siw *ServerInterfaceWrapper) GetQueueEntryList(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "n" -------------
+	var n N
+
+	err = runtime.BindStyledParameter("simple", false, "n", c.Param("n"), &n)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetQueueEntryListParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetQueueEntryList(c, n, params)
+}
+
+// GetAllQueueMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetAllQueueMetadata(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAllQueueMetadataParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAllQueueMetadata(c, params)
+}
+
+// GetQueueMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetQueueMetadata(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetQueueMetadataParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetQueueMetadata(c, key, params)
+}
+
+// GetQueueValue operation middleware
+func (siw *ServerInterfaceWrapper) GetQueueValue(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetQueueValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetQueueValue(c, params)
+}
+
+// PopQueueValue operation middleware
+func (siw *ServerInterfaceWrapper) PopQueueValue(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PopQueueValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PopQueueValue(c, params)
+}
+
+// GetSortedQueueEntries operation middleware
+func (siw *ServerInterfaceWrapper) GetSortedQueueEntries(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "sort-by" -------------
+	var sortBy GetSortedQueueEntriesParamsSortBy
+
+	err = runtime.BindStyledParameter("simple", false, "sort-by", c.Param("sort-by"), &sortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort-by: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "n" -------------
+	var n N
+
+	err = runtime.BindStyledParameter("simple", false, "n", c.Param("n"), &n)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter n: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSortedQueueEntriesParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSortedQueueEntries(c, sortBy, n, params)
+}
+
+// DeleteQueueValue operation middleware
+func (siw *ServerInterfaceWrapper) DeleteQueueValue(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteQueueValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteQueueValue(c, key, params)
+}
+
+// UpdateQueueValue operation middleware
+func (siw *ServerInterfaceWrapper) UpdateQueueValue(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateQueueValueParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateQueueValue(c, key, params)
+}
+
+// GetSet operation middleware
+func (siw *ServerInterfaceWrapper) GetSet(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSetParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSet(c, key, params)
+}
+
+// UpdateSet operation middleware
+func (siw *ServerInterfaceWrapper) UpdateSet(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key Key
+
+	err = runtime.BindStyledParameter("simple", false, "key", c.Param("key"), &key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateSetParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateSet(c, key, params)
+}
+
+// ListTopics operation middleware
+func (siw *ServerInterfaceWrapper) ListTopics(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTopics(c)
+}
+
+// PublishMessage operation middleware
+func (siw *ServerInterfaceWrapper) PublishMessage(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "topic" -------------
+	var topic Topic
+
+	err = runtime.BindStyledParameter("simple", false, "topic", c.Param("topic"), &topic)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topic: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PublishMessage(c, topic)
+}
+
+// ListSubscriptions operation middleware
+func (siw *ServerInterfaceWrapper) ListSubscriptions(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "topic" -------------
+	var topic Topic
+
+	err = runtime.BindStyledParameter("simple", false, "topic", c.Param("topic"), &topic)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topic: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSubscriptions(c, topic)
+}
+
+// CreateSubscription operation middleware
+func (siw *ServerInterfaceWrapper) CreateSubscription(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "topic" -------------
+	var topic Topic
+
+	err = runtime.BindStyledParameter("simple", false, "topic", c.Param("topic"), &topic)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topic: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateSubscription(c, topic)
+}
+
+// DeleteSubscription operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSubscription(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "topic" -------------
+	var topic Topic
+
+	err = runtime.BindStyledParameter("simple", false, "topic", c.Param("topic"), &topic)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topic: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "subscription" -------------
+	var subscription SubscriptionName
+
+	err = runtime.BindStyledParameter("simple", false, "subscription", c.Param("subscription"), &subscription)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter subscription: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteSubscription(c, topic, subscription)
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router gin.IRouter, si ServerInterface) {
+	RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options GinServerOptions) {
+	errorHandler := options.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, gin.H{"msg": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
 		HandlerMiddlewares: options.Middlewares,
 		ErrorHandler:       errorHandler,
 	}
 
-	router.POST(options.BaseURL+"/cache", wrapper.PostCache)
-	router.GET(options.BaseURL+"/cache/entries", wrapper.GetCacheEntries)
-	router.GET(options.BaseURL+"/cache/list/:n-entries", wrapper.GetCacheListNEntries)
-	router.GET(options.BaseURL+"/cache/list/:sort/:n", wrapper.GetCacheListSortN)
-	router.GET(options.BaseURL+"/cache/metadata", wrapper.GetCacheMetadata)
-	router.GET(options.BaseURL+"/cache/metadata/:key", wrapper.GetCacheMetadataKey)
-	router.GET(options.BaseURL+"/cache/:key", wrapper.GetCacheKey)
-	router.PUT(options.BaseURL+"/cache/:key", wrapper.PutCacheKey)
-	router.POST(options.BaseURL+"/cache/:time-to-live", wrapper.PostCacheTimeToLive)
+	router.GET(options.BaseURL+"/admin/cluster", wrapper.GetCluster)
+	router.PUT(options.BaseURL+"/admin/cluster", wrapper.ChangeCluster)
+	router.GET(options.BaseURL+"/admin/crdt", wrapper.GetCRDTStatus)
+	router.GET(options.BaseURL+"/admin/health", wrapper.GetHealth)
+	router.GET(options.BaseURL+"/admin/hotkeys", wrapper.GetHotKeys)
+	router.GET(options.BaseURL+"/admin/info", wrapper.GetInfo)
+	router.GET(options.BaseURL+"/admin/members", wrapper.ListMembers)
+	router.GET(options.BaseURL+"/admin/namespaces", wrapper.ListNamespaces)
+	router.DELETE(options.BaseURL+"/admin/namespaces/:namespace", wrapper.DeleteNamespace)
+	router.GET(options.BaseURL+"/admin/raft", wrapper.GetRaftStatus)
+	router.GET(options.BaseURL+"/admin/replication", wrapper.GetReplicationStatus)
+	router.DELETE(options.BaseURL+"/admin/slowlog", wrapper.ResetSlowLog)
+	router.GET(options.BaseURL+"/admin/slowlog", wrapper.GetSlowLog)
+	router.GET(options.BaseURL+"/cache", wrapper.GetAllMapValues)
+	router.POST(options.BaseURL+"/cache", wrapper.SetMapValue)
+	router.GET(options.BaseURL+"/cache/entries", wrapper.GetListofMapValues)
+	router.GET(options.BaseURL+"/cache/list/:n", wrapper.GetMapEntryList)
+	router.GET(options.BaseURL+"/cache/metadata", wrapper.GetAllMapMetadata)
+	router.GET(options.BaseURL+"/cache/metadata/:key", wrapper.GetMapMetadata)
+	router.GET(options.BaseURL+"/cache/sorted/:sort-by/:n", wrapper.GetSortedMapEntries)
+	router.DELETE(options.BaseURL+"/cache/:key", wrapper.DeleteMapEntry)
+	router.GET(options.BaseURL+"/cache/:key", wrapper.GetMapValue)
+	router.PUT(options.BaseURL+"/cache/:key", wrapper.UpdateMapEntry)
+	router.GET(options.BaseURL+"/cache/:key/ttl", wrapper.GetMapTimeToLive)
+	router.PUT(options.BaseURL+"/cache/:key/ttl", wrapper.SetMapTimeToLive)
+	router.GET(options.BaseURL+"/counters/:key", wrapper.GetCounter)
+	router.POST(options.BaseURL+"/counters/:key", wrapper.IncrementCounter)
+	router.GET(options.BaseURL+"/queue", wrapper.GetAllQueueValues)
+	router.POST(options.BaseURL+"/queue", wrapper.SetQueueValue)
+	router.GET(options.BaseURL+"/queue/list/:n", wrapper.GetQueueEntryList)
+	router.GET(options.BaseURL+"/queue/metadata", wrapper.GetAllQueueMetadata)
+	router.GET(options.BaseURL+"/queue/metadata/:key", wrapper.GetQueueMetadata)
+	router.GET(options.BaseURL+"/queue/peek", wrapper.GetQueueValue)
+	router.POST(options.BaseURL+"/queue/pop", wrapper.PopQueueValue)
+	router.GET(options.BaseURL+"/queue/sorted/:sort-by/:n", wrapper.GetSortedQueueEntries)
+	router.DELETE(options.BaseURL+"/queue/:key", wrapper.DeleteQueueValue)
+	router.PUT(options.BaseURL+"/queue/:key", wrapper.UpdateQueueValue)
+	router.GET(options.BaseURL+"/sets/:key", wrapper.GetSet)
+	router.POST(options.BaseURL+"/sets/:key", wrapper.UpdateSet)
+	router.GET(options.BaseURL+"/topics", wrapper.ListTopics)
+	router.POST(options.BaseURL+"/topics/:topic/messages", wrapper.PublishMessage)
+	router.GET(options.BaseURL+"/topics/:topic/subscriptions", wrapper.ListSubscriptions)
+	router.POST(options.BaseURL+"/topics/:topic/subscriptions", wrapper.CreateSubscription)
+	router.DELETE(options.BaseURL+"/topics/:topic/subscriptions/:subscription", wrapper.DeleteSubscription)
+}
+
+type BadRequestJSONResponse Error
+
+type ConflictJSONResponse Error
+
+type NotFoundJSONResponse Error
+
+type NotImplementedJSONResponse Error
+
+type UnavailableJSONResponse Error
+
+type GetClusterRequestObject struct {
+}
+
+type GetClusterResponseObject interface {
+	VisitGetClusterResponse(w http.ResponseWriter) error
+}
+
+type GetCluster200JSONResponse ClusterInfo
+
+func (response GetCluster200JSONResponse) VisitGetClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCluster501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetCluster501JSONResponse) VisitGetClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChangeClusterRequestObject struct {
+	Body *ChangeClusterJSONRequestBody
+}
+
+type ChangeClusterResponseObject interface {
+	VisitChangeClusterResponse(w http.ResponseWriter) error
+}
+
+type ChangeCluster200JSONResponse ClusterInfo
+
+func (response ChangeCluster200JSONResponse) VisitChangeClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChangeCluster400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response ChangeCluster400JSONResponse) VisitChangeClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChangeCluster501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response ChangeCluster501JSONResponse) VisitChangeClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ChangeCluster502JSONResponse Error
+
+func (response ChangeCluster502JSONResponse) VisitChangeClusterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCRDTStatusRequestObject struct {
+}
+
+type GetCRDTStatusResponseObject interface {
+	VisitGetCRDTStatusResponse(w http.ResponseWriter) error
+}
+
+type GetCRDTStatus200JSONResponse CRDTStatus
+
+func (response GetCRDTStatus200JSONResponse) VisitGetCRDTStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCRDTStatus501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetCRDTStatus501JSONResponse) VisitGetCRDTStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetHealthRequestObject struct {
+}
+
+type GetHealthResponseObject interface {
+	VisitGetHealthResponse(w http.ResponseWriter) error
+}
+
+type GetHealth200JSONResponse Health
+
+func (response GetHealth200JSONResponse) VisitGetHealthResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetHotKeysRequestObject struct {
+	Params GetHotKeysParams
+}
+
+type GetHotKeysResponseObject interface {
+	VisitGetHotKeysResponse(w http.ResponseWriter) error
+}
+
+type GetHotKeys200JSONResponse HotKeys
+
+func (response GetHotKeys200JSONResponse) VisitGetHotKeysResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetHotKeys400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetHotKeys400JSONResponse) VisitGetHotKeysResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetHotKeys501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetHotKeys501JSONResponse) VisitGetHotKeysResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetInfoRequestObject struct {
+}
+
+type GetInfoResponseObject interface {
+	VisitGetInfoResponse(w http.ResponseWriter) error
+}
+
+type GetInfo200JSONResponse Info
+
+func (response GetInfo200JSONResponse) VisitGetInfoResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListMembersRequestObject struct {
+}
+
+type ListMembersResponseObject interface {
+	VisitListMembersResponse(w http.ResponseWriter) error
+}
+
+type ListMembers200JSONResponse MemberList
+
+func (response ListMembers200JSONResponse) VisitListMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListMembers501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response ListMembers501JSONResponse) VisitListMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListNamespacesRequestObject struct {
+}
+
+type ListNamespacesResponseObject interface {
+	VisitListNamespacesResponse(w http.ResponseWriter) error
+}
+
+type ListNamespaces200JSONResponse NamespaceList
+
+func (response ListNamespaces200JSONResponse) VisitListNamespacesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteNamespaceRequestObject struct {
+	Namespace NamespaceName `json:"namespace"`
+}
+
+type DeleteNamespaceResponseObject interface {
+	VisitDeleteNamespaceResponse(w http.ResponseWriter) error
+}
+
+type DeleteNamespace204Response struct {
+}
+
+func (response DeleteNamespace204Response) VisitDeleteNamespaceResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteNamespace400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response DeleteNamespace400JSONResponse) VisitDeleteNamespaceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteNamespace404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response DeleteNamespace404JSONResponse) VisitDeleteNamespaceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRaftStatusRequestObject struct {
+}
+
+type GetRaftStatusResponseObject interface {
+	VisitGetRaftStatusResponse(w http.ResponseWriter) error
+}
+
+type GetRaftStatus200JSONResponse RaftStatus
+
+func (response GetRaftStatus200JSONResponse) VisitGetRaftStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRaftStatus501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetRaftStatus501JSONResponse) VisitGetRaftStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetReplicationStatusRequestObject struct {
+}
+
+type GetReplicationStatusResponseObject interface {
+	VisitGetReplicationStatusResponse(w http.ResponseWriter) error
+}
+
+type GetReplicationStatus200JSONResponse ReplicationStatus
+
+func (response GetReplicationStatus200JSONResponse) VisitGetReplicationStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ResetSlowLogRequestObject struct {
+}
+
+type ResetSlowLogResponseObject interface {
+	VisitResetSlowLogResponse(w http.ResponseWriter) error
+}
+
+type ResetSlowLog204Response struct {
+}
+
+func (response ResetSlowLog204Response) VisitResetSlowLogResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type GetSlowLogRequestObject struct {
+	Params GetSlowLogParams
+}
+
+type GetSlowLogResponseObject interface {
+	VisitGetSlowLogResponse(w http.ResponseWriter) error
+}
+
+type GetSlowLog200JSONResponse SlowLog
+
+func (response GetSlowLog200JSONResponse) VisitGetSlowLogResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSlowLog400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetSlowLog400JSONResponse) VisitGetSlowLogResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapValuesRequestObject struct {
+	Params GetAllMapValuesParams
+}
+
+type GetAllMapValuesResponseObject interface {
+	VisitGetAllMapValuesResponse(w http.ResponseWriter) error
+}
+
+type GetAllMapValues200JSONResponse GetEntryList
+
+func (response GetAllMapValues200JSONResponse) VisitGetAllMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapValues400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetAllMapValues400JSONResponse) VisitGetAllMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapValues503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetAllMapValues503JSONResponse) VisitGetAllMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapValueRequestObject struct {
+	Params SetMapValueParams
+	Body   *SetMapValueJSONRequestBody
+}
+
+type SetMapValueResponseObject interface {
+	VisitSetMapValueResponse(w http.ResponseWriter) error
+}
+
+type SetMapValue200JSONResponse CacheEntryResponse
+
+func (response SetMapValue200JSONResponse) VisitSetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response SetMapValue400JSONResponse) VisitSetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapValue503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response SetMapValue503JSONResponse) VisitSetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetListofMapValuesRequestObject struct {
+	Params GetListofMapValuesParams
+}
+
+type GetListofMapValuesResponseObject interface {
+	VisitGetListofMapValuesResponse(w http.ResponseWriter) error
+}
+
+type GetListofMapValues200JSONResponse GetEntryList
+
+func (response GetListofMapValues200JSONResponse) VisitGetListofMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetListofMapValues400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetListofMapValues400JSONResponse) VisitGetListofMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetListofMapValues503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetListofMapValues503JSONResponse) VisitGetListofMapValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapEntryListRequestObject struct {
+	N      N `json:"n"`
+	Params GetMapEntryListParams
+}
+
+type GetMapEntryListResponseObject interface {
+	VisitGetMapEntryListResponse(w http.ResponseWriter) error
+}
+
+type GetMapEntryList200JSONResponse GetEntryList
+
+func (response GetMapEntryList200JSONResponse) VisitGetMapEntryListResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapEntryList400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetMapEntryList400JSONResponse) VisitGetMapEntryListResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapEntryList503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetMapEntryList503JSONResponse) VisitGetMapEntryListResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapMetadataRequestObject struct {
+	Params GetAllMapMetadataParams
+}
+
+type GetAllMapMetadataResponseObject interface {
+	VisitGetAllMapMetadataResponse(w http.ResponseWriter) error
+}
+
+type GetAllMapMetadata200JSONResponse MetadataList
+
+func (response GetAllMapMetadata200JSONResponse) VisitGetAllMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapMetadata400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetAllMapMetadata400JSONResponse) VisitGetAllMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllMapMetadata503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetAllMapMetadata503JSONResponse) VisitGetAllMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapMetadataRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetMapMetadataParams
+}
+
+type GetMapMetadataResponseObject interface {
+	VisitGetMapMetadataResponse(w http.ResponseWriter) error
+}
+
+type GetMapMetadata200JSONResponse EntryMetadata
+
+func (response GetMapMetadata200JSONResponse) VisitGetMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapMetadata400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetMapMetadata400JSONResponse) VisitGetMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapMetadata404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetMapMetadata404JSONResponse) VisitGetMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapMetadata503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetMapMetadata503JSONResponse) VisitGetMapMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSortedMapEntriesRequestObject struct {
+	SortBy GetSortedMapEntriesParamsSortBy `json:"sort-by"`
+	N      N                               `json:"n"`
+	Params GetSortedMapEntriesParams
+}
+
+type GetSortedMapEntriesResponseObject interface {
+	VisitGetSortedMapEntriesResponse(w http.ResponseWriter) error
+}
+
+type GetSortedMapEntries200JSONResponse GetEntryList
+
+func (response GetSortedMapEntries200JSONResponse) VisitGetSortedMapEntriesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSortedMapEntries400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetSortedMapEntries400JSONResponse) VisitGetSortedMapEntriesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSortedMapEntries503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetSortedMapEntries503JSONResponse) VisitGetSortedMapEntriesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteMapEntryRequestObject struct {
+	Key    Key `json:"key"`
+	Params DeleteMapEntryParams
+}
+
+type DeleteMapEntryResponseObject interface {
+	VisitDeleteMapEntryResponse(w http.ResponseWriter) error
+}
+
+type DeleteMapEntry204Response struct {
+}
+
+func (response DeleteMapEntry204Response) VisitDeleteMapEntryResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteMapEntry400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response DeleteMapEntry400JSONResponse) VisitDeleteMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteMapEntry404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response DeleteMapEntry404JSONResponse) VisitDeleteMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteMapEntry503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response DeleteMapEntry503JSONResponse) VisitDeleteMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapValueRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetMapValueParams
+}
+
+type GetMapValueResponseObject interface {
+	VisitGetMapValueResponse(w http.ResponseWriter) error
+}
+
+type GetMapValue200JSONResponse GetEntry
+
+func (response GetMapValue200JSONResponse) VisitGetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetMapValue400JSONResponse) VisitGetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapValue404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetMapValue404JSONResponse) VisitGetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapValue503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetMapValue503JSONResponse) VisitGetMapValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateMapEntryRequestObject struct {
+	Key    Key `json:"key"`
+	Params UpdateMapEntryParams
+	Body   *UpdateMapEntryJSONRequestBody
+}
+
+type UpdateMapEntryResponseObject interface {
+	VisitUpdateMapEntryResponse(w http.ResponseWriter) error
+}
+
+type UpdateMapEntry200JSONResponse CacheEntryResponse
+
+func (response UpdateMapEntry200JSONResponse) VisitUpdateMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateMapEntry400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response UpdateMapEntry400JSONResponse) VisitUpdateMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateMapEntry404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response UpdateMapEntry404JSONResponse) VisitUpdateMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateMapEntry503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response UpdateMapEntry503JSONResponse) VisitUpdateMapEntryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapTimeToLiveRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetMapTimeToLiveParams
+}
+
+type GetMapTimeToLiveResponseObject interface {
+	VisitGetMapTimeToLiveResponse(w http.ResponseWriter) error
+}
+
+type GetMapTimeToLive200JSONResponse TimeToLive
+
+func (response GetMapTimeToLive200JSONResponse) VisitGetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapTimeToLive400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetMapTimeToLive400JSONResponse) VisitGetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapTimeToLive404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetMapTimeToLive404JSONResponse) VisitGetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMapTimeToLive503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response GetMapTimeToLive503JSONResponse) VisitGetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapTimeToLiveRequestObject struct {
+	Key    Key `json:"key"`
+	Params SetMapTimeToLiveParams
+	Body   *SetMapTimeToLiveJSONRequestBody
+}
+
+type SetMapTimeToLiveResponseObject interface {
+	VisitSetMapTimeToLiveResponse(w http.ResponseWriter) error
+}
+
+type SetMapTimeToLive200JSONResponse TimeToLive
+
+func (response SetMapTimeToLive200JSONResponse) VisitSetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapTimeToLive400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response SetMapTimeToLive400JSONResponse) VisitSetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapTimeToLive404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response SetMapTimeToLive404JSONResponse) VisitSetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMapTimeToLive503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response SetMapTimeToLive503JSONResponse) VisitSetMapTimeToLiveResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCounterRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetCounterParams
+}
+
+type GetCounterResponseObject interface {
+	VisitGetCounterResponse(w http.ResponseWriter) error
+}
+
+type GetCounter200JSONResponse Counter
+
+func (response GetCounter200JSONResponse) VisitGetCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCounter400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetCounter400JSONResponse) VisitGetCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCounter404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetCounter404JSONResponse) VisitGetCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetCounter501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetCounter501JSONResponse) VisitGetCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type IncrementCounterRequestObject struct {
+	Key    Key `json:"key"`
+	Params IncrementCounterParams
+	Body   *IncrementCounterJSONRequestBody
+}
+
+type IncrementCounterResponseObject interface {
+	VisitIncrementCounterResponse(w http.ResponseWriter) error
+}
+
+type IncrementCounter200JSONResponse Counter
+
+func (response IncrementCounter200JSONResponse) VisitIncrementCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type IncrementCounter400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response IncrementCounter400JSONResponse) VisitIncrementCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type IncrementCounter501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response IncrementCounter501JSONResponse) VisitIncrementCounterResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllQueueValuesRequestObject struct {
+	Params GetAllQueueValuesParams
+}
+
+type GetAllQueueValuesResponseObject interface {
+	VisitGetAllQueueValuesResponse(w http.ResponseWriter) error
+}
+
+type GetAllQueueValues200JSONResponse GetEntryList
+
+func (response GetAllQueueValues200JSONResponse) VisitGetAllQueueValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllQueueValues400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetAllQueueValues400JSONResponse) VisitGetAllQueueValuesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetQueueValueRequestObject struct {
+	Params SetQueueValueParams
+	Body   *SetQueueValueJSONRequestBody
+}
+
+type SetQueueValueResponseObject interface {
+	VisitSetQueueValueResponse(w http.ResponseWriter) error
+}
+
+type SetQueueValue200JSONResponse CacheEntryResponse
+
+func (response SetQueueValue200JSONResponse) VisitSetQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetQueueValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response SetQueueValue400JSONResponse) VisitSetQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueEntryListRequestObject struct {
+	N      N `json:"n"`
+	Params GetQueueEntryListParams
+}
+
+type GetQueueEntryListResponseObject interface {
+	VisitGetQueueEntryListResponse(w http.ResponseWriter) error
+}
+
+type GetQueueEntryList200JSONResponse GetEntryList
+
+func (response GetQueueEntryList200JSONResponse) VisitGetQueueEntryListResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueEntryList400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetQueueEntryList400JSONResponse) VisitGetQueueEntryListResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllQueueMetadataRequestObject struct {
+	Params GetAllQueueMetadataParams
+}
+
+type GetAllQueueMetadataResponseObject interface {
+	VisitGetAllQueueMetadataResponse(w http.ResponseWriter) error
+}
+
+type GetAllQueueMetadata200JSONResponse MetadataList
+
+func (response GetAllQueueMetadata200JSONResponse) VisitGetAllQueueMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAllQueueMetadata400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetAllQueueMetadata400JSONResponse) VisitGetAllQueueMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueMetadataRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetQueueMetadataParams
+}
+
+type GetQueueMetadataResponseObject interface {
+	VisitGetQueueMetadataResponse(w http.ResponseWriter) error
+}
+
+type GetQueueMetadata200JSONResponse EntryMetadata
+
+func (response GetQueueMetadata200JSONResponse) VisitGetQueueMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueMetadata400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetQueueMetadata400JSONResponse) VisitGetQueueMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueMetadata404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetQueueMetadata404JSONResponse) VisitGetQueueMetadataResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueValueRequestObject struct {
+	Params GetQueueValueParams
+}
+
+type GetQueueValueResponseObject interface {
+	VisitGetQueueValueResponse(w http.ResponseWriter) error
+}
+
+type GetQueueValue200JSONResponse GetEntry
+
+func (response GetQueueValue200JSONResponse) VisitGetQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetQueueValue400JSONResponse) VisitGetQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQueueValue404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetQueueValue404JSONResponse) VisitGetQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PopQueueValueRequestObject struct {
+	Params PopQueueValueParams
+}
+
+type PopQueueValueResponseObject interface {
+	VisitPopQueueValueResponse(w http.ResponseWriter) error
+}
+
+type PopQueueValue200JSONResponse GetEntry
+
+func (response PopQueueValue200JSONResponse) VisitPopQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PopQueueValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response PopQueueValue400JSONResponse) VisitPopQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PopQueueValue404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response PopQueueValue404JSONResponse) VisitPopQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSortedQueueEntriesRequestObject struct {
+	SortBy GetSortedQueueEntriesParamsSortBy `json:"sort-by"`
+	N      N                                 `json:"n"`
+	Params GetSortedQueueEntriesParams
+}
+
+type GetSortedQueueEntriesResponseObject interface {
+	VisitGetSortedQueueEntriesResponse(w http.ResponseWriter) error
+}
+
+type GetSortedQueueEntries200JSONResponse GetEntryList
+
+func (response GetSortedQueueEntries200JSONResponse) VisitGetSortedQueueEntriesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSortedQueueEntries400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetSortedQueueEntries400JSONResponse) VisitGetSortedQueueEntriesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteQueueValueRequestObject struct {
+	Key    Key `json:"key"`
+	Params DeleteQueueValueParams
+}
+
+type DeleteQueueValueResponseObject interface {
+	VisitDeleteQueueValueResponse(w http.ResponseWriter) error
+}
+
+type DeleteQueueValue204Response struct {
+}
+
+func (response DeleteQueueValue204Response) VisitDeleteQueueValueResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteQueueValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response DeleteQueueValue400JSONResponse) VisitDeleteQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteQueueValue404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response DeleteQueueValue404JSONResponse) VisitDeleteQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateQueueValueRequestObject struct {
+	Key    Key `json:"key"`
+	Params UpdateQueueValueParams
+	Body   *UpdateQueueValueJSONRequestBody
+}
+
+type UpdateQueueValueResponseObject interface {
+	VisitUpdateQueueValueResponse(w http.ResponseWriter) error
+}
+
+type UpdateQueueValue200JSONResponse CacheEntryResponse
+
+func (response UpdateQueueValue200JSONResponse) VisitUpdateQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateQueueValue400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response UpdateQueueValue400JSONResponse) VisitUpdateQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateQueueValue404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response UpdateQueueValue404JSONResponse) VisitUpdateQueueValueResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSetRequestObject struct {
+	Key    Key `json:"key"`
+	Params GetSetParams
+}
+
+type GetSetResponseObject interface {
+	VisitGetSetResponse(w http.ResponseWriter) error
+}
+
+type GetSet200JSONResponse Set
+
+func (response GetSet200JSONResponse) VisitGetSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSet400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response GetSet400JSONResponse) VisitGetSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSet404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response GetSet404JSONResponse) VisitGetSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSet501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response GetSet501JSONResponse) VisitGetSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateSetRequestObject struct {
+	Key    Key `json:"key"`
+	Params UpdateSetParams
+	Body   *UpdateSetJSONRequestBody
+}
+
+type UpdateSetResponseObject interface {
+	VisitUpdateSetResponse(w http.ResponseWriter) error
+}
+
+type UpdateSet200JSONResponse Set
+
+func (response UpdateSet200JSONResponse) VisitUpdateSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateSet400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response UpdateSet400JSONResponse) VisitUpdateSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateSet501JSONResponse struct{ NotImplementedJSONResponse }
+
+func (response UpdateSet501JSONResponse) VisitUpdateSetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListTopicsRequestObject struct {
+}
+
+type ListTopicsResponseObject interface {
+	VisitListTopicsResponse(w http.ResponseWriter) error
+}
+
+type ListTopics200JSONResponse TopicList
+
+func (response ListTopics200JSONResponse) VisitListTopicsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PublishMessageRequestObject struct {
+	Topic Topic `json:"topic"`
+	Body  *PublishMessageJSONRequestBody
+}
+
+type PublishMessageResponseObject interface {
+	VisitPublishMessageResponse(w http.ResponseWriter) error
+}
+
+type PublishMessage200JSONResponse PublishResult
+
+func (response PublishMessage200JSONResponse) VisitPublishMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PublishMessage400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response PublishMessage400JSONResponse) VisitPublishMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListSubscriptionsRequestObject struct {
+	Topic Topic `json:"topic"`
+}
+
+type ListSubscriptionsResponseObject interface {
+	VisitListSubscriptionsResponse(w http.ResponseWriter) error
+}
+
+type ListSubscriptions200JSONResponse SubscriptionList
+
+func (response ListSubscriptions200JSONResponse) VisitListSubscriptionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateSubscriptionRequestObject struct {
+	Topic Topic `json:"topic"`
+	Body  *CreateSubscriptionJSONRequestBody
+}
+
+type CreateSubscriptionResponseObject interface {
+	VisitCreateSubscriptionResponse(w http.ResponseWriter) error
+}
+
+type CreateSubscription201JSONResponse Subscription
+
+func (response CreateSubscription201JSONResponse) VisitCreateSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateSubscription400JSONResponse struct{ BadRequestJSONResponse }
+
+func (response CreateSubscription400JSONResponse) VisitCreateSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateSubscription409JSONResponse struct{ ConflictJSONResponse }
+
+func (response CreateSubscription409JSONResponse) VisitCreateSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteSubscriptionRequestObject struct {
+	Topic        Topic            `json:"topic"`
+	Subscription SubscriptionName `json:"subscription"`
+}
+
+type DeleteSubscriptionResponseObject interface {
+	VisitDeleteSubscriptionResponse(w http.ResponseWriter) error
+}
+
+type DeleteSubscription204Response struct {
+}
+
+func (response DeleteSubscription204Response) VisitDeleteSubscriptionResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteSubscription404JSONResponse struct{ NotFoundJSONResponse }
+
+func (response DeleteSubscription404JSONResponse) VisitDeleteSubscriptionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Show the nodes of the sharded cluster
+	// (GET /admin/cluster)
+	GetCluster(ctx context.Context, request GetClusterRequestObject) (GetClusterResponseObject, error)
+	// Change the nodes of the sharded cluster
+	// (PUT /admin/cluster)
+	ChangeCluster(ctx context.Context, request ChangeClusterRequestObject) (ChangeClusterResponseObject, error)
+	// Show the clock of this node and the synchronization with its peers in active-active mode
+	// (GET /admin/crdt)
+	GetCRDTStatus(ctx context.Context, request GetCRDTStatusRequestObject) (GetCRDTStatusResponseObject, error)
+	// Check that the server is up
+	// (GET /admin/health)
+	GetHealth(ctx context.Context, request GetHealthRequestObject) (GetHealthResponseObject, error)
+	// List the most accessed keys of the maps and the queues, the hottest first
+	// (GET /admin/hotkeys)
+	GetHotKeys(ctx context.Context, request GetHotKeysRequestObject) (GetHotKeysResponseObject, error)
+	// Summarize the uptime, configuration, sizes, memory, hit ratio and persistence and replication status of this node
+	// (GET /admin/info)
+	GetInfo(ctx context.Context, request GetInfoRequestObject) (GetInfoResponseObject, error)
+	// List the members of the gossip cluster known by this node and their states
+	// (GET /admin/members)
+	ListMembers(ctx context.Context, request ListMembersRequestObject) (ListMembersResponseObject, error)
+	// List the namespaces and their sizes
+	// (GET /admin/namespaces)
+	ListNamespaces(ctx context.Context, request ListNamespacesRequestObject) (ListNamespacesResponseObject, error)
+	// Flush and remove a namespace
+	// (DELETE /admin/namespaces/{namespace})
+	DeleteNamespace(ctx context.Context, request DeleteNamespaceRequestObject) (DeleteNamespaceResponseObject, error)
+	// Show the raft role, term and log positions of this node
+	// (GET /admin/raft)
+	GetRaftStatus(ctx context.Context, request GetRaftStatusRequestObject) (GetRaftStatusResponseObject, error)
+	// Show the replication role and, on a follower, its lag behind the leader
+	// (GET /admin/replication)
+	GetReplicationStatus(ctx context.Context, request GetReplicationStatusRequestObject) (GetReplicationStatusResponseObject, error)
+	// Empty the slow log
+	// (DELETE /admin/slowlog)
+	ResetSlowLog(ctx context.Context, request ResetSlowLogRequestObject) (ResetSlowLogResponseObject, error)
+	// List the operations that took longer than the slow log threshold, the most recent first
+	// (GET /admin/slowlog)
+	GetSlowLog(ctx context.Context, request GetSlowLogRequestObject) (GetSlowLogResponseObject, error)
+	// Get all the entries of the cache
+	// (GET /cache)
+	GetAllMapValues(ctx context.Context, request GetAllMapValuesRequestObject) (GetAllMapValuesResponseObject, error)
+	// Add an entry to the cache
+	// (POST /cache)
+	SetMapValue(ctx context.Context, request SetMapValueRequestObject) (SetMapValueResponseObject, error)
+	// Get value for list of keys
+	// (GET /cache/entries)
+	GetListofMapValues(ctx context.Context, request GetListofMapValuesRequestObject) (GetListofMapValuesResponseObject, error)
+	// Get a list of n entries from the cache
+	// (GET /cache/list/{n})
+	GetMapEntryList(ctx context.Context, request GetMapEntryListRequestObject) (GetMapEntryListResponseObject, error)
+	// Get metadata for all entries in cache
+	// (GET /cache/metadata)
+	GetAllMapMetadata(ctx context.Context, request GetAllMapMetadataRequestObject) (GetAllMapMetadataResponseObject, error)
+	// Get metadata for specific entry in cache
+	// (GET /cache/metadata/{key})
+	GetMapMetadata(ctx context.Context, request GetMapMetadataRequestObject) (GetMapMetadataResponseObject, error)
+	// Get n entries from the cache sorted by key or value
+	// (GET /cache/sorted/{sort-by}/{n})
+	GetSortedMapEntries(ctx context.Context, request GetSortedMapEntriesRequestObject) (GetSortedMapEntriesResponseObject, error)
+	// Delete an entry from the cache
+	// (DELETE /cache/{key})
+	DeleteMapEntry(ctx context.Context, request DeleteMapEntryRequestObject) (DeleteMapEntryResponseObject, error)
+	// Get an entry from the cache
+	// (GET /cache/{key})
+	GetMapValue(ctx context.Context, request GetMapValueRequestObject) (GetMapValueResponseObject, error)
+	// Update an entry in cache
+	// (PUT /cache/{key})
+	UpdateMapEntry(ctx context.Context, request UpdateMapEntryRequestObject) (UpdateMapEntryResponseObject, error)
+	// Get the remaining time to live of an entry
+	// (GET /cache/{key}/ttl)
+	GetMapTimeToLive(ctx context.Context, request GetMapTimeToLiveRequestObject) (GetMapTimeToLiveResponseObject, error)
+	// Set the time to live of an entry, 0 removes the expiration
+	// (PUT /cache/{key}/ttl)
+	SetMapTimeToLive(ctx context.Context, request SetMapTimeToLiveRequestObject) (SetMapTimeToLiveResponseObject, error)
+	// Get the value of a counter
+	// (GET /counters/{key})
+	GetCounter(ctx context.Context, request GetCounterRequestObject) (GetCounterResponseObject, error)
+	// Add to a counter, concurrent additions on different nodes are all kept
+	// (POST /counters/{key})
+	IncrementCounter(ctx context.Context, request IncrementCounterRequestObject) (IncrementCounterResponseObject, error)
+	// Get all the entries of the queue in order
+	// (GET /queue)
+	GetAllQueueValues(ctx context.Context, request GetAllQueueValuesRequestObject) (GetAllQueueValuesResponseObject, error)
+	// Push an entry to the back of the queue
+	// (POST /queue)
+	SetQueueValue(ctx context.Context, request SetQueueValueRequestObject) (SetQueueValueResponseObject, error)
+	// Get the first n entries of the queue
+	// (GET /queue/list/{n})
+	GetQueueEntryList(ctx context.Context, request GetQueueEntryListRequestObject) (GetQueueEntryListResponseObject, error)
+	// Get metadata for all entries in the queue
+	// (GET /queue/metadata)
+	GetAllQueueMetadata(ctx context.Context, request GetAllQueueMetadataRequestObject) (GetAllQueueMetadataResponseObject, error)
+	// Get metadata for the first entry with the given key in the queue
+	// (GET /queue/metadata/{key})
+	GetQueueMetadata(ctx context.Context, request GetQueueMetadataRequestObject) (GetQueueMetadataResponseObject, error)
+	// Get the entry at the front of the queue without removing it
+	// (GET /queue/peek)
+	GetQueueValue(ctx context.Context, request GetQueueValueRequestObject) (GetQueueValueResponseObject, error)
+	// Remove and return the entry at the front of the queue
+	// (POST /queue/pop)
+	PopQueueValue(ctx context.Context, request PopQueueValueRequestObject) (PopQueueValueResponseObject, error)
+	// Get the first n entries of the queue sorted by key or value
+	// (GET /queue/sorted/{sort-by}/{n})
+	GetSortedQueueEntries(ctx context.Context, request GetSortedQueueEntriesRequestObject) (GetSortedQueueEntriesResponseObject, error)
+	// Delete the entries with the given key from the queue
+	// (DELETE /queue/{key})
+	DeleteQueueValue(ctx context.Context, request DeleteQueueValueRequestObject) (DeleteQueueValueResponseObject, error)
+	// Update the value of an entry in the queue
+	// (PUT /queue/{key})
+	UpdateQueueValue(ctx context.Context, request UpdateQueueValueRequestObject) (UpdateQueueValueResponseObject, error)
+	// Get the elements of a set
+	// (GET /sets/{key})
+	GetSet(ctx context.Context, request GetSetRequestObject) (GetSetResponseObject, error)
+	// Add and remove elements of a set, an element added concurrently with its removal is kept
+	// (POST /sets/{key})
+	UpdateSet(ctx context.Context, request UpdateSetRequestObject) (UpdateSetResponseObject, error)
+	// List the topics and their subscriptions
+	// (GET /topics)
+	ListTopics(ctx context.Context, request ListTopicsRequestObject) (ListTopicsResponseObject, error)
+	// Publish a message to every subscription of the topic
+	// (POST /topics/{topic}/messages)
+	PublishMessage(ctx context.Context, request PublishMessageRequestObject) (PublishMessageResponseObject, error)
+	// List the subscriptions of a topic
+	// (GET /topics/{topic}/subscriptions)
+	ListSubscriptions(ctx context.Context, request ListSubscriptionsRequestObject) (ListSubscriptionsResponseObject, error)
+	// Subscribe the queue of a namespace to a topic
+	// (POST /topics/{topic}/subscriptions)
+	CreateSubscription(ctx context.Context, request CreateSubscriptionRequestObject) (CreateSubscriptionResponseObject, error)
+	// Remove a subscription from a topic
+	// (DELETE /topics/{topic}/subscriptions/{subscription})
+	DeleteSubscription(ctx context.Context, request DeleteSubscriptionRequestObject) (DeleteSubscriptionResponseObject, error)
+}
+
+type StrictHandlerFunc = strictgin.StrictGinHandlerFunc
+type StrictMiddlewareFunc = strictgin.StrictGinMiddlewareFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// GetCluster operation middleware
+func (sh *strictHandler) GetCluster(ctx *gin.Context) {
+	var request GetClusterRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetCluster(ctx, request.(GetClusterRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetCluster")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetClusterResponseObject); ok {
+		if err := validResponse.VisitGetClusterResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ChangeCluster operation middleware
+func (sh *strictHandler) ChangeCluster(ctx *gin.Context) {
+	var request ChangeClusterRequestObject
+
+	var body ChangeClusterJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ChangeCluster(ctx, request.(ChangeClusterRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ChangeCluster")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ChangeClusterResponseObject); ok {
+		if err := validResponse.VisitChangeClusterResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetCRDTStatus operation middleware
+func (sh *strictHandler) GetCRDTStatus(ctx *gin.Context) {
+	var request GetCRDTStatusRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetCRDTStatus(ctx, request.(GetCRDTStatusRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetCRDTStatus")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetCRDTStatusResponseObject); ok {
+		if err := validResponse.VisitGetCRDTStatusResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetHealth operation middleware
+func (sh *strictHandler) GetHealth(ctx *gin.Context) {
+	var request GetHealthRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetHealth(ctx, request.(GetHealthRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetHealth")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetHealthResponseObject); ok {
+		if err := validResponse.VisitGetHealthResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetHotKeys operation middleware
+func (sh *strictHandler) GetHotKeys(ctx *gin.Context, params GetHotKeysParams) {
+	var request GetHotKeysRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetHotKeys(ctx, request.(GetHotKeysRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetHotKeys")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetHotKeysResponseObject); ok {
+		if err := validResponse.VisitGetHotKeysResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetInfo operation middleware
+func (sh *strictHandler) GetInfo(ctx *gin.Context) {
+	var request GetInfoRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetInfo(ctx, request.(GetInfoRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetInfo")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetInfoResponseObject); ok {
+		if err := validResponse.VisitGetInfoResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListMembers operation middleware
+func (sh *strictHandler) ListMembers(ctx *gin.Context) {
+	var request ListMembersRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListMembers(ctx, request.(ListMembersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListMembers")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ListMembersResponseObject); ok {
+		if err := validResponse.VisitListMembersResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListNamespaces operation middleware
+func (sh *strictHandler) ListNamespaces(ctx *gin.Context) {
+	var request ListNamespacesRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListNamespaces(ctx, request.(ListNamespacesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListNamespaces")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ListNamespacesResponseObject); ok {
+		if err := validResponse.VisitListNamespacesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteNamespace operation middleware
+func (sh *strictHandler) DeleteNamespace(ctx *gin.Context, namespace NamespaceName) {
+	var request DeleteNamespaceRequestObject
+
+	request.Namespace = namespace
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteNamespace(ctx, request.(DeleteNamespaceRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteNamespace")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(DeleteNamespaceResponseObject); ok {
+		if err := validResponse.VisitDeleteNamespaceResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetRaftStatus operation middleware
+func (sh *strictHandler) GetRaftStatus(ctx *gin.Context) {
+	var request GetRaftStatusRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRaftStatus(ctx, request.(GetRaftStatusRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRaftStatus")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetRaftStatusResponseObject); ok {
+		if err := validResponse.VisitGetRaftStatusResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetReplicationStatus operation middleware
+func (sh *strictHandler) GetReplicationStatus(ctx *gin.Context) {
+	var request GetReplicationStatusRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetReplicationStatus(ctx, request.(GetReplicationStatusRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetReplicationStatus")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetReplicationStatusResponseObject); ok {
+		if err := validResponse.VisitGetReplicationStatusResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ResetSlowLog operation middleware
+func (sh *strictHandler) ResetSlowLog(ctx *gin.Context) {
+	var request ResetSlowLogRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ResetSlowLog(ctx, request.(ResetSlowLogRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ResetSlowLog")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ResetSlowLogResponseObject); ok {
+		if err := validResponse.VisitResetSlowLogResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetSlowLog operation middleware
+func (sh *strictHandler) GetSlowLog(ctx *gin.Context, params GetSlowLogParams) {
+	var request GetSlowLogRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetSlowLog(ctx, request.(GetSlowLogRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetSlowLog")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetSlowLogResponseObject); ok {
+		if err := validResponse.VisitGetSlowLogResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetAllMapValues operation middleware
+func (sh *strictHandler) GetAllMapValues(ctx *gin.Context, params GetAllMapValuesParams) {
+	var request GetAllMapValuesRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllMapValues(ctx, request.(GetAllMapValuesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllMapValues")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetAllMapValuesResponseObject); ok {
+		if err := validResponse.VisitGetAllMapValuesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// SetMapValue operation middleware
+func (sh *strictHandler) SetMapValue(ctx *gin.Context, params SetMapValueParams) {
+	var request SetMapValueRequestObject
+
+	request.Params = params
+
+	var body SetMapValueJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.SetMapValue(ctx, request.(SetMapValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetMapValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(SetMapValueResponseObject); ok {
+		if err := validResponse.VisitSetMapValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetListofMapValues operation middleware
+func (sh *strictHandler) GetListofMapValues(ctx *gin.Context, params GetListofMapValuesParams) {
+	var request GetListofMapValuesRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetListofMapValues(ctx, request.(GetListofMapValuesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetListofMapValues")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetListofMapValuesResponseObject); ok {
+		if err := validResponse.VisitGetListofMapValuesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetMapEntryList operation middleware
+func (sh *strictHandler) GetMapEntryList(ctx *gin.Context, n N, params GetMapEntryListParams) {
+	var request GetMapEntryListRequestObject
+
+	request.N = n
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMapEntryList(ctx, request.(GetMapEntryListRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMapEntryList")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetMapEntryListResponseObject); ok {
+		if err := validResponse.VisitGetMapEntryListResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetAllMapMetadata operation middleware
+func (sh *strictHandler) GetAllMapMetadata(ctx *gin.Context, params GetAllMapMetadataParams) {
+	var request GetAllMapMetadataRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllMapMetadata(ctx, request.(GetAllMapMetadataRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllMapMetadata")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetAllMapMetadataResponseObject); ok {
+		if err := validResponse.VisitGetAllMapMetadataResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetMapMetadata operation middleware
+func (sh *strictHandler) GetMapMetadata(ctx *gin.Context, key Key, params GetMapMetadataParams) {
+	var request GetMapMetadataRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMapMetadata(ctx, request.(GetMapMetadataRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMapMetadata")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetMapMetadataResponseObject); ok {
+		if err := validResponse.VisitGetMapMetadataResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetSortedMapEntries operation middleware
+func (sh *strictHandler) GetSortedMapEntries(ctx *gin.Context, sortBy GetSortedMapEntriesParamsSortBy, n N, params GetSortedMapEntriesParams) {
+	var request GetSortedMapEntriesRequestObject
+
+	request.SortBy = sortBy
+	request.N = n
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetSortedMapEntries(ctx, request.(GetSortedMapEntriesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetSortedMapEntries")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetSortedMapEntriesResponseObject); ok {
+		if err := validResponse.VisitGetSortedMapEntriesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteMapEntry operation middleware
+func (sh *strictHandler) DeleteMapEntry(ctx *gin.Context, key Key, params DeleteMapEntryParams) {
+	var request DeleteMapEntryRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteMapEntry(ctx, request.(DeleteMapEntryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteMapEntry")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(DeleteMapEntryResponseObject); ok {
+		if err := validResponse.VisitDeleteMapEntryResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetMapValue operation middleware
+func (sh *strictHandler) GetMapValue(ctx *gin.Context, key Key, params GetMapValueParams) {
+	var request GetMapValueRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMapValue(ctx, request.(GetMapValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMapValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetMapValueResponseObject); ok {
+		if err := validResponse.VisitGetMapValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdateMapEntry operation middleware
+func (sh *strictHandler) UpdateMapEntry(ctx *gin.Context, key Key, params UpdateMapEntryParams) {
+	var request UpdateMapEntryRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	var body UpdateMapEntryJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateMapEntry(ctx, request.(UpdateMapEntryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateMapEntry")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(UpdateMapEntryResponseObject); ok {
+		if err := validResponse.VisitUpdateMapEntryResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetMapTimeToLive operation middleware
+func (sh *strictHandler) GetMapTimeToLive(ctx *gin.Context, key Key, params GetMapTimeToLiveParams) {
+	var request GetMapTimeToLiveRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMapTimeToLive(ctx, request.(GetMapTimeToLiveRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMapTimeToLive")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetMapTimeToLiveResponseObject); ok {
+		if err := validResponse.VisitGetMapTimeToLiveResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// SetMapTimeToLive operation middleware
+func (sh *strictHandler) SetMapTimeToLive(ctx *gin.Context, key Key, params SetMapTimeToLiveParams) {
+	var request SetMapTimeToLiveRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	var body SetMapTimeToLiveJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.SetMapTimeToLive(ctx, request.(SetMapTimeToLiveRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetMapTimeToLive")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(SetMapTimeToLiveResponseObject); ok {
+		if err := validResponse.VisitSetMapTimeToLiveResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetCounter operation middleware
+func (sh *strictHandler) GetCounter(ctx *gin.Context, key Key, params GetCounterParams) {
+	var request GetCounterRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetCounter(ctx, request.(GetCounterRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetCounter")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetCounterResponseObject); ok {
+		if err := validResponse.VisitGetCounterResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// IncrementCounter operation middleware
+func (sh *strictHandler) IncrementCounter(ctx *gin.Context, key Key, params IncrementCounterParams) {
+	var request IncrementCounterRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	var body IncrementCounterJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.IncrementCounter(ctx, request.(IncrementCounterRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "IncrementCounter")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(IncrementCounterResponseObject); ok {
+		if err := validResponse.VisitIncrementCounterResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetAllQueueValues operation middleware
+func (sh *strictHandler) GetAllQueueValues(ctx *gin.Context, params GetAllQueueValuesParams) {
+	var request GetAllQueueValuesRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllQueueValues(ctx, request.(GetAllQueueValuesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllQueueValues")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetAllQueueValuesResponseObject); ok {
+		if err := validResponse.VisitGetAllQueueValuesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// SetQueueValue operation middleware
+func (sh *strictHandler) SetQueueValue(ctx *gin.Context, params SetQueueValueParams) {
+	var request SetQueueValueRequestObject
+
+	request.Params = params
+
+	var body SetQueueValueJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.SetQueueValue(ctx, request.(SetQueueValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetQueueValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(SetQueueValueResponseObject); ok {
+		if err := validResponse.VisitSetQueueValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetQueueEntryList operation middleware
+func (sh *strictHandler) GetQueueEntryList(ctx *gin.Context, n N, params GetQueueEntryListParams) {
+	var request GetQueueEntryListRequestObject
+
+	request.N = n
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetQueueEntryList(ctx, request.(GetQueueEntryListRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetQueueEntryList")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetQueueEntryListResponseObject); ok {
+		if err := validResponse.VisitGetQueueEntryListResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetAllQueueMetadata operation middleware
+func (sh *strictHandler) GetAllQueueMetadata(ctx *gin.Context, params GetAllQueueMetadataParams) {
+	var request GetAllQueueMetadataRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAllQueueMetadata(ctx, request.(GetAllQueueMetadataRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAllQueueMetadata")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetAllQueueMetadataResponseObject); ok {
+		if err := validResponse.VisitGetAllQueueMetadataResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetQueueMetadata operation middleware
+func (sh *strictHandler) GetQueueMetadata(ctx *gin.Context, key Key, params GetQueueMetadataParams) {
+	var request GetQueueMetadataRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetQueueMetadata(ctx, request.(GetQueueMetadataRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetQueueMetadata")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetQueueMetadataResponseObject); ok {
+		if err := validResponse.VisitGetQueueMetadataResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetQueueValue operation middleware
+func (sh *strictHandler) GetQueueValue(ctx *gin.Context, params GetQueueValueParams) {
+	var request GetQueueValueRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetQueueValue(ctx, request.(GetQueueValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetQueueValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetQueueValueResponseObject); ok {
+		if err := validResponse.VisitGetQueueValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PopQueueValue operation middleware
+func (sh *strictHandler) PopQueueValue(ctx *gin.Context, params PopQueueValueParams) {
+	var request PopQueueValueRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.PopQueueValue(ctx, request.(PopQueueValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PopQueueValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(PopQueueValueResponseObject); ok {
+		if err := validResponse.VisitPopQueueValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetSortedQueueEntries operation middleware
+func (sh *strictHandler) GetSortedQueueEntries(ctx *gin.Context, sortBy GetSortedQueueEntriesParamsSortBy, n N, params GetSortedQueueEntriesParams) {
+	var request GetSortedQueueEntriesRequestObject
+
+	request.SortBy = sortBy
+	request.N = n
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetSortedQueueEntries(ctx, request.(GetSortedQueueEntriesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetSortedQueueEntries")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetSortedQueueEntriesResponseObject); ok {
+		if err := validResponse.VisitGetSortedQueueEntriesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteQueueValue operation middleware
+func (sh *strictHandler) DeleteQueueValue(ctx *gin.Context, key Key, params DeleteQueueValueParams) {
+	var request DeleteQueueValueRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteQueueValue(ctx, request.(DeleteQueueValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteQueueValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(DeleteQueueValueResponseObject); ok {
+		if err := validResponse.VisitDeleteQueueValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdateQueueValue operation middleware
+func (sh *strictHandler) UpdateQueueValue(ctx *gin.Context, key Key, params UpdateQueueValueParams) {
+	var request UpdateQueueValueRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	var body UpdateQueueValueJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateQueueValue(ctx, request.(UpdateQueueValueRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateQueueValue")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(UpdateQueueValueResponseObject); ok {
+		if err := validResponse.VisitUpdateQueueValueResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetSet operation middleware
+func (sh *strictHandler) GetSet(ctx *gin.Context, key Key, params GetSetParams) {
+	var request GetSetRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetSet(ctx, request.(GetSetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetSet")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetSetResponseObject); ok {
+		if err := validResponse.VisitGetSetResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdateSet operation middleware
+func (sh *strictHandler) UpdateSet(ctx *gin.Context, key Key, params UpdateSetParams) {
+	var request UpdateSetRequestObject
+
+	request.Key = key
+	request.Params = params
+
+	var body UpdateSetJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateSet(ctx, request.(UpdateSetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateSet")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(UpdateSetResponseObject); ok {
+		if err := validResponse.VisitUpdateSetResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListTopics operation middleware
+func (sh *strictHandler) ListTopics(ctx *gin.Context) {
+	var request ListTopicsRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListTopics(ctx, request.(ListTopicsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListTopics")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ListTopicsResponseObject); ok {
+		if err := validResponse.VisitListTopicsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PublishMessage operation middleware
+func (sh *strictHandler) PublishMessage(ctx *gin.Context, topic Topic) {
+	var request PublishMessageRequestObject
+
+	request.Topic = topic
+
+	var body PublishMessageJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.PublishMessage(ctx, request.(PublishMessageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PublishMessage")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(PublishMessageResponseObject); ok {
+		if err := validResponse.VisitPublishMessageResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListSubscriptions operation middleware
+func (sh *strictHandler) ListSubscriptions(ctx *gin.Context, topic Topic) {
+	var request ListSubscriptionsRequestObject
+
+	request.Topic = topic
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListSubscriptions(ctx, request.(ListSubscriptionsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListSubscriptions")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ListSubscriptionsResponseObject); ok {
+		if err := validResponse.VisitListSubscriptionsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// CreateSubscription operation middleware
+func (sh *strictHandler) CreateSubscription(ctx *gin.Context, topic Topic) {
+	var request CreateSubscriptionRequestObject
+
+	request.Topic = topic
+
+	var body CreateSubscriptionJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateSubscription(ctx, request.(CreateSubscriptionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateSubscription")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(CreateSubscriptionResponseObject); ok {
+		if err := validResponse.VisitCreateSubscriptionResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteSubscription operation middleware
+func (sh *strictHandler) DeleteSubscription(ctx *gin.Context, topic Topic, subscription SubscriptionName) {
+	var request DeleteSubscriptionRequestObject
+
+	request.Topic = topic
+	request.Subscription = subscription
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteSubscription(ctx, request.(DeleteSubscriptionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteSubscription")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(DeleteSubscriptionResponseObject); ok {
+		if err := validResponse.VisitDeleteSubscriptionResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+yY32/bNhDH/xWC26NSeVsHbHrriiEI1gXF1gEFCj9w4tliI5EseXJrGPrfhyOtX5Fc",
-	"O83sZUDfFOV0PH4/d0eedzw3lTUaNHqe7bjPC6hEeHwp8gJ+1ei29Jd1xoJDBeF/dxBe4tYCz7hHp/Sa",
-	"NwlHVcEVmqtSbWBgoDTCGhxZbERZw8y3TcIdfKiVA8mzd2GB1niZtMbm7/eQI7npg/sDvDXawzTIYhxl",
-	"F0Qz4+8a8MBWTwz4cKit61fK49Q9aHT7R4VQhYdvHax4xr9JezbpHkw6oNLvQzgntpOQWtfToMhS6ZWh",
-	"xST43CmLymie8Revb5gHtwHHsBDISkDPtqZmpJ4TObKPCguGBbCcIuHEHEtyHiJjvQPiB85Hv989W1C8",
-	"xoIWVvGM//CMXiXcCizCptPojwQyUSiSSVBcN5Jn/LXx+HK/JO0SPP5iZACWG42gwzfC2lLl4av0vael",
-	"25x+iLBNlDImVoju+8XiDCt1uRsWHJMIBkxICZKhGSjeJPx5jGb8wY3eiFJJprStMVr9PLXauy0dCLll",
-	"8El59GT849u3cy4RnBZlmxLgnIkF5OuqElQu/IWUTGgGwe840CbZU00HWb6GGbjXgJ0qZEd54UQFCM7z",
-	"7N39uKiUmFmxO9iSraJ3H2pw1DO0qCgbYwPpywFdDckAUFds0x42rqnlGTNh1BpmcqDdZytfgPp8yunW",
-	"tCZsZWotHwX0GpCFbsZWxrFyKPWAKL1Pd/pqv25zFC3t5fYg34CQekFPsPP9WY6T5v5YXo/vwk8Ho+jw",
-	"6d6zM9V8kUak3jhs0p0+jeifxuHtSTjJ72dJgq4rOraEz3lUcHBy9afufK5cNkf+jZomPUBePif0ZOU+",
-	"AypAIQWKo+h/bw3/QyHbGEKbEmV5eSmrAxEwpafV1RqnuzvYNicr/Fs4x46X17Hz7n4dLS9ywTnKjRrR",
-	"Wm1A0wFzkFy8tGiD5wDnLeRqpfL9FWaO3WnI/o+ournn4A20U/wiZLqb5P1jKuF0q51OBfUFtP+yYWM8",
-	"52n4eLUR5RdNzc3sADfexIGBZY5WbaVAkA8ZJM5M/q8QUQ9/tgaHKjUnzIlvVAVvzCvS9JTMGEF4+G3i",
-	"60D6VAZSSoA+lcLPFfDJqpgijDDH3UUXMR9qV/KMF4g2S9PS5KIsjMfsp8ViwZtl808AAAD//x02S3km",
-	"EwAA",
+	"H4sIAAAAAAAC/+x9W3PcuJXwX0H45WHyFduSJ95Uoio/eOyZ2BXb8UpOUrVjZwtNnm4iIgEOAEpuO9rf",
+	"vnVwIUESZLNlSbZm/WKru0Hg4NxvAD8lmahqwYFrlZx8SmoqaQUapPn0F9jhf4wnJ0lNdZGkCacVJCfJ",
+	"OeySNJHwS8Mk5MmJlg2kicoKqCg+onc1DlNaMr5Nrq7S5DV+nYPKJKs1Ezjl66ZagyRiQ4BryUAlaWwt",
+	"PrtSxTirmio5eZj6VRnXsAVpl6UVqJpmEFne/0QKUeaMb4kugFS0JpTn5JcGGkhJDhvalFoRLci7xH16",
+	"l3hIf2lA7gJQ29VCEH8rYZOcJP/vqMP1kf1VHbVA4B8G4jMh9Q9TeFdC6tV6HvfAER0/Oxpd0LKB5H0a",
+	"IclZs27xYZafWDMYNrvwYft8K2qWTSypzW/XXcvM7Na5wklULbgCw9I/0PwUfmlAafyUCa6Bmz9pXZcs",
+	"o7jJo38pZJBPC5f7UUoh7VJ9BnvBL2jJcsJ43ejkKk2eCr4pWXYHS5+CEo3MgNBSAs13BD4wpRUC8Vro",
+	"n0TD89sH4keu5Y5wocnGLGgXf1HVJVTANdwBCK+FJqqpayE15GS9I7pgiiiQFyARnr9xekFZSdcl3D4w",
+	"bwsgogZpJiWZaMrcYGcNJBNVxTTCKCSpaI7fcMUUQpMavZSVjdIgSUEV4YKUQHPUnRI/VPRfQjK9M3Ll",
+	"wEAon54+e/sGQOLftcSlNbNiAAbGkUo0oKNCxhVLqjSBD1lB+RbIJdOF+boGkCmpmFKoMtmGMERxlgHk",
+	"kCcjPZMmOM9K7Xg2Xu8fBfBuLco1WwHXUtS7iYV7K22ErKhOTpKcalhpVkFsee40W3/lv52+9Pt8/vbt",
+	"G/LkzQv/GddJYvqy00Y/21k7rSrW/4LMyvjps7dnmupGjbGelSI7H8PyEjdf7NaS5aQUW5bRkpihBPek",
+	"NK1qCxtD0ufRXWai4d5sD81gmtTgfmIaKrWPgVu2uWoXolLSXWJwsEWulGq8jc6cow11Jp1oSbNzyNGS",
+	"lmAYnIMijGdlk0NOGq5ZiWjfESqBbITcCq2BJ2lkGxKMTI6XfpED12zDQPYQRRh3siOyc2XMusdTimxr",
+	"OUwRwQlcgNwRpanUMfQqKDcHMNEMoRToKJEG/GUW7HacOt4JCRAQ3U3rCR3lS5oVYDTymC/PrZ83ghXZ",
+	"b6XFqmQXERF6yypAtwh/RUwryATPVUpEp8qOCUfMEvhQM2kcvNZfO45R2HorUQcyxE7ctYlt9tQZ/+Wb",
+	"Vq3wLoHBjY4CYTX2C74R49WRPVSUo9SUXrKPpJ0Mj+k1ENab49oLkIoJPp7s7/YHP2EFqANUweoUZVw6",
+	"Q99JmBW5UHk3jOs/PErShRLhIUkdCmcw/9rj+A5QXzH+wv74cEiHoeWYBtuK83JWbaWlReZCXO6VHwvJ",
+	"C0/BMUg5lDqiiJ9U+CShOep2Laz2tZOlhMOWalQWWhDVrNEy6CQ9GHi7dAxsI/GvQNOcajqGmWYZKLUy",
+	"8MyZL/SYW1YwSEoJtQGh/UiYMoPIJfovTOPnGuAcckK1eWojBdeLuDxNMglUQ76iesZBOocduaSKXEqm",
+	"NXByWbAScOmcWSfSOPgp6txL/4yFFZ/q0cNsZLH7NMV7xquzGJ0BuwMBxxukpYSuFXDtXEf8zdoI/HEx",
+	"WIp9hDkarncaWhoi8tD4hyT1H8CYxAVcuM8cnkJFGTdphCnD2G28XXlkH7vtiwajkhYQbvaGcDR1vp9h",
+	"Bph3fLMYv5MK/6nzmaw6t1stArmILNQcpJUCcehtNe3Lr2OBDtKoQvBhzlB5acrK5Q6xmeaZeShmZttg",
+	"at5hsMMmwXTzj4BlESK8oVJ7BpY2nYGS7zMdRBfUKCVmMxBJ2iaFXJ7F560KE0YmabIW+S7pciXJ+8WR",
+	"FCZaPCxuPdKmEWOsJYG6eHoeX4wn7eAY1v4M+kB/9oa8S7/wS6YiltHnMhdHW51jfrXHdfBTx4B6DrTU",
+	"xRicpe7sjCP7XGiXCe5PPWFIf1SaVVRDx6AZoE9gpBeUN0PnsDNBWEVNsA+Qu9EY/eLMZN0YJrYacg2l",
+	"uCRsqVGdYoFz2K0Kqoox2M+pKkKDUdI1lKXJcWhFtrTZtvFkBVqyTKVohbOC5MJYYBfTthMw7VzWqCC1",
+	"SekJL77L41a0thI7lccNyWh+TXuJaMvO7cZd2DhD6VjmgtY0Y3o3Z3ILoXHfbcg/RZflouEYb59YtMC5",
+	"6WM7i0dhmeAbtsW/aJ4z3BEt3/RGjKgzCH+8K2HN4aakW89DNsmYYtLRKM4IUBVUQu72YeGVGWU2EPLO",
+	"cjS2uXfMS6mYBatB2mRjBvsme9MN9SC5JIV2/sLc06fdUP+0ybm03swy70QLTUu1vw6Ao/w6TW38N+eM",
+	"9deacLXGKlIGnklvutTzUkvVFsoezfq47uMuxrevTEAd8woyKnmL84ErSpny6W4fk6PalbBpNBBKVKNq",
+	"ltkweok2rYKY6pqS4sMyQjkXDc+G8MXk4+DU7XCqMF7gGUy6yz5fqOhliDLgGqRR/2gdl8dMdnSgwqkJ",
+	"GdIE8Q4m6M1tsFPCRu9X6k5/eCBC2vudTfNO3EuxO1yuQ+xct5lkiqd7PJwT+/OKccoLW5k4cL+PYmWW",
+	"FFC2bOlm6JR7aFMXRIsF0Hpq+R98dErLUmQY4RAcTuze1EKpVDu1Z4G1poxDjumIymzKlZ/4lqid0lD5",
+	"zdZSmEj+GlFbH9O9fYcgxglodcLNONL97M/n+NKtwYzzVkXrVQDcmDBeb40Ug3Hi5p6NC3644HCSWfjj",
+	"iP0cF8Jb01ncBgvMguebDmqqNUienCT//PnJ6r/o6uPx6k//vXr/6WH6h0dXv42p2IFPM2YeTKgYFdk3",
+	"9TPiVDC9Mo+MxemsoDKIZzA9aEJsU9cmtE0S2vzOGjZC2jhgw6RLey1L7RRML4a3CvXLAoW0l20rphQs",
+	"Xn6SyUWZg9Iry6bIXSu67fldkTKSqk1ijHcJStRMaHntbAMMtylOO5QpAlWtd8twbAE7BHM3IrZVoBb7",
+	"E/ZBckzQkiPt8XJUnuAybOUZC8OGla6uMLCCH2oJytRvKKlAKboFUjUKg3KdFegyrgELt+wCJNZwNwJT",
+	"lbSqSxvi/vMxeZc0CuTJu4S8a46Pv/+D/dflA3+Dv79LHpCnoqqpZEpwRbCzYodz4wxC+kwllvkfP07J",
+	"bx6nOO93tYQN+/C7lPz2MflONRv74f8/Jt9lgqNdU79LyeP/Id9J2DYlRcD8bn5HhLTAWqqr1AD3+8z/",
+	"/9j9Af7/x6mrDldrxh0w4X5S8u9/p+Q3ZlBNJXBdgAL1js91HNCy/OsmOfn5oM6o9+lMfi1sxer3puGv",
+	"nYMyzDHcHCTmd3JZCOWFT0IG7AKU86MME6lpkxDl4GFQOQ7UTdZ5pTDXGXXkz9wvjuK9HpYDao6t9nLP",
+	"LF3HPuVWIudQG2XWRoJLFpZ0o1c5k5BplxTor/zM/0TOAWrfrohPYdNIXzVyoQscwZSBJRqj1Kwsl61n",
+	"KexX9E0TuRR17X1Lu9ORfrZNHTlTGZXR7qBhJmdA5iE5orzTrEumilNQTRkvVFrtNZe6CuWqx8imgJKJ",
+	"mpnyWZRu2vcxzm/NDksDeGKbOaUbPdU9ZDrSIF8xnsOHSA8Mft3je1tgco95JVHReml8YZvSDl1PaSFt",
+	"cE/bxrRhHX3J6iy/mdYFU6g8cA/+G7FdCKxtxzsoV2Ef6TrpbEWXC278mXMuLuPG5S4aR6Qoe/mLjShL",
+	"cQkySZOM8pzlNhHhdh2rFSlOa1WIgzGPZolmGBAz7jjWT7WQFBpktcyHHdab8sTt3E3ikd3joYFYpAOx",
+	"HO08KuWDLGgsL80h0zGl9Y8CdAGSUOKJguzSPoBijm5z6Qt7bvG1ECVQbgViO+2NPxeXZEOx3FIwngdT",
+	"9Rdc5mkbtE1VR6eF5qVdMBTsNFze50xBYf2ImgjM2EEciW7fJZW5VeNYjbYlJxa1ghUqjSgS8GmTr+w3",
+	"FCqiMBbMrYsoDCk8l3hpaXEfio1tSUqsnTfFbGyFWdn/4uXWuKR3VssM8DLkFkgtJtwndK97C5nmVpWG",
+	"O3KdmPvlw+BqDzvvs10HOHA80JIRd661ay6DFeB6mW3bK2HhrIbwWgKthm5QT5tPypsbPUfN6ISmsOh3",
+	"uoOlOnCviJeCb/sbxL7yNQD3kt/b1MGyPsRon0ZdWwq6hq3qarOUQ2TuVxmBnXWIc2t1Tf+T/oEZ/1mh",
+	"BZGQCZn7xWKIm3P8B8Z2rD6iBvbm4O2Qc7DNdOayBSamHs4glty1Z0BiqXn3S1dH1SlR5gDHQT5MvAMg",
+	"2urRAjMBPqao3oqXru2rv5FhU9hcf/Ng8d6jE0v/zTRAjZeled5L3u536aASFzCDbjsgT4ngpeViX+lT",
+	"RBnFEPCKiesangsOBxDlKrbHUly+FNvPT/+7iSY6aVDO+VYXcxq4PZujfCbSBgARH7OQoPDU4LSWfdbY",
+	"uaxWs40ilHdroEUpxXYLedAYyzY+AFiidIfsNAKq3XQ6W+7oYS5yaIVBrM3nSZ5LUIH/wcxZJZcBwOSb",
+	"Ds87KXusySlI63pMNsjkDnkHVevjIeOfpbhU1luzZTyL9A6w1DXwoqWVoEB3J46Wx39O2fSX/gu0kWSw",
+	"2jSCTBr2shClPYUqZNuqe2AHkajHwPy1g0A1yIuKbI1mBe3P5qSkbrAHSpJa1LFV2+NIM32nHYO7donF",
+	"VfPP6nsywVsHX4SF0lhvlKjjErEwnT6Z+j2QYAtzSL6PyKWS5nOq4R7idcBe1mu5ng2e2lsJ7C8RA3PO",
+	"tl7vXNKCRuzVw/km7EWNynvNtzmHPHH8Z4pP7oAmjosWkAbhj7OOYcHl8HWY2Aecm3gSnPmS8QNTM374",
+	"/R/jReOgIez/asH4BgvAh1ZF57oYDi96Wsd4wmfhcLm6oOU1lMdruJxWG8fOSba5pQ7CPccah/LngBvv",
+	"Ckcyx50DZ+vNC9dQavmoBK3ITjQEF5E0C5yWjGaFYRWmS5zc9JeTboLgyMRJ8vDBsXUYgNOaJSfJ7x/g",
+	"V6k5J2BQeUTzivEjn786+ZRsbTzXGvoXObpZoJ92Ka7wxoXvj49v7Gh9eKQycsDe/RycQsTN/cfxw6l5",
+	"W0CPBhcTXBlVXFUUuSs5K8Rl67Z2gWlhilqkS+1pulVIYYOy5P1VmtSNjhmouqQZqPGUbiqT01TAXRKT",
+	"w2WwJeRM15CGTnRXgDRPjcc/IG+DbjZq9QwnFdtKqru6kIPjkrsEV/XAFLf7VLaHf0JCm7MnP+DhkRum",
+	"sT28eXV1NbwF5OrL8RcqhyzKY4+Oj6fm7ngsuHvkumyJj31/NzdVBBxniqCG9Lk5lNG7usJYMC2IEpXj",
+	"ooH0WKa5jvxcpa36kbme1T3drQu3yR7dKhGkPekl2t15mpvVP/ZWiF5Fwgu92vGskIKzjzb6MvaAaWUz",
+	"/mjG+oWAyqVDJ1FetAeKppDujhzdIsLdChFkn1ljyBRp6hG/QXZuzWR3DsONnN2w0P50yuSO3fmYtHdR",
+	"1s/TKSV7HgZdCaVTf2bSHo8hAhuNUBW7+xIm7pRqjzsuu/Lq/W2Sw20/Qo/n7vDPXSrDHtUxTjH0roRq",
+	"D5vllgC+yZvWqpUX438qe7dOIbQGpa3zPcsj3jubYhBjOW6RAlOW6czioacahkrE/MU+Wk1sj6+kxJ5a",
+	"adrUEPuISPHtPAXTxPxk0BacXDGfg7MrTt2NmjEmERmcP4jiEsn5yo25RXwGRyQiWMXvcUse2BtmVDur",
+	"586tUIrVrX9hGkAGOXfLukwabIOaRXC/yXsSx6+7YbeI5n5D+gymA6gnsNaNCPHBPi5Gx9Gn9u8r1yAG",
+	"GsbYeWa+fx0kCwc6P3ZVYTD6Zu7JG6vzR3M3GboizjW18KPjR/sfae+P6xPopxJzx1YtIAyE9g7MTFPG",
+	"9EPMqNSgJe4WOTRYJXadHvZU3IpHh7snUpSQEg2yMggsxZbUQrna22KVOjiGOYnPUbfGbaJ1tFgMuyM7",
+	"MomtYCQiDdGV2rqJL5antmeJbqONDNPYU6W4LMV2TiOcggLt65VL5BLHGmpCVWs2YoIf8cyCgU+5gdEc",
+	"whQhO0gWOqNBqWngkvqa2L3zSD0OYi4R4rTb8rVUYtwEhXg0UYYQ56ajxmbneI+kpC3Lpp176q5EmPM3",
+	"bRpvRo6flOUrWpsD6JGAJLbHbkhnZpJbJU/vpowZs+/TwNcNHn6//5Hwps8+Xf8MGs9hDg999nKpjkAV",
+	"rW1aT6gIUc5Ae4p8NkFuIa8W3DNyx1m18W18k7fF9m8Owwe/DFM8yTGf6sqDWsxwQyusR0EpZEpokeHF",
+	"ZkZu4/JxbpMOMWW87ybu5b0590Vp/Ohk1NbSfB/Fll0AN4jqWurxk8l4l7DRRDT6yykYe8gNgS37NJ1i",
+	"Jhx29IlfzXHTK1p3mDpY4yT3h+Rfj51oycdbW9G2re7TD+EtGvNWvT3F/pXa9d7B/Qi9/O+G4dG2Olz5",
+	"Rk7Ma5zD7svRsZoAEPPkS8l49Okcdvvk89qUtBcffS0EH9ytsIfiPX18N7mIm+cKVUPGNixzPsACxrC8",
+	"ffTJvaThap/6PjPjnRJn1/Di3asilrDJPVT2TlV8cZ0/pen7uqw9yT7DIK3CmM85erP+RXRGJI1hPXML",
+	"c35PxNlisnPh9xjpdE6HXy+i+7oUuBe66cirfUfHPVHWy0lbNxHS2v6xuxa1mw/rwz64rziudxf53hP+",
+	"skjtWGyB8Te6/Ujrco9DGPQ732+NEmwkmtOPNWHv67y+P9rHViNiezTXuvH2XvFFyujsC7HGzauj/mm5",
+	"O1ZI8yzZe1/J/dJHZ47jpvhstjU5orHce2P2R7D+lRT3W1X5XUSY4u/huwAyP+6umOJzy8heE7VvNKDt",
+	"Hjq6m97J6apF+5KPO6b1LVQ4hm8tuWt/6DA2I3SjQfZO2n6x7jksd2jRsY9pDMsaKYHrFjpzQjFnmw2Y",
+	"r20zL5VgEmj+nqk+06G2sSf45nOe/4ljvtUyl9Yl3bWHnAjZ72twpyXnSpQdqr8VKa8ZzOAx2eH7dD6b",
+	"3G9s/1S/5Limvt8busPAA1q3MraoZmTI/61qdG2J7A7p8ahUzhJoYQXI0OjXWwNi3Ckwq71uhCxzBZ3D",
+	"aLPfKf488nwr7Fy/yXRE6U4ardpsT0G2UC5ngBrgfC/Zb8ZwfrEkb/h6ur7a+jLE7BJBk5AZmopG2wDX",
+	"vo9nno72+ou4A/RG1L8GOro+7y73/VVQ9NR1fpsmcN1IvoS+s7S8Xkm19XG+FVVvoah6sEu0v1A6JvzC",
+	"UulnyPKvp1gaK3yGQWPEKLZ6Y0oEZ6tmd4/1b3WzO2ckVwPrpxeDgtg+Ba5AL0gwn4G+5x407iBGvvE1",
+	"hvcvrwzBJqjZwuK0smWfOyTvrdSy7C7uWkEsZ6kgh9w4UL9gBjk4/TfinNQoD/ut6/LvUszlrruvwExA",
+	"y/DdBeOccnf/1OTJ1rd2yG2WG9v7sWYcLgfpxFEi+2t4krV3K1e3dzOwt/mjT+b/q6P2nRvTQY99RcIr",
+	"O/BggTT7/BWmfPtvjojmPgzCSG0HXtMMD7K8ZqrgfTvtdT4h6b2I+6v+FrDB6Oa4Sck4G/DY9bnhtvTf",
+	"8OrCuYCmt5kJMesNsippCrNT9uypBLRnwURfmRwN3wK1SJge3grN4jdUdL8T9473aztEf9r/yFPBNyXL",
+	"9OgWDAPGGoLw1PBDe1jd1kGvK3hHn8KPCyLYm+Co/c5SuEr7dqcFB5hDovXuFvj8JFFf35mAdAbtOIW5",
+	"yMfipZFlcpIUWtcnR0f4Cs2yEEqf/PH4+NhszT0fuZH3yAYz5oLdzeCSAnekrqK1wWn/2Z9e/PTXKMN0",
+	"D/q82/BRz4urjYS2/K/cLW+dPxe9oMlNbXyf8czOnBypjq2tR7Gh3HSmYeJUi4C7DYyqm1i3LBS9YAlh",
+	"7B42B5SZ0m1vkZvEfJ9cvb/63wEAQ5ND+1CUAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -334,16 +4360,16 @@ var swaggerSpec = []string{
 func decodeSpec() ([]byte, error) {
 	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
 	if err != nil {
-		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
 	}
 	zr, err := gzip.NewReader(bytes.NewReader(zipped))
 	if err != nil {
-		return nil, fmt.Errorf("error decompressing spec: %s", err)
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
 	}
 	var buf bytes.Buffer
 	_, err = buf.ReadFrom(zr)
 	if err != nil {
-		return nil, fmt.Errorf("error decompressing spec: %s", err)
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
 	}
 
 	return buf.Bytes(), nil
@@ -361,7 +4387,7 @@ func decodeSpecCached() func() ([]byte, error) {
 
 // Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
 func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
-	var res = make(map[string]func() ([]byte, error))
+	res := make(map[string]func() ([]byte, error))
 	if len(pathToFile) > 0 {
 		res[pathToFile] = rawSpec
 	}
@@ -375,12 +4401,12 @@ func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
 // Externally referenced files must be embedded in the corresponding golang packages.
 // Urls can be supported but this task was out of the scope.
 func GetSwagger() (swagger *openapi3.T, err error) {
-	var resolvePath = PathToRawSpec("")
+	resolvePath := PathToRawSpec("")
 
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
 	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
-		var pathToFile = url.String()
+		pathToFile := url.String()
 		pathToFile = path.Clean(pathToFile)
 		getSpec, ok := resolvePath[pathToFile]
 		if !ok {
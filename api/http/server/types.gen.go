@@ -1,28 +1,203 @@
 // Package apiSpec provides primitives to interact with the openapi HTTP API.
 //
-// Code generated by unknown module path version unknown version DO NOT EDIT.
+// Code generated by github.com/deepmap/oapi-codegen version (devel) DO NOT EDIT.
 package apiSpec
 
-// Defines values for GetCacheListSortNParamsSort.
+import (
+	"time"
+)
+
+// Defines values for ErrorDetailIn.
+const (
+	Body     ErrorDetailIn = "body"
+	Header   ErrorDetailIn = "header"
+	Path     ErrorDetailIn = "path"
+	Query    ErrorDetailIn = "query"
+	Response ErrorDetailIn = "response"
+)
+
+// Defines values for HotKeyType.
+const (
+	HotKeyTypeMap   HotKeyType = "map"
+	HotKeyTypeQueue HotKeyType = "queue"
+)
+
+// Defines values for MemberState.
 const (
-	Asc  GetCacheListSortNParamsSort = "asc"
-	Desc GetCacheListSortNParamsSort = "desc"
+	Alive   MemberState = "alive"
+	Dead    MemberState = "dead"
+	Left    MemberState = "left"
+	Suspect MemberState = "suspect"
 )
 
+// Defines values for RaftStatusRole.
+const (
+	RaftStatusRoleCandidate RaftStatusRole = "candidate"
+	RaftStatusRoleFollower  RaftStatusRole = "follower"
+	RaftStatusRoleLeader    RaftStatusRole = "leader"
+)
+
+// Defines values for ReplicationInfoMode.
+const (
+	ReplicationInfoModeActiveActive ReplicationInfoMode = "active-active"
+	ReplicationInfoModeCluster      ReplicationInfoMode = "cluster"
+	ReplicationInfoModeFollower     ReplicationInfoMode = "follower"
+	ReplicationInfoModeLeader       ReplicationInfoMode = "leader"
+	ReplicationInfoModeRaft         ReplicationInfoMode = "raft"
+)
+
+// Defines values for ReplicationStatusRole.
+const (
+	Follower ReplicationStatusRole = "follower"
+	Leader   ReplicationStatusRole = "leader"
+)
+
+// Defines values for SlowLogEntryType.
+const (
+	SlowLogEntryTypeMap   SlowLogEntryType = "map"
+	SlowLogEntryTypeQueue SlowLogEntryType = "queue"
+)
+
+// Defines values for SortBy.
+const (
+	SortByKey   SortBy = "key"
+	SortByValue SortBy = "value"
+)
+
+// Defines values for GetSortedMapEntriesParamsSortBy.
+const (
+	GetSortedMapEntriesParamsSortByKey   GetSortedMapEntriesParamsSortBy = "key"
+	GetSortedMapEntriesParamsSortByValue GetSortedMapEntriesParamsSortBy = "value"
+)
+
+// Defines values for GetSortedQueueEntriesParamsSortBy.
+const (
+	GetSortedQueueEntriesParamsSortByKey   GetSortedQueueEntriesParamsSortBy = "key"
+	GetSortedQueueEntriesParamsSortByValue GetSortedQueueEntriesParamsSortBy = "value"
+)
+
+// CRDTPeer defines model for CRDTPeer.
+type CRDTPeer struct {
+	// Error Error of the last exchange with the peer, missing if it succeeded
+	Error *string `json:"error,omitempty"`
+
+	// LastSync When the last anti-entropy exchange with the peer succeeded
+	LastSync *time.Time `json:"last-sync,omitempty"`
+
+	// Name URL of the HTTP API of the peer
+	Name string `json:"name"`
+}
+
+// CRDTStatus defines model for CRDTStatus.
+type CRDTStatus struct {
+	// Clock Last hybrid logical clock timestamp of this node
+	Clock    string     `json:"clock"`
+	Counters int        `json:"counters"`
+	Peers    []CRDTPeer `json:"peers"`
+
+	// Registers Number of map entries tracked, deleted ones included until they are forgotten
+	Registers int `json:"registers"`
+
+	// Replica Identifier of this node in the clocks and counters, it changes on every start
+	Replica string `json:"replica"`
+
+	// Self URL of the HTTP API of this node
+	Self string `json:"self"`
+	Sets int    `json:"sets"`
+}
+
 // CacheEntry defines model for CacheEntry.
 type CacheEntry struct {
-	Key        string `json:"key"`
+	Key string `json:"key"`
+
+	// TimeToLive Time to live in seconds, omitted or 0 never expires
 	TimeToLive *int   `json:"time-to-live,omitempty"`
 	Value      string `json:"value"`
 }
 
 // CacheEntryResponse defines model for CacheEntryResponse.
 type CacheEntryResponse struct {
-	Hkey *int `json:"hkey,omitempty"`
+	Key    string `json:"key"`
+	Status string `json:"status"`
+}
+
+// ClusterInfo defines model for ClusterInfo.
+type ClusterInfo struct {
+	// Nodes URLs of the HTTP API of the nodes
+	Nodes []string `json:"nodes"`
+
+	// Self URL of the HTTP API of this node
+	Self string `json:"self"`
+
+	// Version Version of the membership, incremented on every change
+	Version uint64 `json:"version"`
+}
+
+// ClusterNodes defines model for ClusterNodes.
+type ClusterNodes struct {
+	// Nodes URLs of the HTTP API of the nodes
+	Nodes []string `json:"nodes"`
+}
+
+// Counter defines model for Counter.
+type Counter struct {
+	Key   string `json:"key"`
+	Value int64  `json:"value"`
+}
+
+// CounterIncrement defines model for CounterIncrement.
+type CounterIncrement struct {
+	// Delta Amount added to the counter, negative to subtract
+	Delta int64 `json:"delta"`
 }
 
+// EntryMetadata defines model for EntryMetadata.
+type EntryMetadata struct {
+	// AccessCount Number of reads of the value, a queue value is read when it is peeked at the front
+	AccessCount uint64 `json:"access-count"`
+
+	// CreatedAt When the key was written while it did not exist, or when the value was added to the queue
+	CreatedAt time.Time `json:"created-at"`
+	Key       string    `json:"key"`
+
+	// LastAccess When the value was last read, absent if it was never read
+	LastAccess *time.Time `json:"last-access,omitempty"`
+
+	// Size Number of bytes of the key and of the value of the entry
+	Size int64 `json:"size"`
+
+	// TimeToLive Remaining time to live in seconds, absent if the entry never expires
+	TimeToLive *float64 `json:"time-to-live,omitempty"`
+
+	// UpdatedAt When the value was last written
+	UpdatedAt time.Time `json:"updated-at"`
+
+	// Version Changes every time the value is written
+	Version uint64 `json:"version"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Details *[]ErrorDetail `json:"details,omitempty"`
+	Error   string         `json:"error"`
+}
+
+// ErrorDetail defines model for ErrorDetail.
+type ErrorDetail struct {
+	// In Part of the request or response that is invalid
+	In ErrorDetailIn `json:"in"`
+
+	// Name Name of the invalid parameter
+	Name   *string `json:"name,omitempty"`
+	Reason string  `json:"reason"`
+}
+
+// ErrorDetailIn Part of the request or response that is invalid
+type ErrorDetailIn string
+
 // GetEntry defines model for GetEntry.
 type GetEntry struct {
+	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
@@ -31,26 +206,585 @@ type GetEntryList struct {
 	Entries []CacheEntry `json:"entries"`
 }
 
-// GetCacheEntriesParams defines parameters for GetCacheEntries.
-type GetCacheEntriesParams struct {
+// Health defines model for Health.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// HotKey defines model for HotKey.
+type HotKey struct {
+	// Count Estimate of the recent accesses to the key, it may exceed the real count but is never below it
+	Count uint64 `json:"count"`
+	Key   string `json:"key"`
+
+	// KeyHash Hash of the key labelling its gauge in the metrics, which do not include the key itself
+	KeyHash   string     `json:"key-hash"`
+	Namespace string     `json:"namespace"`
+	Type      HotKeyType `json:"type"`
+}
+
+// HotKeyType defines model for HotKey.Type.
+type HotKeyType string
+
+// HotKeys defines model for HotKeys.
+type HotKeys struct {
+	// Capacity Number of hot keys tracked
+	Capacity int      `json:"capacity"`
+	Keys     []HotKey `json:"keys"`
+}
+
+// Info defines model for Info.
+type Info struct {
+	// Config Value of every flag of the server, by name
+	Config        map[string]string `json:"config"`
+	Memory        MemoryInfo        `json:"memory"`
+	Namespaces    []NamespaceStats  `json:"namespaces"`
+	Persistence   PersistenceInfo   `json:"persistence"`
+	Replication   ReplicationInfo   `json:"replication"`
+	StartedAt     time.Time         `json:"started-at"`
+	Totals        TotalsInfo        `json:"totals"`
+	UptimeSeconds float64           `json:"uptime-seconds"`
+}
+
+// Member defines model for Member.
+type Member struct {
+	// Incarnation Raised by the member to refute a suspicion
+	Incarnation uint64 `json:"incarnation"`
+
+	// Metadata Metadata announced by the member
+	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// Name URL of the HTTP API of the member
+	Name string `json:"name"`
+
+	// Since When this node saw the member enter its state
+	Since time.Time   `json:"since"`
+	State MemberState `json:"state"`
+}
+
+// MemberState defines model for Member.State.
+type MemberState string
+
+// MemberList defines model for MemberList.
+type MemberList struct {
+	Members []Member `json:"members"`
+
+	// Self URL of the HTTP API of this node
+	Self string `json:"self"`
+}
+
+// MemoryInfo defines model for MemoryInfo.
+type MemoryInfo struct {
+	// EntriesBytes Estimate of the memory held by the entries of every namespace
+	EntriesBytes int64 `json:"entries-bytes"`
+
+	// HeapBytes Bytes of allocated heap objects
+	HeapBytes uint64 `json:"heap-bytes"`
+
+	// SysBytes Bytes obtained from the operating system by the process
+	SysBytes uint64 `json:"sys-bytes"`
+}
+
+// MetadataList defines model for MetadataList.
+type MetadataList struct {
+	Entries []EntryMetadata `json:"entries"`
+}
+
+// NamespaceInfo defines model for NamespaceInfo.
+type NamespaceInfo struct {
+	MapEntries   int    `json:"map-entries"`
+	Name         string `json:"name"`
+	QueueEntries int    `json:"queue-entries"`
+}
+
+// NamespaceList defines model for NamespaceList.
+type NamespaceList struct {
+	Namespaces []NamespaceInfo `json:"namespaces"`
+}
+
+// NamespaceName defines model for NamespaceName.
+type NamespaceName = string
+
+// NamespaceStats defines model for NamespaceStats.
+type NamespaceStats struct {
+	Expirations uint64 `json:"expirations"`
+
+	// HitRatio Share of the reads that found a value, absent before the first read
+	HitRatio   *float64 `json:"hit-ratio,omitempty"`
+	Hits       uint64   `json:"hits"`
+	MapBytes   int64    `json:"map-bytes"`
+	MapEntries int      `json:"map-entries"`
+	Misses     uint64   `json:"misses"`
+	Name       string   `json:"name"`
+
+	// OldestQueueItemAgeSeconds Time spent in the queue by its oldest value, absent when the queue is empty
+	OldestQueueItemAgeSeconds *float64 `json:"oldest-queue-item-age-seconds,omitempty"`
+	QueueBytes                int64    `json:"queue-bytes"`
+	QueueEntries              int      `json:"queue-entries"`
+}
+
+// NewSubscription defines model for NewSubscription.
+type NewSubscription struct {
+	// Filter Expression a message must match to be delivered, for example key ^= "user:" && value != "". Comparisons apply to key or value with ==, !=, ^= (prefix), $= (suffix), *= (contains), =~ (regular expression) or, for numbers, <, <=, >, >=, and combine with &&, ||, ! and parentheses
+	Filter *string `json:"filter,omitempty"`
+
+	// Name Name of the subscription, defaults to the namespace
+	Name *NamespaceName `json:"name,omitempty"`
+
+	// Namespace Namespace whose queue receives the messages
+	Namespace NamespaceName `json:"namespace"`
+}
+
+// PersistenceInfo defines model for PersistenceInfo.
+type PersistenceInfo struct {
+	// ChangeSequence Sequence number of the last change
+	ChangeSequence uint64 `json:"change-sequence"`
+
+	// OldestChange Sequence number of the oldest change kept in memory
+	OldestChange uint64 `json:"oldest-change"`
+
+	// RaftDirectory Directory keeping the raft log, absent when nothing is kept
+	RaftDirectory *string `json:"raft-directory,omitempty"`
+
+	// SpillDirectory Directory receiving the changes dropped from memory, absent when they are discarded
+	SpillDirectory *string `json:"spill-directory,omitempty"`
+}
+
+// PublishResult defines model for PublishResult.
+type PublishResult struct {
+	// Delivered Number of subscriptions the message was copied to
+	Delivered int    `json:"delivered"`
+	Topic     string `json:"topic"`
+}
+
+// RaftStatus defines model for RaftStatus.
+type RaftStatus struct {
+	// AppliedIndex Index of the last entry applied to the maps
+	AppliedIndex uint64 `json:"applied-index"`
+
+	// CommitIndex Index of the last entry stored by a majority of the nodes
+	CommitIndex uint64 `json:"commit-index"`
+
+	// Id URL of the HTTP API of this node
+	Id string `json:"id"`
+
+	// LastIndex Index of the last entry of the log
+	LastIndex uint64 `json:"last-index"`
+
+	// Leader URL of the HTTP API of the leader, missing while none is known
+	Leader *string `json:"leader,omitempty"`
+
+	// Nodes URLs of the HTTP API of the nodes
+	Nodes []string       `json:"nodes"`
+	Role  RaftStatusRole `json:"role"`
+
+	// SnapshotIndex Index of the last entry compacted into the snapshot
+	SnapshotIndex uint64 `json:"snapshot-index"`
+	Term          uint64 `json:"term"`
+}
+
+// RaftStatusRole defines model for RaftStatus.Role.
+type RaftStatusRole string
+
+// ReplicationInfo defines model for ReplicationInfo.
+type ReplicationInfo struct {
+	// Connected Whether a follower is connected to its leader
+	Connected *bool `json:"connected,omitempty"`
+
+	// LagSeconds How far behind its leader a follower is
+	LagSeconds *float64 `json:"lag-seconds,omitempty"`
+	LastError  *string  `json:"last-error,omitempty"`
+
+	// Leader Leader of this node, a follower replicates it and a raft node forwards the writes to it
+	Leader *string `json:"leader,omitempty"`
+
+	// Mode How the data of this node is shared with other nodes
+	Mode ReplicationInfoMode `json:"mode"`
+
+	// Nodes Number of nodes of the cluster, raft cluster or active-active peers, this node included
+	Nodes *int `json:"nodes,omitempty"`
+}
+
+// ReplicationInfoMode How the data of this node is shared with other nodes
+type ReplicationInfoMode string
+
+// ReplicationStatus defines model for ReplicationStatus.
+type ReplicationStatus struct {
+	// AppliedSequence Sequence number on the leader of the last change applied by the follower
+	AppliedSequence *uint64 `json:"applied-sequence,omitempty"`
+
+	// Connected Whether the follower is streaming the changes of the leader
+	Connected *bool `json:"connected,omitempty"`
+
+	// LagChanges Number of changes of the leader not applied yet
+	LagChanges *uint64 `json:"lag-changes,omitempty"`
+
+	// LagSeconds How long the follower has been behind the leader
+	LagSeconds *float64 `json:"lag-seconds,omitempty"`
+
+	// LastError Why the follower was last disconnected from the leader
+	LastError *string `json:"last-error,omitempty"`
+
+	// Leader URL of the leader followed by this node
+	Leader *string `json:"leader,omitempty"`
+
+	// LeaderSequence Sequence number of the last change recorded by the leader
+	LeaderSequence *uint64               `json:"leader-sequence,omitempty"`
+	Role           ReplicationStatusRole `json:"role"`
+
+	// Sequence Sequence number of the last change recorded by this node
+	Sequence uint64 `json:"sequence"`
+}
+
+// ReplicationStatusRole defines model for ReplicationStatus.Role.
+type ReplicationStatusRole string
+
+// Set defines model for Set.
+type Set struct {
+	// Elements Elements of the set, sorted
+	Elements []string `json:"elements"`
+	Key      string   `json:"key"`
+}
+
+// SetTimeToLive defines model for SetTimeToLive.
+type SetTimeToLive struct {
+	TimeToLive int `json:"time-to-live"`
+}
+
+// SetUpdate defines model for SetUpdate.
+type SetUpdate struct {
+	Add *[]string `json:"add,omitempty"`
+
+	// Remove Elements removed, only the additions seen by this node are undone
+	Remove *[]string `json:"remove,omitempty"`
+}
+
+// SlowLog defines model for SlowLog.
+type SlowLog struct {
+	Entries []SlowLogEntry `json:"entries"`
+
+	// Length Number of operations in the log
+	Length int `json:"length"`
+
+	// ThresholdSeconds Duration from which an operation is logged, negative if none is
+	ThresholdSeconds float64 `json:"threshold-seconds"`
+}
+
+// SlowLogEntry defines model for SlowLogEntry.
+type SlowLogEntry struct {
+	// Client Address of the client, absent for the operations made by the node itself
+	Client          *string `json:"client,omitempty"`
+	DurationSeconds float64 `json:"duration-seconds"`
+
+	// Id Grows with every logged operation, it is not reset with the log
+	Id uint64 `json:"id"`
+
+	// Key Key of the operation, absent for the operations on a whole map or queue
+	Key       *string `json:"key,omitempty"`
+	Namespace string  `json:"namespace"`
+
+	// Op Operation, such as get, set, delete, push or pop
+	Op string `json:"op"`
+
+	// Timestamp When the operation started
+	Timestamp time.Time        `json:"timestamp"`
+	Type      SlowLogEntryType `json:"type"`
+}
+
+// SlowLogEntryType defines model for SlowLogEntry.Type.
+type SlowLogEntryType string
+
+// Subscription defines model for Subscription.
+type Subscription struct {
+	Filter    *string `json:"filter,omitempty"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace"`
+	Topic     string  `json:"topic"`
+}
+
+// SubscriptionList defines model for SubscriptionList.
+type SubscriptionList struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// TimeToLive defines model for TimeToLive.
+type TimeToLive struct {
+	Key string `json:"key"`
+
+	// TimeToLive Remaining time to live in seconds, -1 if the entry never expires
+	TimeToLive int `json:"time-to-live"`
+}
+
+// TopicInfo defines model for TopicInfo.
+type TopicInfo struct {
+	Name          string         `json:"name"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// TopicList defines model for TopicList.
+type TopicList struct {
+	Topics []TopicInfo `json:"topics"`
+}
+
+// TopicName defines model for TopicName.
+type TopicName = string
+
+// TotalsInfo defines model for TotalsInfo.
+type TotalsInfo struct {
+	Expirations uint64 `json:"expirations"`
+
+	// HitRatio Share of the reads that found a value, absent before the first read
+	HitRatio     *float64 `json:"hit-ratio,omitempty"`
+	Hits         uint64   `json:"hits"`
+	MapEntries   int      `json:"map-entries"`
+	Misses       uint64   `json:"misses"`
+	QueueEntries int      `json:"queue-entries"`
+}
+
+// UpdateEntry defines model for UpdateEntry.
+type UpdateEntry struct {
+	NewVal string `json:"new-val"`
+
+	// TimeToLive New time to live in seconds, 0 removes the expiration
+	TimeToLive *int `json:"time-to-live,omitempty"`
+}
+
+// Key defines model for Key.
+type Key = string
+
+// N defines model for N.
+type N = int
+
+// Namespace defines model for Namespace.
+type Namespace = NamespaceName
+
+// SortBy defines model for SortBy.
+type SortBy string
+
+// SubscriptionName defines model for SubscriptionName.
+type SubscriptionName = NamespaceName
+
+// Topic defines model for Topic.
+type Topic = TopicName
+
+// BadRequest defines model for BadRequest.
+type BadRequest = Error
+
+// Conflict defines model for Conflict.
+type Conflict = Error
+
+// NotFound defines model for NotFound.
+type NotFound = Error
+
+// NotImplemented defines model for NotImplemented.
+type NotImplemented = Error
+
+// Unavailable defines model for Unavailable.
+type Unavailable = Error
+
+// GetHotKeysParams defines parameters for GetHotKeys.
+type GetHotKeysParams struct {
+	// Count Number of keys to list, every tracked one when omitted
+	Count *int `form:"count,omitempty" json:"count,omitempty"`
+}
+
+// GetSlowLogParams defines parameters for GetSlowLog.
+type GetSlowLogParams struct {
+	// Count Number of operations to list, every logged one when omitted
+	Count *int `form:"count,omitempty" json:"count,omitempty"`
+}
+
+// GetAllMapValuesParams defines parameters for GetAllMapValues.
+type GetAllMapValuesParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// SetMapValueParams defines parameters for SetMapValue.
+type SetMapValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetListofMapValuesParams defines parameters for GetListofMapValues.
+type GetListofMapValuesParams struct {
 	// Key List of keys
 	Key []string `form:"key" json:"key"`
+
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetMapEntryListParams defines parameters for GetMapEntryList.
+type GetMapEntryListParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetAllMapMetadataParams defines parameters for GetAllMapMetadata.
+type GetAllMapMetadataParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetMapMetadataParams defines parameters for GetMapMetadata.
+type GetMapMetadataParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetSortedMapEntriesParams defines parameters for GetSortedMapEntries.
+type GetSortedMapEntriesParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetSortedMapEntriesParamsSortBy defines parameters for GetSortedMapEntries.
+type GetSortedMapEntriesParamsSortBy string
+
+// DeleteMapEntryParams defines parameters for DeleteMapEntry.
+type DeleteMapEntryParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetMapValueParams defines parameters for GetMapValue.
+type GetMapValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// UpdateMapEntryParams defines parameters for UpdateMapEntry.
+type UpdateMapEntryParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetMapTimeToLiveParams defines parameters for GetMapTimeToLive.
+type GetMapTimeToLiveParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// SetMapTimeToLiveParams defines parameters for SetMapTimeToLive.
+type SetMapTimeToLiveParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetCounterParams defines parameters for GetCounter.
+type GetCounterParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// IncrementCounterParams defines parameters for IncrementCounter.
+type IncrementCounterParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetAllQueueValuesParams defines parameters for GetAllQueueValues.
+type GetAllQueueValuesParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// SetQueueValueParams defines parameters for SetQueueValue.
+type SetQueueValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetQueueEntryListParams defines parameters for GetQueueEntryList.
+type GetQueueEntryListParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetAllQueueMetadataParams defines parameters for GetAllQueueMetadata.
+type GetAllQueueMetadataParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetQueueMetadataParams defines parameters for GetQueueMetadata.
+type GetQueueMetadataParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetQueueValueParams defines parameters for GetQueueValue.
+type GetQueueValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// PopQueueValueParams defines parameters for PopQueueValue.
+type PopQueueValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
 }
 
-// GetCacheListSortNParamsSort defines parameters for GetCacheListSortN.
-type GetCacheListSortNParamsSort string
+// GetSortedQueueEntriesParams defines parameters for GetSortedQueueEntries.
+type GetSortedQueueEntriesParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetSortedQueueEntriesParamsSortBy defines parameters for GetSortedQueueEntries.
+type GetSortedQueueEntriesParamsSortBy string
+
+// DeleteQueueValueParams defines parameters for DeleteQueueValue.
+type DeleteQueueValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// UpdateQueueValueParams defines parameters for UpdateQueueValue.
+type UpdateQueueValueParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// GetSetParams defines parameters for GetSet.
+type GetSetParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+}
 
-// PutCacheKeyJSONBody defines parameters for PutCacheKey.
-type PutCacheKeyJSONBody struct {
-	NewVal     *string `json:"new-val,omitempty"`
-	TimeToLive *int    `json:"time-to-live,omitempty"`
+// UpdateSetParams defines parameters for UpdateSet.
+type UpdateSetParams struct {
+	// Namespace Namespace holding the map and queue, defaults to "default"
+	Namespace *Namespace `form:"namespace,omitempty" json:"namespace,omitempty"`
 }
 
-// PostCacheJSONRequestBody defines body for PostCache for application/json ContentType.
-type PostCacheJSONRequestBody = CacheEntry
+// ChangeClusterJSONRequestBody defines body for ChangeCluster for application/json ContentType.
+type ChangeClusterJSONRequestBody = ClusterNodes
+
+// SetMapValueJSONRequestBody defines body for SetMapValue for application/json ContentType.
+type SetMapValueJSONRequestBody = CacheEntry
+
+// UpdateMapEntryJSONRequestBody defines body for UpdateMapEntry for application/json ContentType.
+type UpdateMapEntryJSONRequestBody = UpdateEntry
+
+// SetMapTimeToLiveJSONRequestBody defines body for SetMapTimeToLive for application/json ContentType.
+type SetMapTimeToLiveJSONRequestBody = SetTimeToLive
+
+// IncrementCounterJSONRequestBody defines body for IncrementCounter for application/json ContentType.
+type IncrementCounterJSONRequestBody = CounterIncrement
+
+// SetQueueValueJSONRequestBody defines body for SetQueueValue for application/json ContentType.
+type SetQueueValueJSONRequestBody = CacheEntry
+
+// UpdateQueueValueJSONRequestBody defines body for UpdateQueueValue for application/json ContentType.
+type UpdateQueueValueJSONRequestBody = UpdateEntry
+
+// UpdateSetJSONRequestBody defines body for UpdateSet for application/json ContentType.
+type UpdateSetJSONRequestBody = SetUpdate
 
-// PutCacheKeyJSONRequestBody defines body for PutCacheKey for application/json ContentType.
-type PutCacheKeyJSONRequestBody PutCacheKeyJSONBody
+// PublishMessageJSONRequestBody defines body for PublishMessage for application/json ContentType.
+type PublishMessageJSONRequestBody = CacheEntry
 
-// PostCacheTimeToLiveJSONRequestBody defines body for PostCacheTimeToLive for application/json ContentType.
-type PostCacheTimeToLiveJSONRequestBody = CacheEntry
+// CreateSubscriptionJSONRequestBody defines body for CreateSubscription for application/json ContentType.
+type CreateSubscriptionJSONRequestBody = NewSubscription
@@ -1,17 +1,401 @@
 package main
 
 import (
-	repositoryMap "github.com/zelta-7/cache/pkg/repository/map"
-	repositoryQueue "github.com/zelta-7/cache/pkg/repository/queue"
-	serviceMap "github.com/zelta-7/cache/pkg/service/map"
-	serviceQueue "github.com/zelta-7/cache/pkg/service/queue"
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	auditService "github.com/zelta-7/cache/pkg/service/audit"
+	changeService "github.com/zelta-7/cache/pkg/service/changes"
+	clusterService "github.com/zelta-7/cache/pkg/service/cluster"
+	crdtService "github.com/zelta-7/cache/pkg/service/crdt"
+	eventService "github.com/zelta-7/cache/pkg/service/events"
+	gossipService "github.com/zelta-7/cache/pkg/service/gossip"
+	hotkeysService "github.com/zelta-7/cache/pkg/service/hotkeys"
+	infoService "github.com/zelta-7/cache/pkg/service/info"
+	metricsService "github.com/zelta-7/cache/pkg/service/metrics"
+	monitorService "github.com/zelta-7/cache/pkg/service/monitor"
+	namespaceService "github.com/zelta-7/cache/pkg/service/namespace"
+	raftService "github.com/zelta-7/cache/pkg/service/raft"
+	replicationService "github.com/zelta-7/cache/pkg/service/replication"
+	slowlogService "github.com/zelta-7/cache/pkg/service/slowlog"
+	topicService "github.com/zelta-7/cache/pkg/service/topic"
+	"github.com/zelta-7/cache/pkg/transport"
+	"github.com/zelta-7/cache/pkg/transport/listener"
+	"github.com/zelta-7/cache/pkg/transport/memcached"
+	"github.com/zelta-7/cache/pkg/transport/resp"
+	"github.com/zelta-7/cache/pkg/transport/rpc"
+	"github.com/zelta-7/cache/pkg/transport/wire"
+	"github.com/zelta-7/cache/pkg/transport/ws"
+	"k8s.io/klog/v2"
 )
 
 func main() {
-	queueRepository := repositoryQueue.NewQueueRepo()
-	mapRepository := repositoryMap.NewMapRepo()
+	addr := flag.String("addr", ":8000", "comma separated addresses the HTTP API listens on, unix:<path> listens on a Unix domain socket")
+	sweepInterval := flag.Duration("sweep-interval", time.Second, "how often expired entries are removed")
+	grpcAddr := flag.String("grpc-addr", "", "comma separated addresses the gRPC API listens on, disabled when empty")
+	wireAddr := flag.String("wire-addr", "", "comma separated addresses the binary protocol listener listens on, disabled when empty")
+	respAddr := flag.String("resp-addr", "", "comma separated addresses the Redis protocol listener listens on, disabled when empty")
+	memcachedAddr := flag.String("memcached-addr", "", "comma separated addresses the memcached protocol listener listens on, disabled when empty")
+	memcachedNamespace := flag.String("memcached-namespace", namespaceService.DefaultNamespace, "namespace the memcached items are stored in")
+	validateResponses := flag.Bool("validate-responses", false, "check the responses against the API spec, for debugging")
+	eventHistory := flag.Int("event-history", 10000, "number of events retained for subscribers resuming from a sequence number")
+	changeHistory := flag.Int("change-history", 10000, "number of changes kept in memory for change stream consumers")
+	changeSpillDir := flag.String("change-spill-dir", "", "directory receiving the changes dropped from memory, they are discarded when empty")
+	changeSpillMaxBytes := flag.Int64("change-spill-max-bytes", 1<<30, "maximum size of the spilled changes, the oldest are removed first")
+	replicateFrom := flag.String("replicate-from", "", "URL of the HTTP API of a leader to replicate, the node then rejects the writes of its clients")
+	clusterSelf := flag.String("cluster-self", "", "URL of the HTTP API of this node in the sharded cluster, cluster mode is disabled when empty")
+	clusterNodes := flag.String("cluster-nodes", "", "comma separated URLs of the HTTP API of the initial nodes of the sharded cluster, with gossip the members joining and leaving are added and removed")
+	clusterRedirect := flag.Bool("cluster-redirect", false, "redirect the requests for keys owned by another node instead of forwarding them")
+	raftSelf := flag.String("raft-self", "", "URL of the HTTP API of this node in the raft cluster, raft mode is disabled when empty")
+	raftNodes := flag.String("raft-nodes", "", "comma separated URLs of the HTTP API of every node of the raft cluster, including this one")
+	raftDir := flag.String("raft-dir", "", "directory keeping the raft log, vote and snapshots across restarts, nothing is kept when empty")
+	raftSnapshotThreshold := flag.Uint64("raft-snapshot-threshold", raftService.DefaultSnapshotThreshold, "number of applied raft entries after which the log is compacted into a snapshot")
+	crdtSelf := flag.String("crdt-self", "", "URL of the HTTP API of this node in active-active mode, the mode is disabled when empty")
+	crdtPeers := flag.String("crdt-peers", "", "comma separated URLs of the HTTP API of the other nodes accepting writes in active-active mode")
+	crdtSyncInterval := flag.Duration("crdt-sync-interval", crdtService.DefaultSyncInterval, "how often the state is compared with every peer in active-active mode")
+	gossipSelf := flag.String("gossip-self", "", "URL of the HTTP API of this node in the gossip membership, gossip is disabled when empty")
+	gossipSeeds := flag.String("gossip-seeds", "", "comma separated URLs of the HTTP API of members to join the gossip membership through")
+	gossipMetadata := flag.String("gossip-metadata", "", "comma separated key=value pairs announced to the other members")
+	slowLogThreshold := flag.Duration("slowlog-threshold", slowlogService.DefaultThreshold, "duration from which an operation is kept in the slow log, 0 keeps every operation and a negative duration none")
+	slowLogMaxLen := flag.Int("slowlog-max-len", slowlogService.DefaultMaxLen, "number of operations kept in the slow log, the oldest are dropped first")
+	hotKeysCapacity := flag.Int("hotkeys-capacity", hotkeysService.DefaultCapacity, "number of most accessed keys tracked, 0 disables the tracking")
+	hotKeysDecayInterval := flag.Duration("hotkeys-decay-interval", hotkeysService.DefaultDecayInterval, "how often the access counts of the keys are halved so that the keys no longer accessed cool down")
+	accessLogSampleRate := flag.Float64("access-log-sample-rate", 0, "fraction of the HTTP requests logged, between 0 and 1, the requests failing with a server error are always logged")
+	auditDir := flag.String("audit-dir", "", "directory receiving the audit log of the mutations, nothing is audited when empty")
+	auditMaxSize := flag.Int64("audit-max-size", auditService.DefaultMaxSize, "size in bytes from which the audit file is rotated")
+	auditMaxAge := flag.Duration("audit-max-age", auditService.DefaultMaxAge, "age from which the audit file is rotated")
+	auditHashChain := flag.Bool("audit-hash-chain", false, "chain the audit records by hash so that an altered or removed record is detected")
+	auditPrincipalHeader := flag.String("audit-principal-header", "", "header naming the principal authenticated by a proxy in front of the node, it is trusted as is")
+	wsAllowedOrigins := flag.String("ws-allowed-origins", "", "comma separated origins allowed to open WebSocket connections besides the same origin, * allows every origin")
+	socketMode := flag.String("socket-mode", "0660", "permissions of the Unix domain sockets, in octal")
+	socketGroup := flag.Int("socket-group", -1, "numeric id of the group owning the Unix domain sockets, -1 keeps the default group")
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	mode, err := strconv.ParseUint(*socketMode, 8, 32)
+	if err != nil || fs.FileMode(mode)&^fs.ModePerm != 0 {
+		klog.ErrorS(err, "Invalid socket mode", "mode", *socketMode)
+		os.Exit(1)
+	}
+	options := listener.Options{SocketMode: fs.FileMode(mode), SocketGroup: *socketGroup}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// failed is set when a server stopped because of an error, the process then exits with a non zero status
+	var failed atomic.Bool
+	var servers sync.WaitGroup
+	serve := func(name, addresses string, serveFunc func(context.Context, net.Listener) error) {
+		for _, address := range listener.Split(addresses) {
+			l, err := listener.Listen(address, options)
+			if err != nil {
+				klog.ErrorS(err, "Error creating listener", "protocol", name, "addr", address)
+				os.Exit(1)
+			}
+			klog.InfoS("Starting server", "protocol", name, "addr", address)
+			address := address
+			servers.Add(1)
+			go func() {
+				defer servers.Done()
+				if err := serveFunc(ctx, l); err != nil {
+					klog.ErrorS(err, "Server stopped", "protocol", name, "addr", address)
+					failed.Store(true)
+					stop()
+				}
+			}()
+		}
+	}
+
+	events := eventService.NewEventService(*eventHistory)
+	changes, err := changeService.NewChangeService(changeService.Options{
+		HistorySize:    *changeHistory,
+		SpillDirectory: *changeSpillDir,
+		SpillMaxBytes:  *changeSpillMaxBytes,
+		// the events are published in the order of the change log
+		Listeners: []changeService.Listener{eventService.NewChangeListener(events)},
+	})
+	if err != nil {
+		klog.ErrorS(err, "Error creating the change log")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := changes.Close(); err != nil {
+			klog.ErrorS(err, "Error closing the change log")
+		}
+	}()
+	metrics := metricsService.NewMetricsService()
+	slowLog := slowlogService.NewSlowLogService(metrics, slowlogService.Options{Threshold: *slowLogThreshold, MaxLen: *slowLogMaxLen})
+	monitor := monitorService.NewMonitorService()
+	var hotKeys hotkeysService.HotKeysServiceInterface
+	if *hotKeysCapacity > 0 {
+		hotKeys = hotkeysService.NewHotKeysService(hotkeysService.Options{Capacity: *hotKeysCapacity, DecayInterval: *hotKeysDecayInterval})
+		go hotKeys.Run(ctx)
+	}
+	var audit auditService.AuditServiceInterface
+	if *auditDir != "" {
+		audit, err = auditService.NewAuditService(auditService.Options{Directory: *auditDir, MaxSize: *auditMaxSize, MaxAge: *auditMaxAge, HashChain: *auditHashChain})
+		if err != nil {
+			klog.ErrorS(err, "Error creating the audit log")
+			os.Exit(1)
+		}
+		defer func() {
+			if err := audit.Close(); err != nil {
+				klog.ErrorS(err, "Error closing the audit log")
+			}
+		}()
+	}
+	observers := []namespaceService.Observer{
+		metricsService.NewObserver(metrics),
+		slowlogService.NewObserver(slowLog),
+	}
+	if audit != nil {
+		observers = append(observers, auditService.NewObserver(audit))
+	}
+	if hotKeys != nil {
+		observers = append(observers, hotkeysService.NewObserver(hotKeys))
+	}
+	observers = append(observers, monitorService.NewObserver(monitor))
+	namespaces := namespaceService.NewNamespaceService(namespaceService.Options{
+		Changes:   changes,
+		Observers: observers,
+	})
+	go namespaces.Run(ctx, *sweepInterval)
+
+	config := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		config[f.Name] = f.Value.String()
+	})
+	infoOptions := infoService.Options{Config: config, SpillDirectory: *changeSpillDir}
+	if *raftSelf != "" {
+		infoOptions.RaftDirectory = *raftDir
+	}
+	info := infoService.NewInfoService(namespaces, metrics, changes, infoOptions)
+
+	if *replicateFrom != "" {
+		namespaces.SetReadOnly(true)
+		klog.InfoS("Replicating leader, writes are rejected", "leader", *replicateFrom)
+	}
+	replication := replicationService.NewReplicationService(namespaces, changes, *replicateFrom)
+	go replication.Run(ctx)
+
+	var raft raftService.RaftServiceInterface
+	if *raftSelf != "" {
+		if *replicateFrom != "" || *clusterSelf != "" {
+			klog.ErrorS(nil, "Raft mode can not be combined with replication or cluster mode")
+			os.Exit(1)
+		}
+		raft, err = raftService.NewRaftService(namespaces, raftService.Options{
+			Self:              *raftSelf,
+			Nodes:             listener.Split(*raftNodes),
+			Directory:         *raftDir,
+			SnapshotThreshold: *raftSnapshotThreshold,
+		})
+		if err != nil {
+			klog.ErrorS(err, "Error creating the raft node")
+			os.Exit(1)
+		}
+		defer func() {
+			if err := raft.Close(); err != nil {
+				klog.ErrorS(err, "Error closing the raft log")
+			}
+		}()
+		namespaces.WrapMaps(raft.WrapMap)
+		klog.InfoS("Starting in raft mode", "self", *raftSelf, "nodes", *raftNodes)
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			raft.Run(ctx)
+		}()
+	}
+
+	var crdt crdtService.CRDTServiceInterface
+	if *crdtSelf != "" {
+		if *replicateFrom != "" || *clusterSelf != "" || *raftSelf != "" {
+			klog.ErrorS(nil, "Active-active mode can not be combined with replication, cluster or raft mode")
+			os.Exit(1)
+		}
+		crdt, err = crdtService.NewCRDTService(namespaces, crdtService.Options{
+			Self:         *crdtSelf,
+			Peers:        listener.Split(*crdtPeers),
+			SyncInterval: *crdtSyncInterval,
+		})
+		if err != nil {
+			klog.ErrorS(err, "Invalid active-active configuration")
+			os.Exit(1)
+		}
+		namespaces.WrapMaps(crdt.WrapMap)
+		klog.InfoS("Starting in active-active mode", "self", *crdtSelf, "peers", *crdtPeers)
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			crdt.Run(ctx)
+		}()
+	}
+
+	var gossip gossipService.GossipServiceInterface
+	if *gossipSelf != "" {
+		metadata := make(map[string]string)
+		for _, pair := range listener.Split(*gossipMetadata) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				klog.ErrorS(nil, "Invalid gossip metadata, it must be key=value", "metadata", pair)
+				os.Exit(1)
+			}
+			metadata[key] = value
+		}
+		gossip, err = gossipService.NewGossipService(gossipService.Options{
+			Self:     *gossipSelf,
+			Seeds:    listener.Split(*gossipSeeds),
+			Metadata: metadata,
+		})
+		if err != nil {
+			klog.ErrorS(err, "Invalid gossip configuration")
+			os.Exit(1)
+		}
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			gossip.Run(ctx)
+		}()
+	}
+
+	var cluster clusterService.ClusterServiceInterface
+	if *clusterSelf != "" {
+		cluster, err = clusterService.NewClusterService(namespaces, *clusterSelf, listener.Split(*clusterNodes))
+		if err != nil {
+			klog.ErrorS(err, "Invalid cluster configuration")
+			os.Exit(1)
+		}
+		klog.InfoS("Starting in cluster mode", "self", cluster.Self(), "nodes", cluster.Members().Nodes)
+		// the frontends other than HTTP reject the keys owned by another node
+		namespaces.SetRouter(cluster)
+		go cluster.Run(ctx)
+
+		if gossip != nil {
+			if gossip.Self() != cluster.Self() {
+				klog.ErrorS(nil, "The gossip and the cluster must name this node the same", "gossipSelf", gossip.Self(), "clusterSelf", cluster.Self())
+				os.Exit(1)
+			}
+			// the suspect members stay in the ring until they are declared
+			// dead so that a slow probe does not migrate their keys back and forth
+			go cluster.Follow(ctx, func() (alive, gone []string) {
+				for _, member := range gossip.Members() {
+					switch member.State {
+					case gossipService.StateAlive, gossipService.StateSuspect:
+						alive = append(alive, member.Name)
+					case gossipService.StateDead, gossipService.StateLeft:
+						gone = append(gone, member.Name)
+					}
+				}
+				return alive, gone
+			})
+		}
+	}
+
+	serve("gRPC", *grpcAddr, rpc.NewServer(namespaces).Serve)
+	serve("binary", *wireAddr, wire.NewServer(namespaces).Serve)
+	serve("Redis", *respAddr, resp.NewServer(namespaces).Serve)
+	if *memcachedAddr != "" {
+		if err := namespaceService.ValidateName(*memcachedNamespace); err != nil {
+			klog.ErrorS(err, "Invalid memcached namespace")
+			os.Exit(1)
+		}
+		serve("memcached", *memcachedAddr, memcached.NewServer(namespaces, *memcachedNamespace).Serve)
+	}
+
+	topics := topicService.NewTopicService(namespaces)
+	cacheHandler := transport.NewCacheHandler(namespaces, transport.HandlerOptions{
+		Topics:      topics,
+		Replication: replication,
+		Cluster:     cluster,
+		Raft:        raft,
+		Gossip:      gossip,
+		CRDT:        crdt,
+		Info:        info,
+		SlowLog:     slowLog,
+		HotKeys:     hotKeys,
+	})
+
+	swagger, err := apiSpec.GetSwagger()
+	if err != nil {
+		klog.ErrorS(err, "Error loading the API spec")
+		os.Exit(1)
+	}
+	validator, err := transport.NewValidationMiddleware(swagger, transport.ValidationOptions{ValidateResponses: *validateResponses})
+	if err != nil {
+		klog.ErrorS(err, "Error creating the validation middleware")
+		os.Exit(1)
+	}
+
+	router := gin.New()
+	// the handlers get the client address and the request ID from the request context
+	router.ContextWithFallback = true
+	router.Use(gin.Recovery(), transport.NewRequestIDMiddleware(), transport.NewAccessLogMiddleware(transport.AccessLogOptions{SampleRate: *accessLogSampleRate}), transport.NewMetricsMiddleware(metrics), transport.NewClientMiddleware(), transport.NewReadOnlyMiddleware(namespaces))
+	if *auditPrincipalHeader != "" {
+		router.Use(transport.NewPrincipalMiddleware(*auditPrincipalHeader))
+	}
+	if cluster != nil {
+		router.Use(transport.NewClusterMiddleware(cluster, transport.ClusterOptions{Redirect: *clusterRedirect}))
+		transport.RegisterCluster(router, cluster)
+	}
+	if raft != nil {
+		transport.RegisterRaft(router, raft)
+	}
+	if gossip != nil {
+		transport.RegisterGossip(router, gossip)
+	}
+	if crdt != nil {
+		transport.RegisterCRDT(router, crdt)
+	}
+	router.Use(validator)
+	if err := transport.RegisterDocs(router, swagger); err != nil {
+		klog.ErrorS(err, "Error registering the API docs")
+		os.Exit(1)
+	}
+	transport.RegisterEvents(router, events)
+	transport.RegisterChanges(router, changes)
+	transport.RegisterMonitor(router, monitor)
+	transport.RegisterMetrics(router, namespaces, metrics, hotKeys)
+	transport.RegisterReplication(router, replication)
+	router.GET("/ws", ws.NewHandler(namespaces, events, ws.Options{AllowedOrigins: listener.Split(*wsAllowedOrigins)}))
+	apiSpec.RegisterHandlersWithOptions(router, apiSpec.NewStrictHandler(cacheHandler, nil), apiSpec.GinServerOptions{
+		ErrorHandler: func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, apiSpec.Error{Error: err.Error()})
+		},
+	})
 
-	queueService := serviceQueue.NewQueueService(queueRepository)
-	mapService := serviceMap.NewMapService(mapRepository)
+	server := &http.Server{Handler: router}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Error shutting down the HTTP server")
+		}
+	}()
+	serve("HTTP", *addr, func(ctx context.Context, l net.Listener) error {
+		if err := server.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
 
+	servers.Wait()
+	if failed.Load() {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// binary is the cache server built once for the tests running it as separate processes
+var binary string
+
+func TestMain(m *testing.M) {
+	directory, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(directory, "cache")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "building the server:", err)
+		os.RemoveAll(directory)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(directory)
+	os.Exit(code)
+}
+
+// freeAddress returns a local address no listener uses
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// startServer runs the server listening on addr with the given flags until
+// the test ends and returns the URL of its HTTP API once it is healthy
+func startServer(t *testing.T, addr string, args ...string) string {
+	t.Helper()
+
+	server := exec.Command(binary, append([]string{"-addr", addr}, args...)...)
+	if err := server.Start(); err != nil {
+		t.Fatalf("starting the server: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Process.Kill()
+		server.Wait()
+	})
+
+	url := "http://" + addr
+	eventually(t, "the server did not become healthy", func() bool {
+		response, err := http.Get(url + "/admin/health")
+		if err != nil {
+			return false
+		}
+		response.Body.Close()
+		return response.StatusCode == http.StatusOK
+	})
+	return url
+}
+
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal(message)
+}
+
+// setKey sets a map key over HTTP and returns the status code
+func setKey(t *testing.T, url, key, value string) int {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"key":%q,"value":%q}`, key, value)
+	response, err := http.Post(url+"/cache", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("setting %s: %v", key, err)
+	}
+	response.Body.Close()
+	return response.StatusCode
+}
+
+// getKey returns the value of a map key read over HTTP and whether it was found
+func getKey(t *testing.T, url, key string) (string, bool) {
+	t.Helper()
+
+	response, err := http.Get(url + "/cache/" + key)
+	if err != nil {
+		t.Fatalf("getting %s: %v", key, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", false
+	}
+	var entry struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&entry); err != nil {
+		t.Fatalf("decoding %s: %v", key, err)
+	}
+	return entry.Value, true
+}
+
+func TestFollowerProcessReplicatesTheLeaderProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the server as separate processes")
+	}
+
+	leader := startServer(t, freeAddress(t))
+	if status := setKey(t, leader, "before", "snapshot"); status != http.StatusOK && status != http.StatusCreated {
+		t.Fatalf("setting a key on the leader returned %d", status)
+	}
+	follower := startServer(t, freeAddress(t), "-replicate-from", leader)
+
+	eventually(t, "the follower did not load the snapshot of the leader", func() bool {
+		value, ok := getKey(t, follower, "before")
+		return ok && value == "snapshot"
+	})
+	setKey(t, leader, "after", "streamed")
+	eventually(t, "the follower did not apply the changes streamed by the leader", func() bool {
+		value, ok := getKey(t, follower, "after")
+		return ok && value == "streamed"
+	})
+	if status := setKey(t, follower, "rejected", "write"); status != http.StatusForbidden {
+		t.Errorf("setting a key on the follower returned %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// mapEntries returns the number of map entries stored on the node in every namespace
+func mapEntries(t *testing.T, url string) int {
+	t.Helper()
+
+	response, err := http.Get(url + "/admin/namespaces")
+	if err != nil {
+		t.Fatalf("listing the namespaces of %s: %v", url, err)
+	}
+	defer response.Body.Close()
+	var list struct {
+		Namespaces []struct {
+			MapEntries int `json:"map-entries"`
+		} `json:"namespaces"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&list); err != nil {
+		t.Fatalf("decoding the namespaces of %s: %v", url, err)
+	}
+	total := 0
+	for _, namespace := range list.Namespaces {
+		total += namespace.MapEntries
+	}
+	return total
+}
+
+func TestClusterProcessesRebalanceOnMembershipChange(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the server as separate processes")
+	}
+
+	addresses := []string{freeAddress(t), freeAddress(t), freeAddress(t)}
+	first := "http://" + addresses[0]
+	var urls []string
+	for _, addr := range addresses {
+		urls = append(urls, startServer(t, addr, "-cluster-self", "http://"+addr, "-cluster-nodes", first))
+	}
+
+	for i := 0; i < 60; i++ {
+		if status := setKey(t, urls[0], fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)); status != http.StatusOK && status != http.StatusCreated {
+			t.Fatalf("setting key-%d returned %d", i, status)
+		}
+	}
+	body := fmt.Sprintf(`{"nodes":[%q,%q,%q]}`, urls[0], urls[1], urls[2])
+	request, err := http.NewRequest(http.MethodPut, urls[0]+"/admin/cluster", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("creating the membership change: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("changing the membership: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("changing the membership returned %s", response.Status)
+	}
+
+	eventually(t, "the entries were not spread over the nodes", func() bool {
+		total := 0
+		for _, url := range urls {
+			entries := mapEntries(t, url)
+			if entries == 0 {
+				return false
+			}
+			total += entries
+		}
+		return total == 60
+	})
+	for i := 0; i < 60; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if value, ok := getKey(t, urls[2], key); !ok || value != fmt.Sprintf("value-%d", i) {
+			t.Errorf("reading %s through the third node returned %q, %v", key, value, ok)
+		}
+	}
+}
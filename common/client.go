@@ -0,0 +1,19 @@
+package common
+
+import "context"
+
+// clientKey is the key of the client address in a context
+type clientKey struct{}
+
+// WithClient returns a copy of ctx carrying the address of the client the
+// operations are run for
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// Client returns the address of the client carried by ctx, empty if the
+// operations are not run for a client
+func Client(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
@@ -0,0 +1,18 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeNode checks that node is the URL of the HTTP API of a node and
+// returns it without trailing slash, so that the same node is always
+// spelled the same by the cluster, the gossip and the active-active mode
+func NormalizeNode(node string) (string, error) {
+	u, err := url.Parse(node)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid node %q, it must be the URL of the HTTP API of a node", node)
+	}
+	return strings.TrimRight(node, "/"), nil
+}
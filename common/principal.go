@@ -0,0 +1,19 @@
+package common
+
+import "context"
+
+// principalKey is the key of the authenticated principal in a context
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated identity
+// of the client the operations are run for
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the authenticated identity carried by ctx, empty if the
+// client was not authenticated
+func Principal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
@@ -0,0 +1,23 @@
+package common
+
+import "context"
+
+// RequestIDHeader carries the ID of a request, it is kept when a request is
+// forwarded to another node and sent back in the response
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the key of the request ID in a context
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request the
+// operations are run for
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request carried by ctx, empty if the
+// operations are not run for a request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -1,64 +1,485 @@
 package repository
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Condition restricts when Store writes an entry
+type Condition int
+
+const (
+	// Always writes the entry whether or not the key exists
+	Always Condition = iota
+	// IfAbsent writes the entry only if the key does not exist
+	IfAbsent
+	// IfPresent writes the entry only if the key exists
+	IfPresent
+	// IfVersion writes the entry only if the key exists with the version of the entry
+	IfVersion
+)
+
+var (
+	// ErrExists is returned when an IfAbsent write finds the key
+	ErrExists = errors.New("key already exists")
+	// ErrNotFound is returned when a write requires a key that does not exist
+	ErrNotFound = errors.New("key not found")
+	// ErrVersionMismatch is returned when an IfVersion write finds another version
+	ErrVersionMismatch = errors.New("version mismatch")
 )
 
 type MapRepoInter interface {
 	// Set sets the value of the key
 	Set(key, value string)
 
-	// Get returns the value of the key
-	Get(key string) string
+	// SetWithTTL sets the value of the key and expires it after ttl, zero never expires
+	SetWithTTL(key, value string, ttl time.Duration)
+
+	// Get returns the value of the key and whether it was found
+	Get(key string) (string, bool)
+
+	// GetEntry returns the entry of the key and whether it was found
+	GetEntry(key string) (CacheEntry, bool)
+
+	// ReadEntry returns the entry of the key like GetEntry and counts the read like Get
+	ReadEntry(key string) (CacheEntry, bool)
+
+	// Store writes the key, value, flags and expiration of the entry if the condition holds and returns the stored entry
+	Store(entry CacheEntry, condition Condition) (CacheEntry, error)
+
+	// Modify replaces the value of an existing key with the result of fn, keeping its flags and expiration
+	Modify(key string, fn func(value string) (string, error)) (CacheEntry, error)
+
+	// UpdateValue updates the value of an existing key and reports whether it was found
+	UpdateValue(key, newValue string) bool
+
+	// Delete removes the key and reports whether it was found
+	Delete(key string) bool
+
+	// DeleteVersion removes the key only if it still has version and reports whether it was removed
+	DeleteVersion(key string, version uint64) bool
+
+	// Expire sets the time to live of an existing key, zero removes the expiration
+	Expire(key string, ttl time.Duration) bool
+
+	// TTL returns the remaining time to live of the key, zero if it never expires
+	TTL(key string) (time.Duration, bool)
 
-	// UpdateValue updates the value of the key
-	UpdateValue(key, newValue string) string
+	// DeleteExpired removes the expired entries and returns their keys
+	DeleteExpired() []string
 
 	// All return all the entries in the map
-	All() map[string]string
+	All() []CacheEntry
+
+	// Metadata returns the metadata of the entry of the key and whether it was found
+	Metadata(key string) (Metadata, bool)
+
+	// AllMetadata returns the metadata of all the entries in the map
+	AllMetadata() []Metadata
+
+	// Len returns the number of entries in the map
+	Len() int
+
+	// Bytes returns an estimate of the memory held by the entries of the map
+	Bytes() int64
+
+	// Flush removes all the entries in the map
+	Flush()
+}
+
+type CacheEntry struct {
+	Key   string
+	Value string
+	TTL   time.Duration
+	// ExpiresAt is when the entry expires, Store keeps it when it is set
+	// rather than computing it from TTL
+	ExpiresAt time.Time
+	// Flags are opaque client flags, as used by memcached clients
+	Flags uint32
+	// Version changes every time the value is written
+	Version uint64
+	// CreatedAt is when the key was written while it did not exist
+	CreatedAt time.Time
+	// UpdatedAt is when the value was last written
+	UpdatedAt time.Time
+	// access counts the reads of the entry, it is shared by its copies and
+	// kept when the value is written again
+	access *access
+}
+
+// access counts the reads of an entry, it is updated under the read lock
+type access struct {
+	count atomic.Uint64
+	// last is the time of the last read in nanoseconds since the epoch
+	last atomic.Int64
+}
+
+// record counts a read of the entry at now
+func (a *access) record(now time.Time) {
+	a.count.Add(1)
+	a.last.Store(now.UnixNano())
+}
+
+// Metadata describes an entry without its value
+type Metadata struct {
+	Key       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// LastAccess is the zero time if the value was never read
+	LastAccess time.Time
+	// Accesses counts the reads of the value through Get
+	Accesses uint64
+	// Size is the number of bytes of the key and of the value as stored
+	Size int64
+	// TTL is the remaining time to live, zero if the entry never expires
+	TTL     time.Duration
+	Version uint64
+}
+
+// metadata returns the metadata of the entry at now
+func (e *CacheEntry) metadata(now time.Time) Metadata {
+	metadata := Metadata{
+		Key:       e.Key,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+		Size:      int64(len(e.Key) + len(e.Value)),
+		Version:   e.Version,
+	}
+	if e.access != nil {
+		metadata.Accesses = e.access.count.Load()
+		if last := e.access.last.Load(); last != 0 {
+			metadata.LastAccess = time.Unix(0, last)
+		}
+	}
+	if !e.ExpiresAt.IsZero() {
+		metadata.TTL = e.ExpiresAt.Sub(now)
+	}
+	return metadata
+}
+
+// entryOverhead estimates the memory held by an entry besides its key and
+// value, the entry itself and its slot in the map
+const entryOverhead = 128
+
+// size returns an estimate of the memory held by the entry
+func (e *CacheEntry) size() int64 {
+	return int64(len(e.Key)+len(e.Value)) + entryOverhead
+}
+
+// expired reports whether the entry has a time to live that elapsed before now
+func (e *CacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
 }
 
 type MapRepo struct {
-	MapCache map[string]string
-	lock     sync.RWMutex
+	MapCache map[string]*CacheEntry
+	version  uint64
+	// bytes is the sum of the sizes of the entries
+	bytes int64
+	lock  sync.RWMutex
 }
 
 func NewMapRepo() MapRepoInter {
 	return &MapRepo{
-		MapCache: make(map[string]string),
+		MapCache: make(map[string]*CacheEntry),
 		lock:     sync.RWMutex{},
 	}
 }
 
 // Set implements the Set method of the MapRepoInter interface
 func (m *MapRepo) Set(key, value string) {
+	m.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL implements the SetWithTTL method of the MapRepoInter interface
+func (m *MapRepo) SetWithTTL(key, value string, ttl time.Duration) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	m.MapCache[key] = value
+	m.store(CacheEntry{Key: key, Value: value, TTL: ttl}, time.Now())
+}
+
+// store writes the entry with a new version, the lock must be held
+func (m *MapRepo) store(entry CacheEntry, now time.Time) CacheEntry {
+	m.version++
+	entry.Version = m.version
+	switch {
+	case entry.ExpiresAt.IsZero() && entry.TTL > 0:
+		entry.ExpiresAt = now.Add(entry.TTL)
+	case !entry.ExpiresAt.IsZero() && entry.TTL == 0:
+		entry.TTL = entry.ExpiresAt.Sub(now)
+	}
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	entry.access = &access{}
+	if current, ok := m.MapCache[entry.Key]; ok {
+		m.bytes -= current.size()
+		if !current.expired(now) {
+			entry.CreatedAt = current.CreatedAt
+			entry.access = current.access
+		}
+	}
+	m.MapCache[entry.Key] = &entry
+	m.bytes += entry.size()
+	return entry
 }
 
 // Get implements the Get method of the MapRepoInter interface
-func (m *MapRepo) Get(key string) string {
+func (m *MapRepo) Get(key string) (string, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return "", false
+	}
+	entry.access.record(now)
+	return entry.Value, true
+}
+
+// UpdateValue implements the UpdateValue method of the MapRepoInter interface
+func (m *MapRepo) UpdateValue(key, newValue string) bool {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	return m.MapCache[key]
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return false
+	}
+	m.version++
+	m.bytes += int64(len(newValue) - len(entry.Value))
+	entry.Value = newValue
+	entry.Version = m.version
+	entry.UpdatedAt = now
+	return true
 }
 
-// UpdateValue implements the UpdateValue method of the MapRepoInter interface
-func (m *MapRepo) UpdateValue(key, newValue string) string {
+// GetEntry implements the GetEntry method of the MapRepoInter interface
+func (m *MapRepo) GetEntry(key string) (CacheEntry, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(time.Now()) {
+		return CacheEntry{}, false
+	}
+	return *entry, true
+}
+
+// ReadEntry implements the ReadEntry method of the MapRepoInter interface
+func (m *MapRepo) ReadEntry(key string) (CacheEntry, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return CacheEntry{}, false
+	}
+	entry.access.record(now)
+	return *entry, true
+}
+
+// Store implements the Store method of the MapRepoInter interface
+func (m *MapRepo) Store(entry CacheEntry, condition Condition) (CacheEntry, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	m.MapCache[key] = newValue
-	return key
+	now := time.Now()
+	current, ok := m.MapCache[entry.Key]
+	if ok && current.expired(now) {
+		ok = false
+	}
+	switch {
+	case condition == IfAbsent && ok:
+		return CacheEntry{}, ErrExists
+	case (condition == IfPresent || condition == IfVersion) && !ok:
+		return CacheEntry{}, ErrNotFound
+	case condition == IfVersion && current.Version != entry.Version:
+		return CacheEntry{}, ErrVersionMismatch
+	}
+	return m.store(entry, now), nil
+}
+
+// Modify implements the Modify method of the MapRepoInter interface
+func (m *MapRepo) Modify(key string, fn func(value string) (string, error)) (CacheEntry, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return CacheEntry{}, ErrNotFound
+	}
+	value, err := fn(entry.Value)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	m.version++
+	m.bytes += int64(len(value) - len(entry.Value))
+	entry.Value = value
+	entry.Version = m.version
+	entry.UpdatedAt = now
+	return *entry, nil
+}
+
+// Delete implements the Delete method of the MapRepoInter interface
+func (m *MapRepo) Delete(key string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	entry, ok := m.MapCache[key]
+	if !ok {
+		return false
+	}
+	delete(m.MapCache, key)
+	m.bytes -= entry.size()
+	return !entry.expired(time.Now())
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapRepoInter interface
+func (m *MapRepo) DeleteVersion(key string, version uint64) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	entry, ok := m.MapCache[key]
+	if !ok || entry.Version != version {
+		return false
+	}
+	delete(m.MapCache, key)
+	m.bytes -= entry.size()
+	return !entry.expired(time.Now())
+}
+
+// Expire implements the Expire method of the MapRepoInter interface
+func (m *MapRepo) Expire(key string, ttl time.Duration) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return false
+	}
+	entry.TTL = ttl
+	entry.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+	return true
+}
+
+// TTL implements the TTL method of the MapRepoInter interface
+func (m *MapRepo) TTL(key string) (time.Duration, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return 0, false
+	}
+	if entry.ExpiresAt.IsZero() {
+		return 0, true
+	}
+	return entry.ExpiresAt.Sub(now), true
+}
+
+// DeleteExpired implements the DeleteExpired method of the MapRepoInter interface
+func (m *MapRepo) DeleteExpired() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	var expired []string
+	for key, entry := range m.MapCache {
+		if entry.expired(now) {
+			delete(m.MapCache, key)
+			m.bytes -= entry.size()
+			expired = append(expired, key)
+		}
+	}
+	return expired
+}
+
+// All implements the All method of the MapRepoInter interface
+func (m *MapRepo) All() []CacheEntry {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntry, 0, len(m.MapCache))
+	for _, entry := range m.MapCache {
+		if entry.expired(now) {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+// Metadata implements the Metadata method of the MapRepoInter interface
+func (m *MapRepo) Metadata(key string) (Metadata, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	entry, ok := m.MapCache[key]
+	if !ok || entry.expired(now) {
+		return Metadata{}, false
+	}
+	return entry.metadata(now), true
+}
+
+// AllMetadata implements the AllMetadata method of the MapRepoInter interface
+func (m *MapRepo) AllMetadata() []Metadata {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	metadata := make([]Metadata, 0, len(m.MapCache))
+	for _, entry := range m.MapCache {
+		if entry.expired(now) {
+			continue
+		}
+		metadata = append(metadata, entry.metadata(now))
+	}
+	return metadata
+}
+
+// Len implements the Len method of the MapRepoInter interface, the expired
+// entries not yet removed are not counted
+func (m *MapRepo) Len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	now := time.Now()
+	n := 0
+	for _, entry := range m.MapCache {
+		if !entry.expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Bytes implements the Bytes method of the MapRepoInter interface
+func (m *MapRepo) Bytes() int64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.bytes
 }
 
-// All implements the GetEntryList method of the MapRepoInter interface
-func (m *MapRepo) All() map[string]string {
+// Flush implements the Flush method of the MapRepoInter interface
+func (m *MapRepo) Flush() {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	return m.MapCache
+	m.MapCache = make(map[string]*CacheEntry)
+	m.bytes = 0
 }
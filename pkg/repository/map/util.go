@@ -2,32 +2,23 @@ package repository
 
 import "sort"
 
-func SortMapByKey(m map[string]string) map[string]string {
-	var keys []string
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	sortedMap := make(map[string]string, len(keys))
-	for _, k := range keys {
-		sortedMap[k] = m[k]
-	}
-	return sortedMap
+func SortMapByKey(m []CacheEntry) []CacheEntry {
+	sort.SliceStable(m, func(i, j int) bool {
+		return m[i].Key < m[j].Key
+	})
+	return m
 }
 
-func SortMapByValue(m map[string]string) map[string]string {
-	var keys []string
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i, j int) bool {
-		return m[keys[i]] < m[keys[j]]
+func SortMapByValue(m []CacheEntry) []CacheEntry {
+	sort.SliceStable(m, func(i, j int) bool {
+		return m[i].Value < m[j].Value
 	})
+	return m
+}
 
-	sortedMap := make(map[string]string, len(keys))
-	for _, k := range keys {
-		sortedMap[k] = m[k]
-	}
-	return sortedMap
+func SortMetadataByKey(m []Metadata) []Metadata {
+	sort.SliceStable(m, func(i, j int) bool {
+		return m[i].Key < m[j].Key
+	})
+	return m
 }
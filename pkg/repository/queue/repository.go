@@ -2,34 +2,163 @@ package repository
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/zelta-7/cache/common"
 )
 
 type QueueRepoInterface interface {
-	// Set adds a value to the queue
-	Set(key, value string, ttl ...int)
+	// Set adds a value to the back of the queue, a zero ttl never expires
+	Set(key, value string, ttl time.Duration)
+
+	// Prepend adds a value to the front of the queue, a zero ttl never expires
+	Prepend(key, value string, ttl time.Duration)
+
+	// Insert adds a value expiring at expiresAt to the back of the queue, or
+	// to its front if front is set, a zero expiresAt never expires
+	Insert(key, value string, expiresAt time.Time, front bool)
+
+	// Get retrives the first value from the queue without removing it
+	Get() (CacheEntry, bool)
+
+	// Pop removes and returns the first value from the queue
+	Pop() (CacheEntry, bool)
+
+	// Update the value of a given key and reports whether it was found
+	Update(key, value string) bool
+
+	// Delete removes every entry with the given key and reports whether one was found
+	Delete(key string) bool
 
-	// Get retrives the first value from the queue
-	Get() (key, value string)
+	// Remove removes the first unexpired entry with the given key and value
+	// wherever it is in the queue and reports whether one was found
+	Remove(key, value string) bool
 
-	// Update the value of a given key
-	Update(key, value string) string
+	// DeleteExpired removes the expired entries and returns their keys
+	DeleteExpired() []string
 
 	// All returns all the values in the queue
 	All() []CacheEntry
+
+	// Metadata returns the metadata of the first entry with the given key and whether one was found
+	Metadata(key string) (Metadata, bool)
+
+	// AllMetadata returns the metadata of all the entries in the queue, in queue order
+	AllMetadata() []Metadata
+
+	// Len returns the number of entries in the queue
+	Len() int
+
+	// Bytes returns an estimate of the memory held by the entries of the queue
+	Bytes() int64
+
+	// Oldest returns when the oldest value still in the queue was added and
+	// whether the queue holds one
+	Oldest() (time.Time, bool)
+
+	// Flush removes all the entries from the queue
+	Flush()
 }
 
 type CacheEntry struct {
-	Value string
-	Key   string
-	TTL   time.Duration
+	Value     string
+	Key       string
+	TTL       time.Duration
+	ExpiresAt time.Time
+	// CreatedAt is when the value was added to the queue
+	CreatedAt time.Time
+	// UpdatedAt is when the value was last written
+	UpdatedAt time.Time
+	// Version changes every time the value is written
+	Version uint64
+	// access counts the reads of the entry, it is shared by its copies
+	access *access
+}
+
+// access counts the reads of an entry, it is updated under the read lock
+type access struct {
+	count atomic.Uint64
+	// last is the time of the last read in nanoseconds since the epoch
+	last atomic.Int64
+}
+
+// record counts a read of the entry at now
+func (a *access) record(now time.Time) {
+	a.count.Add(1)
+	a.last.Store(now.UnixNano())
+}
+
+// Metadata describes an entry without its value
+type Metadata struct {
+	Key       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// LastAccess is the zero time if the value was never read
+	LastAccess time.Time
+	// Accesses counts the times the value was read at the front of the queue
+	Accesses uint64
+	// Size is the number of bytes of the key and of the value as stored
+	Size int64
+	// TTL is the remaining time to live, zero if the entry never expires
+	TTL     time.Duration
+	Version uint64
+}
+
+func newCacheEntry(key, value string, expiresAt time.Time, version uint64) CacheEntry {
+	now := time.Now()
+	entry := CacheEntry{Value: value, Key: key, ExpiresAt: expiresAt, CreatedAt: now, UpdatedAt: now, Version: version, access: &access{}}
+	if !expiresAt.IsZero() {
+		entry.TTL = expiresAt.Sub(now)
+	}
+	return entry
+}
+
+// expiration returns when a value written now with ttl expires, zero if it never expires
+func expiration(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// metadata returns the metadata of the entry at now
+func (e *CacheEntry) metadata(now time.Time) Metadata {
+	metadata := Metadata{
+		Key:       e.Key,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+		Accesses:  e.access.count.Load(),
+		Size:      int64(len(e.Key) + len(e.Value)),
+		Version:   e.Version,
+	}
+	if last := e.access.last.Load(); last != 0 {
+		metadata.LastAccess = time.Unix(0, last)
+	}
+	if !e.ExpiresAt.IsZero() {
+		metadata.TTL = e.ExpiresAt.Sub(now)
+	}
+	return metadata
+}
+
+// entryOverhead estimates the memory held by an entry besides its key and
+// value, its slot in the queue
+const entryOverhead = 64
+
+// size returns an estimate of the memory held by the entry
+func (e *CacheEntry) size() int64 {
+	return int64(len(e.Key)+len(e.Value)) + entryOverhead
+}
+
+// expired reports whether the entry has a time to live that elapsed before now
+func (e *CacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
 }
 
 type QueueRepo struct {
 	queueCache []CacheEntry
-	lock       sync.RWMutex
+	version    uint64
+	// bytes is the sum of the sizes of the entries
+	bytes int64
+	lock  sync.RWMutex
 }
 
 func NewQueueRepo() QueueRepoInterface {
@@ -40,51 +169,243 @@ func NewQueueRepo() QueueRepoInterface {
 }
 
 // Set implements the Set method of the QueueRepoInterface
-func (q *QueueRepo) Set(value, key string, ttl ...int) {
+func (q *QueueRepo) Set(key, value string, ttl time.Duration) {
+	q.Insert(key, value, expiration(ttl), false)
+}
+
+// Prepend implements the Prepend method of the QueueRepoInterface
+func (q *QueueRepo) Prepend(key, value string, ttl time.Duration) {
+	q.Insert(key, value, expiration(ttl), true)
+}
+
+// Insert implements the Insert method of the QueueRepoInterface
+func (q *QueueRepo) Insert(key, value string, expiresAt time.Time, front bool) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
-	if len(ttl) > 0 {
-		q.queueCache = append(q.queueCache, CacheEntry{Value: value, Key: key, TTL: time.Duration(ttl[0])})
-		return
+	q.version++
+	entry := newCacheEntry(key, value, expiresAt, q.version)
+	if front {
+		q.queueCache = append([]CacheEntry{entry}, q.queueCache...)
 	} else {
-		q.queueCache = append(q.queueCache, CacheEntry{Value: value, Key: key})
-		return
+		q.queueCache = append(q.queueCache, entry)
 	}
+	q.bytes += entry.size()
 }
 
 // Get implements the Get method of the QueueRepoInterface
-func (q *QueueRepo) Get() (string, string) {
+func (q *QueueRepo) Get() (CacheEntry, bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	now := time.Now()
+	for _, entry := range q.queueCache {
+		if !entry.expired(now) {
+			entry.access.record(now)
+			return entry, true
+		}
+	}
+	return CacheEntry{}, false
+}
+
+// Pop implements the Pop method of the QueueRepoInterface
+func (q *QueueRepo) Pop() (CacheEntry, bool) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
-	if len(q.queueCache) == 0 {
-		return "", ""
+	now := time.Now()
+	for len(q.queueCache) > 0 {
+		entry := q.queueCache[0]
+		q.queueCache[0] = CacheEntry{}
+		q.queueCache = q.queueCache[1:]
+		q.bytes -= entry.size()
+		if !entry.expired(now) {
+			return entry, true
+		}
 	}
-
-	result := q.queueCache[0]
-	// q.queueCache = q.queueCache[1:]
-	return result.Key, result.Value
+	return CacheEntry{}, false
 }
 
-func (q *QueueRepo) Update(key, value string) string {
+// Update implements the Update method of the QueueRepoInterface
+func (q *QueueRepo) Update(key, value string) bool {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
-	hashedKey := common.HashKey(key)
+	now := time.Now()
 	for i, entry := range q.queueCache {
-		if entry.Key == hashedKey {
+		if entry.Key == key && !entry.expired(now) {
+			q.version++
+			q.bytes += int64(len(value) - len(entry.Value))
 			q.queueCache[i].Value = value
-			break
+			q.queueCache[i].UpdatedAt = now
+			q.queueCache[i].Version = q.version
+			return true
 		}
 	}
-	return key
+	return false
+}
+
+// Delete implements the Delete method of the QueueRepoInterface
+func (q *QueueRepo) Delete(key string) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := time.Now()
+	found := false
+	kept := q.queueCache[:0]
+	for _, entry := range q.queueCache {
+		if entry.Key == key {
+			found = found || !entry.expired(now)
+			q.bytes -= entry.size()
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	for i := len(kept); i < len(q.queueCache); i++ {
+		q.queueCache[i] = CacheEntry{}
+	}
+	q.queueCache = kept
+	return found
+}
+
+// Remove implements the Remove method of the QueueRepoInterface
+func (q *QueueRepo) Remove(key, value string) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := time.Now()
+	for i, entry := range q.queueCache {
+		if entry.Key == key && entry.Value == value && !entry.expired(now) {
+			copy(q.queueCache[i:], q.queueCache[i+1:])
+			q.queueCache[len(q.queueCache)-1] = CacheEntry{}
+			q.queueCache = q.queueCache[:len(q.queueCache)-1]
+			q.bytes -= entry.size()
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteExpired implements the DeleteExpired method of the QueueRepoInterface
+func (q *QueueRepo) DeleteExpired() []string {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := time.Now()
+	var expired []string
+	kept := q.queueCache[:0]
+	for _, entry := range q.queueCache {
+		if entry.expired(now) {
+			expired = append(expired, entry.Key)
+			q.bytes -= entry.size()
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	for i := len(kept); i < len(q.queueCache); i++ {
+		q.queueCache[i] = CacheEntry{}
+	}
+	q.queueCache = kept
+	return expired
 }
 
 // All implements the All method of the QueueRepoInterface
 func (q *QueueRepo) All() []CacheEntry {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntry, 0, len(q.queueCache))
+	for _, entry := range q.queueCache {
+		if !entry.expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Metadata implements the Metadata method of the QueueRepoInterface
+func (q *QueueRepo) Metadata(key string) (Metadata, bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	now := time.Now()
+	for i := range q.queueCache {
+		entry := &q.queueCache[i]
+		if entry.Key == key && !entry.expired(now) {
+			return entry.metadata(now), true
+		}
+	}
+	return Metadata{}, false
+}
+
+// AllMetadata implements the AllMetadata method of the QueueRepoInterface
+func (q *QueueRepo) AllMetadata() []Metadata {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	now := time.Now()
+	metadata := make([]Metadata, 0, len(q.queueCache))
+	for i := range q.queueCache {
+		entry := &q.queueCache[i]
+		if !entry.expired(now) {
+			metadata = append(metadata, entry.metadata(now))
+		}
+	}
+	return metadata
+}
+
+// Len implements the Len method of the QueueRepoInterface, the expired
+// entries not yet removed are not counted
+func (q *QueueRepo) Len() int {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	now := time.Now()
+	n := 0
+	for _, entry := range q.queueCache {
+		if !entry.expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Bytes implements the Bytes method of the QueueRepoInterface
+func (q *QueueRepo) Bytes() int64 {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	return q.bytes
+}
+
+// Oldest implements the Oldest method of the QueueRepoInterface
+func (q *QueueRepo) Oldest() (time.Time, bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	// the values prepended are not the oldest, every entry is checked
+	now := time.Now()
+	var oldest time.Time
+	found := false
+	for i := range q.queueCache {
+		entry := &q.queueCache[i]
+		if entry.expired(now) {
+			continue
+		}
+		if !found || entry.CreatedAt.Before(oldest) {
+			oldest = entry.CreatedAt
+			found = true
+		}
+	}
+	return oldest, found
+}
+
+// Flush implements the Flush method of the QueueRepoInterface
+func (q *QueueRepo) Flush() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
-	return q.queueCache
+	q.queueCache = make([]CacheEntry, 0)
+	q.bytes = 0
 }
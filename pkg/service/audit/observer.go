@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// observer records the mutations of the clients once they succeed, the reads
+// and the removals of the expired entries are not recorded
+type observer struct {
+	audit AuditServiceInterface
+}
+
+// NewObserver returns an observer recording the mutations of the namespaces in audit
+func NewObserver(audit AuditServiceInterface) namespaceservice.Observer {
+	return &observer{audit: audit}
+}
+
+// Observe implements the Observe method of the Observer interface
+func (o *observer) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	if operation.Write && operation.OK {
+		o.audit.Record(ctx, operation.Source, operation.Namespace, operation.Op, operation.Key)
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultMaxSize is the size in bytes from which the audit file is rotated
+	DefaultMaxSize = 100 << 20
+	// DefaultMaxAge is the age from which the audit file is rotated
+	DefaultMaxAge = 24 * time.Hour
+)
+
+const (
+	// activeFile is the name of the file receiving the records
+	activeFile = "audit.log"
+	// rotatedLayout is the time layout of the name of the rotated files,
+	// they sort by rotation time
+	rotatedLayout = "audit-20060102T150405.000000000.log"
+	// readChunk is how much of a file is read at a time, backwards from its
+	// end, to find its last record when the chain is resumed
+	readChunk = 4 << 10
+)
+
+// Options configures the audit log
+type Options struct {
+	// Directory receives the audit files, it is created if needed
+	Directory string
+	// MaxSize is the size in bytes from which the active file is rotated
+	MaxSize int64
+	// MaxAge is the age from which the active file is rotated
+	MaxAge time.Duration
+	// HashChain adds to every record the hash of the previous one and its
+	// own, so that a record altered or removed breaks the chain
+	HashChain bool
+}
+
+// Record is a mutation of the map or the queue of a namespace, it is
+// written as a JSON line in the order of the fields. With the hash chain,
+// the line ends with a "hash" member holding the hex SHA-256 of the line
+// without that member, and Prev is the hash of the previous record.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Principal is the authenticated identity of the client, empty when the
+	// client was not authenticated
+	Principal string `json:"principal,omitempty"`
+	// Client is the address of the client, empty for the internal operations
+	Client    string `json:"client,omitempty"`
+	RequestID string `json:"request-id,omitempty"`
+	// Type is the data structure of the operation, map or queue
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Op        string `json:"op"`
+	// Key is empty for the operations on the whole map or queue
+	Key  string `json:"key,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+type AuditServiceInterface interface {
+	// Record writes a record of a mutation made for the client carried by ctx
+	Record(ctx context.Context, dataType, namespace, op, key string)
+
+	// Close closes the active file
+	Close() error
+}
+
+type auditService struct {
+	options Options
+
+	file   *os.File
+	size   int64
+	opened time.Time
+	// last is the hash of the last record written, empty without the chain
+	last string
+	lock sync.Mutex
+}
+
+// NewAuditService returns an audit log writing JSON lines to the active
+// file of options.Directory, the file is rotated once it reaches
+// options.MaxSize bytes or options.MaxAge. The hash chain resumes from the
+// last record of the active file, or of the last rotated file when the
+// active file is empty.
+func NewAuditService(options Options) (AuditServiceInterface, error) {
+	if options.Directory == "" {
+		return nil, fmt.Errorf("audit directory is required")
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+	if options.MaxAge <= 0 {
+		options.MaxAge = DefaultMaxAge
+	}
+	if err := os.MkdirAll(options.Directory, 0o700); err != nil {
+		return nil, fmt.Errorf("creating audit directory: %w", err)
+	}
+	a := &auditService{options: options, lock: sync.Mutex{}}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	if options.HashChain {
+		last, err := a.resume()
+		if err != nil {
+			a.file.Close()
+			return nil, fmt.Errorf("resuming the audit hash chain: %w", err)
+		}
+		a.last = last
+	}
+	return a, nil
+}
+
+// open opens the active file for appending
+func (a *auditService) open() error {
+	file, err := os.OpenFile(filepath.Join(a.options.Directory, activeFile), os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	a.file = file
+	a.size = info.Size()
+	a.opened = time.Now()
+	return nil
+}
+
+// resume returns the hash of the last record written, the one of the active
+// file or, if it is empty, of the last rotated file
+func (a *auditService) resume() (string, error) {
+	if a.size > 0 {
+		return lastHash(a.file, a.size)
+	}
+	rotated, err := filepath.Glob(filepath.Join(a.options.Directory, "audit-*.log"))
+	if err != nil || len(rotated) == 0 {
+		return "", err
+	}
+	sort.Strings(rotated)
+	file, err := os.Open(rotated[len(rotated)-1])
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	return lastHash(file, info.Size())
+}
+
+// lastHash returns the hash of the last record of the file of the given size
+func lastHash(file io.ReaderAt, size int64) (string, error) {
+	last, err := lastLine(file, size)
+	if err != nil || last == nil {
+		return "", err
+	}
+	var record struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(last, &record); err != nil {
+		return "", err
+	}
+	return record.Hash, nil
+}
+
+// lastLine returns the last non-empty line of the file of the given size,
+// nil if there is none. The file is read backwards by chunks up to the
+// newline before the line, however long the line is.
+func lastLine(file io.ReaderAt, size int64) ([]byte, error) {
+	var line []byte
+	chunk := make([]byte, readChunk)
+	for end := size; end > 0; {
+		n := int64(len(chunk))
+		if n > end {
+			n = end
+		}
+		end -= n
+		if _, err := file.ReadAt(chunk[:n], end); err != nil {
+			return nil, err
+		}
+		line = bytes.TrimRight(append(append([]byte(nil), chunk[:n]...), line...), " \t\r\n")
+		if i := bytes.LastIndexByte(line, '\n'); i >= 0 {
+			return line[i+1:], nil
+		}
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+	return line, nil
+}
+
+// Record implements the Record method of the AuditServiceInterface
+func (a *auditService) Record(ctx context.Context, dataType, namespace, op, key string) {
+	record := Record{
+		Time:      time.Now().UTC(),
+		Principal: common.Principal(ctx),
+		Client:    common.Client(ctx),
+		RequestID: common.RequestID(ctx),
+		Type:      dataType,
+		Namespace: namespace,
+		Op:        op,
+		Key:       key,
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		klog.ErrorS(os.ErrClosed, "Error writing the audit record", "namespace", namespace, "op", op)
+		return
+	}
+	if a.size >= a.options.MaxSize || (a.size > 0 && time.Since(a.opened) >= a.options.MaxAge) {
+		if err := a.rotate(); err != nil {
+			klog.ErrorS(err, "Error rotating the audit file")
+		}
+	}
+
+	record.Prev = a.last
+	line, err := json.Marshal(record)
+	if err != nil {
+		klog.ErrorS(err, "Error encoding the audit record", "namespace", namespace, "op", op)
+		return
+	}
+	var hash string
+	if a.options.HashChain {
+		sum := sha256.Sum256(line)
+		hash = hex.EncodeToString(sum[:])
+		line = append(line[:len(line)-1], `,"hash":"`+hash+`"}`...)
+	}
+	line = append(line, '\n')
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		klog.ErrorS(err, "Error writing the audit record", "namespace", namespace, "op", op)
+		return
+	}
+	a.last = hash
+}
+
+// rotate renames the active file after the current time and opens a new
+// one, the hash chain goes on in the new file
+func (a *auditService) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	a.file = nil
+	active := filepath.Join(a.options.Directory, activeFile)
+	rotated := filepath.Join(a.options.Directory, time.Now().UTC().Format(rotatedLayout))
+	if err := os.Rename(active, rotated); err != nil {
+		// the records keep going to the active file
+		if openErr := a.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return a.open()
+}
+
+// Close implements the Close method of the AuditServiceInterface
+func (a *auditService) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
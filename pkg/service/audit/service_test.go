@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// verify checks the hash chain of the audit files of directory, the rotated
+// ones first, and returns the number of records
+func verify(directory string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(directory, "audit-*.log"))
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(files)
+	files = append(files, filepath.Join(directory, activeFile))
+
+	records, prev := 0, ""
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return 0, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			var record struct {
+				Prev string `json:"prev"`
+				Hash string `json:"hash"`
+			}
+			if err := json.Unmarshal(line, &record); err != nil {
+				return 0, fmt.Errorf("record %d: %w", records, err)
+			}
+			suffix := `,"hash":"` + record.Hash + `"}`
+			if !bytes.HasSuffix(line, []byte(suffix)) {
+				return 0, fmt.Errorf("record %d does not end with its hash", records)
+			}
+			sum := sha256.Sum256(append(bytes.TrimSuffix(line, []byte(suffix)), '}'))
+			if hex.EncodeToString(sum[:]) != record.Hash {
+				return 0, fmt.Errorf("record %d was altered", records)
+			}
+			if record.Prev != prev {
+				return 0, fmt.Errorf("record %d does not follow the previous one", records)
+			}
+			prev = record.Hash
+			records++
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, err
+		}
+	}
+	return records, nil
+}
+
+func newAudit(t *testing.T, options Options) AuditServiceInterface {
+	t.Helper()
+	audit, err := NewAuditService(options)
+	if err != nil {
+		t.Fatalf("creating the audit log: %v", err)
+	}
+	t.Cleanup(func() { audit.Close() })
+	return audit
+}
+
+func TestRotationKeepsTheChain(t *testing.T) {
+	directory := t.TempDir()
+	audit := newAudit(t, Options{Directory: directory, MaxSize: 512, HashChain: true})
+	for i := 0; i < 20; i++ {
+		audit.Record(context.Background(), "map", "", "set", fmt.Sprintf("key-%d", i))
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(directory, "audit-*.log"))
+	if len(rotated) < 2 {
+		t.Fatalf("%d files were rotated, want several", len(rotated))
+	}
+	for _, name := range rotated {
+		if info, _ := os.Stat(name); info.Size() > 512+256 {
+			t.Fatalf("%s grew to %d bytes past the maximum size", name, info.Size())
+		}
+	}
+	if records, err := verify(directory); err != nil || records != 20 {
+		t.Fatalf("verified %d records: %v, want 20", records, err)
+	}
+}
+
+func TestChainResumesAfterARestart(t *testing.T) {
+	directory := t.TempDir()
+	audit := newAudit(t, Options{Directory: directory, HashChain: true})
+	audit.Record(context.Background(), "map", "", "set", "a")
+	// the last record is longer than any chunk read to find it
+	audit.Record(context.Background(), "map", "", "set", strings.Repeat("k", 100<<10))
+	audit.Close()
+
+	audit = newAudit(t, Options{Directory: directory, HashChain: true})
+	audit.Record(context.Background(), "queue", "", "push", "b")
+	audit.Close()
+
+	// a file rotated by another tool leaves an empty active file, the chain
+	// resumes from the rotated one
+	if err := os.Rename(filepath.Join(directory, activeFile), filepath.Join(directory, "audit-1.log")); err != nil {
+		t.Fatal(err)
+	}
+	audit = newAudit(t, Options{Directory: directory, HashChain: true})
+	audit.Record(context.Background(), "map", "", "delete", "a")
+
+	if records, err := verify(directory); err != nil || records != 4 {
+		t.Fatalf("verified %d records: %v, want 4", records, err)
+	}
+}
+
+func TestAlteredOrRemovedRecordBreaksTheChain(t *testing.T) {
+	directory := t.TempDir()
+	audit := newAudit(t, Options{Directory: directory, HashChain: true})
+	for _, key := range []string{"a", "b", "c"} {
+		audit.Record(context.Background(), "map", "", "set", key)
+	}
+	audit.Close()
+
+	active := filepath.Join(directory, activeFile)
+	data, err := os.ReadFile(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+
+	os.WriteFile(active, []byte(lines[0]+strings.Replace(lines[1], `"key":"b"`, `"key":"x"`, 1)+lines[2]), 0o600)
+	if _, err := verify(directory); err == nil || !strings.Contains(err.Error(), "altered") {
+		t.Fatalf("the altered record was verified: %v", err)
+	}
+	os.WriteFile(active, []byte(lines[0]+lines[2]), 0o600)
+	if _, err := verify(directory); err == nil || !strings.Contains(err.Error(), "does not follow") {
+		t.Fatalf("the chain without the removed record was verified: %v", err)
+	}
+}
+
+func TestLastLineIgnoresTheTrailingNewlines(t *testing.T) {
+	for _, content := range []string{"", "\n\n", "first\nlast\n", "first\nlast", "last\n\n\n"} {
+		line, err := lastLine(strings.NewReader(content), int64(len(content)))
+		want := ""
+		if strings.Contains(content, "last") {
+			want = "last"
+		}
+		if err != nil || string(line) != want {
+			t.Fatalf("the last line of %q is %q: %v, want %q", content, line, err, want)
+		}
+	}
+}
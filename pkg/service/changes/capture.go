@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+)
+
+// capturedMap records every mutation made through the map service it wraps
+type capturedMap struct {
+	mapservice.MapServiceInterface
+	namespace string
+	changes   ChangeServiceInterface
+}
+
+// CaptureMap wraps a map service so that its mutations are recorded in changes
+func CaptureMap(m mapservice.MapServiceInterface, namespace string, changes ChangeServiceInterface) mapservice.MapServiceInterface {
+	return &capturedMap{
+		MapServiceInterface: m,
+		namespace:           namespace,
+		changes:             changes,
+	}
+}
+
+func (c *capturedMap) change(op, key string) Change {
+	return Change{Op: op, Source: SourceMap, Namespace: c.namespace, Key: key}
+}
+
+// value returns the current value of the key, nil if it does not exist
+func (c *capturedMap) value(ctx context.Context, key string) *string {
+	entry, ok, _ := c.MapServiceInterface.GetEntry(ctx, key)
+	if !ok {
+		return nil
+	}
+	return &entry.Value
+}
+
+// written fills the expiration and the flags of the change with those of
+// the entry the wrapped service just wrote
+func (c *capturedMap) written(ctx context.Context, change *Change) {
+	if entry, ok, _ := c.MapServiceInterface.GetEntry(ctx, change.Key); ok {
+		change.ExpiresAt = expiresAt(entry.ExpiresAt)
+		change.Flags = entry.Flags
+	}
+}
+
+// Set implements the Set method of the MapServiceInterface
+func (c *capturedMap) Set(ctx context.Context, key, value string) (stored string, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpSet, key)
+		change.OldValue = c.value(ctx, key)
+		change.NewValue = &value
+		if stored, err = c.MapServiceInterface.Set(ctx, key, value); err != nil {
+			return nil
+		}
+		c.written(ctx, &change)
+		return []Change{change}
+	})
+	return stored, err
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the MapServiceInterface
+func (c *capturedMap) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (stored string, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpSet, key)
+		change.OldValue = c.value(ctx, key)
+		change.NewValue = &value
+		change.TimeToLive = ttl
+		if stored, err = c.MapServiceInterface.SetCacheTimetoLive(ctx, key, value, ttl); err != nil {
+			return nil
+		}
+		c.written(ctx, &change)
+		return []Change{change}
+	})
+	return stored, err
+}
+
+// Store implements the Store method of the MapServiceInterface
+func (c *capturedMap) Store(ctx context.Context, entry mapRepository.CacheEntry, condition mapRepository.Condition) (stored mapRepository.CacheEntry, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpSet, entry.Key)
+		change.OldValue = c.value(ctx, entry.Key)
+		stored, err = c.MapServiceInterface.Store(ctx, entry, condition)
+		if err != nil {
+			return nil
+		}
+		change.NewValue = &stored.Value
+		change.ExpiresAt = expiresAt(stored.ExpiresAt)
+		change.Flags = stored.Flags
+		if !stored.ExpiresAt.IsZero() {
+			change.TimeToLive = seconds(time.Until(stored.ExpiresAt))
+		}
+		return []Change{change}
+	})
+	return stored, err
+}
+
+// Modify implements the Modify method of the MapServiceInterface
+func (c *capturedMap) Modify(ctx context.Context, key string, fn func(value string) (string, error)) (entry mapRepository.CacheEntry, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpUpdate, key)
+		entry, err = c.MapServiceInterface.Modify(ctx, key, func(value string) (string, error) {
+			change.OldValue = &value
+			return fn(value)
+		})
+		if err != nil {
+			return nil
+		}
+		change.NewValue = &entry.Value
+		return []Change{change}
+	})
+	return entry, err
+}
+
+// Delete implements the Delete method of the MapServiceInterface
+func (c *capturedMap) Delete(ctx context.Context, key string) (deleted bool, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpDelete, key)
+		change.OldValue = c.value(ctx, key)
+		if deleted, err = c.MapServiceInterface.Delete(ctx, key); !deleted {
+			return nil
+		}
+		return []Change{change}
+	})
+	return deleted, err
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapServiceInterface
+func (c *capturedMap) DeleteVersion(ctx context.Context, key string, version uint64) (deleted bool, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpDelete, key)
+		change.OldValue = c.value(ctx, key)
+		if deleted, err = c.MapServiceInterface.DeleteVersion(ctx, key, version); !deleted {
+			return nil
+		}
+		return []Change{change}
+	})
+	return deleted, err
+}
+
+// Expire implements the Expire method of the MapServiceInterface
+func (c *capturedMap) Expire(ctx context.Context, key string, ttl int) (found bool, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		if found, err = c.MapServiceInterface.Expire(ctx, key, ttl); !found {
+			return nil
+		}
+		change := c.change(OpTimeToLive, key)
+		change.TimeToLive = ttl
+		c.written(ctx, &change)
+		return []Change{change}
+	})
+	return found, err
+}
+
+// UpdateCacheEntry implements the UpdateCacheEntry method of the MapServiceInterface
+func (c *capturedMap) UpdateCacheEntry(ctx context.Context, key, value string) (found bool, err error) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpUpdate, key)
+		change.OldValue = c.value(ctx, key)
+		change.NewValue = &value
+		if found, err = c.MapServiceInterface.UpdateCacheEntry(ctx, key, value); !found {
+			return nil
+		}
+		return []Change{change}
+	})
+	return found, err
+}
+
+// Flush implements the Flush method of the MapServiceInterface
+func (c *capturedMap) Flush(ctx context.Context) {
+	c.changes.Record(c.namespace, func() []Change {
+		c.MapServiceInterface.Flush(ctx)
+		return []Change{c.change(OpFlush, "")}
+	})
+}
+
+// Sweep implements the Sweep method of the MapServiceInterface
+func (c *capturedMap) Sweep(ctx context.Context) (expired []string) {
+	c.changes.Record(c.namespace, func() []Change {
+		expired = c.MapServiceInterface.Sweep(ctx)
+		changes := make([]Change, 0, len(expired))
+		for _, key := range expired {
+			changes = append(changes, c.change(OpExpire, key))
+		}
+		return changes
+	})
+	return expired
+}
+
+// capturedQueue records every mutation made through the queue service it wraps
+type capturedQueue struct {
+	queueservice.QueueServiceInterface
+	namespace string
+	changes   ChangeServiceInterface
+}
+
+// CaptureQueue wraps a queue service so that its mutations are recorded in changes
+func CaptureQueue(q queueservice.QueueServiceInterface, namespace string, changes ChangeServiceInterface) queueservice.QueueServiceInterface {
+	return &capturedQueue{
+		QueueServiceInterface: q,
+		namespace:             namespace,
+		changes:               changes,
+	}
+}
+
+func (c *capturedQueue) change(op, key string) Change {
+	return Change{Op: op, Source: SourceQueue, Namespace: c.namespace, Key: key}
+}
+
+// Set implements the Set method of the QueueServiceInterface
+func (c *capturedQueue) Set(ctx context.Context, key, value string) string {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpPush, key)
+		change.NewValue = &value
+		key = c.QueueServiceInterface.Set(ctx, key, value)
+		return []Change{change}
+	})
+	return key
+}
+
+// Prepend implements the Prepend method of the QueueServiceInterface
+func (c *capturedQueue) Prepend(ctx context.Context, key, value string) string {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpPrepend, key)
+		change.NewValue = &value
+		key = c.QueueServiceInterface.Prepend(ctx, key, value)
+		return []Change{change}
+	})
+	return key
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the QueueServiceInterface
+func (c *capturedQueue) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) string {
+	// the expiration is computed here so that the change carries the one of the entry
+	entry := queueRepository.CacheEntry{Key: key, Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(time.Duration(ttl) * time.Second)
+	}
+	return c.Push(ctx, entry, false)
+}
+
+// Push implements the Push method of the QueueServiceInterface
+func (c *capturedQueue) Push(ctx context.Context, entry queueRepository.CacheEntry, front bool) (key string) {
+	c.changes.Record(c.namespace, func() []Change {
+		change := c.change(OpPush, entry.Key)
+		if front {
+			change.Op = OpPrepend
+		}
+		change.NewValue = &entry.Value
+		change.ExpiresAt = expiresAt(entry.ExpiresAt)
+		if !entry.ExpiresAt.IsZero() {
+			change.TimeToLive = seconds(time.Until(entry.ExpiresAt))
+		}
+		key = c.QueueServiceInterface.Push(ctx, entry, front)
+		return []Change{change}
+	})
+	return key
+}
+
+// Pop implements the Pop method of the QueueServiceInterface
+func (c *capturedQueue) Pop(ctx context.Context) (entry queueRepository.CacheEntry, ok bool) {
+	c.changes.Record(c.namespace, func() []Change {
+		if entry, ok = c.QueueServiceInterface.Pop(ctx); !ok {
+			return nil
+		}
+		change := c.change(OpPop, entry.Key)
+		change.OldValue = &entry.Value
+		return []Change{change}
+	})
+	return entry, ok
+}
+
+// Remove implements the Remove method of the QueueServiceInterface
+func (c *capturedQueue) Remove(ctx context.Context, key, value string) (removed bool) {
+	c.changes.Record(c.namespace, func() []Change {
+		if removed = c.QueueServiceInterface.Remove(ctx, key, value); !removed {
+			return nil
+		}
+		change := c.change(OpPop, key)
+		change.OldValue = &value
+		return []Change{change}
+	})
+	return removed
+}
+
+// UpdateValue implements the UpdateValue method of the QueueServiceInterface
+func (c *capturedQueue) UpdateValue(ctx context.Context, key, newValue string) (found bool) {
+	c.changes.Record(c.namespace, func() []Change {
+		if found = c.QueueServiceInterface.UpdateValue(ctx, key, newValue); !found {
+			return nil
+		}
+		change := c.change(OpUpdate, key)
+		change.NewValue = &newValue
+		return []Change{change}
+	})
+	return found
+}
+
+// Delete implements the Delete method of the QueueServiceInterface
+func (c *capturedQueue) Delete(ctx context.Context, key string) (deleted bool) {
+	c.changes.Record(c.namespace, func() []Change {
+		if deleted = c.QueueServiceInterface.Delete(ctx, key); !deleted {
+			return nil
+		}
+		return []Change{c.change(OpDelete, key)}
+	})
+	return deleted
+}
+
+// Flush implements the Flush method of the QueueServiceInterface
+func (c *capturedQueue) Flush(ctx context.Context) {
+	c.changes.Record(c.namespace, func() []Change {
+		c.QueueServiceInterface.Flush(ctx)
+		return []Change{c.change(OpFlush, "")}
+	})
+}
+
+// Sweep implements the Sweep method of the QueueServiceInterface
+func (c *capturedQueue) Sweep(ctx context.Context) (expired []string) {
+	c.changes.Record(c.namespace, func() []Change {
+		expired = c.QueueServiceInterface.Sweep(ctx)
+		changes := make([]Change, 0, len(expired))
+		for _, key := range expired {
+			changes = append(changes, c.change(OpExpire, key))
+		}
+		return changes
+	})
+	return expired
+}
+
+// expiresAt returns a pointer to t, nil if t is zero and never expires
+func expiresAt(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// seconds converts a time to live to whole seconds rounded up, 0 never expires
+func seconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return 0
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
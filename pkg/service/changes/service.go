@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations recorded in the change log
+const (
+	OpSet    = "set"
+	OpUpdate = "update"
+	OpDelete = "delete"
+	// OpTimeToLive changes the expiration of an entry without changing its value
+	OpTimeToLive = "ttl"
+	OpFlush      = "flush"
+	// OpExpire removes an expired entry
+	OpExpire  = "expire"
+	OpPush    = "push"
+	OpPrepend = "prepend"
+	OpPop     = "pop"
+)
+
+// Sources of the changes
+const (
+	SourceMap   = "map"
+	SourceQueue = "queue"
+)
+
+var (
+	// ErrTruncated is returned when changes after the requested sequence number are no longer retained
+	ErrTruncated = errors.New("changes after the requested sequence number are no longer retained")
+	// ErrInvalidToken is returned for a resume token that was not issued by this change log
+	ErrInvalidToken = errors.New("invalid resume token")
+)
+
+// Change is a mutation of a map or queue entry. Changes are numbered in the
+// order they are applied starting at 1, the values are set when the
+// operation knows them. A pop names the popped entry by its key and OldValue.
+type Change struct {
+	Sequence  uint64  `json:"sequence"`
+	Op        string  `json:"op"`
+	Source    string  `json:"source"`
+	Namespace string  `json:"namespace"`
+	Key       string  `json:"key,omitempty"`
+	OldValue  *string `json:"old-value,omitempty"`
+	NewValue  *string `json:"new-value,omitempty"`
+	// TimeToLive is in seconds, 0 never expires
+	TimeToLive int `json:"time-to-live,omitempty"`
+	// ExpiresAt is when the written entry expires, nil if it never expires,
+	// replicas apply it rather than TimeToLive so they expire the entry at
+	// the same time as the leader
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+	// Flags are the client flags of the written map entry
+	Flags uint32    `json:"flags,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Options configures the change log returned by NewChangeService
+type Options struct {
+	// HistorySize is the number of changes kept in memory, at least 1
+	HistorySize int
+	// SpillDirectory receives the changes dropped from memory, they are
+	// discarded when empty
+	SpillDirectory string
+	// SpillMaxBytes bounds the size of the spilled changes, the oldest are
+	// removed first
+	SpillMaxBytes int64
+	// SegmentBytes is the size at which a new spill file is started,
+	// defaults to 16 MiB
+	SegmentBytes int64
+	// Listeners are told about the recorded changes, in order
+	Listeners []Listener
+}
+
+// Listener is told about the changes as they are recorded
+type Listener interface {
+	// Recorded receives the changes in the order of their sequence numbers
+	// while no other change is recorded, it must not block nor record changes
+	Recorded(changes []Change)
+}
+
+type ChangeServiceInterface interface {
+	// Record runs apply and appends the changes it returns to the log. No
+	// other change of the namespace is recorded while apply runs so the
+	// order of its changes in the log is the order in which they were
+	// applied, the changes of other namespaces are recorded concurrently.
+	Record(namespace string, apply func() []Change)
+
+	// Freeze runs fn while no change of the namespace can be recorded,
+	// sequence is the sequence number of the last recorded change. fn must
+	// not change the namespace.
+	Freeze(namespace string, fn func(sequence uint64))
+
+	// Read returns up to limit changes recorded after sequence, ErrTruncated
+	// is returned if some of them are no longer retained
+	Read(after uint64, limit int) ([]Change, error)
+
+	// Wait blocks until a change is recorded after sequence or ctx is done
+	Wait(ctx context.Context, after uint64) error
+
+	// Sequence returns the sequence number of the last recorded change
+	Sequence() uint64
+
+	// Oldest returns the sequence number of the oldest retained change
+	Oldest() uint64
+
+	// Token returns the resume token of the position after the given sequence number
+	Token(sequence uint64) string
+
+	// ParseToken returns the sequence number of a token returned by Token,
+	// tokens of another process are rejected with ErrInvalidToken
+	ParseToken(token string) (uint64, error)
+
+	// Close removes the spilled changes
+	Close() error
+}
+
+type changeService struct {
+	// ring holds the last len(ring) changes, change s is at s % len(ring)
+	ring     []Change
+	sequence uint64
+	// pending holds the changes dropped from the ring that the spill writer
+	// has not written yet, oldest first
+	pending []Change
+	spill   *spill
+	// spilling wakes up the spill writer, stop ends it
+	spilling chan struct{}
+	stop     chan struct{}
+	// epoch identifies this log in the resume tokens
+	epoch string
+	// recorded is closed and replaced whenever changes are recorded
+	recorded chan struct{}
+	// namespaces serializes the changes of each namespace
+	namespaces map[string]*sync.Mutex
+	lock       sync.Mutex
+	// spillLock serializes the accesses to the spill files, it is taken
+	// before lock when both are held
+	spillLock sync.Mutex
+	listeners []Listener
+}
+
+// NewChangeService returns a change log keeping the last HistorySize changes
+// in memory and spilling the older ones to disk if a directory is configured
+func NewChangeService(options Options) (ChangeServiceInterface, error) {
+	if options.HistorySize < 1 {
+		return nil, fmt.Errorf("history size must be at least 1, got %d", options.HistorySize)
+	}
+	c := &changeService{
+		ring:       make([]Change, options.HistorySize),
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		recorded:   make(chan struct{}),
+		namespaces: make(map[string]*sync.Mutex),
+		lock:       sync.Mutex{},
+		listeners:  options.Listeners,
+	}
+	if options.SpillDirectory != "" {
+		spill, err := openSpill(options.SpillDirectory, options.SpillMaxBytes, options.SegmentBytes)
+		if err != nil {
+			return nil, err
+		}
+		c.spill = spill
+		c.spilling = make(chan struct{}, 1)
+		c.stop = make(chan struct{})
+		go c.writeSpill()
+	}
+	return c, nil
+}
+
+// Record implements the Record method of the ChangeServiceInterface
+func (c *changeService) Record(namespace string, apply func() []Change) {
+	serial := c.namespaceLock(namespace)
+	serial.Lock()
+	defer serial.Unlock()
+
+	changes := apply()
+	if len(changes) == 0 {
+		return
+	}
+	now := time.Now()
+
+	c.lock.Lock()
+	for i := range changes {
+		c.sequence++
+		changes[i].Sequence = c.sequence
+		if changes[i].Time.IsZero() {
+			changes[i].Time = now
+		}
+		slot := c.sequence % uint64(len(c.ring))
+		if c.spill != nil && c.ring[slot].Sequence != 0 {
+			c.pending = append(c.pending, c.ring[slot])
+		}
+		c.ring[slot] = changes[i]
+	}
+	for _, listener := range c.listeners {
+		listener.Recorded(changes)
+	}
+	close(c.recorded)
+	c.recorded = make(chan struct{})
+	spilling := len(c.pending) > 0
+	c.lock.Unlock()
+
+	if spilling {
+		select {
+		case c.spilling <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Freeze implements the Freeze method of the ChangeServiceInterface
+func (c *changeService) Freeze(namespace string, fn func(sequence uint64)) {
+	serial := c.namespaceLock(namespace)
+	serial.Lock()
+	defer serial.Unlock()
+
+	fn(c.Sequence())
+}
+
+// Read implements the Read method of the ChangeServiceInterface
+func (c *changeService) Read(after uint64, limit int) ([]Change, error) {
+	c.lock.Lock()
+	changes, ok := c.readMemory(after, limit)
+	c.lock.Unlock()
+	if ok {
+		return changes, nil
+	}
+
+	// the spill writer holds spillLock while the changes it writes are
+	// neither pending nor readable from the segments
+	c.spillLock.Lock()
+	c.lock.Lock()
+	changes, ok = c.readMemory(after, limit)
+	spill := c.spill
+	c.lock.Unlock()
+	if ok {
+		c.spillLock.Unlock()
+		return changes, nil
+	}
+	if spill == nil || after+1 < spill.oldest() {
+		c.spillLock.Unlock()
+		return nil, ErrTruncated
+	}
+	segments, err := spill.snapshot()
+	c.spillLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return readSegments(segments, after, limit)
+}
+
+// Wait implements the Wait method of the ChangeServiceInterface
+func (c *changeService) Wait(ctx context.Context, after uint64) error {
+	c.lock.Lock()
+	sequence, recorded := c.sequence, c.recorded
+	c.lock.Unlock()
+
+	if sequence > after {
+		return nil
+	}
+	select {
+	case <-recorded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sequence implements the Sequence method of the ChangeServiceInterface
+func (c *changeService) Sequence() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.sequence
+}
+
+// Oldest implements the Oldest method of the ChangeServiceInterface
+func (c *changeService) Oldest() uint64 {
+	c.spillLock.Lock()
+	defer c.spillLock.Unlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.spill != nil && c.spill.oldest() > 0 {
+		return c.spill.oldest()
+	}
+	if len(c.pending) > 0 {
+		return c.pending[0].Sequence
+	}
+	return c.oldestInMemory()
+}
+
+// Token implements the Token method of the ChangeServiceInterface
+func (c *changeService) Token(sequence uint64) string {
+	return c.epoch + "-" + strconv.FormatUint(sequence, 10)
+}
+
+// ParseToken implements the ParseToken method of the ChangeServiceInterface
+func (c *changeService) ParseToken(token string) (uint64, error) {
+	epoch, sequence, ok := strings.Cut(token, "-")
+	if !ok || epoch != c.epoch {
+		return 0, ErrInvalidToken
+	}
+	s, err := strconv.ParseUint(sequence, 10, 64)
+	if err != nil || s > c.Sequence() {
+		return 0, ErrInvalidToken
+	}
+	return s, nil
+}
+
+// Close implements the Close method of the ChangeServiceInterface
+func (c *changeService) Close() error {
+	c.spillLock.Lock()
+	defer c.spillLock.Unlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.spill == nil {
+		return nil
+	}
+	close(c.stop)
+	err := c.spill.close()
+	c.spill = nil
+	c.pending = nil
+	return err
+}
+
+// writeSpill writes the pending changes to the spill files until Close is called
+func (c *changeService) writeSpill() {
+	for {
+		select {
+		case <-c.spilling:
+			c.flushPending()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// flushPending writes the pending changes to the spill files, spilling is
+// disabled after a write error
+func (c *changeService) flushPending() {
+	c.spillLock.Lock()
+	defer c.spillLock.Unlock()
+
+	c.lock.Lock()
+	// Record only appends to pending, the changes copied here stay at its front
+	batch, spill := c.pending, c.spill
+	c.lock.Unlock()
+	if spill == nil {
+		return
+	}
+
+	for _, change := range batch {
+		if err := spill.write(change); err != nil {
+			spill.discard(err)
+			c.lock.Lock()
+			c.spill = nil
+			c.pending = nil
+			c.lock.Unlock()
+			return
+		}
+	}
+	c.lock.Lock()
+	c.pending = c.pending[len(batch):]
+	c.lock.Unlock()
+}
+
+// namespaceLock returns the lock serializing the changes of the namespace
+func (c *changeService) namespaceLock(namespace string) *sync.Mutex {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	serial, ok := c.namespaces[namespace]
+	if !ok {
+		serial = &sync.Mutex{}
+		c.namespaces[namespace] = serial
+	}
+	return serial
+}
+
+// readMemory returns up to limit changes after the given sequence number
+// from the pending changes and the ring, ok is false if some of them are
+// only in the spill files. The lock must be held.
+func (c *changeService) readMemory(after uint64, limit int) (changes []Change, ok bool) {
+	if after+1 < c.oldestInMemory() {
+		if len(c.pending) == 0 || after+1 < c.pending[0].Sequence {
+			return nil, false
+		}
+		for i := after + 1 - c.pending[0].Sequence; i < uint64(len(c.pending)) && len(changes) < limit; i++ {
+			changes = append(changes, c.pending[i])
+		}
+		after = c.pending[len(c.pending)-1].Sequence
+	}
+	for s := after + 1; s <= c.sequence && len(changes) < limit; s++ {
+		changes = append(changes, c.ring[s%uint64(len(c.ring))])
+	}
+	return changes, true
+}
+
+// oldestInMemory returns the sequence number of the oldest change of the ring, the lock must be held
+func (c *changeService) oldestInMemory() uint64 {
+	if c.sequence < uint64(len(c.ring)) {
+		return 1
+	}
+	return c.sequence - uint64(len(c.ring)) + 1
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadReturnsSpilledChangesInOrder(t *testing.T) {
+	changes, err := NewChangeService(Options{HistorySize: 4, SpillDirectory: t.TempDir(), SegmentBytes: 512})
+	if err != nil {
+		t.Fatalf("creating the change log: %v", err)
+	}
+	defer changes.Close()
+
+	var wait sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		namespace := fmt.Sprintf("namespace-%d", n)
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				changes.Record(namespace, func() []Change {
+					return []Change{{Op: OpSet, Source: SourceMap, Namespace: namespace, Key: key}}
+				})
+			}
+		}()
+	}
+	wait.Wait()
+
+	// the spill writer may still be behind, the changes it did not write are read from memory
+	if oldest := changes.Oldest(); oldest != 1 {
+		t.Fatalf("oldest retained change is %d, want 1", oldest)
+	}
+	var read []Change
+	for uint64(len(read)) < changes.Sequence() {
+		batch, err := changes.Read(uint64(len(read)), 1000)
+		if err != nil {
+			t.Fatalf("reading the changes after %d: %v", len(read), err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		read = append(read, batch...)
+	}
+	if len(read) != 200 {
+		t.Fatalf("read %d changes, want 200", len(read))
+	}
+	next := make(map[string]int)
+	for i, change := range read {
+		if change.Sequence != uint64(i+1) {
+			t.Fatalf("change %d has sequence number %d", i+1, change.Sequence)
+		}
+		if want := fmt.Sprintf("key-%d", next[change.Namespace]); change.Key != want {
+			t.Fatalf("change %d of %s is %s, want %s", change.Sequence, change.Namespace, change.Key, want)
+		}
+		next[change.Namespace]++
+	}
+}
+
+func TestFreezeOnlyBlocksItsNamespace(t *testing.T) {
+	changes, err := NewChangeService(Options{HistorySize: 10})
+	if err != nil {
+		t.Fatalf("creating the change log: %v", err)
+	}
+
+	changes.Freeze("frozen", func(sequence uint64) {
+		recorded := make(chan struct{})
+		go func() {
+			changes.Record("other", func() []Change {
+				return []Change{{Op: OpSet, Source: SourceMap, Namespace: "other", Key: "key"}}
+			})
+			close(recorded)
+		}()
+		select {
+		case <-recorded:
+		case <-time.After(10 * time.Second):
+			t.Fatal("recording a change of another namespace waited for the freeze")
+		}
+	})
+	if got := changes.Sequence(); got != 1 {
+		t.Errorf("sequence is %d, want 1", got)
+	}
+}
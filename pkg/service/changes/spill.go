@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+)
+
+// defaultSegmentBytes is the size at which a new spill file is started
+const defaultSegmentBytes = 16 << 20
+
+// segmentPattern matches the spill files, they are named after the sequence number of their first change
+const segmentPattern = "changes-*.jsonl"
+
+// segment is a spill file holding the changes first to last as JSON lines
+type segment struct {
+	path  string
+	first uint64
+	last  uint64
+	size  int64
+}
+
+// spill appends the changes dropped from memory to segment files and
+// removes the oldest segments once they exceed maxBytes
+type spill struct {
+	directory    string
+	maxBytes     int64
+	segmentBytes int64
+	// segments are sorted oldest first, the last one is open for writing
+	segments []segment
+	size     int64
+	file     *os.File
+	writer   *bufio.Writer
+}
+
+// openSpill prepares directory for spilling, the segments of a previous process are removed
+func openSpill(directory string, maxBytes, segmentBytes int64) (*spill, error) {
+	if segmentBytes <= 0 {
+		segmentBytes = defaultSegmentBytes
+	}
+	if maxBytes > 0 && segmentBytes > maxBytes {
+		segmentBytes = maxBytes
+	}
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, fmt.Errorf("creating spill directory: %w", err)
+	}
+	stale, err := filepath.Glob(filepath.Join(directory, segmentPattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale spill file: %w", err)
+		}
+	}
+	return &spill{
+		directory:    directory,
+		maxBytes:     maxBytes,
+		segmentBytes: segmentBytes,
+	}, nil
+}
+
+// write appends a change, changes must be written in sequence order
+func (s *spill) write(change Change) error {
+	line, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if s.file == nil || s.segments[len(s.segments)-1].size >= s.segmentBytes {
+		if err := s.roll(change.Sequence); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.Write(line); err != nil {
+		return err
+	}
+	current := &s.segments[len(s.segments)-1]
+	current.last = change.Sequence
+	current.size += int64(len(line))
+	s.size += int64(len(line))
+
+	for s.maxBytes > 0 && s.size > s.maxBytes && len(s.segments) > 1 {
+		if err := os.Remove(s.segments[0].path); err != nil {
+			return err
+		}
+		s.size -= s.segments[0].size
+		s.segments = s.segments[1:]
+	}
+	return nil
+}
+
+// roll closes the current segment and starts a new one beginning with the change first
+func (s *spill) roll(first uint64) error {
+	if s.file != nil {
+		if err := s.writer.Flush(); err != nil {
+			return err
+		}
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(s.directory, fmt.Sprintf("changes-%020d.jsonl", first))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.segments = append(s.segments, segment{path: path, first: first})
+	return nil
+}
+
+// oldest returns the sequence number of the oldest spilled change, 0 if none is spilled
+func (s *spill) oldest() uint64 {
+	if len(s.segments) == 0 {
+		return 0
+	}
+	return s.segments[0].first
+}
+
+// snapshot flushes the pending writes and returns the segments as they are now
+func (s *spill) snapshot() ([]segment, error) {
+	if s.writer != nil {
+		if err := s.writer.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	return append([]segment(nil), s.segments...), nil
+}
+
+// discard removes every segment after a write error, the spilled changes are lost
+func (s *spill) discard(err error) {
+	klog.ErrorS(err, "Error spilling changes to disk, spilling is disabled", "directory", s.directory)
+	if err := s.close(); err != nil {
+		klog.ErrorS(err, "Error removing the spilled changes", "directory", s.directory)
+	}
+}
+
+// close closes the current segment and removes every segment
+func (s *spill) close() error {
+	var errs []error
+	if s.file != nil {
+		errs = append(errs, s.file.Close())
+		s.file = nil
+	}
+	for _, segment := range s.segments {
+		if err := os.Remove(segment.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	s.segments = nil
+	s.size = 0
+	return errors.Join(errs...)
+}
+
+// readSegments returns up to limit changes after the given sequence number
+// from the flushed part of the segments
+func readSegments(segments []segment, after uint64, limit int) ([]Change, error) {
+	var changes []Change
+	for _, segment := range segments {
+		if segment.last <= after {
+			continue
+		}
+		file, err := os.Open(segment.path)
+		if errors.Is(err, os.ErrNotExist) {
+			// the segment was removed to make room since the snapshot was taken
+			return nil, ErrTruncated
+		}
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bufio.NewReader(io.LimitReader(file, segment.size)))
+		for len(changes) < limit {
+			var change Change
+			if err := decoder.Decode(&change); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				file.Close()
+				return nil, fmt.Errorf("reading spilled changes: %w", err)
+			}
+			if change.Sequence > after {
+				changes = append(changes, change)
+			}
+		}
+		file.Close()
+		if len(changes) >= limit {
+			break
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	"k8s.io/klog/v2"
+)
+
+// followInterval is how often the membership is compared with the members reported by Follow
+const followInterval = time.Second
+
+// Follow implements the Follow method of the ClusterServiceInterface
+func (c *clusterService) Follow(ctx context.Context, members func() (alive, gone []string)) {
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		nodes, ok := c.follow(members())
+		if !ok {
+			continue
+		}
+		if _, err := c.ChangeMembers(ctx, nodes); err != nil {
+			klog.ErrorS(err, "Error changing the membership to the reported members", "nodes", nodes)
+		}
+	}
+}
+
+// follow returns the nodes of the membership with the alive members added
+// and the gone ones removed, and whether this node must change the
+// membership to them: they differ and this node is the first alive member
+func (c *clusterService) follow(alive, gone []string) ([]string, bool) {
+	normalized := make([]string, 0, len(alive))
+	for _, node := range alive {
+		if node, err := common.NormalizeNode(node); err == nil {
+			normalized = append(normalized, node)
+		}
+	}
+	sort.Strings(normalized)
+	if len(normalized) == 0 || normalized[0] != c.self {
+		return nil, false
+	}
+
+	current := c.Members()
+	nodes := make(map[string]bool)
+	for _, node := range current.Nodes {
+		nodes[node] = true
+	}
+	for _, node := range normalized {
+		nodes[node] = true
+	}
+	for _, node := range gone {
+		if node, err := common.NormalizeNode(node); err == nil {
+			delete(nodes, node)
+		}
+	}
+	followed := make([]string, 0, len(nodes))
+	for node := range nodes {
+		followed = append(followed, node)
+	}
+	sort.Strings(followed)
+
+	if len(followed) == len(current.Nodes) {
+		same := true
+		for i := range followed {
+			same = same && followed[i] == current.Nodes[i]
+		}
+		if same {
+			return nil, false
+		}
+	}
+	return followed, true
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+func TestFollowAddsTheAliveAndRemovesTheGoneNodes(t *testing.T) {
+	cluster, err := NewClusterService(namespaceservice.NewNamespaceService(namespaceservice.Options{}), "http://a", []string{"http://a", "http://b", "http://c"})
+	if err != nil {
+		t.Fatalf("creating the cluster service: %v", err)
+	}
+	c := cluster.(*clusterService)
+
+	for _, test := range []struct {
+		name        string
+		alive, gone []string
+		nodes       []string
+		change      bool
+	}{
+		{"unchanged", []string{"http://a", "http://b", "http://c"}, nil, nil, false},
+		{"not known yet", []string{"http://a/"}, nil, nil, false},
+		{"joined", []string{"http://a", "http://d"}, nil, []string{"http://a", "http://b", "http://c", "http://d"}, true},
+		{"left", []string{"http://a", "http://b"}, []string{"http://c"}, []string{"http://a", "http://b"}, true},
+		{"not first", []string{"http://0", "http://a", "http://d"}, []string{"http://c"}, nil, false},
+		{"invalid", []string{"a", "http://a"}, []string{"c"}, nil, false},
+	} {
+		nodes, change := c.follow(test.alive, test.gone)
+		if change != test.change || !reflect.DeepEqual(nodes, test.nodes) {
+			t.Errorf("%s: follow returned %v %t, want %v %t", test.name, nodes, change, test.nodes, test.change)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// migrationBatch is the number of entries sent to a node at once
+	migrationBatch = 500
+	// requestTimeout bounds a request to another node
+	requestTimeout = 10 * time.Second
+)
+
+// pending is an entry to migrate and the version it had when it was read,
+// it is only removed if it was not written since
+type pending struct {
+	Entry
+	version uint64
+}
+
+// migrate sends the entries owned by other nodes to their owner and removes them
+func (c *clusterService) migrate(ctx context.Context) {
+	now := time.Now()
+	byOwner := make(map[string][]pending)
+	for _, namespace := range c.namespaces.List() {
+		entries, err := namespace.Map.All(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Error reading the entries to migrate", "namespace", namespace.Name)
+			continue
+		}
+		for _, entry := range entries {
+			owner := c.Owner(namespace.Name, entry.Key)
+			if owner == c.self || owner == "" {
+				continue
+			}
+			if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+				continue
+			}
+			byOwner[owner] = append(byOwner[owner], pending{
+				Entry:   Entry{Namespace: namespace.Name, Key: entry.Key, Value: entry.Value, Flags: entry.Flags, ExpiresAt: entry.ExpiresAt},
+				version: entry.Version,
+			})
+		}
+	}
+
+	for owner, entries := range byOwner {
+		moved := 0
+		for start := 0; start < len(entries); start += migrationBatch {
+			end := start + migrationBatch
+			if end > len(entries) {
+				end = len(entries)
+			}
+			batch := make([]Entry, 0, end-start)
+			for _, entry := range entries[start:end] {
+				batch = append(batch, entry.Entry)
+			}
+			if err := c.send(ctx, owner, http.MethodPost, "/cluster/entries", batch); err != nil {
+				// the remaining entries are retried on the next rebalance
+				klog.ErrorS(err, "Error migrating entries", "owner", owner)
+				break
+			}
+			for _, entry := range entries[start:end] {
+				// an entry written since it was read stays, the next rebalance migrates it
+				if _, err := c.namespaces.Get(entry.Namespace).Map.DeleteVersion(ctx, entry.Key, entry.version); err != nil {
+					klog.ErrorS(err, "Error removing a migrated entry", "namespace", entry.Namespace, "key", entry.Key)
+				}
+			}
+			moved += len(batch)
+		}
+		if moved > 0 {
+			klog.InfoS("Migrated entries", "owner", owner, "count", moved)
+		}
+	}
+}
+
+// send sends body as JSON to a route of another node
+func (c *clusterService) send(ctx context.Context, node, method, path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, method, node+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", node, response.Status, message)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points of every node on the ring, more
+// points spread the keys more evenly
+const virtualNodes = 128
+
+// point is a position of a node on the ring
+type point struct {
+	hash uint64
+	node string
+}
+
+// ring is a consistent hash ring, a key belongs to the first point at or
+// after its hash so adding or removing a node only moves the keys of the
+// ranges it gains or loses
+type ring struct {
+	points []point
+}
+
+func newRing(nodes []string) *ring {
+	r := &ring{points: make([]point, 0, len(nodes)*virtualNodes)}
+	for _, node := range nodes {
+		for i := 0; i < virtualNodes; i++ {
+			r.points = append(r.points, point{hash: hash(node + "#" + strconv.Itoa(i)), node: node})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash == r.points[j].hash {
+			return r.points[i].node < r.points[j].node
+		}
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// owner returns the node owning the key of the namespace, empty if the ring has no node
+func (r *ring) owner(namespace, key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(namespace + "\x00" + key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].node
+}
+
+// hash is FNV-1a followed by the murmur3 finalizer, which spreads the
+// hashes of strings differing only in their last characters such as the
+// virtual nodes of a node
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// rebalanceInterval is how often the entries owned by other nodes are
+// migrated besides after every membership change, it retries the entries
+// whose migration failed
+const rebalanceInterval = 30 * time.Second
+
+var (
+	// ErrStaleMembership is returned when a membership older than the current one is received
+	ErrStaleMembership = errors.New("membership is older than the current one")
+	// ErrNotDelivered is returned when a membership change was applied but
+	// could not be sent to some nodes, sending it again delivers it
+	ErrNotDelivered = errors.New("membership could not be sent to some nodes")
+)
+
+// Membership is the list of nodes of the cluster, a membership replaces the
+// current one only if its version is higher
+type Membership struct {
+	Version uint64   `json:"version"`
+	Nodes   []string `json:"nodes"`
+}
+
+// Entry is a map entry migrated to its owner, ExpiresAt is the zero time
+// if it never expires
+type Entry struct {
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Flags     uint32    `json:"flags,omitempty"`
+	ExpiresAt time.Time `json:"expires-at,omitempty"`
+}
+
+type ClusterServiceInterface interface {
+	// Self returns the URL of this node
+	Self() string
+
+	// Owner returns the URL of the node owning the key of the map of the namespace
+	Owner(namespace, key string) string
+
+	// Members returns the current membership
+	Members() Membership
+
+	// SetMembers replaces the membership by a newer one and migrates the
+	// entries this node no longer owns, ErrStaleMembership is returned if
+	// it is not newer than the current one
+	SetMembers(membership Membership) error
+
+	// ChangeMembers replaces the membership by nodes with the next version
+	// and sends it to every node of the old and the new membership,
+	// ErrNotDelivered is returned if some nodes could not be reached
+	ChangeMembers(ctx context.Context, nodes []string) (Membership, error)
+
+	// Follow keeps the membership in line with the nodes reported by
+	// members, such as the members of the gossip, every followInterval until
+	// ctx is done. The alive nodes are added and the gone ones removed, the
+	// nodes members does not know about yet are kept. Only the first alive
+	// node in sort order changes the membership so that the nodes do not
+	// race to publish their own version of it.
+	Follow(ctx context.Context, members func() (alive, gone []string))
+
+	// Receive stores the entries migrated to this node, the keys already
+	// written on this node are kept since they are newer than the migrated
+	// copy. An error means some entries were not stored and must be sent again.
+	Receive(ctx context.Context, entries []Entry) error
+
+	// Run migrates the entries owned by other nodes after every membership
+	// change and every rebalanceInterval until ctx is done
+	Run(ctx context.Context)
+}
+
+type clusterService struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	self       string
+	membership Membership
+	ring       *ring
+	// rebalance is signaled when the membership changed
+	rebalance chan struct{}
+	lock      sync.RWMutex
+}
+
+// NewClusterService returns the cluster service of the node at the URL self
+// whose initial members are nodes
+func NewClusterService(namespaces namespaceservice.NamespaceServiceInterface, self string, nodes []string) (ClusterServiceInterface, error) {
+	self, err := common.NormalizeNode(self)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := newMembership(1, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return &clusterService{
+		namespaces: namespaces,
+		self:       self,
+		membership: membership,
+		ring:       newRing(membership.Nodes),
+		rebalance:  make(chan struct{}, 1),
+		lock:       sync.RWMutex{},
+	}, nil
+}
+
+// newMembership returns the membership of the normalized, sorted and deduplicated nodes
+func newMembership(version uint64, nodes []string) (Membership, error) {
+	seen := make(map[string]bool)
+	membership := Membership{Version: version, Nodes: []string{}}
+	for _, node := range nodes {
+		node, err := common.NormalizeNode(node)
+		if err != nil {
+			return membership, err
+		}
+		if !seen[node] {
+			seen[node] = true
+			membership.Nodes = append(membership.Nodes, node)
+		}
+	}
+	sort.Strings(membership.Nodes)
+	return membership, nil
+}
+
+// Self implements the Self method of the ClusterServiceInterface
+func (c *clusterService) Self() string {
+	return c.self
+}
+
+// Owner implements the Owner method of the ClusterServiceInterface
+func (c *clusterService) Owner(namespace, key string) string {
+	if namespace == "" {
+		namespace = namespaceservice.DefaultNamespace
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ring.owner(namespace, key)
+}
+
+// Members implements the Members method of the ClusterServiceInterface
+func (c *clusterService) Members() Membership {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return Membership{Version: c.membership.Version, Nodes: append([]string(nil), c.membership.Nodes...)}
+}
+
+// SetMembers implements the SetMembers method of the ClusterServiceInterface
+func (c *clusterService) SetMembers(membership Membership) error {
+	membership, err := newMembership(membership.Version, membership.Nodes)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if membership.Version <= c.membership.Version {
+		return ErrStaleMembership
+	}
+	c.membership = membership
+	c.ring = newRing(membership.Nodes)
+	klog.InfoS("Cluster membership changed", "version", membership.Version, "nodes", membership.Nodes)
+	select {
+	case c.rebalance <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ChangeMembers implements the ChangeMembers method of the ClusterServiceInterface
+func (c *clusterService) ChangeMembers(ctx context.Context, nodes []string) (Membership, error) {
+	old := c.Members()
+	membership, err := newMembership(old.Version+1, nodes)
+	if err != nil {
+		return membership, err
+	}
+	if err := c.SetMembers(membership); err != nil {
+		return membership, err
+	}
+
+	targets := make(map[string]bool)
+	for _, node := range append(old.Nodes, membership.Nodes...) {
+		if node != c.self {
+			targets[node] = true
+		}
+	}
+	var errs []error
+	for node := range targets {
+		if err := c.send(ctx, node, http.MethodPut, "/cluster/members", membership); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", node, err))
+		}
+	}
+	if len(errs) > 0 {
+		return membership, fmt.Errorf("%w: %w", ErrNotDelivered, errors.Join(errs...))
+	}
+	return membership, nil
+}
+
+// Receive implements the Receive method of the ClusterServiceInterface
+func (c *clusterService) Receive(ctx context.Context, entries []Entry) error {
+	now := time.Now()
+	stored := 0
+	for _, entry := range entries {
+		if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+			continue
+		}
+		_, err := c.namespaces.Get(entry.Namespace).Map.Store(ctx, mapRepository.CacheEntry{
+			Key:       entry.Key,
+			Value:     entry.Value,
+			Flags:     entry.Flags,
+			ExpiresAt: entry.ExpiresAt,
+		}, mapRepository.IfAbsent)
+		switch {
+		case errors.Is(err, mapRepository.ErrExists):
+		case err != nil:
+			return fmt.Errorf("storing %q of namespace %q: %w", entry.Key, entry.Namespace, err)
+		default:
+			stored++
+		}
+	}
+	klog.V(2).InfoS("Received migrated entries", "count", len(entries), "stored", stored)
+	return nil
+}
+
+// Run implements the Run method of the ClusterServiceInterface
+func (c *clusterService) Run(ctx context.Context) {
+	ticker := time.NewTicker(rebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.rebalance:
+		}
+		c.migrate(ctx)
+	}
+}
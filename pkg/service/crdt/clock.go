@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// maxDrift is how far ahead of the local time a timestamp received from a
+// peer may move the clock, a peer with a clock running further ahead would
+// otherwise win every conflict until the other clocks caught up
+const maxDrift = time.Minute
+
+// Timestamp is a hybrid logical clock timestamp, it follows the physical
+// time while ordering the events causally, and the node breaks the ties so
+// that every node orders the timestamps the same way
+type Timestamp struct {
+	// Wall is the physical time in nanoseconds since the epoch
+	Wall int64 `json:"wall"`
+	// Logical orders the events of the same wall time
+	Logical uint32 `json:"logical"`
+	Node    string `json:"node"`
+}
+
+// After reports whether t orders after other
+func (t Timestamp) After(other Timestamp) bool {
+	if t.Wall != other.Wall {
+		return t.Wall > other.Wall
+	}
+	if t.Logical != other.Logical {
+		return t.Logical > other.Logical
+	}
+	return t.Node > other.Node
+}
+
+// Time returns the wall time of the timestamp
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, t.Wall)
+}
+
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.%d@%s", t.Wall, t.Logical, t.Node)
+}
+
+// clock is the hybrid logical clock of a node
+type clock struct {
+	node    string
+	wall    int64
+	logical uint32
+	lock    sync.Mutex
+}
+
+// now returns a timestamp after every timestamp returned or observed before
+func (c *clock) now() Timestamp {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if wall := time.Now().UnixNano(); wall > c.wall {
+		c.wall, c.logical = wall, 0
+	} else {
+		c.logical++
+	}
+	return Timestamp{Wall: c.wall, Logical: c.logical, Node: c.node}
+}
+
+// observe moves the clock past a timestamp received from a peer
+func (c *clock) observe(t Timestamp) {
+	if t.Wall > time.Now().Add(maxDrift).UnixNano() {
+		klog.V(2).InfoS("Ignoring the clock of a peer running ahead", "timestamp", t, "maxDrift", maxDrift)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	switch {
+	case t.Wall > c.wall:
+		c.wall, c.logical = t.Wall, t.Logical
+	case t.Wall == c.wall && t.Logical > c.logical:
+		c.logical = t.Logical
+	}
+}
+
+// last returns the last timestamp of the clock
+func (c *clock) last() Timestamp {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return Timestamp{Wall: c.wall, Logical: c.logical, Node: c.node}
+}
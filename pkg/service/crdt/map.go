@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+)
+
+// WrapMap implements the WrapMap method of the CRDTServiceInterface
+func (c *crdtService) WrapMap(namespace string, m mapservice.MapServiceInterface) mapservice.MapServiceInterface {
+	c.register(namespace, m)
+	return &crdtMap{
+		MapServiceInterface: m,
+		namespace:           namespace,
+		crdt:                c,
+	}
+}
+
+// crdtMap records the writes of the map it wraps in registers sent to the
+// peers. The writes are accepted locally whatever the peers, the conditions
+// of the conditional writes only hold against the writes this node has
+// seen so far. The reads are served by the map it wraps.
+type crdtMap struct {
+	mapservice.MapServiceInterface
+	namespace string
+	crdt      *crdtService
+}
+
+// lock locks the bucket of the register of the key and returns the
+// function unlocking it, the writes of the keys of the other buckets go on
+// meanwhile
+func (m *crdtMap) lock(key string) func() {
+	b := m.crdt.bucketOf(kindRegister, m.namespace, key)
+	b.lock.Lock()
+	return b.lock.Unlock
+}
+
+// recordEntry records the write of the key if it is found in the wrapped
+// map, the lock of the bucket of the key must be held
+func (m *crdtMap) recordEntry(ctx context.Context, key string) {
+	if entry, ok, _ := m.MapServiceInterface.GetEntry(ctx, key); ok {
+		m.crdt.write(m.namespace, key, &entry)
+	}
+}
+
+// Set implements the Set method of the MapServiceInterface
+func (m *crdtMap) Set(ctx context.Context, key, value string) (string, error) {
+	_, err := m.Store(ctx, mapRepository.CacheEntry{Key: key, Value: value}, mapRepository.Always)
+	return key, err
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the MapServiceInterface
+func (m *crdtMap) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (string, error) {
+	_, err := m.Store(ctx, mapRepository.CacheEntry{Key: key, Value: value, TTL: time.Duration(ttl) * time.Second}, mapRepository.Always)
+	return key, err
+}
+
+// Store implements the Store method of the MapServiceInterface
+func (m *crdtMap) Store(ctx context.Context, entry mapRepository.CacheEntry, condition mapRepository.Condition) (mapRepository.CacheEntry, error) {
+	unlock := m.lock(entry.Key)
+	defer unlock()
+
+	stored, err := m.MapServiceInterface.Store(ctx, entry, condition)
+	if err == nil {
+		m.crdt.write(m.namespace, stored.Key, &stored)
+	}
+	return stored, err
+}
+
+// Modify implements the Modify method of the MapServiceInterface
+func (m *crdtMap) Modify(ctx context.Context, key string, fn func(value string) (string, error)) (mapRepository.CacheEntry, error) {
+	unlock := m.lock(key)
+	defer unlock()
+
+	entry, err := m.MapServiceInterface.Modify(ctx, key, fn)
+	if err == nil {
+		m.recordEntry(ctx, key)
+	}
+	return entry, err
+}
+
+// UpdateCacheEntry implements the UpdateCacheEntry method of the MapServiceInterface
+func (m *crdtMap) UpdateCacheEntry(ctx context.Context, key, value string) (bool, error) {
+	unlock := m.lock(key)
+	defer unlock()
+
+	ok, err := m.MapServiceInterface.UpdateCacheEntry(ctx, key, value)
+	if ok {
+		m.recordEntry(ctx, key)
+	}
+	return ok, err
+}
+
+// Delete implements the Delete method of the MapServiceInterface
+func (m *crdtMap) Delete(ctx context.Context, key string) (bool, error) {
+	unlock := m.lock(key)
+	defer unlock()
+
+	ok, err := m.MapServiceInterface.Delete(ctx, key)
+	if ok {
+		m.crdt.write(m.namespace, key, nil)
+	}
+	return ok, err
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapServiceInterface
+func (m *crdtMap) DeleteVersion(ctx context.Context, key string, version uint64) (bool, error) {
+	unlock := m.lock(key)
+	defer unlock()
+
+	ok, err := m.MapServiceInterface.DeleteVersion(ctx, key, version)
+	if ok {
+		m.crdt.write(m.namespace, key, nil)
+	}
+	return ok, err
+}
+
+// Expire implements the Expire method of the MapServiceInterface
+func (m *crdtMap) Expire(ctx context.Context, key string, ttl int) (bool, error) {
+	unlock := m.lock(key)
+	defer unlock()
+
+	ok, err := m.MapServiceInterface.Expire(ctx, key, ttl)
+	if ok {
+		m.recordEntry(ctx, key)
+	}
+	return ok, err
+}
+
+// Flush implements the Flush method of the MapServiceInterface, only the
+// entries this node has seen are deleted on the peers
+func (m *crdtMap) Flush(ctx context.Context) {
+	// the flush is not interleaved with the writes of any key
+	for i := range m.crdt.buckets {
+		m.crdt.buckets[i].lock.Lock()
+		defer m.crdt.buckets[i].lock.Unlock()
+	}
+
+	// the wrapped map is local, reading it does not fail
+	entries, _ := m.MapServiceInterface.All(ctx)
+	for _, entry := range entries {
+		m.crdt.write(m.namespace, entry.Key, nil)
+	}
+	m.MapServiceInterface.Flush(ctx)
+}
@@ -0,0 +1,518 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// DefaultSyncInterval is how often the state is compared with every peer by default
+const DefaultSyncInterval = 10 * time.Second
+
+const (
+	// digestBuckets is the number of buckets the state is hashed into, the
+	// anti-entropy only exchanges the buckets that differ between two nodes
+	digestBuckets = 256
+	// pushInterval is how often the local writes are sent to the peers
+	pushInterval = 100 * time.Millisecond
+	// maxPending is the number of local writes waiting to be sent, the
+	// writes beyond are left to the anti-entropy
+	maxPending = 10000
+	// tombstoneTimeout is how long the deleted and expired entries are
+	// remembered, a peer partitioned for longer may bring them back
+	tombstoneTimeout = time.Hour
+	// gcInterval is how often the tombstones older than tombstoneTimeout are forgotten
+	gcInterval = time.Minute
+)
+
+// Kinds of the state, hashed along with the keys so that a register, a
+// counter and a set of the same key land in different buckets
+const (
+	kindRegister = "register"
+	kindCounter  = "counter"
+	kindSet      = "set"
+)
+
+// Options configures the active-active mode
+type Options struct {
+	// Self is the URL of the HTTP API of this node
+	Self string
+	// Peers are the URLs of the HTTP API of the other nodes
+	Peers []string
+	// SyncInterval is how often the state is compared with every peer, DefaultSyncInterval if zero
+	SyncInterval time.Duration
+}
+
+// PeerStatus is the state of the synchronization with a peer
+type PeerStatus struct {
+	Name string
+	// LastSync is when the last anti-entropy exchange succeeded, the zero time if none did
+	LastSync time.Time
+	// Error is the error of the last exchange, empty if it succeeded
+	Error string
+}
+
+// Status is the state of the active-active mode on this node
+type Status struct {
+	Self string
+	// Replica identifies this node in the timestamps and the counters
+	Replica   string
+	Clock     Timestamp
+	Registers int
+	Counters  int
+	Sets      int
+	Peers     []PeerStatus
+}
+
+type CRDTServiceInterface interface {
+	// Status returns the clock of this node and the synchronization with its peers
+	Status() Status
+
+	// WrapMap returns the map of a namespace recording its writes in
+	// registers sent to the peers and applying the registers they send,
+	// it is meant to wrap the maps of the namespace service
+	WrapMap(namespace string, m mapservice.MapServiceInterface) mapservice.MapServiceInterface
+
+	// Increment adds delta to the counter of the key, creating it, and returns its value
+	Increment(namespace, key string, delta int64) int64
+
+	// Counter returns the value of the counter of the key and whether it exists
+	Counter(namespace, key string) (int64, bool)
+
+	// UpdateSet adds and removes elements of the set of the key, creating
+	// it, and returns its elements
+	UpdateSet(namespace, key string, add, remove []string) []string
+
+	// Set returns the elements of the set of the key and whether it exists
+	Set(namespace, key string) ([]string, bool)
+
+	// Merge merges the state sent by a peer
+	Merge(request MergeRequest)
+
+	// Sync compares the digest of a peer with the one of this node and
+	// returns the state of this node in the buckets that differ
+	Sync(request SyncRequest) SyncResponse
+
+	// Run sends the writes to the peers and compares the state with them
+	// until ctx is done
+	Run(ctx context.Context)
+}
+
+type crdtService struct {
+	self         string
+	replica      string
+	peers        []string
+	syncInterval time.Duration
+	namespaces   namespaceservice.NamespaceServiceInterface
+	client       *http.Client
+
+	clock *clock
+	// buckets hold the state, the writes of keys in different buckets do
+	// not wait for each other
+	buckets [digestBuckets]stateBucket
+
+	// pending are the local writes to send to the peers
+	pending     State
+	dropped     int
+	pendingLock sync.Mutex
+
+	status     map[string]*PeerStatus
+	statusLock sync.Mutex
+
+	// maps are the maps of the namespaces, unwrapped
+	maps     map[string]mapservice.MapServiceInterface
+	mapsLock sync.Mutex
+}
+
+// stateBucket is the part of the state hashed into a digest bucket, its
+// lock is held while it changes and the maps of the namespaces change
+// along with their registers in the bucket
+type stateBucket struct {
+	lock      sync.Mutex
+	registers map[stateKey]*Register
+	counters  map[stateKey]*Counter
+	sets      map[stateKey]*Set
+	// digest combines the fingerprints of the state of the bucket
+	digest uint64
+}
+
+// stateKey identifies a key of a namespace in the state
+type stateKey struct {
+	namespace string
+	key       string
+}
+
+// NewCRDTService returns the active-active mode of the node options.Self
+// for the maps of namespaces
+func NewCRDTService(namespaces namespaceservice.NamespaceServiceInterface, options Options) (CRDTServiceInterface, error) {
+	self, err := common.NormalizeNode(options.Self)
+	if err != nil {
+		return nil, err
+	}
+	var peers []string
+	for _, peer := range options.Peers {
+		peer, err := common.NormalizeNode(peer)
+		if err != nil {
+			return nil, err
+		}
+		if peer != self {
+			peers = append(peers, peer)
+		}
+	}
+	if options.SyncInterval <= 0 {
+		options.SyncInterval = DefaultSyncInterval
+	}
+
+	// the counters of a replica only grow, a restarted node starts a new
+	// replica since it does not remember the totals of the previous one
+	replica := fmt.Sprintf("%s#%d", self, time.Now().UnixNano())
+	c := &crdtService{
+		self:         self,
+		replica:      replica,
+		peers:        peers,
+		syncInterval: options.SyncInterval,
+		namespaces:   namespaces,
+		client:       &http.Client{},
+		clock:        &clock{node: replica},
+		status:       make(map[string]*PeerStatus),
+		maps:         make(map[string]mapservice.MapServiceInterface),
+	}
+	for i := range c.buckets {
+		c.buckets[i].registers = make(map[stateKey]*Register)
+		c.buckets[i].counters = make(map[stateKey]*Counter)
+		c.buckets[i].sets = make(map[stateKey]*Set)
+	}
+	for _, peer := range peers {
+		c.status[peer] = &PeerStatus{Name: peer}
+	}
+	return c, nil
+}
+
+// bucket returns the digest bucket of a key
+func bucket(kind, namespace, key string) int {
+	h := fnv.New32a()
+	for _, value := range []string{kind, namespace, key} {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return int(h.Sum32() % digestBuckets)
+}
+
+// bucketOf returns the bucket of the state holding a key
+func (c *crdtService) bucketOf(kind, namespace, key string) *stateBucket {
+	return &c.buckets[bucket(kind, namespace, key)]
+}
+
+// Status implements the Status method of the CRDTServiceInterface
+func (c *crdtService) Status() Status {
+	status := Status{Self: c.self, Replica: c.replica, Clock: c.clock.last()}
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		b.lock.Lock()
+		status.Registers += len(b.registers)
+		status.Counters += len(b.counters)
+		status.Sets += len(b.sets)
+		b.lock.Unlock()
+	}
+
+	c.statusLock.Lock()
+	defer c.statusLock.Unlock()
+	for _, peer := range c.peers {
+		status.Peers = append(status.Peers, *c.status[peer])
+	}
+	return status
+}
+
+// register records the unwrapped map of a namespace
+func (c *crdtService) register(namespace string, m mapservice.MapServiceInterface) {
+	c.mapsLock.Lock()
+	defer c.mapsLock.Unlock()
+
+	c.maps[namespace] = m
+}
+
+// mapOf returns the unwrapped map of a namespace, creating the namespace if needed
+func (c *crdtService) mapOf(namespace string) mapservice.MapServiceInterface {
+	// getting the namespace creates it again if it was deleted, which
+	// registers its new map
+	c.namespaces.Get(namespace)
+
+	c.mapsLock.Lock()
+	defer c.mapsLock.Unlock()
+	return c.maps[namespace]
+}
+
+// putRegister stores a register if it is newer than the stored one and
+// reports whether it did, the lock of the bucket must be held
+func (b *stateBucket) putRegister(register Register) bool {
+	key := stateKey{register.Namespace, register.Key}
+	if old, ok := b.registers[key]; ok {
+		if !register.Timestamp.After(old.Timestamp) {
+			return false
+		}
+		b.digest ^= old.fingerprint()
+	}
+	b.digest ^= register.fingerprint()
+	b.registers[key] = &register
+	return true
+}
+
+// counter returns the counter of the key, creating it, the lock of the bucket must be held
+func (b *stateBucket) counter(namespace, key string) *Counter {
+	counter, ok := b.counters[stateKey{namespace, key}]
+	if !ok {
+		counter = newCounter(namespace, key)
+		b.counters[stateKey{namespace, key}] = counter
+		b.digest ^= counter.fingerprint()
+	}
+	return counter
+}
+
+// set returns the set of the key, creating it, the lock of the bucket must be held
+func (b *stateBucket) set(namespace, key string) *Set {
+	set, ok := b.sets[stateKey{namespace, key}]
+	if !ok {
+		set = newSet(namespace, key)
+		b.sets[stateKey{namespace, key}] = set
+		b.digest ^= set.fingerprint()
+	}
+	return set
+}
+
+// write records a local write of the map of a namespace, entry is the
+// entry written, nil for a deletion, the lock of the bucket of the
+// register must be held
+func (c *crdtService) write(namespace, key string, entry *mapRepository.CacheEntry) {
+	register := Register{Namespace: namespace, Key: key, Deleted: entry == nil, Timestamp: c.clock.now()}
+	if entry != nil {
+		register.Value = entry.Value
+		register.Flags = entry.Flags
+		register.ExpiresAt = entry.ExpiresAt
+	}
+	c.bucketOf(kindRegister, namespace, key).putRegister(register)
+	c.queue(State{Registers: []Register{register}})
+}
+
+// apply writes a register received from a peer to the map of its
+// namespace, the lock of the bucket of the register must be held
+func (c *crdtService) apply(register Register) {
+	ctx := context.Background()
+	m := c.mapOf(register.Namespace)
+	if register.dead(time.Now()) {
+		m.Delete(ctx, register.Key)
+		return
+	}
+	entry := mapRepository.CacheEntry{Key: register.Key, Value: register.Value, Flags: register.Flags}
+	if !register.ExpiresAt.IsZero() {
+		entry.TTL = time.Until(register.ExpiresAt)
+	}
+	m.Store(ctx, entry, mapRepository.Always)
+}
+
+// queue adds local writes to the ones waiting to be sent to the peers,
+// they are left to the anti-entropy beyond maxPending
+func (c *crdtService) queue(state State) {
+	if len(c.peers) == 0 {
+		return
+	}
+	c.pendingLock.Lock()
+	defer c.pendingLock.Unlock()
+
+	if c.pending.size() >= maxPending {
+		c.dropped += state.size()
+		return
+	}
+	c.pending.Registers = append(c.pending.Registers, state.Registers...)
+	c.pending.Counters = append(c.pending.Counters, state.Counters...)
+	c.pending.Sets = append(c.pending.Sets, state.Sets...)
+}
+
+// Increment implements the Increment method of the CRDTServiceInterface
+func (c *crdtService) Increment(namespace, key string, delta int64) int64 {
+	b := c.bucketOf(kindCounter, namespace, key)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	counter := b.counter(namespace, key)
+	b.digest ^= counter.fingerprint()
+	if delta >= 0 {
+		counter.Increments[c.replica] += uint64(delta)
+	} else {
+		counter.Decrements[c.replica] += uint64(-delta)
+	}
+	b.digest ^= counter.fingerprint()
+	c.queue(State{Counters: []Counter{cloneCounter(counter)}})
+	return counter.value()
+}
+
+// Counter implements the Counter method of the CRDTServiceInterface
+func (c *crdtService) Counter(namespace, key string) (int64, bool) {
+	b := c.bucketOf(kindCounter, namespace, key)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	counter, ok := b.counters[stateKey{namespace, key}]
+	if !ok {
+		return 0, false
+	}
+	return counter.value(), true
+}
+
+// UpdateSet implements the UpdateSet method of the CRDTServiceInterface
+func (c *crdtService) UpdateSet(namespace, key string, add, remove []string) []string {
+	b := c.bucketOf(kindSet, namespace, key)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	set := b.set(namespace, key)
+	b.digest ^= set.fingerprint()
+	for _, element := range remove {
+		set.remove(element)
+	}
+	for _, element := range add {
+		set.Tags[c.clock.now().String()] = element
+	}
+	b.digest ^= set.fingerprint()
+	c.queue(State{Sets: []Set{cloneSet(set)}})
+	return set.elements()
+}
+
+// Set implements the Set method of the CRDTServiceInterface
+func (c *crdtService) Set(namespace, key string) ([]string, bool) {
+	b := c.bucketOf(kindSet, namespace, key)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	set, ok := b.sets[stateKey{namespace, key}]
+	if !ok {
+		return nil, false
+	}
+	return set.elements(), true
+}
+
+// Merge implements the Merge method of the CRDTServiceInterface
+func (c *crdtService) Merge(request MergeRequest) {
+	c.merge(request.State)
+}
+
+// merge merges the state of a peer, locking the bucket of every part in turn
+func (c *crdtService) merge(state State) {
+	horizon := time.Now().Add(-tombstoneTimeout)
+	for _, register := range state.Registers {
+		c.clock.observe(register.Timestamp)
+		// a forgotten tombstone is not brought back by a peer which did not forget it yet
+		if register.diedBefore(horizon) {
+			continue
+		}
+		b := c.bucketOf(kindRegister, register.Namespace, register.Key)
+		b.lock.Lock()
+		if b.putRegister(register) {
+			c.apply(register)
+		}
+		b.lock.Unlock()
+	}
+	for _, other := range state.Counters {
+		b := c.bucketOf(kindCounter, other.Namespace, other.Key)
+		b.lock.Lock()
+		counter := b.counter(other.Namespace, other.Key)
+		before := counter.fingerprint()
+		if counter.merge(other) {
+			b.digest ^= before ^ counter.fingerprint()
+		}
+		b.lock.Unlock()
+	}
+	for _, other := range state.Sets {
+		b := c.bucketOf(kindSet, other.Namespace, other.Key)
+		b.lock.Lock()
+		set := b.set(other.Namespace, other.Key)
+		before := set.fingerprint()
+		if set.merge(other) {
+			b.digest ^= before ^ set.fingerprint()
+		}
+		b.lock.Unlock()
+	}
+}
+
+// Sync implements the Sync method of the CRDTServiceInterface
+func (c *crdtService) Sync(request SyncRequest) SyncResponse {
+	digest := c.digest()
+	var buckets []int
+	for b := range digest {
+		if len(request.Digest) != digestBuckets || request.Digest[b] != digest[b] {
+			buckets = append(buckets, b)
+		}
+	}
+	return SyncResponse{Buckets: buckets, State: c.stateOf(buckets)}
+}
+
+// digest returns the digest of every bucket
+func (c *crdtService) digest() []uint64 {
+	digest := make([]uint64, digestBuckets)
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		b.lock.Lock()
+		digest[i] = b.digest
+		b.lock.Unlock()
+	}
+	return digest
+}
+
+// stateOf returns a copy of the state in the buckets
+func (c *crdtService) stateOf(buckets []int) State {
+	var state State
+	for _, i := range buckets {
+		if i < 0 || i >= digestBuckets {
+			continue
+		}
+		b := &c.buckets[i]
+		b.lock.Lock()
+		for _, register := range b.registers {
+			state.Registers = append(state.Registers, *register)
+		}
+		for _, counter := range b.counters {
+			state.Counters = append(state.Counters, cloneCounter(counter))
+		}
+		for _, set := range b.sets {
+			state.Sets = append(state.Sets, cloneSet(set))
+		}
+		b.lock.Unlock()
+	}
+	return state
+}
+
+// gc forgets the tombstones and the expired entries older than tombstoneTimeout
+func (c *crdtService) gc() {
+	horizon := time.Now().Add(-tombstoneTimeout)
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		b.lock.Lock()
+		for key, register := range b.registers {
+			if register.diedBefore(horizon) {
+				b.digest ^= register.fingerprint()
+				delete(b.registers, key)
+			}
+		}
+		b.lock.Unlock()
+	}
+}
+
+func cloneCounter(counter *Counter) Counter {
+	clone := *newCounter(counter.Namespace, counter.Key)
+	mergeTotals(clone.Increments, counter.Increments)
+	mergeTotals(clone.Decrements, counter.Decrements)
+	return clone
+}
+
+func cloneSet(set *Set) Set {
+	clone := *newSet(set.Namespace, set.Key)
+	clone.merge(*set)
+	return clone
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// testNode is a node in active-active mode whose maps are wrapped
+type testNode struct {
+	*crdtService
+	namespaces namespaceservice.NamespaceServiceInterface
+}
+
+// newNode returns the node self of the peers
+func newNode(t *testing.T, self string, peers ...string) *testNode {
+	t.Helper()
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	crdt, err := NewCRDTService(namespaces, Options{Self: self, Peers: peers})
+	if err != nil {
+		t.Fatalf("creating the active-active mode of %s: %v", self, err)
+	}
+	namespaces.WrapMaps(crdt.WrapMap)
+	return &testNode{crdtService: crdt.(*crdtService), namespaces: namespaces}
+}
+
+// get returns the value of the key in the default namespace, empty if it is not found
+func (n *testNode) get(t *testing.T, key string) string {
+	t.Helper()
+	value, _, err := n.namespaces.Get("").Map.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("getting %s: %v", key, err)
+	}
+	return value
+}
+
+// exchange sends to the node the state of from differing from its own, as
+// the anti-entropy does
+func exchange(to, from *testNode) {
+	to.Merge(MergeRequest{From: from.self, State: from.Sync(SyncRequest{From: to.self, Digest: to.digest()}).State})
+}
+
+func TestClockOrdersTheTimestamps(t *testing.T) {
+	c := &clock{node: "a"}
+	first := c.now()
+	second := c.now()
+	if !second.After(first) {
+		t.Fatalf("%s does not order after %s", second, first)
+	}
+
+	// a peer slightly ahead moves the clock past its timestamp
+	ahead := Timestamp{Wall: time.Now().Add(time.Second).UnixNano(), Logical: 3, Node: "b"}
+	c.observe(ahead)
+	if now := c.now(); !now.After(ahead) {
+		t.Fatalf("%s does not order after the observed %s", now, ahead)
+	}
+
+	// a peer too far ahead is ignored
+	drifted := Timestamp{Wall: time.Now().Add(2 * maxDrift).UnixNano(), Node: "b"}
+	c.observe(drifted)
+	if last := c.last(); last.Wall >= drifted.Wall {
+		t.Fatalf("the clock moved to %s past the maximum drift", last)
+	}
+
+	// the node breaks the ties
+	tie := Timestamp{Wall: 1, Logical: 1, Node: "a"}
+	if !(Timestamp{Wall: 1, Logical: 1, Node: "b"}).After(tie) || tie.After(tie) {
+		t.Fatal("the ties are not broken by the node")
+	}
+}
+
+func TestLastWriterWins(t *testing.T) {
+	ctx := context.Background()
+	a, b := newNode(t, "http://a"), newNode(t, "http://b")
+
+	a.namespaces.Get("").Map.Set(ctx, "key", "a")
+	old := a.Sync(SyncRequest{}).State
+	// b has seen the write of a, its own write orders after it
+	exchange(b, a)
+	b.namespaces.Get("").Map.Set(ctx, "key", "b")
+
+	exchange(a, b)
+	if value := a.get(t, "key"); value != "b" {
+		t.Fatalf("a holds %q, want the last write", value)
+	}
+	// the earlier write received again is ignored
+	b.Merge(MergeRequest{From: a.self, State: old})
+	if value := b.get(t, "key"); value != "b" {
+		t.Fatalf("b holds %q after an earlier write, want the last write", value)
+	}
+
+	// the deletion leaves a tombstone winning over the earlier writes
+	a.namespaces.Get("").Map.Delete(ctx, "key")
+	exchange(b, a)
+	b.Merge(MergeRequest{From: a.self, State: old})
+	if value := b.get(t, "key"); value != "" {
+		t.Fatalf("b holds %q after the deletion", value)
+	}
+	if !reflect.DeepEqual(a.digest(), b.digest()) {
+		t.Fatal("the digests differ once the nodes converged")
+	}
+}
+
+func TestCountersAddUpTheReplicas(t *testing.T) {
+	a, b := newNode(t, "http://a"), newNode(t, "http://b")
+
+	a.Increment("", "hits", 2)
+	b.Increment("", "hits", 3)
+	b.Increment("", "hits", -1)
+	for i := 0; i < 2; i++ {
+		// merging the same totals again changes nothing
+		exchange(a, b)
+		exchange(b, a)
+	}
+	for _, node := range []*testNode{a, b} {
+		if value, ok := node.Counter("", "hits"); !ok || value != 4 {
+			t.Fatalf("%s counts %d, want 4", node.self, value)
+		}
+	}
+	if _, ok := a.Counter("", "missing"); ok {
+		t.Fatal("a missing counter exists")
+	}
+}
+
+func TestSetKeepsTheAdditionsTheRemovalDidNotSee(t *testing.T) {
+	a, b := newNode(t, "http://a"), newNode(t, "http://b")
+
+	a.UpdateSet("", "tags", []string{"x", "y"}, nil)
+	exchange(b, a)
+	// b removes x while a adds it again
+	b.UpdateSet("", "tags", nil, []string{"x"})
+	a.UpdateSet("", "tags", []string{"x"}, nil)
+	exchange(a, b)
+	exchange(b, a)
+
+	for _, node := range []*testNode{a, b} {
+		if elements, _ := node.Set("", "tags"); !reflect.DeepEqual(elements, []string{"x", "y"}) {
+			t.Fatalf("%s holds %v, want the concurrent addition kept", node.self, elements)
+		}
+	}
+
+	// a removal which saw every addition removes the element everywhere
+	b.UpdateSet("", "tags", nil, []string{"x"})
+	exchange(a, b)
+	if elements, _ := a.Set("", "tags"); !reflect.DeepEqual(elements, []string{"y"}) {
+		t.Fatalf("a holds %v, want the element removed", elements)
+	}
+}
+
+// serve serves the routes of the peers of the node on server
+func serve(t *testing.T, server *httptest.Server, node *testNode) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(MergePath, func(w http.ResponseWriter, r *http.Request) {
+		var request MergeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		node.Merge(request)
+	})
+	mux.HandleFunc(SyncPath, func(w http.ResponseWriter, r *http.Request) {
+		var request SyncRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(node.Sync(request))
+	})
+	server.Config.Handler = mux
+	server.Start()
+	t.Cleanup(server.Close)
+}
+
+func TestAntiEntropyRepairsBothNodes(t *testing.T) {
+	ctx := context.Background()
+	serverA, serverB := httptest.NewUnstartedServer(nil), httptest.NewUnstartedServer(nil)
+	urlA, urlB := "http://"+serverA.Listener.Addr().String(), "http://"+serverB.Listener.Addr().String()
+	a, b := newNode(t, urlA, urlB), newNode(t, urlB, urlA)
+	serve(t, serverA, a)
+	serve(t, serverB, b)
+
+	// the writes are not pushed, only the anti-entropy sends them
+	a.namespaces.Get("").Map.Set(ctx, "from-a", "1")
+	a.Increment("", "hits", 1)
+	b.namespaces.Get("tenant").Map.Set(ctx, "from-b", "2")
+	b.UpdateSet("", "tags", []string{"x"}, nil)
+
+	a.antiEntropy(ctx)
+	if value := b.get(t, "from-a"); value != "1" {
+		t.Fatalf("b holds %q, want the write of a", value)
+	}
+	if value, _, _ := a.namespaces.Get("tenant").Map.Get(ctx, "from-b"); value != "2" {
+		t.Fatalf("a holds %q, want the write of b", value)
+	}
+	if value, _ := b.Counter("", "hits"); value != 1 {
+		t.Fatalf("b counts %d, want the increment of a", value)
+	}
+	if elements, _ := a.Set("", "tags"); !reflect.DeepEqual(elements, []string{"x"}) {
+		t.Fatalf("a holds %v, want the addition of b", elements)
+	}
+	if !reflect.DeepEqual(a.digest(), b.digest()) {
+		t.Fatal("the digests differ after the anti-entropy")
+	}
+	if status := a.Status(); status.Peers[0].Error != "" || status.Peers[0].LastSync.IsZero() {
+		t.Fatalf("the exchange with b is reported as %+v", status.Peers[0])
+	}
+
+	// an identical state exchanges no bucket
+	if response := b.Sync(SyncRequest{From: a.self, Digest: a.digest()}); len(response.Buckets) != 0 {
+		t.Fatalf("%d buckets differ between identical states", len(response.Buckets))
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Routes of the HTTP API of a node serving the peers
+const (
+	MergePath = "/crdt/merge"
+	SyncPath  = "/crdt/sync"
+)
+
+const (
+	// pushTimeout bounds the sending of the local writes to a peer
+	pushTimeout = 2 * time.Second
+	// syncTimeout bounds an anti-entropy exchange with a peer
+	syncTimeout = 30 * time.Second
+)
+
+// MergeRequest carries a part of the state of From, the local writes or the
+// buckets that differ from the receiver
+type MergeRequest struct {
+	From  string `json:"from"`
+	State State  `json:"state"`
+}
+
+// SyncRequest carries the digest of the state of From
+type SyncRequest struct {
+	From   string   `json:"from"`
+	Digest []uint64 `json:"digest"`
+}
+
+// SyncResponse carries the buckets whose digest differs from the one of
+// the request and the state of the receiver in them
+type SyncResponse struct {
+	Buckets []int `json:"buckets,omitempty"`
+	State   State `json:"state"`
+}
+
+// Run implements the Run method of the CRDTServiceInterface
+func (c *crdtService) Run(ctx context.Context) {
+	pushes := time.NewTicker(pushInterval)
+	defer pushes.Stop()
+	syncs := time.NewTicker(c.syncInterval)
+	defer syncs.Stop()
+	gcs := time.NewTicker(gcInterval)
+	defer gcs.Stop()
+
+	// a restarted node gets the state back from its peers
+	c.antiEntropy(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			// the last writes are only kept by the peers
+			pushCtx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+			c.push(pushCtx)
+			cancel()
+			return
+		case <-pushes.C:
+			c.push(ctx)
+		case <-syncs.C:
+			c.antiEntropy(ctx)
+		case <-gcs.C:
+			c.gc()
+		}
+	}
+}
+
+// push sends the pending local writes to every peer, a peer which does not
+// get them gets them through the anti-entropy
+func (c *crdtService) push(ctx context.Context) {
+	c.pendingLock.Lock()
+	pending, dropped := c.pending, c.dropped
+	c.pending, c.dropped = State{}, 0
+	c.pendingLock.Unlock()
+
+	if dropped > 0 {
+		klog.InfoS("Too many writes to send to the peers, the anti-entropy will send them", "dropped", dropped)
+	}
+	if pending.size() == 0 {
+		return
+	}
+	request := MergeRequest{From: c.self, State: pending}
+	c.forEachPeer(func(peer string) {
+		pushCtx, cancel := context.WithTimeout(ctx, pushTimeout)
+		defer cancel()
+		if err := c.call(pushCtx, peer, MergePath, request, nil); err != nil {
+			klog.V(2).InfoS("Error sending the writes to a peer", "peer", peer, "err", err)
+			c.setStatus(peer, err, false)
+		}
+	})
+}
+
+// antiEntropy exchanges the buckets of the state that differ with every peer
+func (c *crdtService) antiEntropy(ctx context.Context) {
+	c.forEachPeer(func(peer string) {
+		syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+		defer cancel()
+		err := c.syncWith(syncCtx, peer)
+		if err != nil {
+			klog.V(2).InfoS("Error synchronizing with a peer", "peer", peer, "err", err)
+		}
+		c.setStatus(peer, err, err == nil)
+	})
+}
+
+// syncWith sends the digest of this node to peer, merges the state it
+// answers with and sends back the state of this node in the same buckets
+func (c *crdtService) syncWith(ctx context.Context, peer string) error {
+	request := SyncRequest{From: c.self, Digest: c.digest()}
+
+	var response SyncResponse
+	if err := c.call(ctx, peer, SyncPath, request, &response); err != nil {
+		return err
+	}
+	if len(response.Buckets) == 0 {
+		return nil
+	}
+
+	c.merge(response.State)
+	state := c.stateOf(response.Buckets)
+	klog.V(2).InfoS("Repairing the state differing from a peer", "peer", peer, "buckets", len(response.Buckets), "received", response.State.size(), "sent", state.size())
+	return c.call(ctx, peer, MergePath, MergeRequest{From: c.self, State: state}, nil)
+}
+
+// forEachPeer calls fn for every peer concurrently and returns once every call returned
+func (c *crdtService) forEachPeer(fn func(peer string)) {
+	var wg sync.WaitGroup
+	for _, peer := range c.peers {
+		peer := peer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(peer)
+		}()
+	}
+	wg.Wait()
+}
+
+// setStatus records the outcome of an exchange with peer
+func (c *crdtService) setStatus(peer string, err error, synced bool) {
+	c.statusLock.Lock()
+	defer c.statusLock.Unlock()
+
+	status := c.status[peer]
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if synced {
+		status.LastSync = time.Now()
+	}
+}
+
+// call posts request to the route of node and decodes its response unless it is nil
+func (c *crdtService) call(ctx context.Context, node, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, node+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", node, httpResponse.Status, message)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Register is a map entry as a last-writer-wins register, the write with
+// the latest timestamp wins whatever the order the nodes receive the
+// writes in. A deletion is a write leaving a tombstone, so that an older
+// write received afterwards does not bring the entry back.
+type Register struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+	Flags     uint32 `json:"flags,omitempty"`
+	// ExpiresAt is the same on every node, the zero time never expires
+	ExpiresAt time.Time `json:"expires-at,omitempty"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	Timestamp Timestamp `json:"timestamp"`
+}
+
+// dead reports whether the register no longer holds an entry at now
+func (r *Register) dead(now time.Time) bool {
+	return r.Deleted || (!r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt))
+}
+
+// diedBefore reports whether the register holds no entry since before horizon
+func (r *Register) diedBefore(horizon time.Time) bool {
+	if r.Deleted {
+		return r.Timestamp.Time().Before(horizon)
+	}
+	return !r.ExpiresAt.IsZero() && r.ExpiresAt.Before(horizon)
+}
+
+// fingerprint identifies the write held by the register, the fingerprints
+// of the counters and sets combine their parts so that the order of their
+// maps does not matter
+func (r *Register) fingerprint() uint64 {
+	return hashStrings(r.Namespace, r.Key, r.Timestamp.String())
+}
+
+// Counter is a counter adding up the increments and the decrements of every
+// replica, a replica only changes its own totals so the merge keeps the
+// highest totals of every replica and no addition is lost
+type Counter struct {
+	Namespace  string            `json:"namespace"`
+	Key        string            `json:"key"`
+	Increments map[string]uint64 `json:"increments,omitempty"`
+	Decrements map[string]uint64 `json:"decrements,omitempty"`
+}
+
+func newCounter(namespace, key string) *Counter {
+	return &Counter{Namespace: namespace, Key: key, Increments: make(map[string]uint64), Decrements: make(map[string]uint64)}
+}
+
+func (c *Counter) value() int64 {
+	var value int64
+	for _, increments := range c.Increments {
+		value += int64(increments)
+	}
+	for _, decrements := range c.Decrements {
+		value -= int64(decrements)
+	}
+	return value
+}
+
+// merge merges the totals of other and reports whether the counter changed
+func (c *Counter) merge(other Counter) bool {
+	changed := mergeTotals(c.Increments, other.Increments)
+	return mergeTotals(c.Decrements, other.Decrements) || changed
+}
+
+func mergeTotals(totals, other map[string]uint64) bool {
+	changed := false
+	for replica, total := range other {
+		if total > totals[replica] {
+			totals[replica] = total
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *Counter) fingerprint() uint64 {
+	fingerprint := hashStrings(c.Namespace, c.Key)
+	for replica, total := range c.Increments {
+		fingerprint ^= hashStrings("+", replica, strconv.FormatUint(total, 10))
+	}
+	for replica, total := range c.Decrements {
+		fingerprint ^= hashStrings("-", replica, strconv.FormatUint(total, 10))
+	}
+	return fingerprint
+}
+
+// Set is an observed-remove set, every addition is tagged uniquely and a
+// removal only removes the tags it saw, so that an element added on a node
+// while another node removed it stays in the set
+type Set struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	// Tags maps the tag of every addition to the element added
+	Tags map[string]string `json:"tags,omitempty"`
+	// Removed are the tags of the additions removed
+	Removed map[string]bool `json:"removed,omitempty"`
+}
+
+func newSet(namespace, key string) *Set {
+	return &Set{Namespace: namespace, Key: key, Tags: make(map[string]string), Removed: make(map[string]bool)}
+}
+
+// elements returns the elements of the set, sorted
+func (s *Set) elements() []string {
+	seen := make(map[string]bool)
+	elements := []string{}
+	for tag, element := range s.Tags {
+		if !s.Removed[tag] && !seen[element] {
+			seen[element] = true
+			elements = append(elements, element)
+		}
+	}
+	sort.Strings(elements)
+	return elements
+}
+
+// remove removes the additions of element
+func (s *Set) remove(element string) {
+	for tag, added := range s.Tags {
+		if added == element {
+			s.Removed[tag] = true
+		}
+	}
+}
+
+// merge merges the additions and removals of other and reports whether the set changed
+func (s *Set) merge(other Set) bool {
+	changed := false
+	for tag, element := range other.Tags {
+		if _, ok := s.Tags[tag]; !ok {
+			s.Tags[tag] = element
+			changed = true
+		}
+	}
+	for tag := range other.Removed {
+		if !s.Removed[tag] {
+			s.Removed[tag] = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (s *Set) fingerprint() uint64 {
+	fingerprint := hashStrings(s.Namespace, s.Key)
+	for tag, element := range s.Tags {
+		fingerprint ^= hashStrings("+", tag, element)
+	}
+	for tag := range s.Removed {
+		fingerprint ^= hashStrings("-", tag)
+	}
+	return fingerprint
+}
+
+// State is a part of the state of a node, sent to the peers to be merged
+type State struct {
+	Registers []Register `json:"registers,omitempty"`
+	Counters  []Counter  `json:"counters,omitempty"`
+	Sets      []Set      `json:"sets,omitempty"`
+}
+
+// size returns the number of registers, counters and sets of the state
+func (s *State) size() int {
+	return len(s.Registers) + len(s.Counters) + len(s.Sets)
+}
+
+// hashStrings hashes the strings, each one terminated so that their
+// concatenations do not collide
+func hashStrings(values ...string) uint64 {
+	h := fnv.New64a()
+	for _, value := range values {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+)
+
+// changeListener publishes an event for every change recorded in the change log
+type changeListener struct {
+	events EventServiceInterface
+}
+
+// NewChangeListener returns a listener of the change log publishing its
+// changes on events, the events are numbered in the order of the log
+func NewChangeListener(events EventServiceInterface) changeservice.Listener {
+	return &changeListener{events: events}
+}
+
+// Recorded implements the Recorded method of the Listener interface
+func (l *changeListener) Recorded(changes []changeservice.Change) {
+	for _, change := range changes {
+		l.events.Publish(Event{Type: eventType(change.Op), Source: change.Source, Namespace: change.Namespace, Key: change.Key, Time: change.Time})
+	}
+}
+
+// eventType returns the type of the event published for a change operation
+func eventType(op string) string {
+	switch op {
+	case changeservice.OpSet:
+		return EventSet
+	case changeservice.OpUpdate:
+		return EventUpdate
+	case changeservice.OpDelete:
+		return EventDelete
+	case changeservice.OpTimeToLive:
+		return EventTimeToLive
+	case changeservice.OpFlush:
+		return EventFlush
+	case changeservice.OpExpire:
+		return EventExpire
+	case changeservice.OpPush, changeservice.OpPrepend:
+		return EventPush
+	case changeservice.OpPop:
+		return EventPop
+	}
+	return op
+}
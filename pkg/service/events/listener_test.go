@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+)
+
+func TestEventsFollowTheOrderOfTheChangeLog(t *testing.T) {
+	events := NewEventService(1000)
+	changes, err := changeservice.NewChangeService(changeservice.Options{HistorySize: 1000, Listeners: []changeservice.Listener{NewChangeListener(events)}})
+	if err != nil {
+		t.Fatalf("creating the change log: %v", err)
+	}
+	stream, cancel := events.Subscribe(nil)
+	defer cancel()
+
+	var wait sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		namespace := fmt.Sprintf("namespace-%d", n)
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				changes.Record(namespace, func() []changeservice.Change {
+					return []changeservice.Change{{Op: changeservice.OpPrepend, Source: changeservice.SourceQueue, Namespace: namespace, Key: key}}
+				})
+			}
+		}()
+	}
+	wait.Wait()
+
+	recorded, err := changes.Read(0, 1000)
+	if err != nil {
+		t.Fatalf("reading the change log: %v", err)
+	}
+	if len(recorded) != 200 {
+		t.Fatalf("recorded %d changes, want 200", len(recorded))
+	}
+	for _, change := range recorded {
+		event := <-stream
+		if event.Sequence != change.Sequence || event.Namespace != change.Namespace || event.Key != change.Key {
+			t.Fatalf("event %d is %s/%s, change %d is %s/%s", event.Sequence, event.Namespace, event.Key, change.Sequence, change.Namespace, change.Key)
+		}
+		if event.Type != EventPush || event.Source != SourceQueue || !event.Time.Equal(change.Time) {
+			t.Fatalf("event %d is a %s of the %s at %v, want a push of the queue at %v", event.Sequence, event.Type, event.Source, event.Time, change.Time)
+		}
+	}
+}
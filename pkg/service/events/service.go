@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Types of the events published for the map and queue operations. The
+// cache has no memory limit and never evicts entries, they are only removed
+// by the clients or once expired.
+const (
+	EventSet        = "set"
+	EventUpdate     = "update"
+	EventDelete     = "delete"
+	EventTimeToLive = "ttl"
+	EventFlush      = "flush"
+	// EventExpire is published when an expired entry is removed
+	EventExpire = "expire"
+	EventPush   = "push"
+	EventPop    = "pop"
+)
+
+// Sources of the events
+const (
+	SourceMap   = "map"
+	SourceQueue = "queue"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it is dropped
+const subscriberBuffer = 256
+
+// ErrHistoryTruncated is returned when events after the requested sequence number are no longer retained
+var ErrHistoryTruncated = errors.New("events after the requested sequence number are no longer retained")
+
+// Event describes a change of a map or queue entry, events are numbered in
+// the order they are published starting at 1 which is the order of the
+// changes in the change log
+type Event struct {
+	Sequence  uint64    `json:"sequence"`
+	Type      string    `json:"type"`
+	Source    string    `json:"source"`
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+type EventServiceInterface interface {
+	// Publish numbers the event and sends it to every subscriber whose filter accepts it
+	Publish(event Event)
+
+	// Subscribe returns a channel receiving the events accepted by filter, a
+	// nil filter accepts every event. The channel is closed by cancel or when
+	// the subscriber falls too far behind.
+	Subscribe(filter func(Event) bool) (events <-chan Event, cancel func())
+
+	// SubscribeAfter is like Subscribe but first replays the retained events
+	// published after sequence, ErrHistoryTruncated is returned if some of
+	// them are no longer retained
+	SubscribeAfter(sequence uint64, filter func(Event) bool) (events <-chan Event, cancel func(), err error)
+
+	// Sequence returns the sequence number of the last published event
+	Sequence() uint64
+}
+
+type subscriber struct {
+	events chan Event
+	filter func(Event) bool
+}
+
+type eventService struct {
+	subscribers map[*subscriber]struct{}
+	// history is a ring buffer of the last published events
+	history  []Event
+	sequence uint64
+	lock     sync.Mutex
+}
+
+// NewEventService returns an event service retaining the last historySize
+// events for subscribers resuming from a sequence number
+func NewEventService(historySize int) EventServiceInterface {
+	return &eventService{
+		subscribers: make(map[*subscriber]struct{}),
+		history:     make([]Event, historySize),
+		lock:        sync.Mutex{},
+	}
+}
+
+// Publish implements the Publish method of the EventServiceInterface
+func (e *eventService) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.sequence++
+	event.Sequence = e.sequence
+	if len(e.history) > 0 {
+		e.history[e.sequence%uint64(len(e.history))] = event
+	}
+
+	for sub := range e.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(e.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe implements the Subscribe method of the EventServiceInterface
+func (e *eventService) Subscribe(filter func(Event) bool) (<-chan Event, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.subscribe(nil, filter)
+}
+
+// SubscribeAfter implements the SubscribeAfter method of the EventServiceInterface
+func (e *eventService) SubscribeAfter(sequence uint64, filter func(Event) bool) (<-chan Event, func(), error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if sequence > e.sequence {
+		sequence = e.sequence
+	}
+	oldest := uint64(1)
+	if e.sequence > uint64(len(e.history)) {
+		oldest = e.sequence - uint64(len(e.history)) + 1
+	}
+	if sequence+1 < oldest {
+		return nil, nil, ErrHistoryTruncated
+	}
+
+	var replay []Event
+	for s := sequence + 1; s <= e.sequence; s++ {
+		event := e.history[s%uint64(len(e.history))]
+		if filter == nil || filter(event) {
+			replay = append(replay, event)
+		}
+	}
+	events, cancel := e.subscribe(replay, filter)
+	return events, cancel, nil
+}
+
+// Sequence implements the Sequence method of the EventServiceInterface
+func (e *eventService) Sequence() uint64 {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.sequence
+}
+
+// subscribe adds a subscriber whose channel starts with the replayed events, the lock must be held
+func (e *eventService) subscribe(replay []Event, filter func(Event) bool) (<-chan Event, func()) {
+	sub := &subscriber{
+		events: make(chan Event, subscriberBuffer+len(replay)),
+		filter: filter,
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	e.subscribers[sub] = struct{}{}
+
+	cancel := func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+
+		if _, ok := e.subscribers[sub]; ok {
+			delete(e.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return sub.events, cancel
+}
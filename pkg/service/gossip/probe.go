@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Run implements the Run method of the GossipServiceInterface
+func (g *gossipService) Run(ctx context.Context) {
+	probes := time.NewTicker(probeInterval)
+	defer probes.Stop()
+	syncs := time.NewTicker(syncInterval)
+	defer syncs.Stop()
+
+	joined := g.join(ctx)
+	joins := time.NewTicker(joinRetryInterval)
+	defer joins.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			g.leave()
+			return
+		case <-joins.C:
+			if !joined {
+				joined = g.join(ctx)
+			}
+		case <-syncs.C:
+			if node := g.randomMembers(1, ""); len(node) > 0 {
+				if err := g.sync(ctx, node[0]); err != nil {
+					klog.V(2).InfoS("Error syncing the member list", "member", node[0], "err", err)
+				}
+			}
+		case <-probes.C:
+			g.expireSuspicions()
+			g.probe(ctx)
+		}
+	}
+}
+
+// join exchanges the member lists with the seeds and reports whether one answered
+func (g *gossipService) join(ctx context.Context) bool {
+	if len(g.seeds) == 0 {
+		return true
+	}
+	joined := false
+	for _, seed := range g.seeds {
+		syncCtx, cancel := context.WithTimeout(ctx, probeInterval)
+		err := g.sync(syncCtx, seed)
+		cancel()
+		if err != nil {
+			klog.V(2).InfoS("Error joining through seed", "seed", seed, "err", err)
+			continue
+		}
+		joined = true
+	}
+	if joined {
+		klog.InfoS("Joined the cluster", "members", len(g.Members()))
+	} else {
+		klog.InfoS("No seed answered, retrying", "seeds", g.seeds, "retryIn", joinRetryInterval)
+	}
+	return joined
+}
+
+// probe checks the next member, directly and then through other members,
+// and suspects it if nobody got its answer
+func (g *gossipService) probe(ctx context.Context) {
+	target := g.nextTarget()
+	if target == "" {
+		return
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	err := g.ping(pingCtx, target)
+	cancel()
+	if err == nil {
+		return
+	}
+	klog.V(4).InfoS("Member did not answer the probe", "member", target, "err", err)
+
+	helpers := g.randomMembers(indirectProbes, target)
+	acks := make(chan bool, len(helpers))
+	indirectCtx, cancel := context.WithTimeout(ctx, probeInterval-probeTimeout)
+	defer cancel()
+	for _, helper := range helpers {
+		helper := helper
+		go func() {
+			acks <- g.pingIndirect(indirectCtx, helper, target)
+		}()
+	}
+	for range helpers {
+		if <-acks {
+			return
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if member, ok := g.members[target]; ok && member.State == StateAlive {
+		g.apply(Update{Name: target, State: StateSuspect, Incarnation: member.Incarnation})
+	}
+}
+
+// nextTarget returns the next alive or suspect member to probe, empty if there is none
+func (g *gossipService) nextTarget() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for checked := 0; checked < len(g.probeOrder); checked++ {
+		if g.probeIndex >= len(g.probeOrder) {
+			rand.Shuffle(len(g.probeOrder), func(i, j int) {
+				g.probeOrder[i], g.probeOrder[j] = g.probeOrder[j], g.probeOrder[i]
+			})
+			g.probeIndex = 0
+		}
+		name := g.probeOrder[g.probeIndex]
+		g.probeIndex++
+		if member, ok := g.members[name]; ok && (member.State == StateAlive || member.State == StateSuspect) {
+			return name
+		}
+	}
+	return ""
+}
+
+// randomMembers returns up to n random alive members other than this node and except
+func (g *gossipService) randomMembers(n int, except string) []string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	var candidates []string
+	for name, member := range g.members {
+		if name != g.self && name != except && member.State == StateAlive {
+			candidates = append(candidates, name)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// expireSuspicions declares dead the suspect members that did not refute
+// the suspicion in time and forgets the members dead for long
+func (g *gossipService) expireSuspicions() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	now := time.Now()
+	timeout := time.Duration(suspicionMultiplier*math.Max(1, math.Log10(float64(len(g.members))))) * probeInterval
+	for name, member := range g.members {
+		switch {
+		case member.State == StateSuspect && now.Sub(member.Since) >= timeout:
+			g.apply(Update{Name: name, State: StateDead, Incarnation: member.Incarnation})
+		case (member.State == StateDead || member.State == StateLeft) && now.Sub(member.Since) >= reapTimeout:
+			delete(g.members, name)
+			for i, probed := range g.probeOrder {
+				if probed == name {
+					g.probeOrder = append(g.probeOrder[:i], g.probeOrder[i+1:]...)
+					break
+				}
+			}
+			klog.V(2).InfoS("Forgot member", "member", name)
+		}
+	}
+}
+
+// leave tells a few members that this node leaves so that it is not
+// suspected, the others learn it through the gossip
+func (g *gossipService) leave() {
+	g.lock.Lock()
+	self := g.members[g.self]
+	self.Incarnation++
+	self.State = StateLeft
+	self.Since = time.Now()
+	g.queue(Update{Name: self.Name, State: self.State, Incarnation: self.Incarnation})
+	g.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	done := make(chan struct{})
+	members := g.randomMembers(indirectProbes, "")
+	for _, member := range members {
+		member := member
+		go func() {
+			g.ping(ctx, member)
+			done <- struct{}{}
+		}()
+	}
+	for range members {
+		<-done
+	}
+	klog.InfoS("Left the cluster")
+}
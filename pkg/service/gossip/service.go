@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	"k8s.io/klog/v2"
+)
+
+// States of a member
+const (
+	StateAlive   = "alive"
+	StateSuspect = "suspect"
+	StateDead    = "dead"
+	StateLeft    = "left"
+)
+
+const (
+	// probeInterval is how often a member is probed
+	probeInterval = time.Second
+	// probeTimeout is how long a probed member has to answer before other
+	// members are asked to probe it
+	probeTimeout = 500 * time.Millisecond
+	// indirectProbes is the number of members asked to probe a member that did not answer
+	indirectProbes = 3
+	// suspicionMultiplier scales the time a suspect member has to refute
+	// the suspicion before it is declared dead, with the log of the size of
+	// the cluster since the suspicion takes longer to reach everyone
+	suspicionMultiplier = 5
+	// retransmitMultiplier scales the number of times an update is gossiped
+	// with the log of the size of the cluster
+	retransmitMultiplier = 4
+	// maxPiggyback is the number of updates sent along with a message
+	maxPiggyback = 16
+	// syncInterval is how often the whole member list is exchanged with a
+	// random member, it repairs what the gossip missed
+	syncInterval = 30 * time.Second
+	// joinRetryInterval is how often the seeds are contacted until one answers
+	joinRetryInterval = 5 * time.Second
+	// reapTimeout is how long dead and departed members stay listed
+	reapTimeout = 5 * time.Minute
+)
+
+// ErrWrongTarget is returned when a probe meant for another member reaches this one
+var ErrWrongTarget = errors.New("probe is meant for another member")
+
+// Member is a node of the cluster as known by this node
+type Member struct {
+	// Name is the URL of the HTTP API of the member
+	Name        string            `json:"name"`
+	State       string            `json:"state"`
+	Incarnation uint64            `json:"incarnation"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	// Since is when this node saw the member enter its state
+	Since time.Time `json:"since"`
+}
+
+// Update is the state of a member as gossiped between the nodes, only the
+// member itself raises its incarnation, to refute a suspicion
+type Update struct {
+	Name        string            `json:"name"`
+	State       string            `json:"state"`
+	Incarnation uint64            `json:"incarnation"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Options configures the gossip service
+type Options struct {
+	// Self is the URL of the HTTP API of this node, the other members reach it there
+	Self string
+	// Seeds are the URLs of members contacted to join the cluster
+	Seeds []string
+	// Metadata is propagated to every member along with the state of this node
+	Metadata map[string]string
+}
+
+type GossipServiceInterface interface {
+	// Self returns the name of this node
+	Self() string
+
+	// Members returns the members known by this node, itself included, sorted by name
+	Members() []Member
+
+	// Ping answers the probe of another member, ErrWrongTarget is returned
+	// if the probe is meant for another member
+	Ping(request PingRequest) (PingResponse, error)
+
+	// PingIndirect probes a member on behalf of another member which did not get its answer
+	PingIndirect(ctx context.Context, request IndirectRequest) IndirectResponse
+
+	// Sync merges the member list of another member and returns the one of this node
+	Sync(request SyncRequest) SyncResponse
+
+	// Run joins the cluster through the seeds, probes the members until
+	// ctx is done and then tells the others that this node leaves
+	Run(ctx context.Context)
+}
+
+// broadcast is an update gossiped along with the messages of this node
+type broadcast struct {
+	update    Update
+	transmits int
+}
+
+type gossipService struct {
+	self    string
+	seeds   []string
+	client  *http.Client
+	members map[string]*Member
+	// probeOrder is the random order the members are probed in, it is
+	// shuffled after every round so that every member is probed in turn
+	probeOrder []string
+	probeIndex int
+	broadcasts []*broadcast
+	lock       sync.Mutex
+}
+
+// NewGossipService returns the gossip service of the node options.Self
+func NewGossipService(options Options) (GossipServiceInterface, error) {
+	self, err := common.NormalizeNode(options.Self)
+	if err != nil {
+		return nil, err
+	}
+	var seeds []string
+	for _, seed := range options.Seeds {
+		seed, err := common.NormalizeNode(seed)
+		if err != nil {
+			return nil, err
+		}
+		if seed != self {
+			seeds = append(seeds, seed)
+		}
+	}
+
+	g := &gossipService{
+		self:    self,
+		seeds:   seeds,
+		client:  &http.Client{},
+		members: make(map[string]*Member),
+		lock:    sync.Mutex{},
+	}
+	g.members[self] = &Member{Name: self, State: StateAlive, Metadata: options.Metadata, Since: time.Now()}
+	return g, nil
+}
+
+// Self implements the Self method of the GossipServiceInterface
+func (g *gossipService) Self() string {
+	return g.self
+}
+
+// Members implements the Members method of the GossipServiceInterface
+func (g *gossipService) Members() []Member {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	members := make([]Member, 0, len(g.members))
+	for _, member := range g.members {
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+	return members
+}
+
+// Ping implements the Ping method of the GossipServiceInterface
+func (g *gossipService) Ping(request PingRequest) (PingResponse, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if request.Target != g.self {
+		return PingResponse{}, ErrWrongTarget
+	}
+	g.applyAll(request.Updates)
+	return PingResponse{Updates: g.piggyback()}, nil
+}
+
+// PingIndirect implements the PingIndirect method of the GossipServiceInterface
+func (g *gossipService) PingIndirect(ctx context.Context, request IndirectRequest) IndirectResponse {
+	g.lock.Lock()
+	g.applyAll(request.Updates)
+	g.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	ack := g.ping(ctx, request.Target) == nil
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return IndirectResponse{Ack: ack, Updates: g.piggyback()}
+}
+
+// Sync implements the Sync method of the GossipServiceInterface
+func (g *gossipService) Sync(request SyncRequest) SyncResponse {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.applyAll(request.Members)
+	return SyncResponse{Members: g.state()}
+}
+
+// state returns the update of every member, the lock must be held
+func (g *gossipService) state() []Update {
+	updates := make([]Update, 0, len(g.members))
+	for _, member := range g.members {
+		updates = append(updates, Update{Name: member.Name, State: member.State, Incarnation: member.Incarnation, Metadata: member.Metadata})
+	}
+	return updates
+}
+
+// applyAll applies updates received from another member, the lock must be held
+func (g *gossipService) applyAll(updates []Update) {
+	for _, update := range updates {
+		g.apply(update)
+	}
+}
+
+// rank orders the states of a member for the same incarnation, a state
+// overrides the states of lower rank
+func rank(state string) int {
+	switch state {
+	case StateAlive:
+		return 0
+	case StateSuspect:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// apply merges an update into the member list and gossips it further if
+// it is news, the lock must be held
+func (g *gossipService) apply(update Update) {
+	if update.Name == g.self {
+		g.refute(update)
+		return
+	}
+	member, ok := g.members[update.Name]
+	if !ok {
+		// a suspicion or a death is only news for the members that knew the member
+		if update.State != StateAlive {
+			return
+		}
+		if _, err := common.NormalizeNode(update.Name); err != nil {
+			return
+		}
+		member = &Member{Name: update.Name}
+		g.members[update.Name] = member
+		g.probeOrder = append(g.probeOrder, update.Name)
+		i := rand.Intn(len(g.probeOrder))
+		g.probeOrder[i], g.probeOrder[len(g.probeOrder)-1] = g.probeOrder[len(g.probeOrder)-1], g.probeOrder[i]
+	} else if update.Incarnation < member.Incarnation || (update.Incarnation == member.Incarnation && rank(update.State) <= rank(member.State)) {
+		return
+	}
+
+	if member.State != update.State {
+		klog.InfoS("Member state changed", "member", update.Name, "state", update.State, "incarnation", update.Incarnation)
+		member.Since = time.Now()
+	}
+	member.State = update.State
+	member.Incarnation = update.Incarnation
+	if update.State == StateAlive {
+		member.Metadata = update.Metadata
+	}
+	g.queue(Update{Name: member.Name, State: member.State, Incarnation: member.Incarnation, Metadata: member.Metadata})
+}
+
+// refute answers an update about this node that does not match its state,
+// such as a suspicion, by gossiping that it is alive with a higher
+// incarnation, the lock must be held
+func (g *gossipService) refute(update Update) {
+	self := g.members[g.self]
+	if self.State == StateLeft || update.Incarnation < self.Incarnation {
+		return
+	}
+	if update.State == StateAlive && update.Incarnation == self.Incarnation {
+		return
+	}
+	self.Incarnation = update.Incarnation + 1
+	klog.V(2).InfoS("Refuting member state", "state", update.State, "incarnation", self.Incarnation)
+	g.queue(Update{Name: self.Name, State: self.State, Incarnation: self.Incarnation, Metadata: self.Metadata})
+}
+
+// queue gossips an update, replacing the previous update of the member, the lock must be held
+func (g *gossipService) queue(update Update) {
+	for i, b := range g.broadcasts {
+		if b.update.Name == update.Name {
+			g.broadcasts = append(g.broadcasts[:i], g.broadcasts[i+1:]...)
+			break
+		}
+	}
+	g.broadcasts = append(g.broadcasts, &broadcast{update: update})
+}
+
+// piggyback returns the updates to send along with a message, the least
+// sent first, the lock must be held
+func (g *gossipService) piggyback() []Update {
+	if len(g.broadcasts) == 0 {
+		return nil
+	}
+	limit := retransmitMultiplier * int(math.Ceil(math.Log10(float64(len(g.members)+1))))
+	sort.SliceStable(g.broadcasts, func(i, j int) bool {
+		return g.broadcasts[i].transmits < g.broadcasts[j].transmits
+	})
+
+	var updates []Update
+	kept := g.broadcasts[:0]
+	for i, b := range g.broadcasts {
+		if i < maxPiggyback {
+			updates = append(updates, b.update)
+			b.transmits++
+		}
+		if b.transmits < limit {
+			kept = append(kept, b)
+		}
+	}
+	g.broadcasts = kept
+	return updates
+}
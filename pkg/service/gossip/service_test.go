@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testNode is a member serving the gossip routes on a local port
+type testNode struct {
+	*gossipService
+	server *httptest.Server
+}
+
+// startNode starts a member whose probes sent directly by the members of
+// blocked are dropped, as if the network between them was partitioned
+func startNode(t *testing.T, blocked ...string) *testNode {
+	t.Helper()
+	node := &testNode{}
+	dropped := func(from string) bool {
+		for _, name := range blocked {
+			if name == from {
+				return true
+			}
+		}
+		return false
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PingPath, func(w http.ResponseWriter, r *http.Request) {
+		var request PingRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || dropped(request.From) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		response, err := node.Ping(request)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+	mux.HandleFunc(PingIndirectPath, func(w http.ResponseWriter, r *http.Request) {
+		var request IndirectRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(node.PingIndirect(r.Context(), request))
+	})
+	mux.HandleFunc(SyncPath, func(w http.ResponseWriter, r *http.Request) {
+		var request SyncRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(node.Sync(request))
+	})
+
+	node.server = httptest.NewUnstartedServer(mux)
+	gossip, err := NewGossipService(Options{Self: "http://" + node.server.Listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("creating the gossip service: %v", err)
+	}
+	node.gossipService = gossip.(*gossipService)
+	node.server.Start()
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+// member returns the member as known by the node
+func (n *testNode) member(t *testing.T, name string) Member {
+	t.Helper()
+	for _, member := range n.Members() {
+		if member.Name == name {
+			return member
+		}
+	}
+	t.Fatalf("%s does not know %s", n.self, name)
+	return Member{}
+}
+
+// join makes every node know every other node
+func join(t *testing.T, nodes ...*testNode) {
+	t.Helper()
+	for _, node := range nodes[1:] {
+		if err := node.sync(context.Background(), nodes[0].self); err != nil {
+			t.Fatalf("joining %s: %v", node.self, err)
+		}
+	}
+	for _, node := range nodes[1:] {
+		if err := node.sync(context.Background(), nodes[0].self); err != nil {
+			t.Fatalf("syncing %s: %v", node.self, err)
+		}
+	}
+}
+
+// probeUntil probes the members of node until done returns true
+func probeUntil(t *testing.T, node *testNode, message string, done func() bool) {
+	t.Helper()
+	for i := 0; i < 50; i++ {
+		node.probe(context.Background())
+		if done() {
+			return
+		}
+	}
+	t.Fatal(message)
+}
+
+func TestSuspectedMemberRefutesTheSuspicion(t *testing.T) {
+	a, b := startNode(t), startNode(t)
+	join(t, a, b)
+
+	a.lock.Lock()
+	a.apply(Update{Name: b.self, State: StateSuspect, Incarnation: 0})
+	a.lock.Unlock()
+	if state := a.member(t, b.self).State; state != StateSuspect {
+		t.Fatalf("%s is %s, want suspect", b.self, state)
+	}
+
+	// the suspicion reaches b along with the probe and b answers that it is
+	// alive with a higher incarnation, which overrides the suspicion
+	if err := a.ping(context.Background(), b.self); err != nil {
+		t.Fatalf("probing %s: %v", b.self, err)
+	}
+	member := a.member(t, b.self)
+	if member.State != StateAlive || member.Incarnation != 1 {
+		t.Fatalf("%s is %s at incarnation %d, want alive at incarnation 1", b.self, member.State, member.Incarnation)
+	}
+	if incarnation := b.member(t, b.self).Incarnation; incarnation != 1 {
+		t.Fatalf("%s raised its incarnation to %d, want 1", b.self, incarnation)
+	}
+
+	// an older suspicion no longer overrides the refutation
+	a.lock.Lock()
+	a.apply(Update{Name: b.self, State: StateSuspect, Incarnation: 0})
+	a.lock.Unlock()
+	if state := a.member(t, b.self).State; state != StateAlive {
+		t.Fatalf("a stale suspicion made %s %s", b.self, state)
+	}
+}
+
+func TestUnreachableMemberIsSuspectedAndDeclaredDead(t *testing.T) {
+	a, b, c := startNode(t), startNode(t), startNode(t)
+	join(t, a, b, c)
+	c.server.Close()
+
+	probeUntil(t, a, "the unreachable member was not suspected", func() bool {
+		return a.member(t, c.self).State == StateSuspect
+	})
+	if state := a.member(t, b.self).State; state != StateAlive {
+		t.Fatalf("the reachable member is %s", state)
+	}
+
+	// a suspicion that was not refuted in time declares the member dead
+	a.expireSuspicions()
+	if state := a.member(t, c.self).State; state != StateSuspect {
+		t.Fatalf("the member is %s before the suspicion timed out", state)
+	}
+	a.lock.Lock()
+	a.members[c.self].Since = time.Now().Add(-time.Minute)
+	a.lock.Unlock()
+	a.expireSuspicions()
+	if state := a.member(t, c.self).State; state != StateDead {
+		t.Fatalf("the member is %s once the suspicion timed out, want dead", state)
+	}
+
+	// the death is gossiped to the other members
+	if err := a.ping(context.Background(), b.self); err != nil {
+		t.Fatalf("probing %s: %v", b.self, err)
+	}
+	if state := b.member(t, c.self).State; state != StateDead {
+		t.Fatalf("%s sees the member %s, want dead", b.self, state)
+	}
+}
+
+func TestIndirectProbeKeepsAMemberReachableThroughOthersAlive(t *testing.T) {
+	a, b := startNode(t), startNode(t)
+	// c drops the probes of a but answers those b sends on behalf of a
+	c := startNode(t, a.self)
+	join(t, a, b, c)
+
+	probed := 0
+	probeUntil(t, a, "c was never probed", func() bool {
+		if a.member(t, c.self).State != StateAlive {
+			t.Fatalf("c was suspected although b reached it")
+		}
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		// every member is probed once per round of probeOrder
+		if a.probeIndex == len(a.probeOrder) {
+			probed++
+		}
+		return probed == 2
+	})
+}
+
+func TestLeavingMemberIsNotSuspected(t *testing.T) {
+	a, b := startNode(t), startNode(t)
+	join(t, a, b)
+
+	b.leave()
+	member := a.member(t, b.self)
+	if member.State != StateLeft {
+		t.Fatalf("the member that left is %s, want left", member.State)
+	}
+	if target := a.nextTarget(); target != "" {
+		t.Fatalf("%s still probes %s", a.self, target)
+	}
+}
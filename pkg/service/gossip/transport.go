@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Routes of the HTTP API of a node serving the gossip messages
+const (
+	PingPath         = "/gossip/ping"
+	PingIndirectPath = "/gossip/ping-indirect"
+	SyncPath         = "/gossip/sync"
+)
+
+// PingRequest probes Target, the updates of From are gossiped along
+type PingRequest struct {
+	From    string   `json:"from"`
+	Target  string   `json:"target"`
+	Updates []Update `json:"updates,omitempty"`
+}
+
+// PingResponse acknowledges a probe
+type PingResponse struct {
+	Updates []Update `json:"updates,omitempty"`
+}
+
+// IndirectRequest asks a member to probe Target on behalf of From
+type IndirectRequest struct {
+	From    string   `json:"from"`
+	Target  string   `json:"target"`
+	Updates []Update `json:"updates,omitempty"`
+}
+
+// IndirectResponse reports whether Target acknowledged the probe
+type IndirectResponse struct {
+	Ack     bool     `json:"ack"`
+	Updates []Update `json:"updates,omitempty"`
+}
+
+// SyncRequest carries the whole member list of a member
+type SyncRequest struct {
+	Members []Update `json:"members"`
+}
+
+// SyncResponse carries the whole member list of the member that received a SyncRequest
+type SyncResponse struct {
+	Members []Update `json:"members"`
+}
+
+// ping probes target and applies the updates it answers with
+func (g *gossipService) ping(ctx context.Context, target string) error {
+	g.lock.Lock()
+	request := PingRequest{From: g.self, Target: target, Updates: g.piggyback()}
+	g.lock.Unlock()
+
+	var response PingResponse
+	if err := g.call(ctx, target, PingPath, request, &response); err != nil {
+		return err
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.applyAll(response.Updates)
+	return nil
+}
+
+// pingIndirect asks helper to probe target and reports whether target answered
+func (g *gossipService) pingIndirect(ctx context.Context, helper, target string) bool {
+	g.lock.Lock()
+	request := IndirectRequest{From: g.self, Target: target, Updates: g.piggyback()}
+	g.lock.Unlock()
+
+	var response IndirectResponse
+	if err := g.call(ctx, helper, PingIndirectPath, request, &response); err != nil {
+		return false
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.applyAll(response.Updates)
+	return response.Ack
+}
+
+// sync exchanges the member lists with node
+func (g *gossipService) sync(ctx context.Context, node string) error {
+	g.lock.Lock()
+	request := SyncRequest{Members: g.state()}
+	g.lock.Unlock()
+
+	var response SyncResponse
+	if err := g.call(ctx, node, SyncPath, request, &response); err != nil {
+		return err
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.applyAll(response.Members)
+	return nil
+}
+
+// call posts request to the route of node and decodes its response
+func (g *gossipService) call(ctx context.Context, node, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, node+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpResponse, err := g.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", node, httpResponse.Status, message)
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// observer counts the reads and the writes of the keys, the operations on a
+// whole map or queue and the ones not touching a value are not counted
+type observer struct {
+	hotKeys HotKeysServiceInterface
+}
+
+// NewObserver returns an observer recording the accesses to the keys of the namespaces in hotKeys
+func NewObserver(hotKeys HotKeysServiceInterface) namespaceservice.Observer {
+	return &observer{hotKeys: hotKeys}
+}
+
+// Observe implements the Observe method of the Observer interface
+func (o *observer) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	switch operation.Op {
+	case "get", "set", "modify", "update", "push", "prepend":
+		o.hotKeys.Record(operation.Source, operation.Namespace, operation.Key)
+	case "peek", "pop":
+		// the key of an empty queue is not known
+		if operation.OK {
+			o.hotKeys.Record(operation.Source, operation.Namespace, operation.Key)
+		}
+	case "mget":
+		for _, key := range operation.Keys {
+			o.hotKeys.Record(operation.Source, operation.Namespace, key)
+		}
+	}
+}
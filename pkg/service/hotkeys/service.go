@@ -0,0 +1,319 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"hash/maphash"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCapacity is the number of hot keys tracked
+	DefaultCapacity = 100
+	// DefaultDecayInterval is how often the counts are halved so that the
+	// keys hot in the past cool down
+	DefaultDecayInterval = time.Minute
+)
+
+const (
+	// sketchDepth is the number of rows of the count-min sketch, the
+	// estimate is wrong with a probability of about e^-depth
+	sketchDepth = 4
+	// sketchWidth is the number of counters of a row, the estimate exceeds
+	// the count by at most e/width of the accesses with a high probability
+	sketchWidth = 4096
+)
+
+// Options configures the hot key tracker
+type Options struct {
+	// Capacity is the number of hot keys tracked
+	Capacity int
+	// DecayInterval is how often the counts are halved
+	DecayInterval time.Duration
+}
+
+// HotKey is a key among the most accessed ones
+type HotKey struct {
+	// Type is the data structure of the key, map or queue
+	Type      string
+	Namespace string
+	Key       string
+	// Count estimates the accesses to the key since the last decays, it may
+	// exceed the real count but is never below it
+	Count uint64
+}
+
+type HotKeysServiceInterface interface {
+	// Record counts an access to the key of the map or the queue of a namespace
+	Record(dataType, namespace, key string)
+
+	// Top returns the n most accessed keys, the most accessed first, as many
+	// as the capacity if n is zero or less
+	Top(n int) []HotKey
+
+	// Capacity returns the number of hot keys tracked
+	Capacity() int
+
+	// Run halves the counts every decay interval until ctx is done
+	Run(ctx context.Context)
+}
+
+// candidate is a tracked key in the heap of the hot keys
+type candidate struct {
+	HotKey
+	index int
+}
+
+// candidates is a min-heap of the tracked keys by count
+type candidates []*candidate
+
+func (c candidates) Len() int           { return len(c) }
+func (c candidates) Less(i, j int) bool { return c[i].Count < c[j].Count }
+func (c candidates) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+	c[i].index = i
+	c[j].index = j
+}
+
+func (c *candidates) Push(x interface{}) {
+	item := x.(*candidate)
+	item.index = len(*c)
+	*c = append(*c, item)
+}
+
+func (c *candidates) Pop() interface{} {
+	old := *c
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*c = old[:len(old)-1]
+	return item
+}
+
+// topShards is the number of shards of the tracked keys, a key is tracked
+// by the shard its hash selects
+const topShards = 16
+
+// trackedKey identifies a tracked key
+type trackedKey struct {
+	dataType, namespace, key string
+}
+
+// topShard tracks the hottest keys of a shard, every shard keeps up to the
+// capacity of the tracker so that the hottest keys overall are all tracked
+// whatever shards they fall in
+type topShard struct {
+	top     candidates
+	tracked map[trackedKey]*candidate
+	// floor is the count of the coldest tracked key once the shard is full,
+	// a key counted up to it can not enter the shard and is not looked up
+	floor atomic.Uint64
+	lock  sync.Mutex
+}
+
+type hotKeysService struct {
+	capacity      int
+	decayInterval time.Duration
+	seed          maphash.Seed
+
+	sketch [sketchDepth][sketchWidth]atomic.Uint32
+	shards [topShards]topShard
+}
+
+// NewHotKeysService returns a tracker of the options.Capacity most accessed
+// keys, their accesses are counted in a count-min sketch of a fixed size so
+// that the keys are not stored unless they are among the hot ones
+func NewHotKeysService(options Options) HotKeysServiceInterface {
+	if options.Capacity <= 0 {
+		options.Capacity = DefaultCapacity
+	}
+	if options.DecayInterval <= 0 {
+		options.DecayInterval = DefaultDecayInterval
+	}
+	h := &hotKeysService{
+		capacity:      options.Capacity,
+		decayInterval: options.DecayInterval,
+		seed:          maphash.MakeSeed(),
+	}
+	for i := range h.shards {
+		h.shards[i].tracked = make(map[trackedKey]*candidate)
+	}
+	return h
+}
+
+// Record implements the Record method of the HotKeysServiceInterface, the
+// counters of the sketch are updated without a lock and the shard of the
+// key is only locked when the key is among its hottest ones
+func (h *hotKeysService) Record(dataType, namespace, key string) {
+	var hasher maphash.Hash
+	hasher.SetSeed(h.seed)
+	hasher.WriteString(dataType)
+	hasher.WriteByte(0)
+	hasher.WriteString(namespace)
+	hasher.WriteByte(0)
+	hasher.WriteString(key)
+	hash := hasher.Sum64()
+	// the rows are indexed by double hashing of the two halves of the hash
+	h1, h2 := uint32(hash), uint32(hash>>32)|1
+
+	// conservative update: only the counters below the estimate are raised,
+	// the others already overestimate the key
+	estimate := ^uint32(0)
+	var slots [sketchDepth]uint32
+	for row := range slots {
+		slots[row] = (h1 + uint32(row)*h2) % sketchWidth
+		if count := h.sketch[row][slots[row]].Load(); count < estimate {
+			estimate = count
+		}
+	}
+	if estimate < ^uint32(0) {
+		estimate++
+	}
+	for row, slot := range slots {
+		counter := &h.sketch[row][slot]
+		for {
+			count := counter.Load()
+			if count >= estimate || counter.CompareAndSwap(count, estimate) {
+				break
+			}
+		}
+	}
+
+	count := uint64(estimate)
+	shard := &h.shards[hash%topShards]
+	if count <= shard.floor.Load() {
+		return
+	}
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	shard.update(trackedKey{dataType: dataType, namespace: namespace, key: key}, count, h.capacity)
+}
+
+// update raises the count of a tracked key or tracks the key if the shard
+// is not full or the key is hotter than the coldest one, the lock must be held
+func (s *topShard) update(id trackedKey, count uint64, capacity int) {
+	defer s.updateFloor(capacity)
+
+	if tracked, ok := s.tracked[id]; ok {
+		if count > tracked.Count {
+			tracked.Count = count
+			heap.Fix(&s.top, tracked.index)
+		}
+		return
+	}
+	if len(s.top) < capacity {
+		item := &candidate{HotKey: HotKey{Type: id.dataType, Namespace: id.namespace, Key: id.key, Count: count}}
+		heap.Push(&s.top, item)
+		s.tracked[id] = item
+		return
+	}
+	if coldest := s.top[0]; count > coldest.Count {
+		delete(s.tracked, trackedKey{dataType: coldest.Type, namespace: coldest.Namespace, key: coldest.Key})
+		coldest.HotKey = HotKey{Type: id.dataType, Namespace: id.namespace, Key: id.key, Count: count}
+		s.tracked[id] = coldest
+		heap.Fix(&s.top, 0)
+	}
+}
+
+// updateFloor sets the floor of the shard from its coldest key, the lock must be held
+func (s *topShard) updateFloor(capacity int) {
+	if len(s.top) < capacity {
+		s.floor.Store(0)
+		return
+	}
+	s.floor.Store(s.top[0].Count)
+}
+
+// Top implements the Top method of the HotKeysServiceInterface
+func (h *hotKeysService) Top(n int) []HotKey {
+	var keys []HotKey
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.lock.Lock()
+		for _, item := range shard.top {
+			keys = append(keys, item.HotKey)
+		}
+		shard.lock.Unlock()
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Count != keys[j].Count {
+			return keys[i].Count > keys[j].Count
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	if n <= 0 || n > h.capacity {
+		n = h.capacity
+	}
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// Capacity implements the Capacity method of the HotKeysServiceInterface
+func (h *hotKeysService) Capacity() int {
+	return h.capacity
+}
+
+// Run implements the Run method of the HotKeysServiceInterface
+func (h *hotKeysService) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.decayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.decay()
+		}
+	}
+}
+
+// decay halves the counts, the keys whose count drops to zero are no
+// longer tracked. The accesses recorded while the counters are halved may
+// be halved or not.
+func (h *hotKeysService) decay() {
+	for row := range h.sketch {
+		for slot := range h.sketch[row] {
+			counter := &h.sketch[row][slot]
+			for {
+				count := counter.Load()
+				if count == 0 || counter.CompareAndSwap(count, count>>1) {
+					break
+				}
+			}
+		}
+	}
+	for i := range h.shards {
+		h.shards[i].decay(h.capacity)
+	}
+}
+
+// decay halves the counts of the tracked keys of the shard
+func (s *topShard) decay(capacity int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	kept := s.top[:0]
+	for _, item := range s.top {
+		item.Count >>= 1
+		if item.Count == 0 {
+			delete(s.tracked, trackedKey{dataType: item.Type, namespace: item.Namespace, key: item.Key})
+			continue
+		}
+		kept = append(kept, item)
+	}
+	for i := len(kept); i < len(s.top); i++ {
+		s.top[i] = nil
+	}
+	s.top = kept
+	for i, item := range s.top {
+		item.index = i
+	}
+	heap.Init(&s.top)
+	s.updateFloor(capacity)
+}
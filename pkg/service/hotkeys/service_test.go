@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTopReturnsTheMostAccessedKeys(t *testing.T) {
+	h := NewHotKeysService(Options{Capacity: 3})
+	for i := 0; i < 1000; i++ {
+		// cold keys accessed once each, spread over every shard
+		h.Record("map", "default", fmt.Sprintf("cold-%d", i))
+		if i%2 == 0 {
+			h.Record("map", "default", "hot")
+		}
+		if i%4 == 0 {
+			h.Record("queue", "jobs", "warm")
+		}
+		if i%10 == 0 {
+			h.Record("map", "other", "hot")
+		}
+	}
+
+	top := h.Top(0)
+	if len(top) != 3 {
+		t.Fatalf("Top returned %d keys, want the capacity of 3", len(top))
+	}
+	want := []HotKey{
+		{Type: "map", Namespace: "default", Key: "hot", Count: 500},
+		{Type: "queue", Namespace: "jobs", Key: "warm", Count: 250},
+		{Type: "map", Namespace: "other", Key: "hot", Count: 100},
+	}
+	for i, key := range want {
+		got := top[i]
+		// the count-min sketch never underestimates and, with far fewer keys
+		// than counters, rarely overestimates by much
+		if got.Type != key.Type || got.Namespace != key.Namespace || got.Key != key.Key || got.Count < key.Count || got.Count > key.Count+5 {
+			t.Errorf("key %d is %+v, want %+v", i, got, key)
+		}
+	}
+	if top := h.Top(1); len(top) != 1 || top[0].Key != "hot" {
+		t.Errorf("Top(1) returned %+v", top)
+	}
+}
+
+func TestSketchNeverUnderestimates(t *testing.T) {
+	h := NewHotKeysService(Options{Capacity: 10}).(*hotKeysService)
+	counts := make(map[string]uint64)
+	for i := 0; i < 50000; i++ {
+		key := fmt.Sprintf("key-%d", (i*7919)%20000)
+		counts[key]++
+		h.Record("map", "default", key)
+	}
+	for _, key := range h.Top(0) {
+		if key.Count < counts[key.Key] {
+			t.Errorf("%s is estimated at %d accesses, it had %d", key.Key, key.Count, counts[key.Key])
+		}
+	}
+}
+
+func TestDecayHalvesTheCounts(t *testing.T) {
+	h := NewHotKeysService(Options{Capacity: 2}).(*hotKeysService)
+	for i := 0; i < 8; i++ {
+		h.Record("map", "default", "a")
+	}
+	h.Record("map", "default", "b")
+
+	h.decay()
+	top := h.Top(0)
+	if len(top) != 1 || top[0].Key != "a" || top[0].Count != 4 {
+		t.Fatalf("after a decay the hot keys are %+v, want a counted 4 times and b forgotten", top)
+	}
+	h.Record("map", "default", "a")
+	if top := h.Top(0); top[0].Count != 5 {
+		t.Fatalf("a is counted %d times after one more access, want 5", top[0].Count)
+	}
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	h := NewHotKeysService(Options{Capacity: 5})
+	var wait sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		g := g
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := 0; i < 1000; i++ {
+				h.Record("map", "default", "shared")
+				h.Record("map", "default", fmt.Sprintf("own-%d-%d", g, i%50))
+			}
+		}()
+	}
+	wait.Wait()
+
+	top := h.Top(1)
+	if len(top) != 1 || top[0].Key != "shared" || top[0].Count < 8000 {
+		t.Fatalf("the hottest key is %+v, want shared counted at least 8000 times", top)
+	}
+}
+
+func BenchmarkRecord(b *testing.B) {
+	h := NewHotKeysService(Options{})
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			h.Record("map", "default", keys[i%len(keys)])
+			i++
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"runtime"
+	"time"
+
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	metricsservice "github.com/zelta-7/cache/pkg/service/metrics"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// Options describes the server to the info service
+type Options struct {
+	// Config is the configuration of the server by flag name
+	Config map[string]string
+	// SpillDirectory receives the changes dropped from memory, empty if they are discarded
+	SpillDirectory string
+	// RaftDirectory keeps the raft log, empty if nothing is kept
+	RaftDirectory string
+}
+
+// Counts are the operations counted since the server started, there are no
+// evictions to count since the cache has no memory limit
+type Counts struct {
+	Hits        uint64
+	Misses      uint64
+	Expirations uint64
+}
+
+// HitRatio returns the share of the reads that found a value and whether there was any read
+func (c Counts) HitRatio() (float64, bool) {
+	reads := c.Hits + c.Misses
+	if reads == 0 {
+		return 0, false
+	}
+	return float64(c.Hits) / float64(reads), true
+}
+
+func (c *Counts) add(counters *metricsservice.Counters) {
+	c.Hits += counters.Hits.Load()
+	c.Misses += counters.Misses.Load()
+	c.Expirations += counters.Expirations.Load()
+}
+
+// NamespaceInfo summarizes the map and the queue of a namespace
+type NamespaceInfo struct {
+	Name         string
+	MapEntries   int
+	MapBytes     int64
+	QueueEntries int
+	QueueBytes   int64
+	// OldestQueueItem is when the oldest value of the queue was added, the zero time if the queue is empty
+	OldestQueueItem time.Time
+	Counts          Counts
+}
+
+// Info summarizes the server
+type Info struct {
+	StartedAt time.Time
+	Uptime    time.Duration
+	Config    map[string]string
+	// Namespaces are sorted by name
+	Namespaces   []NamespaceInfo
+	MapEntries   int
+	QueueEntries int
+	// EntriesBytes is the estimate of the memory held by the entries
+	EntriesBytes int64
+	// HeapBytes and SysBytes are the memory of the process as seen by the Go runtime
+	HeapBytes uint64
+	SysBytes  uint64
+	Counts    Counts
+	// ChangeSequence and ChangeOldest are the newest and the oldest changes of the change log
+	ChangeSequence uint64
+	ChangeOldest   uint64
+	SpillDirectory string
+	RaftDirectory  string
+}
+
+type InfoServiceInterface interface {
+	// Info summarizes the server, the sizes come from the repositories
+	// without copying their entries
+	Info() Info
+}
+
+type infoService struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	metrics    metricsservice.MetricsServiceInterface
+	changes    changeservice.ChangeServiceInterface
+	options    Options
+	startedAt  time.Time
+}
+
+// NewInfoService returns the info service of a server started now, the
+// counts are zero when metrics is nil
+func NewInfoService(namespaces namespaceservice.NamespaceServiceInterface, metrics metricsservice.MetricsServiceInterface, changes changeservice.ChangeServiceInterface, options Options) InfoServiceInterface {
+	return &infoService{
+		namespaces: namespaces,
+		metrics:    metrics,
+		changes:    changes,
+		options:    options,
+		startedAt:  time.Now(),
+	}
+}
+
+// Info implements the Info method of the InfoServiceInterface
+func (i *infoService) Info() Info {
+	info := Info{
+		StartedAt:      i.startedAt,
+		Uptime:         time.Since(i.startedAt),
+		Config:         i.options.Config,
+		SpillDirectory: i.options.SpillDirectory,
+		RaftDirectory:  i.options.RaftDirectory,
+		ChangeSequence: i.changes.Sequence(),
+		ChangeOldest:   i.changes.Oldest(),
+	}
+
+	for _, namespace := range i.namespaces.List() {
+		n := NamespaceInfo{
+			Name:         namespace.Name,
+			MapEntries:   namespace.Map.Len(),
+			MapBytes:     namespace.Map.Bytes(),
+			QueueEntries: namespace.Queue.Len(),
+			QueueBytes:   namespace.Queue.Bytes(),
+		}
+		if oldest, ok := namespace.Queue.Oldest(); ok {
+			n.OldestQueueItem = oldest
+		}
+		if i.metrics != nil {
+			n.Counts.add(i.metrics.Counters(namespace.Name, metricsservice.TypeMap))
+			n.Counts.add(i.metrics.Counters(namespace.Name, metricsservice.TypeQueue))
+		}
+		info.Namespaces = append(info.Namespaces, n)
+		info.MapEntries += n.MapEntries
+		info.QueueEntries += n.QueueEntries
+		info.EntriesBytes += n.MapBytes + n.QueueBytes
+		info.Counts.Hits += n.Counts.Hits
+		info.Counts.Misses += n.Counts.Misses
+		info.Counts.Expirations += n.Counts.Expirations
+	}
+
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	info.HeapBytes = memory.HeapAlloc
+	info.SysBytes = memory.Sys
+	return info
+}
@@ -1,32 +1,88 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/zelta-7/cache/common"
 	repository "github.com/zelta-7/cache/pkg/repository/map"
 	"k8s.io/klog/v2"
 )
 
+// Selectors accepted by GetSortedEntryList
+const (
+	SortByValue = iota
+	SortByKey
+)
+
 type MapServiceInterface interface {
-	// Set sets the value of the key
-	Set(key, value string) string
+	// Set sets the value of the key, the error reports a write that was not
+	// made, such as one a replicated map could not commit
+	Set(ctx context.Context, key, value string) (string, error)
+
+	// SetCacheTimetoLive sets the value of the key and expires it after ttl seconds
+	SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (string, error)
+
+	// Get returns the value of the key and whether it was found, the error
+	// reports a read that could not be made, such as one a replicated map
+	// could not make consistent
+	Get(ctx context.Context, key string) (string, bool, error)
+
+	// GetEntry returns the entry of the key, including its flags and version, and whether it was found
+	GetEntry(ctx context.Context, key string) (repository.CacheEntry, bool, error)
+
+	// Store writes the value, flags and time to live of the entry if the condition holds
+	Store(ctx context.Context, entry repository.CacheEntry, condition repository.Condition) (repository.CacheEntry, error)
+
+	// Modify atomically replaces the value of an existing key with the result of fn
+	Modify(ctx context.Context, key string, fn func(value string) (string, error)) (repository.CacheEntry, error)
+
+	// Delete removes the key and reports whether it was found
+	Delete(ctx context.Context, key string) (bool, error)
 
-	// Get returns the value of the key
-	Get(key string) string
+	// DeleteVersion removes the key only if it was not written since it had
+	// version and reports whether it was removed
+	DeleteVersion(ctx context.Context, key string, version uint64) (bool, error)
+
+	// Expire sets the time to live of the key in seconds, 0 removes the expiration
+	Expire(ctx context.Context, key string, ttl int) (bool, error)
+
+	// TimeToLive returns the remaining time to live of the key, zero if it never expires
+	TimeToLive(ctx context.Context, key string) (time.Duration, bool, error)
 
 	// All returns all the entries in the map
-	All() map[string]string
+	All(ctx context.Context) ([]repository.CacheEntry, error)
+
+	// Metadata returns the metadata of the entry of the key and whether it was found
+	Metadata(ctx context.Context, key string) (repository.Metadata, bool, error)
+
+	// AllMetadata returns the metadata of all the entries in the map sorted by key
+	AllMetadata(ctx context.Context) ([]repository.Metadata, error)
 
 	// GetEntryList returns the first n entries in the map
-	GetEntryList(n int) map[string]string
+	GetEntryList(ctx context.Context, n int) ([]repository.CacheEntry, error)
+
+	// GetSortedEntryList returns the first n entries in the map sorted by value or key
+	GetSortedEntryList(ctx context.Context, selector, n int) ([]repository.CacheEntry, error)
+
+	// UpdateCacheEntry updates the value of the key and reports whether it was found
+	UpdateCacheEntry(ctx context.Context, key, value string) (bool, error)
+
+	// GetListofValues returns the entries found for the given keys, including
+	// their flags and version, and counts the reads like Get
+	GetListofValues(ctx context.Context, keys []string) ([]repository.CacheEntry, error)
+
+	// Len returns the number of entries in the map
+	Len() int
 
-	// GetSortedEntryList returns the first n entries in the map sorted by value
-	GetSortedEntryList(selector, n int) map[string]string
+	// Bytes returns an estimate of the memory held by the entries of the map
+	Bytes() int64
 
-	// UpdateCacheEntry updates the value of the key
-	UpdateCacheEntry(key, value string) string
+	// Flush removes all the entries in the map
+	Flush(ctx context.Context)
 
-	// GetListofValues returns the array of values for the given keys
-	GetListofValues(keys []string) []string
+	// Sweep removes the expired entries and returns their keys
+	Sweep(ctx context.Context) []string
 }
 
 type mapService struct {
@@ -40,76 +96,183 @@ func NewMapService(mapRepoInterface repository.MapRepoInter) MapServiceInterface
 }
 
 // Set implements the Set method of the MapServiceInterface
-func (m *mapService) Set(key, value string) string {
+func (m *mapService) Set(ctx context.Context, key, value string) (string, error) {
 	hashedKey := common.HashKey(key)
 	m.mapInterface.Set(hashedKey, value)
-	return key
+	return key, nil
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the MapServiceInterface
+func (m *mapService) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (string, error) {
+	hashedKey := common.HashKey(key)
+	m.mapInterface.SetWithTTL(hashedKey, value, time.Duration(ttl)*time.Second)
+	return key, nil
 }
 
 // Get implements the Get method of the MapServiceInterface
-func (m *mapService) Get(key string) string {
+func (m *mapService) Get(ctx context.Context, key string) (string, bool, error) {
+	hashedKey := common.HashKey(key)
+	value, ok := m.mapInterface.Get(hashedKey)
+	return value, ok, nil
+}
+
+// GetEntry implements the GetEntry method of the MapServiceInterface
+func (m *mapService) GetEntry(ctx context.Context, key string) (repository.CacheEntry, bool, error) {
+	entry, ok := m.mapInterface.GetEntry(common.HashKey(key))
+	entry.Key = key
+	return entry, ok, nil
+}
+
+// Store implements the Store method of the MapServiceInterface
+func (m *mapService) Store(ctx context.Context, entry repository.CacheEntry, condition repository.Condition) (repository.CacheEntry, error) {
+	key := entry.Key
+	entry.Key = common.HashKey(key)
+	stored, err := m.mapInterface.Store(entry, condition)
+	stored.Key = key
+	return stored, err
+}
+
+// Modify implements the Modify method of the MapServiceInterface
+func (m *mapService) Modify(ctx context.Context, key string, fn func(value string) (string, error)) (repository.CacheEntry, error) {
+	entry, err := m.mapInterface.Modify(common.HashKey(key), fn)
+	entry.Key = key
+	return entry, err
+}
+
+// Delete implements the Delete method of the MapServiceInterface
+func (m *mapService) Delete(ctx context.Context, key string) (bool, error) {
 	hashedKey := common.HashKey(key)
-	return m.mapInterface.Get(hashedKey)
+	return m.mapInterface.Delete(hashedKey), nil
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapServiceInterface
+func (m *mapService) DeleteVersion(ctx context.Context, key string, version uint64) (bool, error) {
+	return m.mapInterface.DeleteVersion(common.HashKey(key), version), nil
+}
+
+// Expire implements the Expire method of the MapServiceInterface
+func (m *mapService) Expire(ctx context.Context, key string, ttl int) (bool, error) {
+	hashedKey := common.HashKey(key)
+	return m.mapInterface.Expire(hashedKey, time.Duration(ttl)*time.Second), nil
+}
+
+// TimeToLive implements the TimeToLive method of the MapServiceInterface
+func (m *mapService) TimeToLive(ctx context.Context, key string) (time.Duration, bool, error) {
+	hashedKey := common.HashKey(key)
+	ttl, ok := m.mapInterface.TTL(hashedKey)
+	return ttl, ok, nil
 }
 
 // All implements the All method of the MapServiceInterface
-func (m *mapService) All() map[string]string {
-	return m.mapInterface.All()
+func (m *mapService) All(ctx context.Context) ([]repository.CacheEntry, error) {
+	return m.GetEntryList(ctx, 0)
 }
 
-// GetEntryList implements the GetEntryList method of the MapServiceInterface
-func (m *mapService) GetEntryList(n int) map[string]string {
-	entryList := make(map[string]string)
-	for hashedKey, value := range m.mapInterface.All() {
-		key, err := common.DecodeHashedKey(hashedKey)
+// Metadata implements the Metadata method of the MapServiceInterface
+func (m *mapService) Metadata(ctx context.Context, key string) (repository.Metadata, bool, error) {
+	hashedKey := common.HashKey(key)
+	metadata, ok := m.mapInterface.Metadata(hashedKey)
+	metadata.Key = key
+	// the size is reported for the key as written rather than as stored
+	metadata.Size += int64(len(key) - len(hashedKey))
+	return metadata, ok, nil
+}
+
+// AllMetadata implements the AllMetadata method of the MapServiceInterface
+func (m *mapService) AllMetadata(ctx context.Context) ([]repository.Metadata, error) {
+	all := m.mapInterface.AllMetadata()
+	metadata := make([]repository.Metadata, 0, len(all))
+	for _, entry := range all {
+		key, err := common.DecodeHashedKey(entry.Key)
 		if err != nil {
-			klog.ErrorS(err, "Error decoding hashed key", "key", hashedKey)
+			klog.ErrorS(err, "Error decoding hashed key", "key", entry.Key)
 			continue
 		}
-		entryList[key] = value
-		n--
-		if n == 0 {
-			break
+		entry.Size += int64(len(key) - len(entry.Key))
+		entry.Key = key
+		metadata = append(metadata, entry)
+	}
+	return repository.SortMetadataByKey(metadata), nil
+}
+
+// GetEntryList implements the GetEntryList method of the MapServiceInterface
+func (m *mapService) GetEntryList(ctx context.Context, n int) ([]repository.CacheEntry, error) {
+	entryList := make([]repository.CacheEntry, 0)
+	for _, entry := range m.mapInterface.All() {
+		key, err := common.DecodeHashedKey(entry.Key)
+		if err != nil {
+			klog.ErrorS(err, "Error decoding hashed key", "key", entry.Key)
+			continue
 		}
+		entry.Key = key
+		entryList = append(entryList, entry)
 	}
-	return entryList
+	entryList = repository.SortMapByKey(entryList)
+	if n > 0 && n < len(entryList) {
+		entryList = entryList[:n]
+	}
+	return entryList, nil
 }
 
 // GetSortedEntryList implements the GetSortedEntryList method of the MapServiceInterface
-func (m *mapService) GetSortedEntryList(selector, n int) map[string]string {
-	if selector != 0 && selector != 1 {
+func (m *mapService) GetSortedEntryList(ctx context.Context, selector, n int) ([]repository.CacheEntry, error) {
+	if selector != SortByValue && selector != SortByKey {
 		klog.Warning("Invalid selector value")
-		return nil
-	}
-	if selector == 0 {
-		return repository.SortMapByValue(m.GetEntryList(n))
+		return nil, nil
 	}
-	if selector == 1 {
-		return repository.SortMapByKey(m.GetEntryList(n))
+	entries, err := m.GetEntryList(ctx, n)
+	if selector == SortByValue {
+		return repository.SortMapByValue(entries), err
 	}
-	return nil
+	return repository.SortMapByKey(entries), err
 }
 
 // UpdateCacheEntry implements the UpdateCacheEntry method of the MapServiceInterface
-func (m *mapService) UpdateCacheEntry(key, value string) string {
+func (m *mapService) UpdateCacheEntry(ctx context.Context, key, value string) (bool, error) {
 	hashedKey := common.HashKey(key)
-	m.mapInterface.UpdateValue(hashedKey, value)
-	return key
+	return m.mapInterface.UpdateValue(hashedKey, value), nil
 }
 
 // GetListofValues implements the GetListofValues method of the MapServiceInterface
-func (m *mapService) GetListofValues(keys []string) []string {
-	values := make([]string, 0)
-	for _, hasedKey := range keys {
-		key, err := common.DecodeHashedKey(hasedKey)
-		if err != nil {
-			klog.ErrorS(err, "Error decoding hashed key", "key", hasedKey)
+func (m *mapService) GetListofValues(ctx context.Context, keys []string) ([]repository.CacheEntry, error) {
+	entries := make([]repository.CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		entry, ok := m.mapInterface.ReadEntry(common.HashKey(key))
+		if !ok {
 			continue
 		}
-		values = append(values, m.mapInterface.Get(key))
+		entry.Key = key
+		entries = append(entries, entry)
 	}
-	return values
+	return entries, nil
+}
+
+// Len implements the Len method of the MapServiceInterface
+func (m *mapService) Len() int {
+	return m.mapInterface.Len()
+}
+
+// Bytes implements the Bytes method of the MapServiceInterface
+func (m *mapService) Bytes() int64 {
+	return m.mapInterface.Bytes()
+}
+
+// Flush implements the Flush method of the MapServiceInterface
+func (m *mapService) Flush(ctx context.Context) {
+	m.mapInterface.Flush()
 }
 
-// TODO: Implement SetCacheTimetoLive function
-// func (m *mapService) SetCacheTimetoLive(key string, ttl int)
+// Sweep implements the Sweep method of the MapServiceInterface
+func (m *mapService) Sweep(ctx context.Context) []string {
+	expired := m.mapInterface.DeleteExpired()
+	keys := make([]string, 0, len(expired))
+	for _, hashedKey := range expired {
+		key, err := common.DecodeHashedKey(hashedKey)
+		if err != nil {
+			klog.ErrorS(err, "Error decoding hashed key", "key", hashedKey)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// observer counts the reads and the writes of the clients in the counters of their namespace
+type observer struct {
+	metrics MetricsServiceInterface
+}
+
+// NewObserver returns an observer counting the operations of the namespaces in metrics
+func NewObserver(metrics MetricsServiceInterface) namespaceservice.Observer {
+	return &observer{metrics: metrics}
+}
+
+// Observe implements the Observe method of the Observer interface
+func (o *observer) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	switch operation.Op {
+	case "get", "peek", "pop":
+		counters := o.metrics.Counters(operation.Namespace, operation.Source)
+		if operation.OK {
+			counters.Hits.Add(1)
+		} else {
+			counters.Misses.Add(1)
+		}
+	case "mget":
+		counters := o.metrics.Counters(operation.Namespace, operation.Source)
+		counters.Hits.Add(uint64(operation.Hits))
+		counters.Misses.Add(uint64(len(operation.Keys) - operation.Hits))
+	case "set", "push", "prepend", "modify", "update":
+		if operation.OK {
+			o.metrics.Counters(operation.Namespace, operation.Source).Sets.Add(1)
+		}
+	}
+}
+
+// ObserveSweep implements the ObserveSweep method of the SweepObserver interface
+func (o *observer) ObserveSweep(namespace, source string, expired int) {
+	o.metrics.Counters(namespace, source).Expirations.Add(uint64(expired))
+}
+
+// ObserveDelete implements the ObserveDelete method of the DeleteObserver interface
+func (o *observer) ObserveDelete(namespace string) {
+	o.metrics.Forget(namespace)
+}
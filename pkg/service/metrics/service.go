@@ -0,0 +1,355 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Types of the data structures of a namespace, counted apart
+const (
+	TypeMap   = "map"
+	TypeQueue = "queue"
+)
+
+// latencyBuckets are the upper bounds in seconds of the buckets of the
+// request latency histograms
+var latencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Counters counts the operations of the map or the queue of a namespace,
+// the cache has no memory limit and never evicts entries
+type Counters struct {
+	Hits        atomic.Uint64
+	Misses      atomic.Uint64
+	Sets        atomic.Uint64
+	Expirations atomic.Uint64
+}
+
+// NamespaceGauges are the sizes of a namespace when the metrics are written
+type NamespaceGauges struct {
+	Namespace    string
+	MapEntries   int
+	MapBytes     int64
+	QueueEntries int
+	QueueBytes   int64
+}
+
+// HotKeyGauge is the estimated count of accesses to a hot key when the
+// metrics are written
+type HotKeyGauge struct {
+	Namespace string
+	Type      string
+	Key       string
+	Count     uint64
+}
+
+type MetricsServiceInterface interface {
+	// Counters returns the counters of the map or the queue of a namespace
+	Counters(namespace, dataType string) *Counters
+
+	// Forget drops the counters of a deleted namespace
+	Forget(namespace string)
+
+	// ObserveOperation records the latency of an operation of the map or the
+	// queue, op names the operation such as get or set
+	ObserveOperation(dataType, op string, duration time.Duration)
+
+	// StartRequest counts an HTTP request in flight until the returned
+	// function records its route, status and latency
+	StartRequest() (done func(method, route string, status int))
+
+	// Write writes the metrics in the Prometheus text format along with the
+	// gauges of the namespaces and of the hot keys
+	Write(w io.Writer, namespaces []NamespaceGauges, hotKeys []HotKeyGauge) error
+}
+
+// counterKey identifies the counters of a data structure of a namespace
+type counterKey struct {
+	namespace string
+	dataType  string
+}
+
+// routeKey identifies the latency histogram of a route
+type routeKey struct {
+	method string
+	route  string
+	status int
+}
+
+// operationKey identifies the latency histogram of an operation
+type operationKey struct {
+	dataType string
+	op       string
+}
+
+// histogram counts the observations with atomic counters so that observing
+// takes no lock
+type histogram struct {
+	// buckets counts the observations of every bucket, not cumulated, the
+	// last one counts those above the last bound
+	buckets []atomic.Uint64
+	// sum is the sum of the observations in nanoseconds
+	sum atomic.Int64
+}
+
+// histogramSnapshot is a histogram read at once
+type histogramSnapshot struct {
+	buckets []uint64
+	seconds float64
+	count   uint64
+}
+
+// The maps of the counters and the histograms are sync.Map so that the
+// operations only load them, an entry is only stored the first time its key
+// is seen
+type metricsService struct {
+	// counters maps a counterKey to its *Counters
+	counters sync.Map
+
+	inFlight atomic.Int64
+	// latency maps a routeKey to its *histogram
+	latency sync.Map
+	// operations maps an operationKey to its *histogram
+	operations sync.Map
+}
+
+func NewMetricsService() MetricsServiceInterface {
+	return &metricsService{}
+}
+
+// Counters implements the Counters method of the MetricsServiceInterface
+func (m *metricsService) Counters(namespace, dataType string) *Counters {
+	key := counterKey{namespace: namespace, dataType: dataType}
+	if counters, ok := m.counters.Load(key); ok {
+		return counters.(*Counters)
+	}
+	counters, _ := m.counters.LoadOrStore(key, &Counters{})
+	return counters.(*Counters)
+}
+
+// Forget implements the Forget method of the MetricsServiceInterface
+func (m *metricsService) Forget(namespace string) {
+	m.counters.Delete(counterKey{namespace: namespace, dataType: TypeMap})
+	m.counters.Delete(counterKey{namespace: namespace, dataType: TypeQueue})
+}
+
+// StartRequest implements the StartRequest method of the MetricsServiceInterface
+func (m *metricsService) StartRequest() func(method, route string, status int) {
+	start := time.Now()
+	m.inFlight.Add(1)
+	return func(method, route string, status int) {
+		m.inFlight.Add(-1)
+		loadHistogram(&m.latency, routeKey{method: method, route: route, status: status}).observe(time.Since(start))
+	}
+}
+
+// ObserveOperation implements the ObserveOperation method of the MetricsServiceInterface
+func (m *metricsService) ObserveOperation(dataType, op string, duration time.Duration) {
+	loadHistogram(&m.operations, operationKey{dataType: dataType, op: op}).observe(duration)
+}
+
+// loadHistogram returns the histogram of key in histograms, storing a new one the first time
+func loadHistogram(histograms *sync.Map, key interface{}) *histogram {
+	if h, ok := histograms.Load(key); ok {
+		return h.(*histogram)
+	}
+	h, _ := histograms.LoadOrStore(key, &histogram{buckets: make([]atomic.Uint64, len(latencyBuckets)+1)})
+	return h.(*histogram)
+}
+
+func (h *histogram) observe(duration time.Duration) {
+	h.buckets[sort.SearchFloat64s(latencyBuckets, duration.Seconds())].Add(1)
+	h.sum.Add(int64(duration))
+}
+
+// snapshot reads the histogram, the count is the one of the buckets read so
+// that the +Inf bucket always equals it
+func (h *histogram) snapshot() histogramSnapshot {
+	snapshot := histogramSnapshot{buckets: make([]uint64, len(h.buckets))}
+	for b := range h.buckets {
+		snapshot.buckets[b] = h.buckets[b].Load()
+		snapshot.count += snapshot.buckets[b]
+	}
+	snapshot.seconds = time.Duration(h.sum.Load()).Seconds()
+	return snapshot
+}
+
+// Write implements the Write method of the MetricsServiceInterface
+func (m *metricsService) Write(w io.Writer, namespaces []NamespaceGauges, hotKeys []HotKeyGauge) error {
+	out := bufio.NewWriter(w)
+	m.writeCounters(out)
+	writeGauges(out, namespaces)
+	writeHotKeys(out, hotKeys)
+	m.writeRequests(out)
+	m.writeOperations(out)
+	return out.Flush()
+}
+
+// counterFamilies are the counters of the namespaces, by metric name
+var counterFamilies = []struct {
+	name  string
+	help  string
+	value func(*Counters) uint64
+}{
+	{"cache_hits_total", "Reads that found the key, or a value in the queue.", func(c *Counters) uint64 { return c.Hits.Load() }},
+	{"cache_misses_total", "Reads that did not find the key, or found the queue empty.", func(c *Counters) uint64 { return c.Misses.Load() }},
+	{"cache_sets_total", "Values written.", func(c *Counters) uint64 { return c.Sets.Load() }},
+	{"cache_expirations_total", "Expired entries removed.", func(c *Counters) uint64 { return c.Expirations.Load() }},
+}
+
+func (m *metricsService) writeCounters(out *bufio.Writer) {
+	var keys []counterKey
+	byKey := make(map[counterKey]*Counters)
+	m.counters.Range(func(key, counters interface{}) bool {
+		keys = append(keys, key.(counterKey))
+		byKey[key.(counterKey)] = counters.(*Counters)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].dataType < keys[j].dataType
+	})
+	counters := make([]*Counters, 0, len(keys))
+	for _, key := range keys {
+		counters = append(counters, byKey[key])
+	}
+
+	for _, family := range counterFamilies {
+		writeHeader(out, family.name, "counter", family.help)
+		for i, key := range keys {
+			fmt.Fprintf(out, "%s{namespace=%s,type=%s} %d\n", family.name, quote(key.namespace), quote(key.dataType), family.value(counters[i]))
+		}
+	}
+}
+
+func writeGauges(out *bufio.Writer, namespaces []NamespaceGauges) {
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Namespace < namespaces[j].Namespace })
+
+	writeHeader(out, "cache_entries", "gauge", "Entries held, the expired ones not yet removed excluded.")
+	for _, n := range namespaces {
+		fmt.Fprintf(out, "cache_entries{namespace=%s,type=%s} %d\n", quote(n.Namespace), quote(TypeMap), n.MapEntries)
+		fmt.Fprintf(out, "cache_entries{namespace=%s,type=%s} %d\n", quote(n.Namespace), quote(TypeQueue), n.QueueEntries)
+	}
+	writeHeader(out, "cache_memory_bytes", "gauge", "Estimate of the memory held by the entries.")
+	for _, n := range namespaces {
+		fmt.Fprintf(out, "cache_memory_bytes{namespace=%s,type=%s} %d\n", quote(n.Namespace), quote(TypeMap), n.MapBytes)
+		fmt.Fprintf(out, "cache_memory_bytes{namespace=%s,type=%s} %d\n", quote(n.Namespace), quote(TypeQueue), n.QueueBytes)
+	}
+	writeHeader(out, "cache_queue_depth", "gauge", "Values waiting in the queue.")
+	for _, n := range namespaces {
+		fmt.Fprintf(out, "cache_queue_depth{namespace=%s} %d\n", quote(n.Namespace), n.QueueEntries)
+	}
+}
+
+// writeHotKeys writes the gauges of the hot keys, the family is written even
+// without hot keys so that it is always described. The keys are hashed so
+// that their content, which may be personal data, does not reach the
+// metrics storage, /admin/hotkeys lists them with the same hash.
+func writeHotKeys(out *bufio.Writer, hotKeys []HotKeyGauge) {
+	writeHeader(out, "cache_hot_key_accesses", "gauge", "Estimate of the recent accesses to the most accessed keys, by the SHA-256 prefix of the key.")
+	for _, h := range hotKeys {
+		fmt.Fprintf(out, "cache_hot_key_accesses{namespace=%s,type=%s,key_hash=%s} %d\n", quote(h.Namespace), quote(h.Type), quote(KeyHash(h.Key)), h.Count)
+	}
+}
+
+// KeyHash returns the hash of a key written in the metrics instead of the
+// key, the hex encoded first 8 bytes of its SHA-256
+func KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (m *metricsService) writeRequests(out *bufio.Writer) {
+	// the values popped from a queue are not acknowledged, there are no
+	// messages in flight to count besides the requests being served
+	writeHeader(out, "cache_http_requests_in_flight", "gauge", "HTTP requests being served.")
+	fmt.Fprintf(out, "cache_http_requests_in_flight %d\n", m.inFlight.Load())
+
+	var keys []routeKey
+	byKey := make(map[routeKey]*histogram)
+	m.latency.Range(func(key, h interface{}) bool {
+		keys = append(keys, key.(routeKey))
+		byKey[key.(routeKey)] = h.(*histogram)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+	histograms := make([]histogramSnapshot, 0, len(keys))
+	for _, key := range keys {
+		histograms = append(histograms, byKey[key].snapshot())
+	}
+
+	const name = "cache_http_request_duration_seconds"
+	writeHeader(out, name, "histogram", "Latency of the HTTP requests by route.")
+	for i, key := range keys {
+		labels := fmt.Sprintf("method=%s,route=%s,status=%s", quote(key.method), quote(key.route), quote(strconv.Itoa(key.status)))
+		writeHistogram(out, name, labels, histograms[i])
+	}
+}
+
+func (m *metricsService) writeOperations(out *bufio.Writer) {
+	var keys []operationKey
+	byKey := make(map[operationKey]*histogram)
+	m.operations.Range(func(key, h interface{}) bool {
+		keys = append(keys, key.(operationKey))
+		byKey[key.(operationKey)] = h.(*histogram)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].dataType != keys[j].dataType {
+			return keys[i].dataType < keys[j].dataType
+		}
+		return keys[i].op < keys[j].op
+	})
+	histograms := make([]histogramSnapshot, 0, len(keys))
+	for _, key := range keys {
+		histograms = append(histograms, byKey[key].snapshot())
+	}
+
+	const name = "cache_operation_duration_seconds"
+	writeHeader(out, name, "histogram", "Latency of the operations of the maps and the queues, whatever the protocol.")
+	for i, key := range keys {
+		labels := fmt.Sprintf("type=%s,op=%s", quote(key.dataType), quote(key.op))
+		writeHistogram(out, name, labels, histograms[i])
+	}
+}
+
+func writeHistogram(out *bufio.Writer, name, labels string, h histogramSnapshot) {
+	var cumulated uint64
+	for b, bound := range latencyBuckets {
+		cumulated += h.buckets[b]
+		fmt.Fprintf(out, "%s_bucket{%s,le=%s} %d\n", name, labels, quote(strconv.FormatFloat(bound, 'g', -1, 64)), cumulated)
+	}
+	fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(out, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.seconds, 'g', -1, 64))
+	fmt.Fprintf(out, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+func writeHeader(out *bufio.Writer, name, metricType, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// labelEscaper escapes a label value as the Prometheus text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote returns a label value escaped and quoted
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
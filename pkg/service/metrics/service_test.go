@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestObserveOperationConcurrently(t *testing.T) {
+	metrics := NewMetricsService()
+
+	var wait sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := 0; i < 1000; i++ {
+				metrics.ObserveOperation(TypeMap, "get", time.Millisecond)
+				metrics.Counters("default", TypeMap).Hits.Add(1)
+			}
+		}()
+	}
+	wait.Wait()
+
+	var out bytes.Buffer
+	if err := metrics.Write(&out, nil, nil); err != nil {
+		t.Fatalf("writing the metrics: %v", err)
+	}
+	for _, want := range []string{
+		`cache_hits_total{namespace="default",type="map"} 8000`,
+		`cache_operation_duration_seconds_bucket{type="map",op="get",le="0.0005"} 0`,
+		`cache_operation_duration_seconds_bucket{type="map",op="get",le="0.001"} 8000`,
+		`cache_operation_duration_seconds_bucket{type="map",op="get",le="+Inf"} 8000`,
+		`cache_operation_duration_seconds_sum{type="map",op="get"} 8`,
+		`cache_operation_duration_seconds_count{type="map",op="get"} 8000`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("the metrics miss %s", want)
+		}
+	}
+}
+
+func TestForgetDropsTheCountersOfTheNamespace(t *testing.T) {
+	metrics := NewMetricsService()
+	metrics.Counters("deleted", TypeQueue).Sets.Add(1)
+	metrics.Forget("deleted")
+
+	if sets := metrics.Counters("deleted", TypeQueue).Sets.Load(); sets != 0 {
+		t.Fatalf("a namespace created again counts %d sets, want 0", sets)
+	}
+}
+
+func TestHotKeysAreWrittenByHash(t *testing.T) {
+	metrics := NewMetricsService()
+
+	var out bytes.Buffer
+	hotKeys := []HotKeyGauge{{Namespace: "default", Type: TypeMap, Key: "user:alice@example.com", Count: 42}}
+	if err := metrics.Write(&out, nil, hotKeys); err != nil {
+		t.Fatalf("writing the metrics: %v", err)
+	}
+	if strings.Contains(out.String(), "alice") {
+		t.Errorf("the metrics contain the hot key:\n%s", out.String())
+	}
+	want := `cache_hot_key_accesses{namespace="default",type="map",key_hash="` + KeyHash("user:alice@example.com") + `"} 42`
+	if !strings.Contains(out.String(), want+"\n") {
+		t.Errorf("the metrics miss %s", want)
+	}
+	if hash := KeyHash("user:alice@example.com"); len(hash) != 16 || hash == KeyHash("user:bob@example.com") {
+		t.Errorf("the hash %q is not 16 hex digits distinguishing the keys", hash)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zelta-7/cache/common"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// observer publishes the operations of the clients while the monitor has
+// subscribers, the sizes and the sweeps of the expired entries are not
+// operations of clients and are not published
+type observer struct {
+	monitor MonitorServiceInterface
+}
+
+// NewObserver returns an observer publishing the operations of the namespaces on monitor
+func NewObserver(monitor MonitorServiceInterface) namespaceservice.Observer {
+	return &observer{monitor: monitor}
+}
+
+// Observe implements the Observe method of the Observer interface
+func (o *observer) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	if !o.monitor.Active() {
+		return
+	}
+	var keys []string
+	if operation.Keys != nil {
+		keys = append(keys, operation.Keys...)
+	}
+	o.monitor.Publish(Command{
+		Time:       operation.Start,
+		Client:     common.Client(ctx),
+		Source:     operation.Source,
+		Namespace:  operation.Namespace,
+		Op:         operation.Op,
+		Key:        operation.Key,
+		Keys:       keys,
+		Value:      operation.Value,
+		TimeToLive: operation.TimeToLive,
+	})
+}
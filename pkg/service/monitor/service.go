@@ -0,0 +1,164 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sources of the commands
+const (
+	SourceMap   = "map"
+	SourceQueue = "queue"
+)
+
+// subscriberBuffer is how many commands a subscriber may fall behind before
+// the next ones are dropped for it
+const subscriberBuffer = 1024
+
+// Command is an operation received by the map or the queue of a namespace,
+// it is published once it ran whether it succeeded or not, Time is when it
+// started
+type Command struct {
+	Time      time.Time `json:"time"`
+	Client    string    `json:"client,omitempty"`
+	Source    string    `json:"source"`
+	Namespace string    `json:"namespace"`
+	Op        string    `json:"op"`
+	Key       string    `json:"key,omitempty"`
+	// Keys are the keys of the operations reading several keys
+	Keys  []string `json:"keys,omitempty"`
+	Value *string  `json:"value,omitempty"`
+	// Redacted is set when the value was removed for the subscriber
+	Redacted   bool `json:"redacted,omitempty"`
+	TimeToLive int  `json:"time-to-live,omitempty"`
+}
+
+// Filter selects the commands sent to a subscriber
+type Filter struct {
+	// Namespace keeps the commands of a single namespace unless it is empty
+	Namespace string
+	// Prefix keeps the commands on the keys starting with it, the commands
+	// on a whole map or queue are always kept
+	Prefix string
+	// Redact removes the values of the commands
+	Redact bool
+}
+
+// accepts reports whether the command is sent to the subscriber
+func (f Filter) accepts(command *Command) bool {
+	if f.Namespace != "" && command.Namespace != f.Namespace {
+		return false
+	}
+	if f.Prefix == "" || (command.Key == "" && len(command.Keys) == 0) {
+		return true
+	}
+	if command.Key != "" {
+		return strings.HasPrefix(command.Key, f.Prefix)
+	}
+	for _, key := range command.Keys {
+		if strings.HasPrefix(key, f.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives the commands accepted by its filter
+type Subscription struct {
+	Commands <-chan Command
+	// Dropped returns the number of commands dropped so far because the
+	// subscriber fell behind
+	Dropped func() uint64
+	// Cancel ends the subscription and closes Commands
+	Cancel func()
+}
+
+type MonitorServiceInterface interface {
+	// Active reports whether anybody is subscribed, the commands are only
+	// built and published while it is the case
+	Active() bool
+
+	// Publish sends the command to every subscriber whose filter accepts it
+	Publish(command Command)
+
+	// Subscribe returns a subscription to the commands accepted by filter
+	Subscribe(filter Filter) Subscription
+}
+
+type subscriber struct {
+	commands chan Command
+	filter   Filter
+	dropped  atomic.Uint64
+}
+
+type monitorService struct {
+	subscribers map[*subscriber]struct{}
+	// active counts the subscribers so that Active does not take the lock
+	active atomic.Int32
+	lock   sync.RWMutex
+}
+
+func NewMonitorService() MonitorServiceInterface {
+	return &monitorService{
+		subscribers: make(map[*subscriber]struct{}),
+		lock:        sync.RWMutex{},
+	}
+}
+
+// Active implements the Active method of the MonitorServiceInterface
+func (m *monitorService) Active() bool {
+	return m.active.Load() > 0
+}
+
+// Publish implements the Publish method of the MonitorServiceInterface
+func (m *monitorService) Publish(command Command) {
+	if command.Time.IsZero() {
+		command.Time = time.Now()
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for sub := range m.subscribers {
+		if !sub.filter.accepts(&command) {
+			continue
+		}
+		sent := command
+		if sub.filter.Redact && sent.Value != nil {
+			sent.Value = nil
+			sent.Redacted = true
+		}
+		select {
+		case sub.commands <- sent:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe implements the Subscribe method of the MonitorServiceInterface
+func (m *monitorService) Subscribe(filter Filter) Subscription {
+	sub := &subscriber{
+		commands: make(chan Command, subscriberBuffer),
+		filter:   filter,
+	}
+
+	m.lock.Lock()
+	m.subscribers[sub] = struct{}{}
+	m.active.Add(1)
+	m.lock.Unlock()
+
+	cancel := func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		if _, ok := m.subscribers[sub]; ok {
+			delete(m.subscribers, sub)
+			m.active.Add(-1)
+			close(sub.commands)
+		}
+	}
+	return Subscription{Commands: sub.commands, Dropped: sub.dropped.Load, Cancel: cancel}
+}
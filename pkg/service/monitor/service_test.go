@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// receive returns the next command of the subscription
+func receive(t *testing.T, sub Subscription) Command {
+	t.Helper()
+	select {
+	case command := <-sub.Commands:
+		return command
+	case <-time.After(5 * time.Second):
+		t.Fatal("no command was received")
+		return Command{}
+	}
+}
+
+// empty checks that the subscription received nothing more
+func empty(t *testing.T, sub Subscription) {
+	t.Helper()
+	select {
+	case command := <-sub.Commands:
+		t.Fatalf("the subscription received %+v", command)
+	default:
+	}
+}
+
+func TestRedactedSubscribersDoNotReceiveTheValues(t *testing.T) {
+	monitor := NewMonitorService()
+	redacted := monitor.Subscribe(Filter{Redact: true})
+	defer redacted.Cancel()
+	plain := monitor.Subscribe(Filter{})
+	defer plain.Cancel()
+
+	value := "secret"
+	monitor.Publish(Command{Source: SourceMap, Op: "set", Key: "a", Value: &value})
+	if command := receive(t, redacted); command.Value != nil || !command.Redacted || command.Key != "a" {
+		t.Fatalf("the redacted subscriber received %+v, want the command without its value", command)
+	}
+	if command := receive(t, plain); command.Value == nil || *command.Value != "secret" || command.Redacted {
+		t.Fatalf("the other subscriber received %+v, want the value", command)
+	}
+
+	// a command without a value is not marked as redacted
+	monitor.Publish(Command{Source: SourceMap, Op: "get", Key: "a"})
+	if command := receive(t, redacted); command.Redacted {
+		t.Fatalf("a command without a value was received as %+v", command)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	monitor := NewMonitorService()
+	sub := monitor.Subscribe(Filter{Namespace: "tenant", Prefix: "user:"})
+	defer sub.Cancel()
+
+	monitor.Publish(Command{Namespace: "other", Op: "get", Key: "user:1"})
+	monitor.Publish(Command{Namespace: "tenant", Op: "get", Key: "item:1"})
+	monitor.Publish(Command{Namespace: "tenant", Op: "mget", Keys: []string{"item:1", "item:2"}})
+	monitor.Publish(Command{Namespace: "tenant", Op: "get", Key: "user:1"})
+	monitor.Publish(Command{Namespace: "tenant", Op: "mget", Keys: []string{"item:1", "user:2"}})
+	monitor.Publish(Command{Namespace: "tenant", Op: "getall"})
+	for _, want := range []string{"get", "mget", "getall"} {
+		if command := receive(t, sub); command.Op != want || command.Time.IsZero() {
+			t.Fatalf("the subscriber received %+v, want %s", command, want)
+		}
+	}
+	empty(t, sub)
+}
+
+func TestSlowSubscribersDropTheCommands(t *testing.T) {
+	monitor := NewMonitorService()
+	sub := monitor.Subscribe(Filter{})
+	if !monitor.Active() {
+		t.Fatal("the monitor is not active with a subscriber")
+	}
+	for i := 0; i < subscriberBuffer+3; i++ {
+		monitor.Publish(Command{Op: "get", Key: "a"})
+	}
+	if dropped := sub.Dropped(); dropped != 3 {
+		t.Fatalf("%d commands were dropped, want 3", dropped)
+	}
+
+	sub.Cancel()
+	sub.Cancel()
+	if monitor.Active() {
+		t.Fatal("the monitor is active once the subscription is cancelled")
+	}
+	received := 0
+	for range sub.Commands {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("%d commands were received, want %d", received, subscriberBuffer)
+	}
+}
+
+func TestObserverPublishesTheOperations(t *testing.T) {
+	monitor := NewMonitorService()
+	observer := NewObserver(monitor)
+	value := "secret"
+	ctx := common.WithClient(context.Background(), "127.0.0.1:1234")
+	// nothing is published without subscribers
+	observer.Observe(ctx, namespaceservice.Operation{Source: SourceMap, Op: "set", Key: "a", Value: &value})
+
+	sub := monitor.Subscribe(Filter{Redact: true})
+	defer sub.Cancel()
+	observer.Observe(ctx, namespaceservice.Operation{Source: SourceMap, Op: "set", Key: "b", Value: &value, TimeToLive: 10})
+	command := receive(t, sub)
+	if command.Key != "b" || command.Client != "127.0.0.1:1234" || command.TimeToLive != 10 || command.Value != nil || !command.Redacted {
+		t.Fatalf("the subscriber received %+v, want the redacted set of b", command)
+	}
+	empty(t, sub)
+}
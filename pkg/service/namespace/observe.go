@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+)
+
+// Operation is an operation of a client on the map or the queue of a
+// namespace, it is passed to the observers once it returned
+type Operation struct {
+	// Source is changeservice.SourceMap or changeservice.SourceQueue
+	Source    string
+	Namespace string
+	// Op names the operation such as get, set or pop
+	Op string
+	// Key is the key read or written, empty for the operations on every
+	// entry and for a read of several keys
+	Key string
+	// Keys are the keys of a read of several keys
+	Keys []string
+	// Value is the written value when it is known before the operation runs
+	Value *string
+	// TimeToLive is the time to live in seconds set by a write, 0 never expires
+	TimeToLive int
+	// Write reports whether the operation changes the entries
+	Write bool
+	// OK reports whether a read found its key or a write was applied
+	OK bool
+	// Hits is the number of Keys found by a read of several keys
+	Hits  int
+	Start time.Time
+	Err   error
+}
+
+// Observer is told about every operation of the clients on the maps and the
+// queues of the namespaces, the sizes and the sweeps of the expired entries
+// are not operations of clients
+type Observer interface {
+	Observe(ctx context.Context, operation Operation)
+}
+
+// SweepObserver is implemented by the observers counting the entries
+// removed by the sweeps of the expired entries
+type SweepObserver interface {
+	ObserveSweep(namespace, source string, expired int)
+}
+
+// DeleteObserver is implemented by the observers keeping a state per
+// namespace, ObserveDelete is called once the namespace was deleted
+type DeleteObserver interface {
+	ObserveDelete(namespace string)
+}
+
+// observedMap passes the operations made through the map service it wraps to the observers
+type observedMap struct {
+	mapservice.MapServiceInterface
+	namespace string
+	observers []Observer
+}
+
+func (o *observedMap) observe(ctx context.Context, operation Operation) {
+	operation.Source = changeservice.SourceMap
+	operation.Namespace = o.namespace
+	for _, observer := range o.observers {
+		observer.Observe(ctx, operation)
+	}
+}
+
+// Set implements the Set method of the MapServiceInterface
+func (o *observedMap) Set(ctx context.Context, key, value string) (string, error) {
+	start := time.Now()
+	result, err := o.MapServiceInterface.Set(ctx, key, value)
+	o.observe(ctx, Operation{Op: "set", Key: key, Value: &value, Write: true, OK: err == nil, Start: start, Err: err})
+	return result, err
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the MapServiceInterface
+func (o *observedMap) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (string, error) {
+	start := time.Now()
+	result, err := o.MapServiceInterface.SetCacheTimetoLive(ctx, key, value, ttl)
+	o.observe(ctx, Operation{Op: "set", Key: key, Value: &value, TimeToLive: ttl, Write: true, OK: err == nil, Start: start, Err: err})
+	return result, err
+}
+
+// Get implements the Get method of the MapServiceInterface
+func (o *observedMap) Get(ctx context.Context, key string) (string, bool, error) {
+	start := time.Now()
+	value, ok, err := o.MapServiceInterface.Get(ctx, key)
+	o.observe(ctx, Operation{Op: "get", Key: key, OK: ok, Start: start, Err: err})
+	return value, ok, err
+}
+
+// GetEntry implements the GetEntry method of the MapServiceInterface
+func (o *observedMap) GetEntry(ctx context.Context, key string) (mapRepository.CacheEntry, bool, error) {
+	start := time.Now()
+	entry, ok, err := o.MapServiceInterface.GetEntry(ctx, key)
+	o.observe(ctx, Operation{Op: "get", Key: key, OK: ok, Start: start, Err: err})
+	return entry, ok, err
+}
+
+// Store implements the Store method of the MapServiceInterface
+func (o *observedMap) Store(ctx context.Context, entry mapRepository.CacheEntry, condition mapRepository.Condition) (mapRepository.CacheEntry, error) {
+	start := time.Now()
+	stored, err := o.MapServiceInterface.Store(ctx, entry, condition)
+	o.observe(ctx, Operation{Op: "set", Key: entry.Key, Value: &entry.Value, TimeToLive: ttlSeconds(entry.ExpiresAt, start), Write: true, OK: err == nil, Start: start, Err: err})
+	return stored, err
+}
+
+// Modify implements the Modify method of the MapServiceInterface, the new
+// value is only known once the operation ran and is not passed on
+func (o *observedMap) Modify(ctx context.Context, key string, fn func(value string) (string, error)) (mapRepository.CacheEntry, error) {
+	start := time.Now()
+	entry, err := o.MapServiceInterface.Modify(ctx, key, fn)
+	o.observe(ctx, Operation{Op: "modify", Key: key, Write: true, OK: err == nil, Start: start, Err: err})
+	return entry, err
+}
+
+// Delete implements the Delete method of the MapServiceInterface
+func (o *observedMap) Delete(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	deleted, err := o.MapServiceInterface.Delete(ctx, key)
+	o.observe(ctx, Operation{Op: "delete", Key: key, Write: true, OK: deleted, Start: start, Err: err})
+	return deleted, err
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapServiceInterface
+func (o *observedMap) DeleteVersion(ctx context.Context, key string, version uint64) (bool, error) {
+	start := time.Now()
+	deleted, err := o.MapServiceInterface.DeleteVersion(ctx, key, version)
+	o.observe(ctx, Operation{Op: "delete", Key: key, Write: true, OK: deleted, Start: start, Err: err})
+	return deleted, err
+}
+
+// Expire implements the Expire method of the MapServiceInterface
+func (o *observedMap) Expire(ctx context.Context, key string, ttl int) (bool, error) {
+	start := time.Now()
+	found, err := o.MapServiceInterface.Expire(ctx, key, ttl)
+	o.observe(ctx, Operation{Op: "expire", Key: key, TimeToLive: ttl, Write: true, OK: found, Start: start, Err: err})
+	return found, err
+}
+
+// TimeToLive implements the TimeToLive method of the MapServiceInterface
+func (o *observedMap) TimeToLive(ctx context.Context, key string) (time.Duration, bool, error) {
+	start := time.Now()
+	ttl, ok, err := o.MapServiceInterface.TimeToLive(ctx, key)
+	o.observe(ctx, Operation{Op: "ttl", Key: key, OK: ok, Start: start, Err: err})
+	return ttl, ok, err
+}
+
+// All implements the All method of the MapServiceInterface
+func (o *observedMap) All(ctx context.Context) ([]mapRepository.CacheEntry, error) {
+	start := time.Now()
+	entries, err := o.MapServiceInterface.All(ctx)
+	o.observe(ctx, Operation{Op: "list", OK: err == nil, Start: start, Err: err})
+	return entries, err
+}
+
+// Metadata implements the Metadata method of the MapServiceInterface
+func (o *observedMap) Metadata(ctx context.Context, key string) (mapRepository.Metadata, bool, error) {
+	start := time.Now()
+	metadata, ok, err := o.MapServiceInterface.Metadata(ctx, key)
+	o.observe(ctx, Operation{Op: "metadata", Key: key, OK: ok, Start: start, Err: err})
+	return metadata, ok, err
+}
+
+// AllMetadata implements the AllMetadata method of the MapServiceInterface
+func (o *observedMap) AllMetadata(ctx context.Context) ([]mapRepository.Metadata, error) {
+	start := time.Now()
+	metadata, err := o.MapServiceInterface.AllMetadata(ctx)
+	o.observe(ctx, Operation{Op: "metadata", OK: err == nil, Start: start, Err: err})
+	return metadata, err
+}
+
+// GetEntryList implements the GetEntryList method of the MapServiceInterface
+func (o *observedMap) GetEntryList(ctx context.Context, n int) ([]mapRepository.CacheEntry, error) {
+	start := time.Now()
+	entries, err := o.MapServiceInterface.GetEntryList(ctx, n)
+	o.observe(ctx, Operation{Op: "list", OK: err == nil, Start: start, Err: err})
+	return entries, err
+}
+
+// GetSortedEntryList implements the GetSortedEntryList method of the MapServiceInterface
+func (o *observedMap) GetSortedEntryList(ctx context.Context, selector, n int) ([]mapRepository.CacheEntry, error) {
+	start := time.Now()
+	entries, err := o.MapServiceInterface.GetSortedEntryList(ctx, selector, n)
+	o.observe(ctx, Operation{Op: "list", OK: err == nil, Start: start, Err: err})
+	return entries, err
+}
+
+// UpdateCacheEntry implements the UpdateCacheEntry method of the MapServiceInterface
+func (o *observedMap) UpdateCacheEntry(ctx context.Context, key, value string) (bool, error) {
+	start := time.Now()
+	updated, err := o.MapServiceInterface.UpdateCacheEntry(ctx, key, value)
+	o.observe(ctx, Operation{Op: "update", Key: key, Value: &value, Write: true, OK: updated, Start: start, Err: err})
+	return updated, err
+}
+
+// GetListofValues implements the GetListofValues method of the MapServiceInterface
+func (o *observedMap) GetListofValues(ctx context.Context, keys []string) ([]mapRepository.CacheEntry, error) {
+	start := time.Now()
+	entries, err := o.MapServiceInterface.GetListofValues(ctx, keys)
+	o.observe(ctx, Operation{Op: "mget", Keys: keys, OK: len(entries) > 0, Hits: len(entries), Start: start, Err: err})
+	return entries, err
+}
+
+// Flush implements the Flush method of the MapServiceInterface
+func (o *observedMap) Flush(ctx context.Context) {
+	start := time.Now()
+	o.MapServiceInterface.Flush(ctx)
+	o.observe(ctx, Operation{Op: "flush", Write: true, OK: true, Start: start})
+}
+
+// Sweep implements the Sweep method of the MapServiceInterface
+func (o *observedMap) Sweep(ctx context.Context) []string {
+	expired := o.MapServiceInterface.Sweep(ctx)
+	observeSweep(o.observers, o.namespace, changeservice.SourceMap, len(expired))
+	return expired
+}
+
+// observedQueue passes the operations made through the queue service it wraps to the observers
+type observedQueue struct {
+	queueservice.QueueServiceInterface
+	namespace string
+	observers []Observer
+}
+
+func (o *observedQueue) observe(ctx context.Context, operation Operation) {
+	operation.Source = changeservice.SourceQueue
+	operation.Namespace = o.namespace
+	for _, observer := range o.observers {
+		observer.Observe(ctx, operation)
+	}
+}
+
+// Set implements the Set method of the QueueServiceInterface
+func (o *observedQueue) Set(ctx context.Context, key, value string) string {
+	start := time.Now()
+	result := o.QueueServiceInterface.Set(ctx, key, value)
+	o.observe(ctx, Operation{Op: "push", Key: key, Value: &value, Write: true, OK: true, Start: start})
+	return result
+}
+
+// Prepend implements the Prepend method of the QueueServiceInterface
+func (o *observedQueue) Prepend(ctx context.Context, key, value string) string {
+	start := time.Now()
+	result := o.QueueServiceInterface.Prepend(ctx, key, value)
+	o.observe(ctx, Operation{Op: "prepend", Key: key, Value: &value, Write: true, OK: true, Start: start})
+	return result
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the QueueServiceInterface
+func (o *observedQueue) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) string {
+	start := time.Now()
+	result := o.QueueServiceInterface.SetCacheTimetoLive(ctx, key, value, ttl)
+	o.observe(ctx, Operation{Op: "push", Key: key, Value: &value, TimeToLive: ttl, Write: true, OK: true, Start: start})
+	return result
+}
+
+// Push implements the Push method of the QueueServiceInterface
+func (o *observedQueue) Push(ctx context.Context, entry queueRepository.CacheEntry, front bool) string {
+	start := time.Now()
+	result := o.QueueServiceInterface.Push(ctx, entry, front)
+	op := "push"
+	if front {
+		op = "prepend"
+	}
+	o.observe(ctx, Operation{Op: op, Key: entry.Key, Value: &entry.Value, TimeToLive: ttlSeconds(entry.ExpiresAt, start), Write: true, OK: true, Start: start})
+	return result
+}
+
+// Get implements the Get method of the QueueServiceInterface, the key is
+// only known once the entry is found
+func (o *observedQueue) Get(ctx context.Context) (queueRepository.CacheEntry, bool) {
+	start := time.Now()
+	entry, ok := o.QueueServiceInterface.Get(ctx)
+	o.observe(ctx, Operation{Op: "peek", Key: entry.Key, OK: ok, Start: start})
+	return entry, ok
+}
+
+// Pop implements the Pop method of the QueueServiceInterface
+func (o *observedQueue) Pop(ctx context.Context) (queueRepository.CacheEntry, bool) {
+	start := time.Now()
+	entry, ok := o.QueueServiceInterface.Pop(ctx)
+	o.observe(ctx, Operation{Op: "pop", Key: entry.Key, Write: true, OK: ok, Start: start})
+	return entry, ok
+}
+
+// Remove implements the Remove method of the QueueServiceInterface
+func (o *observedQueue) Remove(ctx context.Context, key, value string) bool {
+	start := time.Now()
+	removed := o.QueueServiceInterface.Remove(ctx, key, value)
+	o.observe(ctx, Operation{Op: "remove", Key: key, Write: true, OK: removed, Start: start})
+	return removed
+}
+
+// All implements the All method of the QueueServiceInterface
+func (o *observedQueue) All(ctx context.Context) []queueRepository.CacheEntry {
+	start := time.Now()
+	entries := o.QueueServiceInterface.All(ctx)
+	o.observe(ctx, Operation{Op: "list", OK: true, Start: start})
+	return entries
+}
+
+// Metadata implements the Metadata method of the QueueServiceInterface
+func (o *observedQueue) Metadata(ctx context.Context, key string) (queueRepository.Metadata, bool) {
+	start := time.Now()
+	metadata, ok := o.QueueServiceInterface.Metadata(ctx, key)
+	o.observe(ctx, Operation{Op: "metadata", Key: key, OK: ok, Start: start})
+	return metadata, ok
+}
+
+// AllMetadata implements the AllMetadata method of the QueueServiceInterface
+func (o *observedQueue) AllMetadata(ctx context.Context) []queueRepository.Metadata {
+	start := time.Now()
+	metadata := o.QueueServiceInterface.AllMetadata(ctx)
+	o.observe(ctx, Operation{Op: "metadata", OK: true, Start: start})
+	return metadata
+}
+
+// GetEntryList implements the GetEntryList method of the QueueServiceInterface
+func (o *observedQueue) GetEntryList(ctx context.Context, n int) []queueRepository.CacheEntry {
+	start := time.Now()
+	entries := o.QueueServiceInterface.GetEntryList(ctx, n)
+	o.observe(ctx, Operation{Op: "list", OK: true, Start: start})
+	return entries
+}
+
+// GetSortedEntries implements the GetSortedEntries method of the QueueServiceInterface
+func (o *observedQueue) GetSortedEntries(ctx context.Context, selector, n int) []queueRepository.CacheEntry {
+	start := time.Now()
+	entries := o.QueueServiceInterface.GetSortedEntries(ctx, selector, n)
+	o.observe(ctx, Operation{Op: "list", OK: true, Start: start})
+	return entries
+}
+
+// UpdateValue implements the UpdateValue method of the QueueServiceInterface
+func (o *observedQueue) UpdateValue(ctx context.Context, key, newValue string) bool {
+	start := time.Now()
+	updated := o.QueueServiceInterface.UpdateValue(ctx, key, newValue)
+	o.observe(ctx, Operation{Op: "update", Key: key, Value: &newValue, Write: true, OK: updated, Start: start})
+	return updated
+}
+
+// Delete implements the Delete method of the QueueServiceInterface
+func (o *observedQueue) Delete(ctx context.Context, key string) bool {
+	start := time.Now()
+	deleted := o.QueueServiceInterface.Delete(ctx, key)
+	o.observe(ctx, Operation{Op: "delete", Key: key, Write: true, OK: deleted, Start: start})
+	return deleted
+}
+
+// Flush implements the Flush method of the QueueServiceInterface
+func (o *observedQueue) Flush(ctx context.Context) {
+	start := time.Now()
+	o.QueueServiceInterface.Flush(ctx)
+	o.observe(ctx, Operation{Op: "flush", Write: true, OK: true, Start: start})
+}
+
+// Sweep implements the Sweep method of the QueueServiceInterface
+func (o *observedQueue) Sweep(ctx context.Context) []string {
+	expired := o.QueueServiceInterface.Sweep(ctx)
+	observeSweep(o.observers, o.namespace, changeservice.SourceQueue, len(expired))
+	return expired
+}
+
+// observeSweep passes the number of expired entries removed by a sweep to the observers counting them
+func observeSweep(observers []Observer, namespace, source string, expired int) {
+	if expired == 0 {
+		return
+	}
+	for _, observer := range observers {
+		if sweepObserver, ok := observer.(SweepObserver); ok {
+			sweepObserver.ObserveSweep(namespace, source, expired)
+		}
+	}
+}
+
+// ttlSeconds returns the time to live in seconds rounded up of an entry expiring at expiresAt, 0 if it never expires
+func ttlSeconds(expiresAt, now time.Time) int {
+	if expiresAt.IsZero() {
+		return 0
+	}
+	ttl := expiresAt.Sub(now)
+	if ttl <= 0 {
+		return 0
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+	"k8s.io/klog/v2"
+)
+
+// DefaultNamespace is used when a request does not name a namespace
+const DefaultNamespace = "default"
+
+// ErrReadOnly is returned by the frontends for the writes sent to a read-only replica
+var ErrReadOnly = errors.New("read-only replica, writes must be sent to the leader")
+
+// MovedError is returned by the frontends for a map key owned by another
+// cluster node, the client must send the request to Owner
+type MovedError struct {
+	Owner string
+}
+
+func (e *MovedError) Error() string {
+	return "key is owned by " + e.Owner
+}
+
+// Router tells which cluster node owns a map key
+type Router interface {
+	// Self returns the URL of this node
+	Self() string
+
+	// Owner returns the URL of the node owning the key of the map of the namespace
+	Owner(namespace, key string) string
+}
+
+// namePattern matches the NamespaceName schema of the API spec
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateName returns an error if name can not be used as a namespace name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid namespace name %q, it must match %s", name, namePattern)
+	}
+	return nil
+}
+
+// MapWrapper wraps the map of a namespace when the namespace is created
+type MapWrapper func(namespace string, m mapservice.MapServiceInterface) mapservice.MapServiceInterface
+
+// Namespace groups the map and the queue stored under the same name
+type Namespace struct {
+	Name  string
+	Map   mapservice.MapServiceInterface
+	Queue queueservice.QueueServiceInterface
+}
+
+type NamespaceServiceInterface interface {
+	// Get returns the namespace with the given name, creating it if it does not exist
+	Get(name string) *Namespace
+
+	// Lookup returns the namespace with the given name if it exists
+	Lookup(name string) (*Namespace, bool)
+
+	// List returns all the namespaces sorted by name
+	List() []*Namespace
+
+	// Delete flushes and removes the namespace and reports whether it existed
+	Delete(ctx context.Context, name string) bool
+
+	// Run removes the expired entries of every namespace each interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+
+	// SetReadOnly makes the frontends reject the writes of their clients,
+	// the namespaces themselves stay writable for replication
+	SetReadOnly(readOnly bool)
+
+	// ReadOnly reports whether the frontends must reject the writes of their clients
+	ReadOnly() bool
+
+	// WrapMaps wraps the map of every namespace created afterwards with
+	// wrapper, outside of the change capture so that the change log and
+	// the events published from it see what the wrapper lets through
+	WrapMaps(wrapper MapWrapper)
+
+	// SetRouter makes the frontends reject the map keys that router says
+	// are owned by another node
+	SetRouter(router Router)
+
+	// Route returns a *MovedError for the first of the map keys of the
+	// namespace owned by another node, nil if this node serves them all
+	Route(namespace string, keys ...string) error
+}
+
+type namespaceService struct {
+	namespaces map[string]*Namespace
+	changes    changeservice.ChangeServiceInterface
+	observers  []Observer
+	readOnly   atomic.Bool
+	wrapper    MapWrapper
+	router     Router
+	lock       sync.RWMutex
+}
+
+// Options configures the services observing the namespaces returned by
+// NewNamespaceService, each of them is skipped when it is nil
+type Options struct {
+	// Changes records the changes in the change log
+	Changes changeservice.ChangeServiceInterface
+	// Observers are told about the operations of the clients, in order
+	Observers []Observer
+}
+
+// NewNamespaceService returns a namespace service whose namespaces are
+// observed by the services of options
+func NewNamespaceService(options Options) NamespaceServiceInterface {
+	return &namespaceService{
+		namespaces: make(map[string]*Namespace),
+		changes:    options.Changes,
+		observers:  options.Observers,
+		lock:       sync.RWMutex{},
+	}
+}
+
+// Get implements the Get method of the NamespaceServiceInterface
+func (n *namespaceService) Get(name string) *Namespace {
+	if name == "" {
+		name = DefaultNamespace
+	}
+	if namespace, ok := n.Lookup(name); ok {
+		return namespace
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if namespace, ok := n.namespaces[name]; ok {
+		return namespace
+	}
+	namespace := &Namespace{
+		Name:  name,
+		Map:   mapservice.NewMapService(mapRepository.NewMapRepo()),
+		Queue: queueservice.NewQueueService(queueRepository.NewQueueRepo()),
+	}
+	if n.changes != nil {
+		namespace.Map = changeservice.CaptureMap(namespace.Map, name, n.changes)
+		namespace.Queue = changeservice.CaptureQueue(namespace.Queue, name, n.changes)
+	}
+	if n.wrapper != nil {
+		namespace.Map = n.wrapper(name, namespace.Map)
+	}
+	// the observers see the operations as the clients do: the latency
+	// includes the one of the wrappers, such as the commit of the writes in
+	// raft mode, and the reads and the writes made by the wrappers
+	// themselves are left out
+	if len(n.observers) > 0 {
+		namespace.Map = &observedMap{MapServiceInterface: namespace.Map, namespace: name, observers: n.observers}
+		namespace.Queue = &observedQueue{QueueServiceInterface: namespace.Queue, namespace: name, observers: n.observers}
+	}
+	n.namespaces[name] = namespace
+	return namespace
+}
+
+// Lookup implements the Lookup method of the NamespaceServiceInterface
+func (n *namespaceService) Lookup(name string) (*Namespace, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	namespace, ok := n.namespaces[name]
+	return namespace, ok
+}
+
+// List implements the List method of the NamespaceServiceInterface
+func (n *namespaceService) List() []*Namespace {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	namespaces := make([]*Namespace, 0, len(n.namespaces))
+	for _, namespace := range n.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+	return namespaces
+}
+
+// Delete implements the Delete method of the NamespaceServiceInterface
+func (n *namespaceService) Delete(ctx context.Context, name string) bool {
+	n.lock.Lock()
+	namespace, ok := n.namespaces[name]
+	delete(n.namespaces, name)
+	n.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+	namespace.Map.Flush(ctx)
+	namespace.Queue.Flush(ctx)
+	// a namespace created again under the same name starts from zero
+	for _, observer := range n.observers {
+		if deleteObserver, ok := observer.(DeleteObserver); ok {
+			deleteObserver.ObserveDelete(name)
+		}
+	}
+	return true
+}
+
+// Run implements the Run method of the NamespaceServiceInterface
+func (n *namespaceService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, namespace := range n.List() {
+				expired := len(namespace.Map.Sweep(ctx)) + len(namespace.Queue.Sweep(ctx))
+				if expired > 0 {
+					klog.V(2).InfoS("Removed expired entries", "namespace", namespace.Name, "count", expired)
+				}
+			}
+		}
+	}
+}
+
+// SetReadOnly implements the SetReadOnly method of the NamespaceServiceInterface
+func (n *namespaceService) SetReadOnly(readOnly bool) {
+	n.readOnly.Store(readOnly)
+}
+
+// ReadOnly implements the ReadOnly method of the NamespaceServiceInterface
+func (n *namespaceService) ReadOnly() bool {
+	return n.readOnly.Load()
+}
+
+// WrapMaps implements the WrapMaps method of the NamespaceServiceInterface
+func (n *namespaceService) WrapMaps(wrapper MapWrapper) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.wrapper = wrapper
+}
+
+// SetRouter implements the SetRouter method of the NamespaceServiceInterface
+func (n *namespaceService) SetRouter(router Router) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.router = router
+}
+
+// Route implements the Route method of the NamespaceServiceInterface
+func (n *namespaceService) Route(namespace string, keys ...string) error {
+	n.lock.RLock()
+	router := n.router
+	n.lock.RUnlock()
+
+	if router == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if owner := router.Owner(namespace, key); owner != "" && owner != router.Self() {
+			return &MovedError{Owner: owner}
+		}
+	}
+	return nil
+}
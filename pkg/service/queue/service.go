@@ -1,33 +1,82 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/zelta-7/cache/common"
 	repository "github.com/zelta-7/cache/pkg/repository/queue"
+	"k8s.io/klog/v2"
+)
+
+// Selectors accepted by GetSortedEntries
+const (
+	SortByValue = iota
+	SortByKey
 )
 
 type QueueServiceInterface interface {
-	// Set adds a value to the queue
-	Set(key, value string) string
+	// Set adds a value to the back of the queue
+	Set(ctx context.Context, key, value string) string
+
+	// Prepend adds a value to the front of the queue
+	Prepend(ctx context.Context, key, value string) string
+
+	// Get retrives the first value from the queue without removing it
+	Get(ctx context.Context) (repository.CacheEntry, bool)
 
-	// Get retrives the first value from the queue
-	Get() (key, value string)
+	// Pop removes and returns the first value from the queue
+	Pop(ctx context.Context) (repository.CacheEntry, bool)
 
 	// All returns all the values in the queue
-	All() []repository.CacheEntry
+	All(ctx context.Context) []repository.CacheEntry
+
+	// Metadata returns the metadata of the first entry with the given key and whether one was found
+	Metadata(ctx context.Context, key string) (repository.Metadata, bool)
+
+	// AllMetadata returns the metadata of all the entries in the queue, in queue order
+	AllMetadata(ctx context.Context) []repository.Metadata
 
 	// GetEntryList returns the first n entries in the queue
-	GetEntryList(n int) []repository.CacheEntry
+	GetEntryList(ctx context.Context, n int) []repository.CacheEntry
 
 	// GetSortedEntries returns the first n entries in the queue sorted by key or value
-	GetSortedEntries(selector, n int) []repository.CacheEntry
+	GetSortedEntries(ctx context.Context, selector, n int) []repository.CacheEntry
+
+	// UpdateValue updates the value of a given key and reports whether it was found
+	UpdateValue(ctx context.Context, key, newValue string) bool
+
+	// Delete removes the entries with the given key and reports whether one was found
+	Delete(ctx context.Context, key string) bool
+
+	// Remove removes the first entry with the given key and value and
+	// reports whether one was found, a replica replays with it the pops of
+	// its leader whose first entry may differ from its own
+	Remove(ctx context.Context, key, value string) bool
+
+	// SetCacheTimetoLive adds a value to the queue that expires after ttl seconds
+	SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) string
+
+	// Push adds the value of the entry to the back of the queue, or to its
+	// front if front is set, expiring at the ExpiresAt of the entry so that
+	// a replica or a value put back keeps the expiration it had
+	Push(ctx context.Context, entry repository.CacheEntry, front bool) string
+
+	// Len returns the number of entries in the queue
+	Len() int
+
+	// Bytes returns an estimate of the memory held by the entries of the queue
+	Bytes() int64
 
-	// UpdateValue updates the value of a given key
-	UpdateValue(key, newValue string) string
+	// Oldest returns when the oldest value still in the queue was added and
+	// whether the queue holds one
+	Oldest() (time.Time, bool)
 
-	// SetCacheTimetoLive adds a value to the queue with a time to live
-	SetCacheTimetoLive(key, value string, ttl ...int) string
+	// Flush removes all the entries from the queue
+	Flush(ctx context.Context)
+
+	// Sweep removes the expired entries and returns their keys
+	Sweep(ctx context.Context) []string
 }
 
 type queueService struct {
@@ -41,27 +90,71 @@ func NewQueueService(queueRepoInter repository.QueueRepoInterface) QueueServiceI
 }
 
 // Set implements the Set method of the QueueServiceInterface
-func (q *queueService) Set(key, value string) string {
+func (q *queueService) Set(ctx context.Context, key, value string) string {
+	return q.SetCacheTimetoLive(ctx, key, value, 0)
+}
+
+// Prepend implements the Prepend method of the QueueServiceInterface
+func (q *queueService) Prepend(ctx context.Context, key, value string) string {
 	hashedKey := common.HashKey(key)
-	q.queueInterface.Set(hashedKey, value)
+	q.queueInterface.Prepend(hashedKey, value, 0)
 	return key
 }
 
 // Get implements the Get method of the QueueServiceInterface
-func (q *queueService) Get() (string, string) {
-	key, value := q.queueInterface.Get()
-	return key, value
+func (q *queueService) Get(ctx context.Context) (repository.CacheEntry, bool) {
+	entry, ok := q.queueInterface.Get()
+	if !ok {
+		return entry, false
+	}
+	return q.decode(entry)
+}
+
+// Pop implements the Pop method of the QueueServiceInterface
+func (q *queueService) Pop(ctx context.Context) (repository.CacheEntry, bool) {
+	entry, ok := q.queueInterface.Pop()
+	if !ok {
+		return entry, false
+	}
+	return q.decode(entry)
 }
 
 // All implements the All method of the QueueServiceInterface
-func (q *queueService) All() []repository.CacheEntry {
-	return q.queueInterface.All()
+func (q *queueService) All(ctx context.Context) []repository.CacheEntry {
+	return q.GetEntryList(ctx, 0)
+}
+
+// Metadata implements the Metadata method of the QueueServiceInterface
+func (q *queueService) Metadata(ctx context.Context, key string) (repository.Metadata, bool) {
+	metadata, ok := q.queueInterface.Metadata(common.HashKey(key))
+	metadata.Key = key
+	return metadata, ok
+}
+
+// AllMetadata implements the AllMetadata method of the QueueServiceInterface
+func (q *queueService) AllMetadata(ctx context.Context) []repository.Metadata {
+	all := q.queueInterface.AllMetadata()
+	metadata := make([]repository.Metadata, 0, len(all))
+	for _, entry := range all {
+		key, err := common.DecodeHashedKey(entry.Key)
+		if err != nil {
+			klog.ErrorS(err, "Error decoding hashed key", "key", entry.Key)
+			continue
+		}
+		entry.Key = key
+		metadata = append(metadata, entry)
+	}
+	return metadata
 }
 
 // GetEntryList implements the GetEntryList method of the QueueServiceInterface
-func (q *queueService) GetEntryList(n int) []repository.CacheEntry {
+func (q *queueService) GetEntryList(ctx context.Context, n int) []repository.CacheEntry {
 	result := []repository.CacheEntry{}
 	for _, entry := range q.queueInterface.All() {
+		entry, ok := q.decode(entry)
+		if !ok {
+			continue
+		}
 		result = append(result, entry)
 		n--
 		if n == 0 {
@@ -72,27 +165,89 @@ func (q *queueService) GetEntryList(n int) []repository.CacheEntry {
 }
 
 // GetSortedEntries implements the GetSortedEntries method of the QueueServiceInterface
-func (q *queueService) GetSortedEntries(selector, n int) []repository.CacheEntry {
-	if selector != 1 && selector != 0 {
+func (q *queueService) GetSortedEntries(ctx context.Context, selector, n int) []repository.CacheEntry {
+	if selector != SortByKey && selector != SortByValue {
 		return nil
 	}
-	if selector == 1 {
-		return repository.SortQueueByKey(q.GetEntryList(n))
+	if selector == SortByKey {
+		return repository.SortQueueByKey(q.GetEntryList(ctx, n))
 	} else {
-		return repository.SortQueueByValue(q.GetEntryList(n))
+		return repository.SortQueueByValue(q.GetEntryList(ctx, n))
 	}
 }
 
 // UpdateValue implements the UpdateValue method of the QueueServiceInterface
-func (q *queueService) UpdateValue(key, newValue string) string {
+func (q *queueService) UpdateValue(ctx context.Context, key, newValue string) bool {
 	hashedKey := common.HashKey(key)
-	q.queueInterface.Update(hashedKey, newValue)
-	return key
+	return q.queueInterface.Update(hashedKey, newValue)
+}
+
+// Delete implements the Delete method of the QueueServiceInterface
+func (q *queueService) Delete(ctx context.Context, key string) bool {
+	hashedKey := common.HashKey(key)
+	return q.queueInterface.Delete(hashedKey)
+}
+
+// Remove implements the Remove method of the QueueServiceInterface
+func (q *queueService) Remove(ctx context.Context, key, value string) bool {
+	return q.queueInterface.Remove(common.HashKey(key), value)
 }
 
 // SetCacheTimetoLive implements the SetCacheTimetoLive method of the QueueServiceInterface
-func (q *queueService) SetCacheTimetoLive(key, value string, ttl ...int) string {
-	q.queueInterface.Set(key, value, ttl[0])
-	time.NewTimer(time.Duration(ttl[0]) * time.Second)
+func (q *queueService) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) string {
+	hashedKey := common.HashKey(key)
+	q.queueInterface.Set(hashedKey, value, time.Duration(ttl)*time.Second)
 	return key
 }
+
+// Push implements the Push method of the QueueServiceInterface
+func (q *queueService) Push(ctx context.Context, entry repository.CacheEntry, front bool) string {
+	q.queueInterface.Insert(common.HashKey(entry.Key), entry.Value, entry.ExpiresAt, front)
+	return entry.Key
+}
+
+// Len implements the Len method of the QueueServiceInterface
+func (q *queueService) Len() int {
+	return q.queueInterface.Len()
+}
+
+// Bytes implements the Bytes method of the QueueServiceInterface
+func (q *queueService) Bytes() int64 {
+	return q.queueInterface.Bytes()
+}
+
+// Oldest implements the Oldest method of the QueueServiceInterface
+func (q *queueService) Oldest() (time.Time, bool) {
+	return q.queueInterface.Oldest()
+}
+
+// Flush implements the Flush method of the QueueServiceInterface
+func (q *queueService) Flush(ctx context.Context) {
+	q.queueInterface.Flush()
+}
+
+// Sweep implements the Sweep method of the QueueServiceInterface
+func (q *queueService) Sweep(ctx context.Context) []string {
+	expired := q.queueInterface.DeleteExpired()
+	keys := make([]string, 0, len(expired))
+	for _, hashedKey := range expired {
+		key, err := common.DecodeHashedKey(hashedKey)
+		if err != nil {
+			klog.ErrorS(err, "Error decoding hashed key", "key", hashedKey)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// decode replaces the hashed key of the entry with the original key
+func (q *queueService) decode(entry repository.CacheEntry) (repository.CacheEntry, bool) {
+	key, err := common.DecodeHashedKey(entry.Key)
+	if err != nil {
+		klog.ErrorS(err, "Error decoding hashed key", "key", entry.Key)
+		return entry, false
+	}
+	entry.Key = key
+	return entry, true
+}
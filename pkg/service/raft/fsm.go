@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// Operations executed by the leader
+const (
+	// OpStore writes an entry if its condition holds
+	OpStore = "store"
+	// OpUpdate replaces the value of an existing key
+	OpUpdate = "update"
+	// OpModify replaces the value of a key if it is still Old
+	OpModify = "modify"
+	OpDelete = "delete"
+	OpExpire = "expire"
+	OpFlush  = "flush"
+)
+
+// Commands of the log, they are the operations once checked by the leader
+// and apply the same way on every node
+const (
+	commandPut    = "put"
+	commandUpdate = "update"
+	commandDelete = "delete"
+	commandExpire = "expire"
+	commandFlush  = "flush"
+)
+
+// ErrValueChanged is returned when the value read by Modify changed before it was replaced
+var ErrValueChanged = errors.New("value changed")
+
+// resultErrors are the errors of an operation, by the code sent back to the
+// node that forwarded it
+var resultErrors = map[string]error{
+	"exists":           mapRepository.ErrExists,
+	"not-found":        mapRepository.ErrNotFound,
+	"version-mismatch": mapRepository.ErrVersionMismatch,
+	"value-changed":    ErrValueChanged,
+}
+
+// Operation is a write of a map, the leader checks it against the applied
+// state and appends the resulting command to the log
+type Operation struct {
+	Op         string        `json:"op"`
+	Namespace  string        `json:"namespace"`
+	Key        string        `json:"key,omitempty"`
+	Value      string        `json:"value,omitempty"`
+	Flags      uint32        `json:"flags,omitempty"`
+	TimeToLive time.Duration `json:"time-to-live,omitempty"`
+	// ExpiresAt is the expiration of a store, it is used rather than
+	// TimeToLive when it is set
+	ExpiresAt time.Time               `json:"expires-at,omitempty"`
+	Condition mapRepository.Condition `json:"condition,omitempty"`
+	// Version is the version expected by an IfVersion store or delete
+	Version uint64 `json:"version,omitempty"`
+	// Old is the value expected by a modify
+	Old string `json:"old,omitempty"`
+}
+
+// Result is the outcome of an operation
+type Result struct {
+	// Index is the log index of the command, the forwarding node waits for
+	// it to be applied so that its clients read their writes
+	Index uint64                   `json:"index"`
+	Found bool                     `json:"found"`
+	Entry mapRepository.CacheEntry `json:"entry"`
+	Error string                   `json:"error,omitempty"`
+}
+
+// err returns the error of the operation
+func (r Result) err() error {
+	if r.Error == "" {
+		return nil
+	}
+	if err, ok := resultErrors[r.Error]; ok {
+		return err
+	}
+	return errors.New(r.Error)
+}
+
+// failed returns the result of an operation failing with err
+func failed(err error) Result {
+	for code, resultErr := range resultErrors {
+		if err == resultErr {
+			return Result{Error: code}
+		}
+	}
+	return Result{Error: err.Error()}
+}
+
+// Command is a change of the state machine, it is the same on every node
+// whatever its state so that the nodes never diverge
+type Command struct {
+	Op        string `json:"op"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key,omitempty"`
+	Value     string `json:"value,omitempty"`
+	Flags     uint32 `json:"flags,omitempty"`
+	// ExpiresAt is computed by the leader from the time to live of the
+	// operation, the entry expires at the same time on every node and is
+	// not written again when the log is replayed after it expired
+	ExpiresAt time.Time `json:"expires-at,omitempty"`
+}
+
+// snapshotEntry is an entry of the state machine in a snapshot
+type snapshotEntry struct {
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Flags     uint32    `json:"flags,omitempty"`
+	ExpiresAt time.Time `json:"expires-at,omitempty"`
+	Version   uint64    `json:"version"`
+}
+
+// stateMachine applies the commands to the maps of the namespaces. The
+// version of an entry is the log index of the command that last wrote it,
+// unlike the versions of the repositories it is the same on every node.
+type stateMachine struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	// lock is held for writing while a command is applied
+	lock     sync.RWMutex
+	versions map[string]map[string]uint64
+	// maps are the maps of the namespaces, unwrapped
+	maps     map[string]mapservice.MapServiceInterface
+	mapsLock sync.Mutex
+}
+
+func newStateMachine(namespaces namespaceservice.NamespaceServiceInterface) *stateMachine {
+	return &stateMachine{
+		namespaces: namespaces,
+		versions:   make(map[string]map[string]uint64),
+		maps:       make(map[string]mapservice.MapServiceInterface),
+	}
+}
+
+// register records the unwrapped map of a namespace
+func (s *stateMachine) register(namespace string, m mapservice.MapServiceInterface) {
+	s.mapsLock.Lock()
+	defer s.mapsLock.Unlock()
+
+	s.maps[namespace] = m
+}
+
+// mapOf returns the unwrapped map of a namespace, creating the namespace if needed
+func (s *stateMachine) mapOf(namespace string) mapservice.MapServiceInterface {
+	s.mapsLock.Lock()
+	m, ok := s.maps[namespace]
+	s.mapsLock.Unlock()
+	if ok {
+		return m
+	}
+	// creating the namespace registers its map
+	s.namespaces.Get(namespace)
+
+	s.mapsLock.Lock()
+	defer s.mapsLock.Unlock()
+	return s.maps[namespace]
+}
+
+// entry returns the entry of the key with its version
+func (s *stateMachine) entry(ctx context.Context, namespace, key string) (mapRepository.CacheEntry, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	entry, ok, _ := s.mapOf(namespace).GetEntry(ctx, key)
+	entry.Version = s.versions[namespace][key]
+	return entry, ok
+}
+
+// version returns the version of the key, the index of the entry that last wrote it
+func (s *stateMachine) version(namespace, key string) uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.versions[namespace][key]
+}
+
+// withVersions sets the version of entries of a namespace
+func (s *stateMachine) withVersions(namespace string, entries []mapRepository.CacheEntry) []mapRepository.CacheEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for i := range entries {
+		entries[i].Version = s.versions[namespace][entries[i].Key]
+	}
+	return entries
+}
+
+// sweep removes the expired entries of a namespace, it runs on every node
+// on its own since the commands carry the expiration set by the leader, the
+// entries expire at the same time everywhere as far as the clocks agree
+func (s *stateMachine) sweep(ctx context.Context, namespace string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	expired := s.mapOf(namespace).Sweep(ctx)
+	for _, key := range expired {
+		delete(s.versions[namespace], key)
+	}
+	return expired
+}
+
+// check returns the command of an operation, or its result if it has
+// nothing to change, against the applied state
+func (s *stateMachine) check(ctx context.Context, operation Operation) (*Command, Result) {
+	entry, ok := s.entry(ctx, operation.Namespace, operation.Key)
+	command := &Command{Namespace: operation.Namespace, Key: operation.Key, Value: operation.Value}
+	switch operation.Op {
+	case OpStore:
+		switch {
+		case operation.Condition == mapRepository.IfAbsent && ok:
+			return nil, failed(mapRepository.ErrExists)
+		case (operation.Condition == mapRepository.IfPresent || operation.Condition == mapRepository.IfVersion) && !ok:
+			return nil, failed(mapRepository.ErrNotFound)
+		case operation.Condition == mapRepository.IfVersion && entry.Version != operation.Version:
+			return nil, failed(mapRepository.ErrVersionMismatch)
+		}
+		command.Op = commandPut
+		command.Flags = operation.Flags
+		command.ExpiresAt = operation.ExpiresAt
+		if command.ExpiresAt.IsZero() {
+			command.ExpiresAt = expiresAt(operation.TimeToLive)
+		}
+	case OpUpdate:
+		if !ok {
+			return nil, Result{}
+		}
+		command.Op = commandUpdate
+	case OpModify:
+		if !ok {
+			return nil, failed(mapRepository.ErrNotFound)
+		}
+		if entry.Value != operation.Old {
+			return nil, failed(ErrValueChanged)
+		}
+		command.Op = commandUpdate
+	case OpDelete:
+		if !ok || (operation.Condition == mapRepository.IfVersion && entry.Version != operation.Version) {
+			return nil, Result{}
+		}
+		command.Op = commandDelete
+	case OpExpire:
+		if !ok {
+			return nil, Result{}
+		}
+		command.Op = commandExpire
+		command.ExpiresAt = expiresAt(operation.TimeToLive)
+	case OpFlush:
+		command.Op = commandFlush
+	default:
+		return nil, Result{Error: "unknown operation " + operation.Op}
+	}
+	return command, Result{}
+}
+
+// expiresAt returns the expiration of an entry written now with a time to
+// live, the zero time if it never expires
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// expired reports whether an entry expiring at expiresAt is already expired
+func expired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && !time.Now().Before(expiresAt)
+}
+
+// apply applies the command of a log entry
+func (s *stateMachine) apply(entry LogEntry) Result {
+	if entry.Command == nil {
+		return Result{Index: entry.Index}
+	}
+	command := entry.Command
+	ctx := context.Background()
+	m := s.mapOf(command.Namespace)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	versions, ok := s.versions[command.Namespace]
+	if !ok {
+		versions = make(map[string]uint64)
+		s.versions[command.Namespace] = versions
+	}
+	result := Result{Index: entry.Index}
+	switch command.Op {
+	case commandPut:
+		written := mapRepository.CacheEntry{Key: command.Key, Value: command.Value, Flags: command.Flags, ExpiresAt: command.ExpiresAt}
+		if expired(command.ExpiresAt) {
+			// the log is replayed or this node lags behind, the entry would
+			// be written again only to be swept
+			m.Delete(ctx, command.Key)
+			delete(versions, command.Key)
+			written.Version = entry.Index
+			result.Found, result.Entry = true, written
+			break
+		}
+		stored, _ := m.Store(ctx, written, mapRepository.Always)
+		versions[command.Key] = entry.Index
+		stored.Version = entry.Index
+		result.Found, result.Entry = true, stored
+	case commandUpdate:
+		updated, err := m.Modify(ctx, command.Key, func(string) (string, error) { return command.Value, nil })
+		if err != nil {
+			// the entry expired on this node since the leader checked it
+			result = failed(err)
+			result.Index = entry.Index
+			return result
+		}
+		versions[command.Key] = entry.Index
+		updated.Version = entry.Index
+		result.Found, result.Entry = true, updated
+	case commandDelete:
+		result.Found, _ = m.Delete(ctx, command.Key)
+		delete(versions, command.Key)
+	case commandExpire:
+		current, ok, _ := m.GetEntry(ctx, command.Key)
+		if !ok {
+			break
+		}
+		if expired(command.ExpiresAt) {
+			m.Delete(ctx, command.Key)
+			delete(versions, command.Key)
+			result.Found = true
+			break
+		}
+		// the entry is written again with the expiration of the command,
+		// a time to live would start when this node applies it
+		_, err := m.Store(ctx, mapRepository.CacheEntry{Key: command.Key, Value: current.Value, Flags: current.Flags, ExpiresAt: command.ExpiresAt}, mapRepository.IfPresent)
+		result.Found = err == nil
+	case commandFlush:
+		m.Flush(ctx)
+		delete(s.versions, command.Namespace)
+	}
+	return result
+}
+
+// snapshot returns the entries of every namespace
+func (s *stateMachine) snapshot() (json.RawMessage, error) {
+	ctx := context.Background()
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	entries := []snapshotEntry{}
+	for _, namespace := range s.namespaces.List() {
+		// the maps applying the log are local, reading them does not fail
+		all, _ := s.mapOf(namespace.Name).All(ctx)
+		for _, entry := range all {
+			entries = append(entries, snapshotEntry{
+				Namespace: namespace.Name,
+				Key:       entry.Key,
+				Value:     entry.Value,
+				Flags:     entry.Flags,
+				ExpiresAt: entry.ExpiresAt,
+				Version:   s.versions[namespace.Name][entry.Key],
+			})
+		}
+	}
+	return json.Marshal(entries)
+}
+
+// restore replaces the entries of every namespace by those of a snapshot
+func (s *stateMachine) restore(data json.RawMessage) error {
+	var entries []snapshotEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, namespace := range s.namespaces.List() {
+		s.mapOf(namespace.Name).Flush(ctx)
+	}
+	s.versions = make(map[string]map[string]uint64)
+	for _, entry := range entries {
+		if expired(entry.ExpiresAt) {
+			continue
+		}
+		s.mapOf(entry.Namespace).Store(ctx, mapRepository.CacheEntry{Key: entry.Key, Value: entry.Value, Flags: entry.Flags, ExpiresAt: entry.ExpiresAt}, mapRepository.Always)
+		if s.versions[entry.Namespace] == nil {
+			s.versions[entry.Namespace] = make(map[string]uint64)
+		}
+		s.versions[entry.Namespace][entry.Key] = entry.Version
+	}
+	return nil
+}
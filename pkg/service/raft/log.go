@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+)
+
+// Files of the storage directory
+const (
+	stateFile    = "state.json"
+	snapshotFile = "snapshot.json"
+	logFile      = "log.jsonl"
+)
+
+// LogEntry is an entry of the replicated log, a nil Command is the no-op
+// appended by a new leader
+type LogEntry struct {
+	Index   uint64   `json:"index"`
+	Term    uint64   `json:"term"`
+	Command *Command `json:"command,omitempty"`
+}
+
+// Snapshot is the state machine after the entry Index of term Term, the
+// entries up to Index are removed from the log once it is taken
+type Snapshot struct {
+	Index uint64          `json:"index"`
+	Term  uint64          `json:"term"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// persistentState is what a node must remember across restarts besides its
+// log, so that it never votes twice in a term
+type persistentState struct {
+	Term     uint64 `json:"term"`
+	VotedFor string `json:"voted-for,omitempty"`
+}
+
+// storage keeps the state, the snapshot and the log of a node in a
+// directory, every write is synced before it returns. A nil storage keeps
+// nothing and a restarted node then rejoins with an empty log.
+type storage struct {
+	directory string
+	log       *os.File
+}
+
+// openStorage opens the storage in directory, it returns nil if directory is empty
+func openStorage(directory string) (*storage, error) {
+	if directory == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(directory, logFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &storage{directory: directory, log: f}, nil
+}
+
+// load returns the stored state, snapshot and log entries after the snapshot
+func (s *storage) load() (persistentState, *Snapshot, []LogEntry, error) {
+	var state persistentState
+	if s == nil {
+		return state, nil, nil, nil
+	}
+	if err := readJSON(filepath.Join(s.directory, stateFile), &state); err != nil {
+		return state, nil, nil, err
+	}
+	var snapshot *Snapshot
+	var stored Snapshot
+	if err := readJSON(filepath.Join(s.directory, snapshotFile), &stored); err != nil {
+		return state, nil, nil, err
+	}
+	if stored.Index > 0 {
+		snapshot = &stored
+	}
+
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return state, nil, nil, err
+	}
+	var entries []LogEntry
+	decoder := json.NewDecoder(bufio.NewReader(s.log))
+	torn := false
+	for {
+		var entry LogEntry
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// the last write was interrupted, it was never acknowledged
+			klog.ErrorS(err, "Discarding the end of the raft log", "directory", s.directory)
+			torn = true
+			break
+		}
+		if snapshot != nil && entry.Index <= snapshot.Index {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	next := uint64(1)
+	if snapshot != nil {
+		next = snapshot.Index + 1
+	}
+	for i, entry := range entries {
+		if entry.Index != next+uint64(i) {
+			return state, nil, nil, fmt.Errorf("raft log in %s has a gap at index %d", s.directory, next+uint64(i))
+		}
+	}
+	if torn {
+		if err := s.rewrite(entries); err != nil {
+			return state, nil, nil, err
+		}
+	}
+	return state, snapshot, entries, nil
+}
+
+// saveState stores the term and the vote
+func (s *storage) saveState(state persistentState) error {
+	if s == nil {
+		return nil
+	}
+	return writeJSON(filepath.Join(s.directory, stateFile), state)
+}
+
+// saveSnapshot replaces the stored snapshot
+func (s *storage) saveSnapshot(snapshot Snapshot) error {
+	if s == nil {
+		return nil
+	}
+	return writeJSON(filepath.Join(s.directory, snapshotFile), snapshot)
+}
+
+// append adds entries at the end of the stored log
+func (s *storage) append(entries []LogEntry) error {
+	if s == nil || len(entries) == 0 {
+		return nil
+	}
+	w := bufio.NewWriter(s.log)
+	encoder := json.NewEncoder(w)
+	for i := range entries {
+		if err := encoder.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return s.log.Sync()
+}
+
+// rewrite replaces the stored log by entries, after a conflict with the
+// leader or a compaction
+func (s *storage) rewrite(entries []LogEntry) error {
+	if s == nil {
+		return nil
+	}
+	path := filepath.Join(s.directory, logFile)
+	tmp, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(w)
+	for i := range entries {
+		if err = encoder.Encode(&entries[i]); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	s.log.Close()
+	s.log = f
+	return nil
+}
+
+// close closes the stored log
+func (s *storage) close() error {
+	if s == nil {
+		return nil
+	}
+	return s.log.Close()
+}
+
+// readJSON decodes the file at path into v, a missing file leaves v unchanged
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSON replaces the file at path by the encoding of v, the file is
+// written aside and renamed so that a crash leaves the old or the new one
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+	}
+	return err
+}
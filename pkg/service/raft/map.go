@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	"k8s.io/klog/v2"
+)
+
+// WrapMap implements the WrapMap method of the RaftServiceInterface
+func (r *raftService) WrapMap(namespace string, m mapservice.MapServiceInterface) mapservice.MapServiceInterface {
+	r.state.register(namespace, m)
+	return &raftMap{
+		MapServiceInterface: m,
+		namespace:           namespace,
+		raft:                r,
+	}
+}
+
+// Execute implements the Execute method of the RaftServiceInterface
+func (r *raftService) Execute(ctx context.Context, operation Operation) (Result, error) {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+
+	// the operation is checked once every entry of the log is applied, the
+	// previous operations included
+	r.lock.Lock()
+	if r.role != RoleLeader {
+		r.lock.Unlock()
+		return Result{}, ErrNotLeader
+	}
+	last := r.lastIndex()
+	r.lock.Unlock()
+	if err := r.waitApplied(ctx, last); err != nil {
+		return Result{}, err
+	}
+
+	command, result := r.state.check(ctx, operation)
+	if command == nil {
+		return result, nil
+	}
+	p, err := r.propose(*command)
+	if err != nil {
+		return Result{}, err
+	}
+	select {
+	case <-ctx.Done():
+		return Result{}, ErrTimeout
+	case done := <-p.done:
+		return done.result, done.err
+	}
+}
+
+// execute runs an operation on the leader, forwarding it if this node is
+// not the leader, and returns once this node applied it
+func (r *raftService) execute(ctx context.Context, operation Operation) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	for {
+		var result Result
+		var err error
+		switch leader := r.currentLeader(); leader {
+		case "":
+			err = ErrNoLeader
+		case r.self:
+			result, err = r.Execute(ctx, operation)
+		default:
+			if result, err = r.transport.Execute(ctx, leader, operation); err == nil {
+				err = r.waitApplied(ctx, result.Index)
+			}
+		}
+		// nothing was proposed when no leader took the operation, it is
+		// retried once the nodes agree on a leader
+		if !errors.Is(err, ErrNoLeader) && !errors.Is(err, ErrNotLeader) {
+			if err == nil {
+				err = result.err()
+			}
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(heartbeatInterval):
+		}
+	}
+}
+
+// barrier returns once this node applied every entry committed when it was
+// called, a read that follows it is linearizable
+func (r *raftService) barrier(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
+	for {
+		var index uint64
+		var err error
+		switch leader := r.currentLeader(); leader {
+		case "":
+			err = ErrNoLeader
+		case r.self:
+			index, err = r.ReadIndex(ctx)
+		default:
+			index, err = r.transport.ReadIndex(ctx, leader)
+		}
+		if err == nil {
+			return r.waitApplied(ctx, index)
+		}
+		if !errors.Is(err, ErrNoLeader) && !errors.Is(err, ErrNotLeader) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(heartbeatInterval):
+		}
+	}
+}
+
+// raftMap sends the mutations of the map it wraps through the raft log and
+// makes its reads linearizable. The writes return the error of an operation
+// that was not committed and the reads the error of a read that could not be
+// made linearizable, the methods without an error result log the failures.
+type raftMap struct {
+	mapservice.MapServiceInterface
+	namespace string
+	raft      *raftService
+}
+
+// write executes an operation on the map
+func (m *raftMap) write(ctx context.Context, operation Operation) (Result, error) {
+	operation.Namespace = m.namespace
+	return m.raft.execute(ctx, operation)
+}
+
+// Set implements the Set method of the MapServiceInterface
+func (m *raftMap) Set(ctx context.Context, key, value string) (string, error) {
+	_, err := m.write(ctx, Operation{Op: OpStore, Key: key, Value: value})
+	return key, err
+}
+
+// SetCacheTimetoLive implements the SetCacheTimetoLive method of the MapServiceInterface
+func (m *raftMap) SetCacheTimetoLive(ctx context.Context, key, value string, ttl int) (string, error) {
+	_, err := m.write(ctx, Operation{Op: OpStore, Key: key, Value: value, TimeToLive: time.Duration(ttl) * time.Second})
+	return key, err
+}
+
+// Get implements the Get method of the MapServiceInterface
+func (m *raftMap) Get(ctx context.Context, key string) (string, bool, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return "", false, err
+	}
+	return m.MapServiceInterface.Get(ctx, key)
+}
+
+// GetEntry implements the GetEntry method of the MapServiceInterface
+func (m *raftMap) GetEntry(ctx context.Context, key string) (mapRepository.CacheEntry, bool, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return mapRepository.CacheEntry{}, false, err
+	}
+	entry, ok := m.raft.state.entry(ctx, m.namespace, key)
+	return entry, ok, nil
+}
+
+// Store implements the Store method of the MapServiceInterface
+func (m *raftMap) Store(ctx context.Context, entry mapRepository.CacheEntry, condition mapRepository.Condition) (mapRepository.CacheEntry, error) {
+	result, err := m.raft.execute(ctx, Operation{
+		Op:         OpStore,
+		Namespace:  m.namespace,
+		Key:        entry.Key,
+		Value:      entry.Value,
+		Flags:      entry.Flags,
+		TimeToLive: entry.TTL,
+		ExpiresAt:  entry.ExpiresAt,
+		Condition:  condition,
+		Version:    entry.Version,
+	})
+	result.Entry.Key = entry.Key
+	return result.Entry, err
+}
+
+// Modify implements the Modify method of the MapServiceInterface, the value
+// is replaced only if it did not change since fn was called with it
+func (m *raftMap) Modify(ctx context.Context, key string, fn func(value string) (string, error)) (mapRepository.CacheEntry, error) {
+	for {
+		if err := m.raft.barrier(ctx); err != nil {
+			return mapRepository.CacheEntry{}, err
+		}
+		entry, ok := m.raft.state.entry(ctx, m.namespace, key)
+		if !ok {
+			return mapRepository.CacheEntry{}, mapRepository.ErrNotFound
+		}
+		value, err := fn(entry.Value)
+		if err != nil {
+			return mapRepository.CacheEntry{}, err
+		}
+		result, err := m.raft.execute(ctx, Operation{Op: OpModify, Namespace: m.namespace, Key: key, Value: value, Old: entry.Value})
+		if errors.Is(err, ErrValueChanged) {
+			continue
+		}
+		result.Entry.Key = key
+		return result.Entry, err
+	}
+}
+
+// Delete implements the Delete method of the MapServiceInterface
+func (m *raftMap) Delete(ctx context.Context, key string) (bool, error) {
+	result, err := m.write(ctx, Operation{Op: OpDelete, Key: key})
+	return err == nil && result.Found, err
+}
+
+// DeleteVersion implements the DeleteVersion method of the MapServiceInterface
+func (m *raftMap) DeleteVersion(ctx context.Context, key string, version uint64) (bool, error) {
+	result, err := m.write(ctx, Operation{Op: OpDelete, Key: key, Condition: mapRepository.IfVersion, Version: version})
+	return err == nil && result.Found, err
+}
+
+// Expire implements the Expire method of the MapServiceInterface
+func (m *raftMap) Expire(ctx context.Context, key string, ttl int) (bool, error) {
+	result, err := m.write(ctx, Operation{Op: OpExpire, Key: key, TimeToLive: time.Duration(ttl) * time.Second})
+	return err == nil && result.Found, err
+}
+
+// TimeToLive implements the TimeToLive method of the MapServiceInterface
+func (m *raftMap) TimeToLive(ctx context.Context, key string) (time.Duration, bool, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return 0, false, err
+	}
+	return m.MapServiceInterface.TimeToLive(ctx, key)
+}
+
+// All implements the All method of the MapServiceInterface
+func (m *raftMap) All(ctx context.Context) ([]mapRepository.CacheEntry, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return nil, err
+	}
+	entries, err := m.MapServiceInterface.All(ctx)
+	return m.raft.state.withVersions(m.namespace, entries), err
+}
+
+// Metadata implements the Metadata method of the MapServiceInterface
+func (m *raftMap) Metadata(ctx context.Context, key string) (mapRepository.Metadata, bool, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return mapRepository.Metadata{}, false, err
+	}
+	metadata, ok, err := m.MapServiceInterface.Metadata(ctx, key)
+	metadata.Version = m.raft.state.version(m.namespace, key)
+	return metadata, ok, err
+}
+
+// AllMetadata implements the AllMetadata method of the MapServiceInterface
+func (m *raftMap) AllMetadata(ctx context.Context) ([]mapRepository.Metadata, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return nil, err
+	}
+	metadata, err := m.MapServiceInterface.AllMetadata(ctx)
+	for i := range metadata {
+		metadata[i].Version = m.raft.state.version(m.namespace, metadata[i].Key)
+	}
+	return metadata, err
+}
+
+// GetEntryList implements the GetEntryList method of the MapServiceInterface
+func (m *raftMap) GetEntryList(ctx context.Context, n int) ([]mapRepository.CacheEntry, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return nil, err
+	}
+	entries, err := m.MapServiceInterface.GetEntryList(ctx, n)
+	return m.raft.state.withVersions(m.namespace, entries), err
+}
+
+// GetSortedEntryList implements the GetSortedEntryList method of the MapServiceInterface
+func (m *raftMap) GetSortedEntryList(ctx context.Context, selector, n int) ([]mapRepository.CacheEntry, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return nil, err
+	}
+	entries, err := m.MapServiceInterface.GetSortedEntryList(ctx, selector, n)
+	return m.raft.state.withVersions(m.namespace, entries), err
+}
+
+// UpdateCacheEntry implements the UpdateCacheEntry method of the MapServiceInterface
+func (m *raftMap) UpdateCacheEntry(ctx context.Context, key, value string) (bool, error) {
+	result, err := m.write(ctx, Operation{Op: OpUpdate, Key: key, Value: value})
+	return err == nil && result.Found, err
+}
+
+// GetListofValues implements the GetListofValues method of the MapServiceInterface
+func (m *raftMap) GetListofValues(ctx context.Context, keys []string) ([]mapRepository.CacheEntry, error) {
+	if err := m.raft.barrier(ctx); err != nil {
+		return nil, err
+	}
+	entries, err := m.MapServiceInterface.GetListofValues(ctx, keys)
+	return m.raft.state.withVersions(m.namespace, entries), err
+}
+
+// Flush implements the Flush method of the MapServiceInterface
+func (m *raftMap) Flush(ctx context.Context) {
+	if _, err := m.write(ctx, Operation{Op: OpFlush}); err != nil {
+		klog.ErrorS(err, "Error executing a raft operation", "op", OpFlush, "namespace", m.namespace)
+	}
+}
+
+// Sweep implements the Sweep method of the MapServiceInterface
+func (m *raftMap) Sweep(ctx context.Context) []string {
+	return m.raft.state.sweep(ctx, m.namespace)
+}
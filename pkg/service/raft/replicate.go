@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// replicateTo sends the entries of the leader of term to peer until ctx is
+// done, it sends a heartbeat when there is nothing new
+func (r *raftService) replicateTo(ctx context.Context, peer string, term uint64, signal chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		if more := r.sendEntries(ctx, peer, term); more {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-signal:
+		}
+	}
+}
+
+// sendEntries sends the next entries, or the snapshot if they were
+// compacted, to peer and reports whether more are waiting
+func (r *raftService) sendEntries(ctx context.Context, peer string, term uint64) bool {
+	r.lock.Lock()
+	if r.role != RoleLeader || r.term != term || ctx.Err() != nil {
+		r.lock.Unlock()
+		return false
+	}
+	next := r.nextIndex[peer]
+	offset := r.log[0].Index
+	if next <= offset {
+		request := SnapshotRequest{Term: term, Leader: r.self, Snapshot: r.snapshot}
+		r.lock.Unlock()
+		return r.sendSnapshot(ctx, peer, request)
+	}
+	end := r.lastIndex() + 1
+	if end > next+maxAppendEntries {
+		end = next + maxAppendEntries
+	}
+	request := AppendRequest{
+		Term:         term,
+		Leader:       r.self,
+		PrevLogIndex: next - 1,
+		PrevLogTerm:  r.log[next-1-offset].Term,
+		Entries:      append([]LogEntry(nil), r.log[next-offset:end-offset]...),
+		LeaderCommit: r.commitIndex,
+	}
+	r.lock.Unlock()
+
+	rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	response, err := r.transport.AppendEntries(rpcCtx, peer, request)
+	cancel()
+	if err != nil {
+		klog.V(4).InfoS("Error sending raft entries", "node", peer, "err", err)
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if response.Term > r.term {
+		r.becomeFollower(response.Term, "")
+		return false
+	}
+	if r.role != RoleLeader || r.term != term {
+		return false
+	}
+	if !response.Success {
+		next := request.PrevLogIndex
+		if response.ConflictIndex > 0 && response.ConflictIndex < next {
+			next = response.ConflictIndex
+		}
+		if next < 1 {
+			next = 1
+		}
+		r.nextIndex[peer] = next
+		return true
+	}
+	if response.MatchIndex > r.matchIndex[peer] {
+		r.matchIndex[peer] = response.MatchIndex
+		r.advanceCommit()
+	}
+	if response.MatchIndex+1 > r.nextIndex[peer] {
+		r.nextIndex[peer] = response.MatchIndex + 1
+	}
+	return r.nextIndex[peer] <= r.lastIndex()
+}
+
+// sendSnapshot sends the snapshot of the leader to peer and reports whether entries follow it
+func (r *raftService) sendSnapshot(ctx context.Context, peer string, request SnapshotRequest) bool {
+	rpcCtx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	response, err := r.transport.InstallSnapshot(rpcCtx, peer, request)
+	cancel()
+	if err != nil {
+		klog.V(4).InfoS("Error sending the raft snapshot", "node", peer, "err", err)
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if response.Term > r.term {
+		r.becomeFollower(response.Term, "")
+		return false
+	}
+	if r.role != RoleLeader || r.term != request.Term {
+		return false
+	}
+	if request.Snapshot.Index > r.matchIndex[peer] {
+		r.matchIndex[peer] = request.Snapshot.Index
+		r.advanceCommit()
+	}
+	r.nextIndex[peer] = r.matchIndex[peer] + 1
+	return r.nextIndex[peer] <= r.lastIndex()
+}
+
+// advanceCommit commits the entries stored by a majority of the nodes, the lock must be held
+func (r *raftService) advanceCommit() {
+	matches := []uint64{r.lastIndex()}
+	for _, peer := range r.peers {
+		matches = append(matches, r.matchIndex[peer])
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i] > matches[j] })
+	index := matches[len(r.nodes)/2]
+	// only the entries of its own term are committed by counting, the
+	// previous ones are committed along with them
+	if index > r.commitIndex && r.log[index-r.log[0].Index].Term == r.term {
+		r.commitIndex = index
+		r.signalCommit()
+	}
+}
+
+// signalReplication wakes up the replication to every node, the lock must be held
+func (r *raftService) signalReplication() {
+	for _, signal := range r.replicate {
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ReadIndex implements the ReadIndex method of the RaftServiceInterface
+func (r *raftService) ReadIndex(ctx context.Context) (uint64, error) {
+	r.lock.Lock()
+	if r.role != RoleLeader {
+		r.lock.Unlock()
+		return 0, ErrNotLeader
+	}
+	term, leaderIndex := r.term, r.leaderIndex
+	r.lock.Unlock()
+
+	// the commit index of a new leader is only known once its no-op is committed
+	if err := r.waitApplied(ctx, leaderIndex); err != nil {
+		return 0, err
+	}
+	r.lock.Lock()
+	index := r.commitIndex
+	r.lock.Unlock()
+	if err := r.confirmLeadership(ctx, term); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// confirmLeadership checks that a majority still follows the leader of
+// term, no other leader can then have committed anything newer
+func (r *raftService) confirmLeadership(ctx context.Context, term uint64) error {
+	acks := make(chan bool, len(r.peers))
+	r.lock.Lock()
+	for _, peer := range r.peers {
+		// a heartbeat leaves the log of the peer untouched, its answer
+		// only matters for its term
+		prev := r.nextIndex[peer] - 1
+		if prev < r.log[0].Index {
+			prev = r.log[0].Index
+		}
+		request := AppendRequest{Term: term, Leader: r.self, PrevLogIndex: prev, PrevLogTerm: r.log[prev-r.log[0].Index].Term, LeaderCommit: r.commitIndex}
+		peer := peer
+		go func() {
+			rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+			defer cancel()
+			response, err := r.transport.AppendEntries(rpcCtx, peer, request)
+			if err == nil && response.Term > term {
+				r.lock.Lock()
+				if response.Term > r.term {
+					r.becomeFollower(response.Term, "")
+				}
+				r.lock.Unlock()
+			}
+			acks <- err == nil && response.Term == term
+		}()
+	}
+	r.lock.Unlock()
+
+	votes := 1
+	for i := 0; i < len(r.peers) && votes*2 <= len(r.nodes); i++ {
+		select {
+		case <-ctx.Done():
+			return ErrTimeout
+		case ack := <-acks:
+			if ack {
+				votes++
+			}
+		}
+	}
+	if votes*2 <= len(r.nodes) {
+		return ErrNoQuorum
+	}
+	return nil
+}
+
+// waitApplied returns once the entry index is applied
+func (r *raftService) waitApplied(ctx context.Context, index uint64) error {
+	for {
+		r.lock.Lock()
+		applied, lastApplied := r.applied, r.lastApplied
+		r.lock.Unlock()
+		if lastApplied >= index {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ErrTimeout
+		case <-applied:
+		}
+	}
+}
+
+// propose appends command to the log of the leader and returns the proposal
+// waiting for it to be applied
+func (r *raftService) propose(command Command) (*proposal, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.role != RoleLeader {
+		return nil, ErrNotLeader
+	}
+	entry := LogEntry{Index: r.lastIndex() + 1, Term: r.term, Command: &command}
+	if err := r.storage.append([]LogEntry{entry}); err != nil {
+		return nil, err
+	}
+	r.log = append(r.log, entry)
+	p := &proposal{term: r.term, done: make(chan proposalResult, 1)}
+	r.proposals[entry.Index] = p
+	r.signalReplication()
+	r.advanceCommit()
+	return p, nil
+}
+
+// applyLoop applies the committed entries and restores the snapshots
+// received from the leader until ctx is done
+func (r *raftService) applyLoop(ctx context.Context) {
+	for {
+		r.lock.Lock()
+		snapshot := r.pendingSnapshot
+		r.pendingSnapshot = nil
+		var entries []LogEntry
+		if r.commitIndex > r.lastApplied && r.lastApplied >= r.log[0].Index {
+			offset := r.log[0].Index
+			entries = append(entries, r.log[r.lastApplied+1-offset:r.commitIndex+1-offset]...)
+		}
+		r.lock.Unlock()
+
+		if snapshot != nil {
+			r.restore(*snapshot)
+			continue
+		}
+		if len(entries) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.commit:
+			}
+			continue
+		}
+		for _, entry := range entries {
+			result := r.state.apply(entry)
+			r.lock.Lock()
+			r.lastApplied = entry.Index
+			if p, ok := r.proposals[entry.Index]; ok {
+				delete(r.proposals, entry.Index)
+				if p.term == entry.Term {
+					p.done <- proposalResult{result: result}
+				} else {
+					p.done <- proposalResult{err: ErrLeadershipLost}
+				}
+			}
+			r.lock.Unlock()
+		}
+		r.notifyApplied()
+		r.maybeSnapshot()
+	}
+}
+
+// restore replaces the state machine by a snapshot received from the leader
+func (r *raftService) restore(snapshot Snapshot) {
+	r.lock.Lock()
+	stale := snapshot.Index <= r.lastApplied
+	r.lock.Unlock()
+	if stale {
+		return
+	}
+	if err := r.state.restore(snapshot.Data); err != nil {
+		klog.ErrorS(err, "Error restoring the raft snapshot", "index", snapshot.Index)
+		return
+	}
+
+	r.lock.Lock()
+	r.lastApplied = snapshot.Index
+	for index, p := range r.proposals {
+		if index <= snapshot.Index {
+			delete(r.proposals, index)
+			p.done <- proposalResult{err: ErrLeadershipLost}
+		}
+	}
+	r.lock.Unlock()
+	r.notifyApplied()
+	klog.InfoS("Restored raft snapshot", "index", snapshot.Index)
+}
+
+// notifyApplied wakes up the readers and writers waiting for entries to be applied
+func (r *raftService) notifyApplied() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	close(r.applied)
+	r.applied = make(chan struct{})
+}
+
+// maybeSnapshot compacts the log once enough entries were applied since the
+// last snapshot, it is called by the apply loop so that nothing is applied
+// while the state machine is saved
+func (r *raftService) maybeSnapshot() {
+	r.lock.Lock()
+	index := r.lastApplied
+	if index-r.log[0].Index < r.snapshotThreshold {
+		r.lock.Unlock()
+		return
+	}
+	term := r.log[index-r.log[0].Index].Term
+	r.lock.Unlock()
+
+	data, err := r.state.snapshot()
+	if err != nil {
+		klog.ErrorS(err, "Error taking the raft snapshot")
+		return
+	}
+	snapshot := Snapshot{Index: index, Term: term, Data: data}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if index <= r.log[0].Index {
+		return
+	}
+	if err := r.storage.saveSnapshot(snapshot); err != nil {
+		klog.ErrorS(err, "Error saving the raft snapshot")
+		return
+	}
+	log := append([]LogEntry{{Index: index, Term: term}}, r.log[index-r.log[0].Index+1:]...)
+	if err := r.storage.rewrite(log[1:]); err != nil {
+		// the stored log still starts before the snapshot, which load skips
+		klog.ErrorS(err, "Error compacting the raft log")
+	}
+	r.log = log
+	r.snapshot = snapshot
+	klog.V(2).InfoS("Compacted the raft log", "index", index)
+}
@@ -0,0 +1,587 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// Roles of a node
+const (
+	RoleFollower  = "follower"
+	RoleCandidate = "candidate"
+	RoleLeader    = "leader"
+)
+
+const (
+	// heartbeatInterval is how often the leader sends its entries or a heartbeat to every node
+	heartbeatInterval = 100 * time.Millisecond
+	// electionTimeout is the minimum time without hearing from a leader
+	// before a follower starts an election, the actual timeout is drawn
+	// between it and twice it so that the nodes rarely start together
+	electionTimeout = 500 * time.Millisecond
+	// rpcTimeout bounds the messages sent to the other nodes
+	rpcTimeout = time.Second
+	// snapshotTimeout bounds the sending of a snapshot
+	snapshotTimeout = 30 * time.Second
+	// operationTimeout bounds a write or a read waiting for the cluster
+	operationTimeout = 5 * time.Second
+	// maxAppendEntries is the number of entries sent at once to a node
+	maxAppendEntries = 512
+	// DefaultSnapshotThreshold is the number of applied entries after which the log is compacted
+	DefaultSnapshotThreshold = 10000
+)
+
+// StatusNotLeader is the HTTP status returned by a node asked to do what
+// only the leader can do
+const StatusNotLeader = http.StatusMisdirectedRequest
+
+var (
+	// ErrNotLeader is returned when an operation reserved to the leader is sent to another node
+	ErrNotLeader = errors.New("this node is not the raft leader")
+	// ErrNoLeader is returned when the nodes have not elected a leader yet
+	ErrNoLeader = errors.New("no raft leader is elected")
+	// ErrLeadershipLost is returned when the leader changed before a write
+	// was committed, the write was then discarded
+	ErrLeadershipLost = errors.New("raft leadership changed before the write was committed")
+	// ErrNoQuorum is returned when a majority of the nodes did not confirm
+	// that the leader still leads
+	ErrNoQuorum = errors.New("a majority of the raft nodes did not answer the leader")
+	// ErrTimeout is returned when the nodes did not answer in time, a write
+	// may still be committed later
+	ErrTimeout = errors.New("raft cluster did not answer in time")
+)
+
+// Options configures the raft service
+type Options struct {
+	// Self is the URL of the HTTP API of this node
+	Self string
+	// Nodes are the URLs of the HTTP API of every node, including Self
+	Nodes []string
+	// Directory keeps the log, the vote and the snapshots across restarts,
+	// nothing is kept when it is empty
+	Directory string
+	// SnapshotThreshold is the number of applied entries after which the
+	// log is compacted, DefaultSnapshotThreshold when zero
+	SnapshotThreshold uint64
+	// Transport sends the messages to the other nodes, over HTTP when nil
+	Transport Transport
+}
+
+// Status describes the raft state of a node
+type Status struct {
+	ID            string   `json:"id"`
+	Role          string   `json:"role"`
+	Term          uint64   `json:"term"`
+	Leader        string   `json:"leader,omitempty"`
+	Nodes         []string `json:"nodes"`
+	LastIndex     uint64   `json:"last-index"`
+	CommitIndex   uint64   `json:"commit-index"`
+	AppliedIndex  uint64   `json:"applied-index"`
+	SnapshotIndex uint64   `json:"snapshot-index"`
+}
+
+type RaftServiceInterface interface {
+	// Status returns the raft state of the node
+	Status() Status
+
+	// WrapMap wraps the map of a namespace so that its mutations go through
+	// the raft log and its reads are linearizable, it is used as the map
+	// wrapper of the namespace service
+	WrapMap(namespace string, m mapservice.MapServiceInterface) mapservice.MapServiceInterface
+
+	// Run takes part in the elections and applies the committed entries until ctx is done
+	Run(ctx context.Context)
+
+	// Close closes the storage, Run must have returned
+	Close() error
+
+	// RequestVote handles a vote request of a candidate
+	RequestVote(request VoteRequest) VoteResponse
+
+	// AppendEntries handles the entries or the heartbeat of a leader
+	AppendEntries(request AppendRequest) AppendResponse
+
+	// InstallSnapshot handles a snapshot sent by a leader
+	InstallSnapshot(request SnapshotRequest) SnapshotResponse
+
+	// ReadIndex returns the commit index once the leader confirmed it still
+	// leads, ErrNotLeader is returned on the other nodes
+	ReadIndex(ctx context.Context) (uint64, error)
+
+	// Execute runs an operation on the leader and returns once it is
+	// applied, ErrNotLeader is returned on the other nodes
+	Execute(ctx context.Context, operation Operation) (Result, error)
+}
+
+// proposal is a write of the leader waiting to be applied
+type proposal struct {
+	term uint64
+	done chan proposalResult
+}
+
+type proposalResult struct {
+	result Result
+	err    error
+}
+
+type raftService struct {
+	self              string
+	peers             []string
+	nodes             []string
+	transport         Transport
+	storage           *storage
+	snapshotThreshold uint64
+	state             *stateMachine
+
+	// ctx is the context of Run, the replication of a leader stops with it
+	ctx      context.Context
+	role     string
+	term     uint64
+	votedFor string
+	leader   string
+	// log starts with the entry the snapshot ends at, it holds no command
+	log      []LogEntry
+	snapshot Snapshot
+	// pendingSnapshot is received from the leader and restored by the apply loop
+	pendingSnapshot *Snapshot
+	commitIndex     uint64
+	lastApplied     uint64
+	deadline        time.Time
+	// the fields below are only used by the leader
+	leaderIndex uint64
+	nextIndex   map[string]uint64
+	matchIndex  map[string]uint64
+	replicate   map[string]chan struct{}
+	stepDown    context.CancelFunc
+	proposals   map[uint64]*proposal
+	// commit wakes up the apply loop
+	commit chan struct{}
+	// applied is closed and replaced every time entries are applied
+	applied chan struct{}
+	lock    sync.Mutex
+	// writes serializes the operations executed by the leader, each one is
+	// checked against the state left by the previous ones
+	writes sync.Mutex
+}
+
+// NewRaftService returns the raft service of the node options.Self, the
+// state kept in options.Directory is loaded
+func NewRaftService(namespaces namespaceservice.NamespaceServiceInterface, options Options) (RaftServiceInterface, error) {
+	self := strings.TrimRight(options.Self, "/")
+	seen := make(map[string]bool)
+	var nodes, peers []string
+	for _, node := range options.Nodes {
+		node = strings.TrimRight(node, "/")
+		if node == "" || seen[node] {
+			continue
+		}
+		seen[node] = true
+		nodes = append(nodes, node)
+		if node != self {
+			peers = append(peers, node)
+		}
+	}
+	if !seen[self] {
+		return nil, fmt.Errorf("raft node %q is not one of the nodes %v", self, nodes)
+	}
+	sort.Strings(nodes)
+	if options.SnapshotThreshold == 0 {
+		options.SnapshotThreshold = DefaultSnapshotThreshold
+	}
+	if options.Transport == nil {
+		options.Transport = NewHTTPTransport()
+	}
+
+	storage, err := openStorage(options.Directory)
+	if err != nil {
+		return nil, err
+	}
+	state, snapshot, entries, err := storage.load()
+	if err != nil {
+		storage.close()
+		return nil, err
+	}
+
+	r := &raftService{
+		self:              self,
+		peers:             peers,
+		nodes:             nodes,
+		transport:         options.Transport,
+		storage:           storage,
+		snapshotThreshold: options.SnapshotThreshold,
+		state:             newStateMachine(namespaces),
+		role:              RoleFollower,
+		term:              state.Term,
+		votedFor:          state.VotedFor,
+		log:               []LogEntry{{}},
+		proposals:         make(map[uint64]*proposal),
+		commit:            make(chan struct{}, 1),
+		applied:           make(chan struct{}),
+		lock:              sync.Mutex{},
+	}
+	if snapshot != nil {
+		r.snapshot = *snapshot
+		r.log[0] = LogEntry{Index: snapshot.Index, Term: snapshot.Term}
+		r.commitIndex = snapshot.Index
+		r.pendingSnapshot = snapshot
+	}
+	r.log = append(r.log, entries...)
+	klog.InfoS("Loaded raft state", "term", r.term, "snapshotIndex", r.log[0].Index, "lastIndex", r.lastIndex())
+	return r, nil
+}
+
+// Status implements the Status method of the RaftServiceInterface
+func (r *raftService) Status() Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return Status{
+		ID:            r.self,
+		Role:          r.role,
+		Term:          r.term,
+		Leader:        r.leader,
+		Nodes:         append([]string(nil), r.nodes...),
+		LastIndex:     r.lastIndex(),
+		CommitIndex:   r.commitIndex,
+		AppliedIndex:  r.lastApplied,
+		SnapshotIndex: r.log[0].Index,
+	}
+}
+
+// Run implements the Run method of the RaftServiceInterface
+func (r *raftService) Run(ctx context.Context) {
+	r.lock.Lock()
+	r.ctx = ctx
+	r.resetDeadline()
+	r.lock.Unlock()
+
+	var loops sync.WaitGroup
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		r.applyLoop(ctx)
+	}()
+
+	ticker := time.NewTicker(electionTimeout / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.lock.Lock()
+			if r.stepDown != nil {
+				r.stepDown()
+			}
+			r.lock.Unlock()
+			loops.Wait()
+			return
+		case <-ticker.C:
+			r.tick()
+		}
+	}
+}
+
+// Close implements the Close method of the RaftServiceInterface
+func (r *raftService) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.storage.close()
+}
+
+// tick starts an election when the leader has not been heard from in time
+func (r *raftService) tick() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.role == RoleLeader || time.Now().Before(r.deadline) {
+		return
+	}
+	r.resetDeadline()
+	if err := r.storage.saveState(persistentState{Term: r.term + 1, VotedFor: r.self}); err != nil {
+		klog.ErrorS(err, "Error saving the raft state, not starting an election")
+		return
+	}
+	r.term++
+	r.votedFor = r.self
+	r.role = RoleCandidate
+	r.leader = ""
+	klog.V(2).InfoS("Starting raft election", "term", r.term)
+
+	votes := 1
+	if votes*2 > len(r.nodes) {
+		r.becomeLeader()
+		return
+	}
+	term := r.term
+	request := VoteRequest{Term: term, Candidate: r.self, LastLogIndex: r.lastIndex(), LastLogTerm: r.lastTerm()}
+	for _, peer := range r.peers {
+		peer := peer
+		go func() {
+			ctx, cancel := context.WithTimeout(r.ctx, rpcTimeout)
+			defer cancel()
+			response, err := r.transport.RequestVote(ctx, peer, request)
+			if err != nil {
+				klog.V(4).InfoS("Error requesting a raft vote", "node", peer, "err", err)
+				return
+			}
+
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			if response.Term > r.term {
+				r.becomeFollower(response.Term, "")
+				return
+			}
+			if r.role != RoleCandidate || r.term != term || !response.Granted {
+				return
+			}
+			votes++
+			if votes*2 > len(r.nodes) {
+				r.becomeLeader()
+			}
+		}()
+	}
+}
+
+// RequestVote implements the RequestVote method of the RaftServiceInterface
+func (r *raftService) RequestVote(request VoteRequest) VoteResponse {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if request.Term > r.term {
+		r.becomeFollower(request.Term, "")
+	}
+	response := VoteResponse{Term: r.term}
+	if request.Term < r.term || (r.votedFor != "" && r.votedFor != request.Candidate) {
+		return response
+	}
+	// the candidate must have every committed entry, which a majority has
+	upToDate := request.LastLogTerm > r.lastTerm() || (request.LastLogTerm == r.lastTerm() && request.LastLogIndex >= r.lastIndex())
+	if !upToDate {
+		return response
+	}
+	if err := r.storage.saveState(persistentState{Term: r.term, VotedFor: request.Candidate}); err != nil {
+		klog.ErrorS(err, "Error saving the raft state, vote not granted")
+		return response
+	}
+	r.votedFor = request.Candidate
+	r.resetDeadline()
+	response.Granted = true
+	return response
+}
+
+// AppendEntries implements the AppendEntries method of the RaftServiceInterface
+func (r *raftService) AppendEntries(request AppendRequest) AppendResponse {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if request.Term < r.term {
+		return AppendResponse{Term: r.term}
+	}
+	r.becomeFollower(request.Term, request.Leader)
+	r.resetDeadline()
+	response := AppendResponse{Term: r.term}
+
+	offset := r.log[0].Index
+	if request.PrevLogIndex > r.lastIndex() {
+		response.ConflictIndex = r.lastIndex() + 1
+		return response
+	}
+	entries := request.Entries
+	if request.PrevLogIndex >= offset {
+		if term := r.log[request.PrevLogIndex-offset].Term; term != request.PrevLogTerm {
+			// skip back over the whole conflicting term at once
+			conflict := request.PrevLogIndex
+			for conflict > offset+1 && r.log[conflict-1-offset].Term == term {
+				conflict--
+			}
+			response.ConflictIndex = conflict
+			return response
+		}
+	}
+
+	// drop the entries already in the log, a conflicting entry and every
+	// entry after it are replaced
+	truncate := false
+	for len(entries) > 0 {
+		entry := entries[0]
+		if entry.Index <= offset {
+			entries = entries[1:]
+			continue
+		}
+		if entry.Index > r.lastIndex() {
+			break
+		}
+		if r.log[entry.Index-offset].Term != entry.Term {
+			truncate = true
+			break
+		}
+		entries = entries[1:]
+	}
+	if len(entries) > 0 {
+		log := r.log
+		if truncate {
+			log = log[: entries[0].Index-offset : entries[0].Index-offset]
+		}
+		log = append(log, entries...)
+		var err error
+		if truncate {
+			err = r.storage.rewrite(log[1:])
+		} else {
+			err = r.storage.append(entries)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Error saving the raft log")
+			return response
+		}
+		r.log = log
+	}
+
+	response.Success = true
+	response.MatchIndex = request.PrevLogIndex + uint64(len(request.Entries))
+	if request.LeaderCommit > r.commitIndex {
+		commit := request.LeaderCommit
+		if commit > response.MatchIndex {
+			commit = response.MatchIndex
+		}
+		if commit > r.commitIndex {
+			r.commitIndex = commit
+			r.signalCommit()
+		}
+	}
+	return response
+}
+
+// InstallSnapshot implements the InstallSnapshot method of the RaftServiceInterface
+func (r *raftService) InstallSnapshot(request SnapshotRequest) SnapshotResponse {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if request.Term < r.term {
+		return SnapshotResponse{Term: r.term}
+	}
+	r.becomeFollower(request.Term, request.Leader)
+	r.resetDeadline()
+	response := SnapshotResponse{Term: r.term}
+
+	snapshot := request.Snapshot
+	if snapshot.Index <= r.log[0].Index || snapshot.Index <= r.lastApplied {
+		return response
+	}
+	if err := r.storage.saveSnapshot(snapshot); err != nil {
+		klog.ErrorS(err, "Error saving the raft snapshot")
+		return response
+	}
+	// the entries following the snapshot are kept if the log agrees with it
+	log := []LogEntry{{Index: snapshot.Index, Term: snapshot.Term}}
+	if snapshot.Index <= r.lastIndex() && r.log[snapshot.Index-r.log[0].Index].Term == snapshot.Term {
+		log = append(log, r.log[snapshot.Index-r.log[0].Index+1:]...)
+	}
+	if err := r.storage.rewrite(log[1:]); err != nil {
+		klog.ErrorS(err, "Error saving the raft log")
+		return response
+	}
+	r.log = log
+	r.snapshot = snapshot
+	if r.commitIndex < snapshot.Index {
+		r.commitIndex = snapshot.Index
+	}
+	r.pendingSnapshot = &snapshot
+	r.signalCommit()
+	klog.InfoS("Received raft snapshot", "index", snapshot.Index, "leader", request.Leader)
+	return response
+}
+
+// becomeFollower follows leader in term, the lock must be held
+func (r *raftService) becomeFollower(term uint64, leader string) {
+	if term > r.term {
+		if err := r.storage.saveState(persistentState{Term: term}); err != nil {
+			klog.ErrorS(err, "Error saving the raft state")
+		}
+		r.term = term
+		r.votedFor = ""
+	}
+	if r.role == RoleLeader {
+		klog.InfoS("Stepping down as raft leader", "term", r.term)
+		r.stepDown()
+		r.stepDown = nil
+	}
+	r.role = RoleFollower
+	if leader != "" && leader != r.leader {
+		klog.InfoS("Following raft leader", "leader", leader, "term", r.term)
+	}
+	r.leader = leader
+}
+
+// becomeLeader starts replicating the log to the other nodes, the lock must be held
+func (r *raftService) becomeLeader() {
+	r.role = RoleLeader
+	r.leader = r.self
+	// the no-op commits the entries of the previous terms, which a leader
+	// can only commit along with an entry of its own term
+	entry := LogEntry{Index: r.lastIndex() + 1, Term: r.term}
+	if err := r.storage.append([]LogEntry{entry}); err != nil {
+		klog.ErrorS(err, "Error saving the raft log, not taking the lead")
+		r.role = RoleFollower
+		r.leader = ""
+		return
+	}
+	r.log = append(r.log, entry)
+	r.leaderIndex = entry.Index
+	klog.InfoS("Elected raft leader", "term", r.term)
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.stepDown = cancel
+	r.nextIndex = make(map[string]uint64)
+	r.matchIndex = make(map[string]uint64)
+	r.replicate = make(map[string]chan struct{})
+	for _, peer := range r.peers {
+		r.nextIndex[peer] = entry.Index
+		r.matchIndex[peer] = 0
+		signal := make(chan struct{}, 1)
+		r.replicate[peer] = signal
+		go r.replicateTo(ctx, peer, r.term, signal)
+	}
+	r.advanceCommit()
+}
+
+// resetDeadline draws the next election deadline, the lock must be held
+func (r *raftService) resetDeadline() {
+	r.deadline = time.Now().Add(electionTimeout + time.Duration(rand.Int63n(int64(electionTimeout))))
+}
+
+// signalCommit wakes up the apply loop, the lock must be held
+func (r *raftService) signalCommit() {
+	select {
+	case r.commit <- struct{}{}:
+	default:
+	}
+}
+
+// lastIndex returns the index of the last entry of the log, the lock must be held
+func (r *raftService) lastIndex() uint64 {
+	return r.log[len(r.log)-1].Index
+}
+
+// lastTerm returns the term of the last entry of the log, the lock must be held
+func (r *raftService) lastTerm() uint64 {
+	return r.log[len(r.log)-1].Term
+}
+
+// currentLeader returns the current leader, empty while none is known
+func (r *raftService) currentLeader() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.leader
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+var errUnreachable = errors.New("node unreachable")
+
+// memoryNetwork connects the nodes of an in-process cluster, a node cut off
+// from the network neither sends nor receives messages
+type memoryNetwork struct {
+	nodes    map[string]RaftServiceInterface
+	isolated map[string]bool
+	lock     sync.Mutex
+}
+
+func newMemoryNetwork() *memoryNetwork {
+	return &memoryNetwork{
+		nodes:    make(map[string]RaftServiceInterface),
+		isolated: make(map[string]bool),
+	}
+}
+
+func (n *memoryNetwork) add(name string, node RaftServiceInterface) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.nodes[name] = node
+}
+
+func (n *memoryNetwork) remove(name string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.nodes, name)
+}
+
+// isolate cuts the node off the network or connects it back
+func (n *memoryNetwork) isolate(name string, isolated bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.isolated[name] = isolated
+}
+
+// route returns the node a message of from is delivered to
+func (n *memoryNetwork) route(from, to string) (RaftServiceInterface, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	node, ok := n.nodes[to]
+	if !ok || n.isolated[from] || n.isolated[to] {
+		return nil, fmt.Errorf("%s to %s: %w", from, to, errUnreachable)
+	}
+	return node, nil
+}
+
+// roundTrip encodes and decodes a message the way the HTTP transport does
+func roundTrip(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// memoryTransport sends the messages of a node over a memoryNetwork
+type memoryTransport struct {
+	network *memoryNetwork
+	self    string
+}
+
+func (t *memoryTransport) RequestVote(ctx context.Context, node string, request VoteRequest) (VoteResponse, error) {
+	var response VoteResponse
+	peer, err := t.network.route(t.self, node)
+	if err != nil {
+		return response, err
+	}
+	var sent VoteRequest
+	if err := roundTrip(request, &sent); err != nil {
+		return response, err
+	}
+	err = roundTrip(peer.RequestVote(sent), &response)
+	return response, err
+}
+
+func (t *memoryTransport) AppendEntries(ctx context.Context, node string, request AppendRequest) (AppendResponse, error) {
+	var response AppendResponse
+	peer, err := t.network.route(t.self, node)
+	if err != nil {
+		return response, err
+	}
+	var sent AppendRequest
+	if err := roundTrip(request, &sent); err != nil {
+		return response, err
+	}
+	err = roundTrip(peer.AppendEntries(sent), &response)
+	return response, err
+}
+
+func (t *memoryTransport) InstallSnapshot(ctx context.Context, node string, request SnapshotRequest) (SnapshotResponse, error) {
+	var response SnapshotResponse
+	peer, err := t.network.route(t.self, node)
+	if err != nil {
+		return response, err
+	}
+	var sent SnapshotRequest
+	if err := roundTrip(request, &sent); err != nil {
+		return response, err
+	}
+	err = roundTrip(peer.InstallSnapshot(sent), &response)
+	return response, err
+}
+
+func (t *memoryTransport) ReadIndex(ctx context.Context, node string) (uint64, error) {
+	peer, err := t.network.route(t.self, node)
+	if err != nil {
+		return 0, err
+	}
+	return peer.ReadIndex(ctx)
+}
+
+func (t *memoryTransport) Execute(ctx context.Context, node string, operation Operation) (Result, error) {
+	var result Result
+	peer, err := t.network.route(t.self, node)
+	if err != nil {
+		return result, err
+	}
+	var sent Operation
+	if err := roundTrip(operation, &sent); err != nil {
+		return result, err
+	}
+	executed, err := peer.Execute(ctx, sent)
+	if err != nil {
+		return result, err
+	}
+	err = roundTrip(executed, &result)
+	return result, err
+}
+
+// testNode is a running node of an in-process cluster
+type testNode struct {
+	name       string
+	raft       *raftService
+	namespaces namespaceservice.NamespaceServiceInterface
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// startNode starts the node name of the cluster of nodes, its state is kept
+// in directory when it is not empty
+func startNode(t *testing.T, network *memoryNetwork, name string, nodes []string, directory string) *testNode {
+	t.Helper()
+
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	raft, err := NewRaftService(namespaces, Options{
+		Self:      name,
+		Nodes:     nodes,
+		Directory: directory,
+		Transport: &memoryTransport{network: network, self: name},
+	})
+	if err != nil {
+		t.Fatalf("creating node %s: %v", name, err)
+	}
+	namespaces.WrapMaps(raft.WrapMap)
+	network.add(name, raft)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	node := &testNode{name: name, raft: raft.(*raftService), namespaces: namespaces, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(node.done)
+		raft.Run(ctx)
+	}()
+	return node
+}
+
+// stop stops the node and closes its storage
+func (n *testNode) stop(t *testing.T, network *memoryNetwork) {
+	t.Helper()
+
+	network.remove(n.name)
+	n.cancel()
+	<-n.done
+	if err := n.raft.Close(); err != nil {
+		t.Errorf("closing node %s: %v", n.name, err)
+	}
+}
+
+// cache returns the default namespace of the node
+func (n *testNode) cache() *namespaceservice.Namespace {
+	return n.namespaces.Get(namespaceservice.DefaultNamespace)
+}
+
+// startCluster starts three nodes, directories keep their state when given
+func startCluster(t *testing.T, network *memoryNetwork, directories ...string) []*testNode {
+	t.Helper()
+
+	names := []string{"node-1", "node-2", "node-3"}
+	nodes := make([]*testNode, 0, len(names))
+	for i, name := range names {
+		directory := ""
+		if i < len(directories) {
+			directory = directories[i]
+		}
+		nodes = append(nodes, startNode(t, network, name, names, directory))
+	}
+	return nodes
+}
+
+func stopCluster(t *testing.T, network *memoryNetwork, nodes []*testNode) {
+	t.Helper()
+
+	for _, node := range nodes {
+		node.stop(t, network)
+	}
+}
+
+// waitLeader waits until the nodes agree on a leader among them and returns it
+func waitLeader(t *testing.T, nodes ...*testNode) *testNode {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		leader := nodes[0].raft.Status().Leader
+		agreed := leader != ""
+		for _, node := range nodes[1:] {
+			if node.raft.Status().Leader != leader {
+				agreed = false
+			}
+		}
+		for _, node := range nodes {
+			if agreed && node.name == leader && node.raft.Status().Role == RoleLeader {
+				return node
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("the nodes did not agree on a leader")
+	return nil
+}
+
+// followers returns the nodes other than leader
+func followers(nodes []*testNode, leader *testNode) []*testNode {
+	var others []*testNode
+	for _, node := range nodes {
+		if node != leader {
+			others = append(others, node)
+		}
+	}
+	return others
+}
+
+// eventually polls condition until it holds or the deadline passes
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal(message)
+}
+
+func TestLeaderElection(t *testing.T) {
+	network := newMemoryNetwork()
+	nodes := startCluster(t, network)
+	defer stopCluster(t, network, nodes)
+
+	leader := waitLeader(t, nodes...)
+	term := leader.raft.Status().Term
+	for _, node := range followers(nodes, leader) {
+		status := node.raft.Status()
+		if status.Role != RoleFollower {
+			t.Errorf("%s is %s, want %s", node.name, status.Role, RoleFollower)
+		}
+		if status.Term != term {
+			t.Errorf("%s is in term %d, the leader in term %d", node.name, status.Term, term)
+		}
+	}
+
+	// the others elect a new leader once the leader is cut off
+	network.isolate(leader.name, true)
+	others := followers(nodes, leader)
+	next := waitLeader(t, others...)
+	if next.raft.Status().Term <= term {
+		t.Errorf("new leader %s was elected in term %d, not after term %d", next.name, next.raft.Status().Term, term)
+	}
+	network.isolate(leader.name, false)
+	waitLeader(t, nodes...)
+}
+
+func TestWriteCommittedOnMajority(t *testing.T) {
+	network := newMemoryNetwork()
+	nodes := startCluster(t, network)
+	defer stopCluster(t, network, nodes)
+
+	leader := waitLeader(t, nodes...)
+	others := followers(nodes, leader)
+	lagging, follower := others[0], others[1]
+	network.isolate(lagging.name, true)
+
+	ctx := context.Background()
+	if _, err := leader.cache().Map.Set(ctx, "key", "value"); err != nil {
+		t.Fatalf("setting the key on the leader and one follower: %v", err)
+	}
+	index := leader.raft.Status().CommitIndex
+	if value, ok, err := follower.cache().Map.Get(ctx, "key"); err != nil || !ok || value != "value" {
+		t.Errorf("follower %s read %q, %v, %v, want %q", follower.name, value, ok, err, "value")
+	}
+	if applied := lagging.raft.Status().AppliedIndex; applied >= index {
+		t.Errorf("isolated %s applied index %d, the write was committed at %d", lagging.name, applied, index)
+	}
+
+	network.isolate(lagging.name, false)
+	eventually(t, "the isolated follower did not catch up", func() bool {
+		value, ok, err := lagging.cache().Map.Get(ctx, "key")
+		return err == nil && ok && value == "value"
+	})
+}
+
+func TestMinorityRejectsWrites(t *testing.T) {
+	network := newMemoryNetwork()
+	nodes := startCluster(t, network)
+	defer stopCluster(t, network, nodes)
+
+	leader := waitLeader(t, nodes...)
+	network.isolate(leader.name, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := leader.cache().Map.Set(ctx, "minority", "lost"); err == nil {
+		t.Errorf("the isolated leader accepted a write")
+	}
+	if deleted, err := leader.cache().Map.Delete(ctx, "minority"); err == nil || deleted {
+		t.Errorf("the isolated leader deleted a key: %v, %v", deleted, err)
+	}
+	if _, _, err := leader.cache().Map.Get(ctx, "minority"); err == nil {
+		t.Errorf("the isolated leader served a read it could not make linearizable")
+	}
+
+	others := followers(nodes, leader)
+	next := waitLeader(t, others...)
+	if _, err := next.cache().Map.Set(context.Background(), "majority", "kept"); err != nil {
+		t.Fatalf("setting the key on the majority: %v", err)
+	}
+
+	// the entry appended by the old leader is replaced by the log of the new one
+	network.isolate(leader.name, false)
+	eventually(t, "the old leader did not catch up", func() bool {
+		value, ok, err := leader.cache().Map.Get(context.Background(), "majority")
+		return err == nil && ok && value == "kept"
+	})
+	for _, node := range nodes {
+		if _, ok, _ := node.cache().Map.Get(context.Background(), "minority"); ok {
+			t.Errorf("%s holds the write rejected by the minority", node.name)
+		}
+	}
+}
+
+func TestRestartReplaysLog(t *testing.T) {
+	directories := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	network := newMemoryNetwork()
+	nodes := startCluster(t, network, directories...)
+
+	leader := waitLeader(t, nodes...)
+	ctx := context.Background()
+	writes := []struct {
+		key, value string
+		ttl        int
+	}{
+		{"kept", "value", 0},
+		{"updated", "old", 0},
+		{"deleted", "value", 0},
+		{"expiring", "value", 1},
+	}
+	for _, write := range writes {
+		if _, err := leader.cache().Map.SetCacheTimetoLive(ctx, write.key, write.value, write.ttl); err != nil {
+			t.Fatalf("setting %s: %v", write.key, err)
+		}
+	}
+	if found, err := leader.cache().Map.UpdateCacheEntry(ctx, "updated", "new"); err != nil || !found {
+		t.Fatalf("updating: %v, %v", found, err)
+	}
+	if deleted, err := leader.cache().Map.Delete(ctx, "deleted"); err != nil || !deleted {
+		t.Fatalf("deleting: %v, %v", deleted, err)
+	}
+	stopCluster(t, network, nodes)
+
+	// the entry expires while the nodes are down
+	time.Sleep(1100 * time.Millisecond)
+	nodes = startCluster(t, network, directories...)
+	defer stopCluster(t, network, nodes)
+	waitLeader(t, nodes...)
+
+	want := map[string]string{"kept": "value", "updated": "new"}
+	for _, node := range nodes {
+		for key, value := range want {
+			if got, ok, err := node.cache().Map.Get(ctx, key); err != nil || !ok || got != value {
+				t.Errorf("%s replayed %s as %q, %v, %v, want %q", node.name, key, got, ok, err, value)
+			}
+		}
+		for _, key := range []string{"deleted", "expiring"} {
+			if _, ok, _ := node.cache().Map.Get(ctx, key); ok {
+				t.Errorf("%s replayed %s", node.name, key)
+			}
+			// the expired entry is not written again, only to be swept
+			if version := node.raft.state.version(namespaceservice.DefaultNamespace, key); version != 0 {
+				t.Errorf("%s holds %s at version %d", node.name, key, version)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zelta-7/cache/common"
+)
+
+// Routes of the HTTP API of a node serving the raft messages
+const (
+	VotePath      = "/raft/vote"
+	AppendPath    = "/raft/append"
+	SnapshotPath  = "/raft/snapshot"
+	ReadIndexPath = "/raft/read-index"
+	ExecutePath   = "/raft/execute"
+)
+
+// VoteRequest asks for the vote of a node for Candidate in Term
+type VoteRequest struct {
+	Term         uint64 `json:"term"`
+	Candidate    string `json:"candidate"`
+	LastLogIndex uint64 `json:"last-log-index"`
+	LastLogTerm  uint64 `json:"last-log-term"`
+}
+
+// VoteResponse answers a VoteRequest
+type VoteResponse struct {
+	Term    uint64 `json:"term"`
+	Granted bool   `json:"granted"`
+}
+
+// AppendRequest replicates the entries of the leader following the entry
+// PrevLogIndex, without entries it is a heartbeat
+type AppendRequest struct {
+	Term         uint64     `json:"term"`
+	Leader       string     `json:"leader"`
+	PrevLogIndex uint64     `json:"prev-log-index"`
+	PrevLogTerm  uint64     `json:"prev-log-term"`
+	Entries      []LogEntry `json:"entries,omitempty"`
+	LeaderCommit uint64     `json:"leader-commit"`
+}
+
+// AppendResponse answers an AppendRequest, on a mismatch ConflictIndex is
+// the index the leader should retry from
+type AppendResponse struct {
+	Term          uint64 `json:"term"`
+	Success       bool   `json:"success"`
+	MatchIndex    uint64 `json:"match-index,omitempty"`
+	ConflictIndex uint64 `json:"conflict-index,omitempty"`
+}
+
+// SnapshotRequest replaces the log of a node lagging behind the compacted
+// log of the leader by its snapshot
+type SnapshotRequest struct {
+	Term     uint64   `json:"term"`
+	Leader   string   `json:"leader"`
+	Snapshot Snapshot `json:"snapshot"`
+}
+
+// SnapshotResponse answers a SnapshotRequest
+type SnapshotResponse struct {
+	Term uint64 `json:"term"`
+}
+
+// ReadIndexResponse is the commit index a node must have applied before
+// serving a linearizable read
+type ReadIndexResponse struct {
+	Index uint64 `json:"index"`
+}
+
+// Transport sends the raft messages to the other nodes, the nodes of an
+// in-process cluster can call each other's methods directly
+type Transport interface {
+	RequestVote(ctx context.Context, node string, request VoteRequest) (VoteResponse, error)
+	AppendEntries(ctx context.Context, node string, request AppendRequest) (AppendResponse, error)
+	InstallSnapshot(ctx context.Context, node string, request SnapshotRequest) (SnapshotResponse, error)
+	ReadIndex(ctx context.Context, node string) (uint64, error)
+	Execute(ctx context.Context, node string, operation Operation) (Result, error)
+}
+
+type httpTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport returns a transport sending the messages as JSON to the
+// routes served by the HTTP API of the nodes
+func NewHTTPTransport() Transport {
+	return &httpTransport{client: &http.Client{}}
+}
+
+// RequestVote implements the RequestVote method of the Transport interface
+func (t *httpTransport) RequestVote(ctx context.Context, node string, request VoteRequest) (VoteResponse, error) {
+	var response VoteResponse
+	err := t.call(ctx, node, VotePath, request, &response)
+	return response, err
+}
+
+// AppendEntries implements the AppendEntries method of the Transport interface
+func (t *httpTransport) AppendEntries(ctx context.Context, node string, request AppendRequest) (AppendResponse, error) {
+	var response AppendResponse
+	err := t.call(ctx, node, AppendPath, request, &response)
+	return response, err
+}
+
+// InstallSnapshot implements the InstallSnapshot method of the Transport interface
+func (t *httpTransport) InstallSnapshot(ctx context.Context, node string, request SnapshotRequest) (SnapshotResponse, error) {
+	var response SnapshotResponse
+	err := t.call(ctx, node, SnapshotPath, request, &response)
+	return response, err
+}
+
+// ReadIndex implements the ReadIndex method of the Transport interface
+func (t *httpTransport) ReadIndex(ctx context.Context, node string) (uint64, error) {
+	var response ReadIndexResponse
+	err := t.call(ctx, node, ReadIndexPath, struct{}{}, &response)
+	return response.Index, err
+}
+
+// Execute implements the Execute method of the Transport interface
+func (t *httpTransport) Execute(ctx context.Context, node string, operation Operation) (Result, error) {
+	var result Result
+	err := t.call(ctx, node, ExecutePath, operation, &result)
+	return result, err
+}
+
+// call posts request to the route of node and decodes its response, the
+// status StatusNotLeader is returned as ErrNotLeader
+func (t *httpTransport) call(ctx context.Context, node, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, node+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if id := common.RequestID(ctx); id != "" {
+		httpRequest.Header.Set(common.RequestIDHeader, id)
+	}
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	switch {
+	case httpResponse.StatusCode == StatusNotLeader:
+		return ErrNotLeader
+	case httpResponse.StatusCode != http.StatusOK:
+		message, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", node, httpResponse.Status, message)
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// minRetryDelay and maxRetryDelay bound the delay before reconnecting to the leader
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+	// idleTimeout drops a connection on which the leader sent nothing, not
+	// even a keep-alive, for that long
+	idleTimeout = 45 * time.Second
+	// statusInterval is how often the sequence number of the leader is polled to compute the lag
+	statusInterval = time.Second
+)
+
+// errResumeRejected is returned when the leader no longer has the changes following the resume token
+var errResumeRejected = errors.New("resume token rejected by the leader")
+
+// streamedChange is a line of the change stream of the leader
+type streamedChange struct {
+	Token string `json:"token"`
+	changeservice.Change
+}
+
+// Run implements the Run method of the ReplicationServiceInterface
+func (r *replicationService) Run(ctx context.Context) {
+	if r.leader == "" {
+		return
+	}
+	go r.pollLeader(ctx)
+
+	delay := minRetryDelay
+	for {
+		streamed, err := r.follow(ctx)
+		r.lock.Lock()
+		r.status.Connected = false
+		if err != nil {
+			r.status.LastError = err.Error()
+		}
+		r.lock.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errResumeRejected) {
+			klog.InfoS("Resuming the change stream failed, loading a new snapshot", "leader", r.leader)
+			continue
+		}
+		if streamed {
+			delay = minRetryDelay
+		}
+		klog.ErrorS(err, "Replication interrupted", "leader", r.leader, "retryIn", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// follow loads a snapshot if no change was applied yet and then applies the
+// changes streamed by the leader until the connection breaks, it reports
+// whether the stream could be opened
+func (r *replicationService) follow(ctx context.Context) (bool, error) {
+	if r.token == "" {
+		if err := r.loadSnapshot(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	body, err := r.get(ctx, "/changes?token="+url.QueryEscape(r.token))
+	var responseErr *responseError
+	if errors.As(err, &responseErr) && (responseErr.status == http.StatusGone || responseErr.status == http.StatusBadRequest) {
+		// the changes following the token were dropped or the leader restarted
+		r.token = ""
+		return false, fmt.Errorf("%w: %s", errResumeRejected, responseErr.message)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	r.lock.Lock()
+	r.status.Connected = true
+	r.status.LastError = ""
+	r.lock.Unlock()
+	klog.InfoS("Streaming changes from the leader", "leader", r.leader, "sequence", r.status.AppliedSequence)
+
+	decoder := json.NewDecoder(body)
+	for {
+		var line streamedChange
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("change stream closed by the leader")
+			}
+			return true, err
+		}
+		if line.Sequence > r.applied[line.Namespace] {
+			r.apply(ctx, line.Change)
+		}
+		r.lock.Lock()
+		r.token = line.Token
+		r.status.AppliedSequence = line.Sequence
+		if r.status.AppliedSequence >= r.status.LeaderSequence {
+			r.caughtUp = time.Now()
+		}
+		r.lock.Unlock()
+	}
+}
+
+// loadSnapshot replaces the content of every namespace with a snapshot of the leader
+func (r *replicationService) loadSnapshot(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	body, err := r.get(ctx, "/replication/snapshot")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	var header SnapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return fmt.Errorf("reading snapshot header: %w", err)
+	}
+
+	for _, namespace := range r.namespaces.List() {
+		r.namespaces.Delete(ctx, namespace.Name)
+	}
+	count := 0
+	for {
+		var item Item
+		if err := decoder.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// the token is not set so the next attempt starts over with a new snapshot
+			return fmt.Errorf("reading snapshot: %w", err)
+		}
+		namespace := r.namespaces.Get(item.Namespace)
+		switch item.Source {
+		case changeservice.SourceMap:
+			entry := mapRepository.CacheEntry{Key: item.Key, Value: item.Value, ExpiresAt: deref(item.ExpiresAt), Flags: item.Flags}
+			if _, err := namespace.Map.Store(ctx, entry, mapRepository.Always); err != nil {
+				return fmt.Errorf("loading snapshot entry %q: %w", item.Key, err)
+			}
+		case changeservice.SourceQueue:
+			entry := queueRepository.CacheEntry{Key: item.Key, Value: item.Value, ExpiresAt: deref(item.ExpiresAt)}
+			namespace.Queue.Push(ctx, entry, false)
+		}
+		count++
+	}
+
+	r.lock.Lock()
+	r.token = header.Token
+	r.applied = header.Namespaces
+	r.status.AppliedSequence = header.Sequence
+	r.lock.Unlock()
+	klog.InfoS("Loaded snapshot from the leader", "leader", r.leader, "sequence", header.Sequence, "entries", count)
+	return nil
+}
+
+// apply replays a change of the leader, entries expire on their own since
+// their expiration is replicated
+func (r *replicationService) apply(ctx context.Context, change changeservice.Change) {
+	namespace := r.namespaces.Get(change.Namespace)
+	value := func() string {
+		if change.NewValue == nil {
+			return ""
+		}
+		return *change.NewValue
+	}
+
+	switch change.Source {
+	case changeservice.SourceMap:
+		switch change.Op {
+		case changeservice.OpSet:
+			entry := mapRepository.CacheEntry{Key: change.Key, Value: value(), ExpiresAt: deref(change.ExpiresAt), Flags: change.Flags}
+			namespace.Map.Store(ctx, entry, mapRepository.Always)
+		case changeservice.OpUpdate:
+			namespace.Map.UpdateCacheEntry(ctx, change.Key, value())
+		case changeservice.OpDelete, changeservice.OpExpire:
+			namespace.Map.Delete(ctx, change.Key)
+		case changeservice.OpTimeToLive:
+			if entry, ok, _ := namespace.Map.GetEntry(ctx, change.Key); ok {
+				entry.TTL, entry.ExpiresAt = 0, deref(change.ExpiresAt)
+				namespace.Map.Store(ctx, entry, mapRepository.IfPresent)
+			}
+		case changeservice.OpFlush:
+			namespace.Map.Flush(ctx)
+		}
+
+	case changeservice.SourceQueue:
+		switch change.Op {
+		case changeservice.OpPush, changeservice.OpPrepend:
+			entry := queueRepository.CacheEntry{Key: change.Key, Value: value(), ExpiresAt: deref(change.ExpiresAt)}
+			namespace.Queue.Push(ctx, entry, change.Op == changeservice.OpPrepend)
+		case changeservice.OpPop:
+			// the first entry of the follower may be one the leader already
+			// expired, the entry popped by the leader is removed instead
+			if change.OldValue != nil {
+				namespace.Queue.Remove(ctx, change.Key, *change.OldValue)
+			}
+		case changeservice.OpUpdate:
+			namespace.Queue.UpdateValue(ctx, change.Key, value())
+		case changeservice.OpDelete:
+			namespace.Queue.Delete(ctx, change.Key)
+		case changeservice.OpFlush:
+			namespace.Queue.Flush(ctx)
+		}
+	}
+}
+
+// deref returns the time t points to, zero if t is nil
+func deref(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// pollLeader keeps the sequence number of the leader up to date until ctx is done
+func (r *replicationService) pollLeader(ctx context.Context) {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx, statusInterval)
+		var status Status
+		body, err := r.get(requestCtx, "/admin/replication")
+		if err == nil {
+			err = json.NewDecoder(body).Decode(&status)
+			body.Close()
+		}
+		cancel()
+		if err != nil {
+			klog.V(2).InfoS("Error polling the leader status", "leader", r.leader, "err", err)
+			continue
+		}
+
+		r.lock.Lock()
+		r.status.LeaderSequence = status.Sequence
+		if r.status.AppliedSequence >= r.status.LeaderSequence {
+			r.caughtUp = time.Now()
+		}
+		r.lock.Unlock()
+	}
+}
+
+// get sends a GET request to the leader and returns the body of a 200
+// response, the connection is dropped when nothing is received for idleTimeout
+func (r *replicationService) get(ctx context.Context, path string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, r.leader+path, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		cancel()
+		return nil, &responseError{status: response.StatusCode, message: string(message)}
+	}
+	return &idleReader{ReadCloser: response.Body, timer: time.AfterFunc(idleTimeout, cancel), cancel: cancel}, nil
+}
+
+// responseError is returned for a response of the leader other than a 200
+type responseError struct {
+	status  int
+	message string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("leader returned %d %s: %s", e.status, http.StatusText(e.status), e.message)
+}
+
+// idleReader cancels the request it reads the body of when no data arrives for idleTimeout
+type idleReader struct {
+	io.ReadCloser
+	timer  *time.Timer
+	cancel context.CancelFunc
+}
+
+func (i *idleReader) Read(p []byte) (int, error) {
+	n, err := i.ReadCloser.Read(p)
+	if n > 0 {
+		i.timer.Reset(idleTimeout)
+	}
+	return n, err
+}
+
+func (i *idleReader) Close() error {
+	i.timer.Stop()
+	i.cancel()
+	return i.ReadCloser.Close()
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// Roles of a node
+const (
+	RoleLeader   = "leader"
+	RoleFollower = "follower"
+)
+
+// Item is a map or queue entry of a snapshot, ExpiresAt is nil if the entry
+// never expires and Flags are the client flags of a map entry
+type Item struct {
+	Namespace string     `json:"namespace"`
+	Source    string     `json:"source"`
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+	Flags     uint32     `json:"flags,omitempty"`
+}
+
+// Snapshot is the content of every namespace, the change stream resumes
+// from it with Token after the change Sequence. The namespaces are copied
+// one after the other while their changes keep being recorded, the changes
+// of a namespace up to its sequence number in Namespaces are already part
+// of the snapshot.
+type Snapshot struct {
+	Sequence   uint64
+	Token      string
+	Namespaces map[string]uint64
+	Items      []Item
+}
+
+// SnapshotHeader is the first line of a snapshot sent to a follower, the items follow
+type SnapshotHeader struct {
+	Token      string            `json:"token"`
+	Sequence   uint64            `json:"sequence"`
+	Namespaces map[string]uint64 `json:"namespaces,omitempty"`
+}
+
+// Status describes the replication state of a node, the follower fields are
+// only set on followers
+type Status struct {
+	Role string `json:"role"`
+	// Sequence is the sequence number of the last change recorded by this node
+	Sequence uint64 `json:"sequence"`
+	Leader   string `json:"leader,omitempty"`
+	// Connected reports whether the follower is streaming the changes of the leader
+	Connected bool `json:"connected"`
+	// AppliedSequence is the sequence number on the leader of the last applied change
+	AppliedSequence uint64 `json:"applied-sequence"`
+	// LeaderSequence is the sequence number of the last change recorded by the leader
+	LeaderSequence uint64 `json:"leader-sequence"`
+	LagChanges     uint64 `json:"lag-changes"`
+	// LagSeconds is how long the follower has been behind the leader
+	LagSeconds float64 `json:"lag-seconds"`
+	LastError  string  `json:"last-error,omitempty"`
+}
+
+type ReplicationServiceInterface interface {
+	// Snapshot returns the entries of every namespace and the position of
+	// the change stream they correspond to
+	Snapshot(ctx context.Context) Snapshot
+
+	// Status returns the replication state of the node
+	Status() Status
+
+	// Run replicates the leader until ctx is done, it returns immediately on a leader
+	Run(ctx context.Context)
+}
+
+type replicationService struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	changes    changeservice.ChangeServiceInterface
+	// leader is the base URL of the HTTP API of the leader, empty on a leader
+	leader string
+	// token resumes the change stream of the leader, empty until a snapshot was loaded
+	token string
+	// applied holds per namespace the sequence number up to which the
+	// changes of the leader are part of the loaded snapshot
+	applied map[string]uint64
+	status  Status
+	// caughtUp is the last time the follower had applied every change of the leader
+	caughtUp time.Time
+	lock     sync.Mutex
+}
+
+// NewReplicationService returns the replication service of a leader if
+// leader is empty and otherwise of a follower of the node whose HTTP API is
+// at the URL leader
+func NewReplicationService(namespaces namespaceservice.NamespaceServiceInterface, changes changeservice.ChangeServiceInterface, leader string) ReplicationServiceInterface {
+	role := RoleLeader
+	if leader != "" {
+		role = RoleFollower
+	}
+	return &replicationService{
+		namespaces: namespaces,
+		changes:    changes,
+		leader:     strings.TrimRight(leader, "/"),
+		status:     Status{Role: role, Leader: leader},
+		caughtUp:   time.Now(),
+		lock:       sync.Mutex{},
+	}
+}
+
+// Snapshot implements the Snapshot method of the ReplicationServiceInterface
+func (r *replicationService) Snapshot(ctx context.Context) Snapshot {
+	// the changes recorded from here on are streamed to the follower, those
+	// already copied with their namespace are skipped by it
+	snapshot := Snapshot{Sequence: r.changes.Sequence(), Namespaces: make(map[string]uint64)}
+	now := time.Now()
+	for _, namespace := range r.namespaces.List() {
+		namespace := namespace
+		r.changes.Freeze(namespace.Name, func(sequence uint64) {
+			snapshot.Namespaces[namespace.Name] = sequence
+			entries, err := namespace.Map.All(ctx)
+			if err != nil {
+				klog.ErrorS(err, "Error reading the entries of the snapshot", "namespace", namespace.Name)
+			}
+			for _, entry := range entries {
+				if expiresAt, ok := expiration(entry.ExpiresAt, now); ok {
+					snapshot.Items = append(snapshot.Items, Item{Namespace: namespace.Name, Source: changeservice.SourceMap, Key: entry.Key, Value: entry.Value, ExpiresAt: expiresAt, Flags: entry.Flags})
+				}
+			}
+			for _, entry := range namespace.Queue.All(ctx) {
+				if expiresAt, ok := expiration(entry.ExpiresAt, now); ok {
+					snapshot.Items = append(snapshot.Items, Item{Namespace: namespace.Name, Source: changeservice.SourceQueue, Key: entry.Key, Value: entry.Value, ExpiresAt: expiresAt})
+				}
+			}
+		})
+	}
+	snapshot.Token = r.changes.Token(snapshot.Sequence)
+	return snapshot
+}
+
+// Status implements the Status method of the ReplicationServiceInterface
+func (r *replicationService) Status() Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	status := r.status
+	status.Sequence = r.changes.Sequence()
+	if status.Role == RoleFollower && status.LeaderSequence > status.AppliedSequence {
+		status.LagChanges = status.LeaderSequence - status.AppliedSequence
+		status.LagSeconds = time.Since(r.caughtUp).Seconds()
+	}
+	return status
+}
+
+// expiration returns the expiration of an item for an entry expiring at
+// expiresAt, nil if it never expires, and false if it already expired
+func expiration(expiresAt, now time.Time) (*time.Time, bool) {
+	if expiresAt.IsZero() {
+		return nil, true
+	}
+	if !expiresAt.After(now) {
+		return nil, false
+	}
+	return &expiresAt, true
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// observer times the operations of the clients
+type observer struct {
+	log SlowLogServiceInterface
+}
+
+// NewObserver returns an observer passing the latency of the operations of the namespaces to log
+func NewObserver(log SlowLogServiceInterface) namespaceservice.Observer {
+	return &observer{log: log}
+}
+
+// Observe implements the Observe method of the Observer interface
+func (o *observer) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	key := operation.Key
+	if key == "" && len(operation.Keys) == 1 {
+		key = operation.Keys[0]
+	}
+	o.log.Observe(ctx, operation.Source, operation.Namespace, operation.Op, key, operation.Start)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	metricsservice "github.com/zelta-7/cache/pkg/service/metrics"
+)
+
+const (
+	// DefaultThreshold is the duration above which an operation is logged
+	DefaultThreshold = 10 * time.Millisecond
+	// DefaultMaxLen is the number of slow operations kept
+	DefaultMaxLen = 128
+)
+
+// Options configures the slow log
+type Options struct {
+	// Threshold is the duration from which an operation is logged, every
+	// operation is logged when it is zero and none when it is negative
+	Threshold time.Duration
+	// MaxLen is the number of slow operations kept, the oldest are dropped first
+	MaxLen int
+}
+
+// Entry is an operation that took longer than the threshold
+type Entry struct {
+	// ID grows with every logged operation, it is not reset with the log
+	ID        uint64
+	Timestamp time.Time
+	Duration  time.Duration
+	// Type is the data structure of the operation, map or queue
+	Type      string
+	Namespace string
+	Op        string
+	// Key is empty for the operations on the whole map or queue
+	Key string
+	// Client is the address of the client, empty for the internal operations
+	Client string
+}
+
+type SlowLogServiceInterface interface {
+	// Observe records the latency of an operation that started at start in
+	// the histogram of the operation and in the log if it was slow
+	Observe(ctx context.Context, dataType, namespace, op, key string, start time.Time)
+
+	// Entries returns the n most recent slow operations, the most recent
+	// first, every one of them if n is zero or less
+	Entries(n int) []Entry
+
+	// Len returns the number of slow operations in the log
+	Len() int
+
+	// Reset empties the log
+	Reset()
+
+	// Threshold returns the duration from which an operation is logged
+	Threshold() time.Duration
+}
+
+type slowLogService struct {
+	metrics   metricsservice.MetricsServiceInterface
+	threshold time.Duration
+
+	// entries is a ring of the last operations logged, next is the slot of the
+	// next one and length the number of slots used
+	entries []Entry
+	next    int
+	length  int
+	lastID  uint64
+	lock    sync.Mutex
+}
+
+// NewSlowLogService returns a slow log recording the latency of every
+// operation in the histograms of metrics, unless it is nil
+func NewSlowLogService(metrics metricsservice.MetricsServiceInterface, options Options) SlowLogServiceInterface {
+	if options.MaxLen <= 0 {
+		options.MaxLen = DefaultMaxLen
+	}
+	return &slowLogService{
+		metrics:   metrics,
+		threshold: options.Threshold,
+		entries:   make([]Entry, options.MaxLen),
+		lock:      sync.Mutex{},
+	}
+}
+
+// Observe implements the Observe method of the SlowLogServiceInterface
+func (s *slowLogService) Observe(ctx context.Context, dataType, namespace, op, key string, start time.Time) {
+	duration := time.Since(start)
+	if s.metrics != nil {
+		s.metrics.ObserveOperation(dataType, op, duration)
+	}
+	if s.threshold < 0 || duration < s.threshold {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.lastID++
+	s.entries[s.next] = Entry{
+		ID:        s.lastID,
+		Timestamp: start,
+		Duration:  duration,
+		Type:      dataType,
+		Namespace: namespace,
+		Op:        op,
+		Key:       key,
+		Client:    common.Client(ctx),
+	}
+	s.next = (s.next + 1) % len(s.entries)
+	if s.length < len(s.entries) {
+		s.length++
+	}
+}
+
+// Entries implements the Entries method of the SlowLogServiceInterface
+func (s *slowLogService) Entries(n int) []Entry {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if n <= 0 || n > s.length {
+		n = s.length
+	}
+	entries := make([]Entry, 0, n)
+	for i := 1; i <= n; i++ {
+		entries = append(entries, s.entries[(s.next-i+len(s.entries))%len(s.entries)])
+	}
+	return entries
+}
+
+// Len implements the Len method of the SlowLogServiceInterface
+func (s *slowLogService) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.length
+}
+
+// Reset implements the Reset method of the SlowLogServiceInterface
+func (s *slowLogService) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := range s.entries {
+		s.entries[i] = Entry{}
+	}
+	s.next = 0
+	s.length = 0
+}
+
+// Threshold implements the Threshold method of the SlowLogServiceInterface
+func (s *slowLogService) Threshold() time.Duration {
+	return s.threshold
+}
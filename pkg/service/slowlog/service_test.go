@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// ids returns the ids of the entries
+func ids(entries []Entry) string {
+	var ids []uint64
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestRingKeepsTheMostRecentOperations(t *testing.T) {
+	log := NewSlowLogService(nil, Options{MaxLen: 3})
+	ctx := common.WithClient(context.Background(), "127.0.0.1:1234")
+	for i := 0; i < 5; i++ {
+		log.Observe(ctx, "map", "", "get", fmt.Sprintf("key-%d", i), time.Now())
+	}
+
+	if log.Len() != 3 {
+		t.Fatalf("the log holds %d operations, want 3", log.Len())
+	}
+	entries := log.Entries(0)
+	if ids(entries) != "[5 4 3]" {
+		t.Fatalf("the log holds %s, want the last three the most recent first", ids(entries))
+	}
+	if entries[0].Key != "key-4" || entries[0].Client != "127.0.0.1:1234" || entries[0].Type != "map" || entries[0].Op != "get" {
+		t.Fatalf("the last operation was logged as %+v", entries[0])
+	}
+	if entries := log.Entries(2); ids(entries) != "[5 4]" {
+		t.Fatalf("the two last operations are %s, want [5 4]", ids(entries))
+	}
+	if entries := log.Entries(10); ids(entries) != "[5 4 3]" {
+		t.Fatalf("more operations than logged returned %s, want [5 4 3]", ids(entries))
+	}
+
+	// the ids keep growing after a reset
+	log.Reset()
+	if log.Len() != 0 || len(log.Entries(0)) != 0 {
+		t.Fatalf("the log holds %d operations after a reset", log.Len())
+	}
+	log.Observe(ctx, "queue", "", "pop", "", time.Now())
+	if entries := log.Entries(0); ids(entries) != "[6]" {
+		t.Fatalf("the log holds %s after a reset, want [6]", ids(entries))
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	ctx := context.Background()
+	slow := NewSlowLogService(nil, Options{Threshold: time.Second})
+	slow.Observe(ctx, "map", "", "get", "fast", time.Now())
+	slow.Observe(ctx, "map", "", "get", "slow", time.Now().Add(-2*time.Second))
+	if entries := slow.Entries(0); len(entries) != 1 || entries[0].Key != "slow" || entries[0].Duration < 2*time.Second {
+		t.Fatalf("the log holds %+v, want the slow operation only", entries)
+	}
+
+	none := NewSlowLogService(nil, Options{Threshold: -1})
+	none.Observe(ctx, "map", "", "get", "slow", time.Now().Add(-time.Hour))
+	if none.Len() != 0 {
+		t.Fatal("an operation was logged with a negative threshold")
+	}
+}
+
+func TestObserverLogsTheSingleKey(t *testing.T) {
+	log := NewSlowLogService(nil, Options{})
+	observer := NewObserver(log)
+	observer.Observe(context.Background(), namespaceservice.Operation{Source: "map", Namespace: "tenant", Op: "mget", Keys: []string{"a"}, Start: time.Now()})
+	observer.Observe(context.Background(), namespaceservice.Operation{Source: "map", Namespace: "tenant", Op: "mget", Keys: []string{"a", "b"}, Start: time.Now()})
+
+	entries := log.Entries(0)
+	if len(entries) != 2 || entries[1].Key != "a" || entries[0].Key != "" || entries[0].Namespace != "tenant" {
+		t.Fatalf("the log holds %+v, want the key of the single key operation only", entries)
+	}
+}
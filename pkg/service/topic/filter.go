@@ -0,0 +1,277 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter reports whether a message with the given key and value is accepted
+type Filter func(key, value string) bool
+
+// ParseFilter compiles a filter expression. An expression compares the key or
+// the value of a message with a literal and comparisons combine with &&, ||,
+// ! and parentheses:
+//
+//	key ^= "user:" && !(value == "" || value =~ "^tmp-")
+//	value >= 10 && value < 20.5
+//
+// String literals are double quoted with Go escapes. The operators are ==,
+// !=, ^= (prefix), $= (suffix), *= (contains) and =~ (regular expression) on
+// strings and ==, !=, <, <=, > and >= on numbers, a numeric comparison is
+// false when the compared field is not a number.
+func ParseFilter(expression string) (Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	filter, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", "==", "!=", "^=", "$=", "*=", "=~", "<=", ">=", "<", ">", "!", "(", ")"}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expression) && expression[end] != '"' {
+				if expression[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expression) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			text, err := strconv.Unquote(expression[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, offset: i})
+			i = end + 1
+		case unicode.IsLetter(c):
+			end := i
+			for end < len(expression) && unicode.IsLetter(rune(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[i:end], offset: i})
+			i = end
+		case unicode.IsDigit(c) || c == '-' || c == '+' || c == '.':
+			end := i + 1
+			for end < len(expression) && strings.ContainsRune("0123456789.eE+-", rune(expression[end])) {
+				end++
+			}
+			if _, err := strconv.ParseFloat(expression[i:end], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", expression[i:end], i)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:end], offset: i})
+			i = end
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(expression[i:], operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, offset: i})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// accept consumes the next token if it is the given operator
+func (p *parser) accept(operator string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator && p.tokens[p.pos].text == operator {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) or() (Filter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(key, value string) bool { return l(key, value) || right(key, value) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Filter, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(key, value string) bool { return l(key, value) && right(key, value) }
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Filter, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(key, value string) bool { return !operand(key, value) }, nil
+	}
+	if p.accept("(") {
+		filter, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return filter, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Filter, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind != tokenIdent || (field.text != "key" && field.text != "value") {
+		return nil, fmt.Errorf("expected key or value at offset %d, got %q", field.offset, field.text)
+	}
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if operator.kind != tokenOperator {
+		return nil, fmt.Errorf("expected an operator at offset %d, got %q", operator.offset, operator.text)
+	}
+	literal, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	var match func(string) bool
+	switch literal.kind {
+	case tokenString:
+		match, err = stringComparison(operator, literal.text)
+	case tokenNumber:
+		match, err = numberComparison(operator, literal.text)
+	default:
+		err = fmt.Errorf("expected a string or a number at offset %d, got %q", literal.offset, literal.text)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if field.text == "key" {
+		return func(key, value string) bool { return match(key) }, nil
+	}
+	return func(key, value string) bool { return match(value) }, nil
+}
+
+func stringComparison(operator token, literal string) (func(string) bool, error) {
+	switch operator.text {
+	case "==":
+		return func(s string) bool { return s == literal }, nil
+	case "!=":
+		return func(s string) bool { return s != literal }, nil
+	case "^=":
+		return func(s string) bool { return strings.HasPrefix(s, literal) }, nil
+	case "$=":
+		return func(s string) bool { return strings.HasSuffix(s, literal) }, nil
+	case "*=":
+		return func(s string) bool { return strings.Contains(s, literal) }, nil
+	case "=~":
+		re, err := regexp.Compile(literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at offset %d: %w", operator.offset, err)
+		}
+		return re.MatchString, nil
+	}
+	return nil, fmt.Errorf("operator %q at offset %d does not apply to strings", operator.text, operator.offset)
+}
+
+func numberComparison(operator token, literal string) (func(string) bool, error) {
+	n, _ := strconv.ParseFloat(literal, 64)
+	var compare func(float64) bool
+	switch operator.text {
+	case "==":
+		compare = func(f float64) bool { return f == n }
+	case "!=":
+		compare = func(f float64) bool { return f != n }
+	case "<":
+		compare = func(f float64) bool { return f < n }
+	case "<=":
+		compare = func(f float64) bool { return f <= n }
+	case ">":
+		compare = func(f float64) bool { return f > n }
+	case ">=":
+		compare = func(f float64) bool { return f >= n }
+	default:
+		return nil, fmt.Errorf("operator %q at offset %d does not apply to numbers", operator.text, operator.offset)
+	}
+	return func(s string) bool {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return err == nil && compare(f)
+	}, nil
+}
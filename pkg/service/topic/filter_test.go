@@ -0,0 +1,71 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterMatches(t *testing.T) {
+	tests := []struct {
+		expression string
+		key, value string
+		want       bool
+	}{
+		{`key == "a"`, "a", "", true},
+		{`key != "a"`, "a", "", false},
+		{`key ^= "user:"`, "user:1", "", true},
+		{`key $= ":1"`, "user:1", "", true},
+		{`value *= "ell"`, "", "hello", true},
+		{`value =~ "^tmp-[0-9]+$"`, "", "tmp-42", true},
+		{`value =~ "^tmp-[0-9]+$"`, "", "tmp-x", false},
+		{`value == "quote \" and \\"`, "", `quote " and \`, true},
+		{`value >= 10 && value < 20.5`, "", "20", true},
+		{`value >= 10 && value < 20.5`, "", "20.5", false},
+		{`value == -1.5e1`, "", " -15 ", true},
+		// a numeric comparison is false when the field is not a number
+		{`value != 3`, "", "three", false},
+		{`key ^= "user:" && !(value == "" || value =~ "^tmp-")`, "user:1", "real", true},
+		{`key ^= "user:" && !(value == "" || value =~ "^tmp-")`, "user:1", "tmp-1", false},
+		{`key ^= "user:" && !(value == "" || value =~ "^tmp-")`, "user:1", "", false},
+		// && binds tighter than ||
+		{`key == "a" || key == "b" && value == "x"`, "a", "y", true},
+		{`(key == "a" || key == "b") && value == "x"`, "a", "y", false},
+		{`!!key == "a"`, "a", "", true},
+	}
+	for _, test := range tests {
+		filter, err := ParseFilter(test.expression)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", test.expression, err)
+		}
+		if got := filter(test.key, test.value); got != test.want {
+			t.Errorf("%s on key %q and value %q is %v, want %v", test.expression, test.key, test.value, got, test.want)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{``, "unexpected end of expression"},
+		{`key == "a`, "unterminated string at offset 7"},
+		{`key == "\q"`, "invalid string at offset 7"},
+		{`key == 1.2.3`, `invalid number "1.2.3" at offset 7`},
+		{`key @ "a"`, "unexpected '@' at offset 4"},
+		{`name == "a"`, `expected key or value at offset 0, got "name"`},
+		{`key "a"`, `expected an operator at offset 4, got "a"`},
+		{`key == value`, `expected a string or a number at offset 7, got "value"`},
+		{`key < "a"`, `operator "<" at offset 4 does not apply to strings`},
+		{`value ^= 1`, `operator "^=" at offset 6 does not apply to numbers`},
+		{`value =~ "("`, "invalid regular expression at offset 6"},
+		{`(key == "a"`, "missing closing parenthesis"},
+		{`key == "a" key`, `unexpected "key" at offset 11`},
+		{`key == "a" &&`, "unexpected end of expression"},
+	}
+	for _, test := range tests {
+		if _, err := ParseFilter(test.expression); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("parsing %s returned %v, want %q", test.expression, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// namePattern matches the TopicName schema of the API spec
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// ErrSubscriptionExists is returned when a topic already has a subscription with the same name
+var ErrSubscriptionExists = errors.New("subscription already exists")
+
+// ValidateName returns an error if name can not be used as a topic name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid topic name %q, it must match %s", name, namePattern)
+	}
+	return nil
+}
+
+// Subscription copies the messages published to Topic that are accepted by
+// Filter into the queue of Namespace, an empty Filter accepts every message
+type Subscription struct {
+	Name      string
+	Topic     string
+	Namespace string
+	Filter    string
+}
+
+// Topic is a topic and its subscriptions sorted by name
+type Topic struct {
+	Name          string
+	Subscriptions []Subscription
+}
+
+type TopicServiceInterface interface {
+	// Subscribe adds a subscription to the topic, the name of the
+	// subscription defaults to its namespace. ErrSubscriptionExists is
+	// returned if the name is taken and an error describing the problem if
+	// the filter can not be parsed.
+	Subscribe(topic string, subscription Subscription) (Subscription, error)
+
+	// Unsubscribe removes a subscription and reports whether it existed
+	Unsubscribe(topic, name string) bool
+
+	// Subscriptions returns the subscriptions of the topic sorted by name
+	Subscriptions(topic string) []Subscription
+
+	// List returns the topics having at least one subscription sorted by name
+	List() []Topic
+
+	// Publish copies the message into the queue of every subscription whose
+	// filter accepts it and returns the number of queues it was copied to, a
+	// ttl of 0 never expires
+	Publish(ctx context.Context, topic, key, value string, ttl int) int
+}
+
+type subscription struct {
+	Subscription
+	filter Filter
+}
+
+type topicService struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	// topics maps the topic names to their subscriptions by name
+	topics map[string]map[string]*subscription
+	lock   sync.RWMutex
+}
+
+func NewTopicService(namespaces namespaceservice.NamespaceServiceInterface) TopicServiceInterface {
+	return &topicService{
+		namespaces: namespaces,
+		topics:     make(map[string]map[string]*subscription),
+		lock:       sync.RWMutex{},
+	}
+}
+
+// Subscribe implements the Subscribe method of the TopicServiceInterface
+func (t *topicService) Subscribe(topic string, s Subscription) (Subscription, error) {
+	if err := ValidateName(topic); err != nil {
+		return s, err
+	}
+	if s.Namespace == "" {
+		s.Namespace = namespaceservice.DefaultNamespace
+	}
+	if err := namespaceservice.ValidateName(s.Namespace); err != nil {
+		return s, err
+	}
+	if s.Name == "" {
+		s.Name = s.Namespace
+	}
+	s.Topic = topic
+	sub := &subscription{Subscription: s}
+	if s.Filter != "" {
+		filter, err := ParseFilter(s.Filter)
+		if err != nil {
+			return s, fmt.Errorf("invalid filter: %w", err)
+		}
+		sub.filter = filter
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	subscriptions, ok := t.topics[topic]
+	if !ok {
+		subscriptions = make(map[string]*subscription)
+		t.topics[topic] = subscriptions
+	}
+	if _, ok := subscriptions[s.Name]; ok {
+		return s, ErrSubscriptionExists
+	}
+	subscriptions[s.Name] = sub
+	return s, nil
+}
+
+// Unsubscribe implements the Unsubscribe method of the TopicServiceInterface
+func (t *topicService) Unsubscribe(topic, name string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	subscriptions := t.topics[topic]
+	if _, ok := subscriptions[name]; !ok {
+		return false
+	}
+	delete(subscriptions, name)
+	if len(subscriptions) == 0 {
+		delete(t.topics, topic)
+	}
+	return true
+}
+
+// Subscriptions implements the Subscriptions method of the TopicServiceInterface
+func (t *topicService) Subscriptions(topic string) []Subscription {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return sortedSubscriptions(t.topics[topic])
+}
+
+// List implements the List method of the TopicServiceInterface
+func (t *topicService) List() []Topic {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	topics := make([]Topic, 0, len(t.topics))
+	for name, subscriptions := range t.topics {
+		topics = append(topics, Topic{Name: name, Subscriptions: sortedSubscriptions(subscriptions)})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Name < topics[j].Name
+	})
+	return topics
+}
+
+// Publish implements the Publish method of the TopicServiceInterface
+func (t *topicService) Publish(ctx context.Context, topic, key, value string, ttl int) int {
+	t.lock.RLock()
+	var targets []*subscription
+	for _, sub := range t.topics[topic] {
+		if sub.filter == nil || sub.filter(key, value) {
+			targets = append(targets, sub)
+		}
+	}
+	t.lock.RUnlock()
+
+	for _, sub := range targets {
+		t.namespaces.Get(sub.Namespace).Queue.SetCacheTimetoLive(ctx, key, value, ttl)
+	}
+	return len(targets)
+}
+
+func sortedSubscriptions(subscriptions map[string]*subscription) []Subscription {
+	result := make([]Subscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		result = append(result, sub.Subscription)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
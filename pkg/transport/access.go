@@ -0,0 +1,149 @@
+package transport
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zelta-7/cache/common"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxRequestIDLength is the length from which the ID sent by a client
+	// is replaced by a generated one
+	maxRequestIDLength = 128
+	// maxLoggedKeys is the number of key hashes logged for a request
+	// naming several keys
+	maxLoggedKeys = 16
+)
+
+// NewRequestIDMiddleware returns a gin middleware giving every request an
+// ID, the one sent by the client in the X-Request-ID header is kept when it
+// is valid. The ID is sent back in the response, kept in the header of the
+// request when it is forwarded to another node and carried by its context.
+func NewRequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(common.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+			c.Request.Header.Set(common.RequestIDHeader, id)
+		}
+		c.Header(common.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(common.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// validRequestID reports whether id can be kept and logged as is, only the
+// printable ASCII characters other than the space and the quotes are allowed
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' || id[i] == '"' || id[i] == '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// AccessLogOptions configures the middleware returned by NewAccessLogMiddleware
+type AccessLogOptions struct {
+	// SampleRate is the fraction of the requests logged, between 0 and 1,
+	// the requests failing with a server error are always logged
+	SampleRate float64
+}
+
+// NewAccessLogMiddleware returns a gin middleware logging a sample of the
+// requests with their ID, route, status, latency and sizes. The keys are
+// logged as hashes and the values are never logged: the route is the one
+// of the spec rather than the path, the query string is left out and the
+// body is only read for the key of the entry it holds.
+func NewAccessLogMiddleware(options AccessLogOptions) gin.HandlerFunc {
+	rate := math.Min(math.Max(options.SampleRate, 0), 1)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		sampled := rate == 1 || mathrand.Float64() < rate
+		// the body is consumed by the handler, its key is read beforehand
+		var keys []string
+		if sampled {
+			keys = accessKeys(c)
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if !sampled && status < http.StatusInternalServerError {
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		hashes := make([]string, 0, len(keys))
+		for _, key := range keys {
+			hashes = append(hashes, keyHash(key))
+		}
+		requestBytes := c.Request.ContentLength
+		if requestBytes < 0 {
+			requestBytes = 0
+		}
+		klog.InfoS("HTTP request",
+			"requestID", common.RequestID(c.Request.Context()),
+			"client", c.ClientIP(),
+			"method", c.Request.Method,
+			"route", route,
+			"namespace", c.Query("namespace"),
+			"status", status,
+			"latency", time.Since(start),
+			"requestBytes", requestBytes,
+			"responseBytes", c.Writer.Size(),
+			"keyHashes", hashes,
+		)
+	}
+}
+
+// accessKeys returns the keys named by the path, the query or the body of
+// a push or a write of the request
+func accessKeys(c *gin.Context) []string {
+	if key := c.Param("key"); key != "" {
+		return []string{key}
+	}
+	if keys := c.QueryArray("key"); len(keys) > 0 {
+		if len(keys) > maxLoggedKeys {
+			keys = keys[:maxLoggedKeys]
+		}
+		return keys
+	}
+	if c.Request.Method != http.MethodPost || (c.FullPath() != "/cache" && c.FullPath() != "/queue") {
+		return nil
+	}
+	if key, ok := bodyKey(c); ok {
+		return []string{key}
+	}
+	return nil
+}
+
+// keyHash returns the first 16 hex digits of the SHA-256 of key, enough to
+// tell the keys apart in the logs without revealing them
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
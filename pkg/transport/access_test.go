@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zelta-7/cache/common"
+	"k8s.io/klog/v2"
+)
+
+// captureLogs returns the buffer receiving the logs until the end of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&logs)
+	t.Cleanup(func() {
+		klog.SetOutput(os.Stderr)
+		klog.LogToStderr(true)
+	})
+	return &logs
+}
+
+// accessRouter returns a router logging its requests, the handlers read the
+// body and reply with the status asked in the query
+func accessRouter(options AccessLogOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRequestIDMiddleware(), NewAccessLogMiddleware(options))
+	reply := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		status := http.StatusOK
+		if c.Query("fail") != "" {
+			status = http.StatusInternalServerError
+		}
+		c.String(status, "%s", body)
+	}
+	router.POST("/cache", reply)
+	router.GET("/cache/:key", reply)
+	router.GET("/cache", reply)
+	return router
+}
+
+// serve runs the request through router and returns the recorded response
+func serve(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func TestAccessLogHidesTheKeysAndValues(t *testing.T) {
+	logs := captureLogs(t)
+	router := accessRouter(AccessLogOptions{SampleRate: 1})
+
+	body := `{"key":"secret-key","value":"secret-value"}`
+	if response := serve(router, http.MethodPost, "/cache?namespace=tenant", body); response.Body.String() != body {
+		t.Fatalf("the handler read %q, want the whole body", response.Body.String())
+	}
+	serve(router, http.MethodGet, "/cache/path-key?namespace=tenant&token=secret-query", "")
+	serve(router, http.MethodGet, "/cache?key=query-key&key=other-key", "")
+	klog.Flush()
+
+	output := logs.String()
+	for _, secret := range []string{"secret-key", "secret-value", "path-key", "secret-query", "query-key", "other-key"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("the access log reveals %s:\n%s", secret, output)
+		}
+	}
+	for _, want := range []string{
+		`route="/cache" namespace="tenant" status=200`,
+		`route="/cache/:key"`,
+		`keyHashes=["` + keyHash("secret-key") + `"]`,
+		`keyHashes=["` + keyHash("path-key") + `"]`,
+		`keyHashes=["` + keyHash("query-key") + `","` + keyHash("other-key") + `"]`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("the access log does not hold %s:\n%s", want, output)
+		}
+	}
+}
+
+func TestAccessLogSamplesTheRequests(t *testing.T) {
+	logs := captureLogs(t)
+
+	// a rate above 1 logs every request
+	router := accessRouter(AccessLogOptions{SampleRate: 2})
+	for i := 0; i < 10; i++ {
+		serve(router, http.MethodGet, "/cache/a", "")
+	}
+	klog.Flush()
+	if count := strings.Count(logs.String(), "HTTP request"); count != 10 {
+		t.Fatalf("%d requests were logged at a rate of 1, want 10", count)
+	}
+
+	// no request is sampled below 0 but the server errors are still logged
+	logs.Reset()
+	router = accessRouter(AccessLogOptions{SampleRate: -1})
+	serve(router, http.MethodGet, "/cache/a", "")
+	serve(router, http.MethodGet, "/cache/a?fail=1", "")
+	klog.Flush()
+	if count := strings.Count(logs.String(), "HTTP request"); count != 1 || !strings.Contains(logs.String(), "status=500") {
+		t.Fatalf("the unsampled requests were logged as:\n%s\nwant the server error only", logs.String())
+	}
+	// the key of an unsampled request is not read
+	if strings.Contains(logs.String(), "keyHashes=[\""+keyHash("a")) {
+		t.Fatalf("the key of an unsampled request was logged:\n%s", logs.String())
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	router := accessRouter(AccessLogOptions{})
+
+	response := serve(router, http.MethodGet, "/cache/a", "")
+	if id := response.Header().Get(common.RequestIDHeader); len(id) != 32 {
+		t.Fatalf("the generated request ID is %q", id)
+	}
+
+	for header, kept := range map[string]bool{
+		"client-id-1":                           true,
+		"with space":                            false,
+		`with"quote`:                            false,
+		strings.Repeat("x", maxRequestIDLength): true,
+		strings.Repeat("x", maxRequestIDLength+1): false,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/cache/a", nil)
+		request.Header.Set(common.RequestIDHeader, header)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if id := recorder.Header().Get(common.RequestIDHeader); (id == header) != kept || id == "" {
+			t.Fatalf("the request ID %q was replied as %q", header, id)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+const (
+	// changesKeepAlive is how often an empty line is sent on an idle change stream so that proxies keep it open
+	changesKeepAlive = 15 * time.Second
+	// changesBatch is the number of changes read from the log at once
+	changesBatch = 256
+)
+
+// changeLine is a line of the change stream, token resumes the stream after the change
+type changeLine struct {
+	Token string `json:"token"`
+	changeservice.Change
+}
+
+// RegisterChanges streams the change log as newline delimited JSON on
+// /changes. A client resumes after the last change it received with the token
+// parameter, without one the stream starts with the oldest retained change if
+// from is earliest and with the next change otherwise. The namespace and
+// source parameters filter the changes. The token of the starting position is
+// sent in the X-Resume-Token header.
+func RegisterChanges(router gin.IRouter, changes changeservice.ChangeServiceInterface) {
+	router.GET("/changes", func(c *gin.Context) {
+		namespace, source := c.Query("namespace"), c.Query("source")
+		if namespace != "" {
+			if err := namespaceservice.ValidateName(namespace); err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+				return
+			}
+		}
+		if source != "" && source != changeservice.SourceMap && source != changeservice.SourceQueue {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: "source must be map or queue"})
+			return
+		}
+
+		var after uint64
+		switch token, from := c.Query("token"), c.DefaultQuery("from", "latest"); {
+		case token != "":
+			sequence, err := changes.ParseToken(token)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+				return
+			}
+			after = sequence
+		case from == "earliest":
+			after = changes.Oldest() - 1
+		case from == "latest":
+			after = changes.Sequence()
+		default:
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: "from must be earliest or latest"})
+			return
+		}
+
+		batch, err := changes.Read(after, changesBatch)
+		if errors.Is(err, changeservice.ErrTruncated) {
+			c.JSON(http.StatusGone, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, apiSpec.Error{Error: err.Error()})
+			return
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Header("X-Resume-Token", changes.Token(after))
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		ctx := c.Request.Context()
+		encoder := json.NewEncoder(c.Writer)
+		for {
+			for _, change := range batch {
+				after = change.Sequence
+				if (namespace != "" && change.Namespace != namespace) || (source != "" && change.Source != source) {
+					continue
+				}
+				if err := encoder.Encode(changeLine{Token: changes.Token(change.Sequence), Change: change}); err != nil {
+					return
+				}
+			}
+			c.Writer.Flush()
+
+			if len(batch) == 0 {
+				waitCtx, cancel := context.WithTimeout(ctx, changesKeepAlive)
+				err := changes.Wait(waitCtx, after)
+				cancel()
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					if _, err := c.Writer.WriteString("\n"); err != nil {
+						return
+					}
+					c.Writer.Flush()
+					continue
+				}
+			}
+
+			batch, err = changes.Read(after, changesBatch)
+			if err != nil {
+				// the client fell behind the retained changes, reconnecting with its last token returns a 410
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package transport
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zelta-7/cache/common"
+)
+
+// NewClientMiddleware returns a gin middleware adding the address of the
+// client to the context of the request, the engine has to fall back on the
+// request context for the handlers to see it
+func NewClientMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(common.WithClient(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+// NewPrincipalMiddleware returns a gin middleware adding to the context of
+// the request the principal named by the header, which has to be set by an
+// authenticating proxy in front of the node and stripped from the requests
+// of the clients
+func NewPrincipalMiddleware(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal := c.GetHeader(header); principal != "" {
+			c.Request = c.Request.WithContext(common.WithPrincipal(c.Request.Context(), principal))
+		}
+		c.Next()
+	}
+}
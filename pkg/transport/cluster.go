@@ -0,0 +1,310 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	clusterservice "github.com/zelta-7/cache/pkg/service/cluster"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// forwardedHeader marks a request forwarded by another node, it is served
+// locally even if this node does not own the key so that nodes disagreeing
+// on the membership do not forward it in a loop
+const forwardedHeader = "X-Cache-Forwarded-By"
+
+// keyRoutes are the routes of the spec naming a single map key in their path
+var keyRoutes = map[string]bool{
+	"/cache/:key":          true,
+	"/cache/:key/ttl":      true,
+	"/cache/metadata/:key": true,
+}
+
+// gatherRoutes are the GET routes of the spec listing map entries, their
+// entries are gathered from every node
+var gatherRoutes = map[string]bool{
+	"/cache":                    true,
+	"/cache/list/:n":            true,
+	"/cache/sorted/:sort-by/:n": true,
+	"/cache/entries":            true,
+	"/cache/metadata":           true,
+}
+
+// ClusterOptions configures the middleware returned by NewClusterMiddleware
+type ClusterOptions struct {
+	// Redirect answers the requests for keys owned by another node with a
+	// 307 to that node instead of forwarding them
+	Redirect bool
+}
+
+// NewClusterMiddleware returns a gin middleware sending the requests for a
+// single map key owned by another node to that node. The requests listing
+// map entries are sent to every node owning some of them and answered with
+// the merged entries.
+func NewClusterMiddleware(cluster clusterservice.ClusterServiceInterface, options ClusterOptions) gin.HandlerFunc {
+	var proxies sync.Map
+
+	return func(c *gin.Context) {
+		if c.GetHeader(forwardedHeader) != "" {
+			c.Next()
+			return
+		}
+		if c.Request.Method == http.MethodGet && gatherRoutes[c.FullPath()] {
+			gather(c, cluster)
+			return
+		}
+		key, ok := requestKey(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		owner := cluster.Owner(c.Query("namespace"), key)
+		if owner == "" || owner == cluster.Self() {
+			c.Next()
+			return
+		}
+
+		if options.Redirect {
+			c.Redirect(http.StatusTemporaryRedirect, owner+c.Request.URL.RequestURI())
+			c.Abort()
+			return
+		}
+		proxy, ok := proxies.Load(owner)
+		if !ok {
+			target, err := url.Parse(owner)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, apiSpec.Error{Error: err.Error()})
+				return
+			}
+			reverseProxy := httputil.NewSingleHostReverseProxy(target)
+			reverseProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+				klog.ErrorS(err, "Error forwarding request", "owner", owner, "path", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadGateway)
+				json.NewEncoder(w).Encode(apiSpec.Error{Error: "owner of the key is unreachable: " + err.Error()})
+			}
+			proxy, _ = proxies.LoadOrStore(owner, reverseProxy)
+		}
+		c.Request.Header.Set(forwardedHeader, cluster.Self())
+		proxy.(*httputil.ReverseProxy).ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}
+}
+
+// gathered is the body of the responses listing map entries, the entries
+// are kept as sent by the nodes and only their key and value are decoded to
+// merge them
+type gathered struct {
+	Entries []json.RawMessage `json:"entries"`
+}
+
+// gatheredEntry is the part of an entry used to order the merged entries
+type gatheredEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	raw   json.RawMessage
+}
+
+// gather answers a request listing map entries with the entries of every
+// node, the keys of /cache/entries are only asked to their owner
+func gather(c *gin.Context, cluster clusterservice.ClusterServiceInterface) {
+	queries := make(map[string]url.Values)
+	if c.FullPath() == "/cache/entries" {
+		for _, key := range c.QueryArray("key") {
+			owner := cluster.Owner(c.Query("namespace"), key)
+			if owner == "" {
+				owner = cluster.Self()
+			}
+			if _, ok := queries[owner]; !ok {
+				query := c.Request.URL.Query()
+				query.Del("key")
+				queries[owner] = query
+			}
+			queries[owner].Add("key", key)
+		}
+	} else {
+		for _, node := range cluster.Members().Nodes {
+			queries[node] = c.Request.URL.Query()
+		}
+	}
+	if _, ok := queries[cluster.Self()]; len(queries) == 0 || (len(queries) == 1 && ok) {
+		c.Next()
+		return
+	}
+	defer c.Abort()
+
+	type result struct {
+		node   string
+		status int
+		body   []byte
+		err    error
+	}
+	results := make(chan result, len(queries))
+	for node, query := range queries {
+		node, query := node, query
+		go func() {
+			status, body, err := get(c.Request.Context(), node+c.Request.URL.Path+"?"+query.Encode(), cluster.Self())
+			results <- result{node: node, status: status, body: body, err: err}
+		}()
+	}
+
+	var entries []gatheredEntry
+	for range queries {
+		r := <-results
+		if r.err != nil {
+			klog.ErrorS(r.err, "Error gathering entries", "node", r.node, "path", c.Request.URL.Path)
+			c.JSON(http.StatusBadGateway, apiSpec.Error{Error: "node " + r.node + " is unreachable: " + r.err.Error()})
+			return
+		}
+		if r.status != http.StatusOK {
+			c.Data(r.status, "application/json", r.body)
+			return
+		}
+		var response gathered
+		if err := json.Unmarshal(r.body, &response); err != nil {
+			c.JSON(http.StatusBadGateway, apiSpec.Error{Error: "node " + r.node + " sent an invalid response: " + err.Error()})
+			return
+		}
+		for _, raw := range response.Entries {
+			entry := gatheredEntry{raw: raw}
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				c.JSON(http.StatusBadGateway, apiSpec.Error{Error: "node " + r.node + " sent an invalid response: " + err.Error()})
+				return
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	entries = mergeEntries(c, entries)
+	response := gathered{Entries: make([]json.RawMessage, 0, len(entries))}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, entry.raw)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// mergeEntries orders the entries gathered from the nodes like a single
+// node orders them: the lists by key truncated to n, the sorted lists then
+// by value, the metadata by key and the values of keys in the requested order
+func mergeEntries(c *gin.Context, entries []gatheredEntry) []gatheredEntry {
+	byKey := func(i, j int) bool { return entries[i].Key < entries[j].Key }
+	switch c.FullPath() {
+	case "/cache/metadata":
+		sort.SliceStable(entries, byKey)
+	case "/cache/entries":
+		position := make(map[string]int)
+		for i, key := range c.QueryArray("key") {
+			if _, ok := position[key]; !ok {
+				position[key] = i
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return position[entries[i].Key] < position[entries[j].Key] })
+	case "/cache/list/:n", "/cache/sorted/:sort-by/:n":
+		sort.SliceStable(entries, byKey)
+		if n, err := strconv.Atoi(c.Param("n")); err == nil && n > 0 && n < len(entries) {
+			entries = entries[:n]
+		}
+		if c.Param("sort-by") == string(apiSpec.GetSortedMapEntriesParamsSortByValue) {
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].Value < entries[j].Value })
+		}
+	}
+	return entries
+}
+
+// get sends a GET forwarded by self to another node and returns the status and body of its response
+func get(ctx context.Context, target, self string) (int, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	request.Header.Set(forwardedHeader, self)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	return response.StatusCode, body, err
+}
+
+// requestKey returns the map key named by the request, the body of a POST
+// on /cache is read and put back
+func requestKey(c *gin.Context) (string, bool) {
+	if keyRoutes[c.FullPath()] {
+		return c.Param("key"), true
+	}
+	if c.FullPath() != "/cache" || c.Request.Method != http.MethodPost {
+		return "", false
+	}
+	return bodyKey(c)
+}
+
+// bodyKey returns the key of the entry in the JSON body of the request, the
+// body is read and put back
+func bodyKey(c *gin.Context) (string, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	var entry struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(body, &entry); err != nil || entry.Key == "" {
+		return "", false
+	}
+	return entry.Key, true
+}
+
+// RegisterCluster serves the routes used between the nodes, PUT
+// /cluster/members replaces the membership by a newer one and POST
+// /cluster/entries receives the entries migrated to this node
+func RegisterCluster(router gin.IRouter, cluster clusterservice.ClusterServiceInterface) {
+	router.PUT("/cluster/members", func(c *gin.Context) {
+		var membership clusterservice.Membership
+		if err := c.ShouldBindJSON(&membership); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		err := cluster.SetMembers(membership)
+		switch {
+		case errors.Is(err, clusterservice.ErrStaleMembership):
+			c.JSON(http.StatusConflict, apiSpec.Error{Error: err.Error()})
+		case err != nil:
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+		default:
+			c.Status(http.StatusNoContent)
+		}
+	})
+
+	router.POST("/cluster/entries", func(c *gin.Context) {
+		var entries []clusterservice.Entry
+		if err := c.ShouldBindJSON(&entries); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		for _, entry := range entries {
+			if err := namespaceservice.ValidateName(entry.Namespace); err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+				return
+			}
+		}
+		if err := cluster.Receive(c.Request.Context(), entries); err != nil {
+			c.JSON(http.StatusServiceUnavailable, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,264 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	clusterservice "github.com/zelta-7/cache/pkg/service/cluster"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// clusterNode is a node of a sharded cluster serving the HTTP API on a
+// local port. The nodes run in the test process, cmd/main_test.go runs them
+// as separate processes.
+type clusterNode struct {
+	url        string
+	namespaces namespaceservice.NamespaceServiceInterface
+	cluster    clusterservice.ClusterServiceInterface
+	server     *httptest.Server
+	cancel     context.CancelFunc
+}
+
+// startClusterNodes starts count nodes whose initial membership is the first members of them
+func startClusterNodes(t *testing.T, count, members int) []*clusterNode {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	nodes := make([]*clusterNode, count)
+	urls := make([]string, count)
+	for i := range nodes {
+		server := httptest.NewUnstartedServer(nil)
+		nodes[i] = &clusterNode{url: "http://" + server.Listener.Addr().String(), server: server}
+		urls[i] = nodes[i].url
+	}
+	for _, node := range nodes {
+		node.namespaces = namespaceservice.NewNamespaceService(namespaceservice.Options{})
+		cluster, err := clusterservice.NewClusterService(node.namespaces, node.url, urls[:members])
+		if err != nil {
+			t.Fatalf("creating the cluster service of %s: %v", node.url, err)
+		}
+		node.cluster = cluster
+		node.namespaces.SetRouter(cluster)
+
+		router := gin.New()
+		router.ContextWithFallback = true
+		router.Use(NewClusterMiddleware(cluster, ClusterOptions{}))
+		RegisterCluster(router, cluster)
+		handler := NewCacheHandler(node.namespaces, HandlerOptions{Cluster: cluster})
+		apiSpec.RegisterHandlers(router, apiSpec.NewStrictHandler(handler, nil))
+		node.server.Config.Handler = router
+		node.server.Start()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		node.cancel = cancel
+		go cluster.Run(ctx)
+	}
+	return nodes
+}
+
+func stopClusterNodes(nodes []*clusterNode) {
+	for _, node := range nodes {
+		node.cancel()
+		node.server.Close()
+	}
+}
+
+// owned returns the keys of the default namespace stored on the node and whether they are all owned by it
+func (n *clusterNode) owned() ([]string, bool) {
+	var keys []string
+	owned := true
+	entries, _ := n.namespaces.Get("").Map.All(context.Background())
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+		if n.cluster.Owner("", entry.Key) != n.url {
+			owned = false
+		}
+	}
+	return keys, owned
+}
+
+func TestRebalanceMovesEntriesToOwners(t *testing.T) {
+	nodes := startClusterNodes(t, 3, 1)
+	defer stopClusterNodes(nodes)
+
+	ctx := context.Background()
+	first := nodes[0].namespaces.Get("").Map
+	for i := 0; i < 300; i++ {
+		if _, err := first.Set(ctx, fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)); err != nil {
+			t.Fatalf("setting key-%d: %v", i, err)
+		}
+	}
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	if _, err := first.Store(ctx, mapRepository.CacheEntry{Key: "expiring", Value: "soon", Flags: 7, ExpiresAt: expiresAt}, mapRepository.Always); err != nil {
+		t.Fatalf("storing the expiring key: %v", err)
+	}
+
+	urls := []string{nodes[0].url, nodes[1].url, nodes[2].url}
+	if _, err := nodes[0].cluster.ChangeMembers(ctx, urls); err != nil {
+		t.Fatalf("adding the nodes: %v", err)
+	}
+	eventually(t, "the entries were not migrated to their owners", func() bool {
+		total := 0
+		for _, node := range nodes {
+			keys, owned := node.owned()
+			if !owned {
+				return false
+			}
+			total += len(keys)
+		}
+		return total == 301
+	})
+	for _, node := range nodes {
+		if keys, _ := node.owned(); len(keys) == 0 {
+			t.Errorf("%s owns no key after the rebalance", node.url)
+		}
+	}
+
+	owner := nodes[0].cluster.Owner("", "expiring")
+	for _, node := range nodes {
+		if node.url != owner {
+			continue
+		}
+		entry, ok, _ := node.namespaces.Get("").Map.GetEntry(ctx, "expiring")
+		if !ok || entry.Value != "soon" || entry.Flags != 7 || !entry.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("owner %s has %+v, %v, want the value, flags and expiration of the migrated entry", owner, entry, ok)
+		}
+	}
+}
+
+func TestRebalanceKeepsNewerWritesOfTheOwner(t *testing.T) {
+	nodes := startClusterNodes(t, 2, 1)
+	defer stopClusterNodes(nodes)
+
+	// find a key the second node owns once it joins
+	joined, err := clusterservice.NewClusterService(namespaceservice.NewNamespaceService(namespaceservice.Options{}), nodes[0].url, []string{nodes[0].url, nodes[1].url})
+	if err != nil {
+		t.Fatalf("creating the cluster service: %v", err)
+	}
+	key := ""
+	for i := 0; key == ""; i++ {
+		if candidate := fmt.Sprintf("key-%d", i); joined.Owner("", candidate) == nodes[1].url {
+			key = candidate
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := nodes[0].namespaces.Get("").Map.Set(ctx, key, "old"); err != nil {
+		t.Fatalf("setting %s on the first node: %v", key, err)
+	}
+	if _, err := nodes[1].namespaces.Get("").Map.Set(ctx, key, "new"); err != nil {
+		t.Fatalf("setting %s on the second node: %v", key, err)
+	}
+	if _, err := nodes[0].cluster.ChangeMembers(ctx, []string{nodes[0].url, nodes[1].url}); err != nil {
+		t.Fatalf("adding the second node: %v", err)
+	}
+	eventually(t, "the migrated key was not removed from the first node", func() bool {
+		_, ok, _ := nodes[0].namespaces.Get("").Map.Get(ctx, key)
+		return !ok
+	})
+	if value, _, _ := nodes[1].namespaces.Get("").Map.Get(ctx, key); value != "new" {
+		t.Errorf("owner has %q, want the value written on it %q", value, "new")
+	}
+}
+
+func TestListsAreGatheredFromEveryNode(t *testing.T) {
+	nodes := startClusterNodes(t, 3, 3)
+	defer stopClusterNodes(nodes)
+
+	var keys []string
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		keys = append(keys, key)
+		body := fmt.Sprintf(`{"key":%q,"value":"value-%02d"}`, key, 29-i)
+		response, err := http.Post(nodes[i%3].url+"/cache", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("setting %s: %v", key, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+			t.Fatalf("setting %s returned %s", key, response.Status)
+		}
+	}
+	for _, node := range nodes {
+		if _, owned := node.owned(); !owned {
+			t.Fatalf("%s stores keys it does not own, the writes were not forwarded", node.url)
+		}
+	}
+
+	entries := listKeys(t, nodes[0].url+"/cache")
+	sort.Strings(entries)
+	if fmt.Sprint(entries) != fmt.Sprint(keys) {
+		t.Errorf("GET /cache returned %v, want %v", entries, keys)
+	}
+
+	query := url.Values{"key": {"key-07", "missing", "key-03", "key-21"}}
+	if entries := listKeys(t, nodes[1].url+"/cache/entries?"+query.Encode()); fmt.Sprint(entries) != "[key-07 key-03 key-21]" {
+		t.Errorf("GET /cache/entries returned %v, want the found keys in the requested order", entries)
+	}
+
+	if entries := listKeys(t, nodes[2].url+"/cache/list/5"); fmt.Sprint(entries) != fmt.Sprint(keys[:5]) {
+		t.Errorf("GET /cache/list/5 returned %v, want %v", entries, keys[:5])
+	}
+	if entries := listKeys(t, nodes[2].url+"/cache/sorted/value/3"); fmt.Sprint(entries) != "[key-02 key-01 key-00]" {
+		t.Errorf("GET /cache/sorted/value/3 returned %v, want the first 3 keys sorted by value", entries)
+	}
+}
+
+func TestFrontendsRejectKeysOfOtherNodes(t *testing.T) {
+	nodes := startClusterNodes(t, 2, 2)
+	defer stopClusterNodes(nodes)
+
+	for i := 0; ; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if nodes[0].cluster.Owner("", key) != nodes[1].url {
+			continue
+		}
+		if err := nodes[1].namespaces.Route("", key); err != nil {
+			t.Errorf("the owner rejected %s: %v", key, err)
+		}
+		var moved *namespaceservice.MovedError
+		if err := nodes[0].namespaces.Route("", "other", key); !errors.As(err, &moved) || moved.Owner != nodes[1].url {
+			t.Errorf("routing %s on the other node returned %v, want it moved to %s", key, err, nodes[1].url)
+		}
+		return
+	}
+}
+
+// listKeys returns the keys of the entries listed by a GET
+func listKeys(t *testing.T, target string) []string {
+	t.Helper()
+
+	response, err := http.Get(target)
+	if err != nil {
+		t.Fatalf("GET %s: %v", target, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s returned %s", target, response.Status)
+	}
+	var list struct {
+		Entries []struct {
+			Key string `json:"key"`
+		} `json:"entries"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&list); err != nil {
+		t.Fatalf("decoding the response of GET %s: %v", target, err)
+	}
+	keys := make([]string, 0, len(list.Entries))
+	for _, entry := range list.Entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	crdtservice "github.com/zelta-7/cache/pkg/service/crdt"
+)
+
+// RegisterCRDT serves the state exchanged by the peers of the active-active mode
+func RegisterCRDT(router gin.IRouter, crdt crdtservice.CRDTServiceInterface) {
+	router.POST(crdtservice.MergePath, func(c *gin.Context) {
+		var request crdtservice.MergeRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		crdt.Merge(request)
+		c.Status(http.StatusOK)
+	})
+
+	router.POST(crdtservice.SyncPath, func(c *gin.Context) {
+		var request crdtservice.SyncRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, crdt.Sync(request))
+	})
+}
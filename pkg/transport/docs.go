@@ -0,0 +1,40 @@
+package transport
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/invopop/yaml"
+)
+
+// explorerPage is a self contained page, it loads nothing but /openapi.json
+// from the cache so it works without internet access
+//
+//go:embed explorer/index.html
+var explorerPage []byte
+
+// RegisterDocs serves the spec at /openapi.json and /openapi.yaml and the API explorer at /docs
+func RegisterDocs(router gin.IRouter, swagger *openapi3.T) error {
+	specJSON, err := json.Marshal(swagger)
+	if err != nil {
+		return err
+	}
+	specYAML, err := yaml.JSONToYAML(specJSON)
+	if err != nil {
+		return err
+	}
+
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", specJSON)
+	})
+	router.GET("/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", specYAML)
+	})
+	router.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", explorerPage)
+	})
+	return nil
+}
@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	eventservice "github.com/zelta-7/cache/pkg/service/events"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// eventsKeepAlive is how often a comment is sent on an idle event stream so that proxies keep it open
+const eventsKeepAlive = 15 * time.Second
+
+// RegisterEvents serves the keyspace notifications as Server-Sent Events on
+// /events. The namespace, source, key, prefix and type query parameters
+// filter the events, type may be repeated. A client resumes after the
+// sequence number given by the Last-Event-ID header or the after parameter.
+func RegisterEvents(router gin.IRouter, events eventservice.EventServiceInterface) {
+	router.GET("/events", func(c *gin.Context) {
+		filter, err := eventFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+
+		after := c.GetHeader("Last-Event-ID")
+		if after == "" {
+			after = c.Query("after")
+		}
+		var stream <-chan eventservice.Event
+		var cancel func()
+		if after == "" {
+			stream, cancel = events.Subscribe(filter)
+			// resuming from the ready event skips nothing published after the subscription started
+			after = strconv.FormatUint(events.Sequence(), 10)
+		} else {
+			sequence, err := strconv.ParseUint(after, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: "invalid sequence number " + after})
+				return
+			}
+			stream, cancel, err = events.SubscribeAfter(sequence, filter)
+			if errors.Is(err, eventservice.ErrHistoryTruncated) {
+				c.JSON(http.StatusGone, apiSpec.Error{Error: err.Error()})
+				return
+			}
+		}
+		defer cancel()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Render(-1, sse.Event{Event: "ready", Id: after, Data: "ready"})
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-stream:
+				if !ok {
+					// the client fell behind, it may reconnect with the last id it received
+					return
+				}
+				c.Render(-1, sse.Event{Event: event.Type, Id: strconv.FormatUint(event.Sequence, 10), Data: event})
+			}
+			c.Writer.Flush()
+		}
+	})
+}
+
+func eventFilter(c *gin.Context) (func(eventservice.Event) bool, error) {
+	namespace, source, key, prefix := c.Query("namespace"), c.Query("source"), c.Query("key"), c.Query("prefix")
+	types := c.QueryArray("type")
+
+	if namespace != "" {
+		if err := namespaceservice.ValidateName(namespace); err != nil {
+			return nil, err
+		}
+	}
+	if source != "" && source != eventservice.SourceMap && source != eventservice.SourceQueue {
+		return nil, errors.New("source must be map or queue")
+	}
+	if key != "" && prefix != "" {
+		return nil, errors.New("key and prefix can not be combined")
+	}
+
+	return func(event eventservice.Event) bool {
+		if namespace != "" && event.Namespace != namespace {
+			return false
+		}
+		if source != "" && event.Source != source {
+			return false
+		}
+		// a flush changes every key, it is sent to the key and prefix subscribers too
+		if event.Type != eventservice.EventFlush {
+			if key != "" && event.Key != key {
+				return false
+			}
+			if !strings.HasPrefix(event.Key, prefix) {
+				return false
+			}
+		}
+		if len(types) > 0 {
+			for _, t := range types {
+				if t == event.Type {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	}, nil
+}
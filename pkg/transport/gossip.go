@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	gossipservice "github.com/zelta-7/cache/pkg/service/gossip"
+)
+
+// RegisterGossip serves the messages exchanged by the members of the
+// gossip cluster, the probes and the member lists
+func RegisterGossip(router gin.IRouter, gossip gossipservice.GossipServiceInterface) {
+	router.POST(gossipservice.PingPath, func(c *gin.Context) {
+		var request gossipservice.PingRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		response, err := gossip.Ping(request)
+		if err != nil {
+			c.JSON(http.StatusNotFound, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	})
+
+	router.POST(gossipservice.PingIndirectPath, func(c *gin.Context) {
+		var request gossipservice.IndirectRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gossip.PingIndirect(c.Request.Context(), request))
+	})
+
+	router.POST(gossipservice.SyncPath, func(c *gin.Context) {
+		var request gossipservice.SyncRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gossip.Sync(request))
+	})
+}
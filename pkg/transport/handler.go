@@ -1,218 +1,912 @@
 package transport
 
 import (
-	"net/http"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
+	"time"
+
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	clusterservice "github.com/zelta-7/cache/pkg/service/cluster"
+	crdtservice "github.com/zelta-7/cache/pkg/service/crdt"
+	gossipservice "github.com/zelta-7/cache/pkg/service/gossip"
+	hotkeysservice "github.com/zelta-7/cache/pkg/service/hotkeys"
+	infoservice "github.com/zelta-7/cache/pkg/service/info"
 	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	metricsservice "github.com/zelta-7/cache/pkg/service/metrics"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
 	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+	raftservice "github.com/zelta-7/cache/pkg/service/raft"
+	replicationservice "github.com/zelta-7/cache/pkg/service/replication"
+	slowlogservice "github.com/zelta-7/cache/pkg/service/slowlog"
+	topicservice "github.com/zelta-7/cache/pkg/service/topic"
 	"k8s.io/klog/v2"
 )
 
+// CacheHandlerInterface is the set of operations described by api-spec.yaml,
+// every route of the spec has to be implemented for the handler to compile
 type CacheHandlerInterface interface {
-	// SetQueueValue sets a value in the queue
-	SetQueueValue(c *gin.Context)
+	apiSpec.StrictServerInterface
+}
 
-	// GetQueueValue gets a value from the queue
-	GetQueueValue(c *gin.Context)
+type cacheHandler struct {
+	namespaces  namespaceservice.NamespaceServiceInterface
+	topics      topicservice.TopicServiceInterface
+	replication replicationservice.ReplicationServiceInterface
+	// cluster is nil when the node is not part of a sharded cluster
+	cluster clusterservice.ClusterServiceInterface
+	// raft is nil when the maps are not replicated through raft
+	raft raftservice.RaftServiceInterface
+	// gossip is nil when the node does not take part in the gossip membership
+	gossip gossipservice.GossipServiceInterface
+	// crdt is nil unless the node runs in active-active mode
+	crdt    crdtservice.CRDTServiceInterface
+	info    infoservice.InfoServiceInterface
+	slowLog slowlogservice.SlowLogServiceInterface
+	// hotKeys is nil when the hot keys are not tracked
+	hotKeys hotkeysservice.HotKeysServiceInterface
+}
 
-	// GetAllQueueValues gets all the values from the queue
-	GetAllQueueValues(c *gin.Context)
+// HandlerOptions holds the services answering the routes of the cache
+// handler besides the namespaces
+type HandlerOptions struct {
+	Topics      topicservice.TopicServiceInterface
+	Replication replicationservice.ReplicationServiceInterface
+	// Cluster is nil when the node is not part of a sharded cluster
+	Cluster clusterservice.ClusterServiceInterface
+	// Raft is nil when the maps are not replicated through raft
+	Raft raftservice.RaftServiceInterface
+	// Gossip is nil when the node does not take part in the gossip membership
+	Gossip gossipservice.GossipServiceInterface
+	// CRDT is nil unless the node runs in active-active mode
+	CRDT    crdtservice.CRDTServiceInterface
+	Info    infoservice.InfoServiceInterface
+	SlowLog slowlogservice.SlowLogServiceInterface
+	// HotKeys is nil when the hot keys are not tracked
+	HotKeys hotkeysservice.HotKeysServiceInterface
+}
+
+func NewCacheHandler(namespaces namespaceservice.NamespaceServiceInterface, options HandlerOptions) CacheHandlerInterface {
+	return &cacheHandler{
+		namespaces:  namespaces,
+		topics:      options.Topics,
+		replication: options.Replication,
+		cluster:     options.Cluster,
+		raft:        options.Raft,
+		gossip:      options.Gossip,
+		crdt:        options.CRDT,
+		info:        options.Info,
+		slowLog:     options.SlowLog,
+		hotKeys:     options.HotKeys,
+	}
+}
+
+// GetHealth implements the GetHealth method of the CacheHandlerInterface
+func (handler *cacheHandler) GetHealth(ctx context.Context, request apiSpec.GetHealthRequestObject) (apiSpec.GetHealthResponseObject, error) {
+	return apiSpec.GetHealth200JSONResponse{Status: "ok"}, nil
+}
+
+// GetReplicationStatus implements the GetReplicationStatus method of the CacheHandlerInterface
+func (handler *cacheHandler) GetReplicationStatus(ctx context.Context, request apiSpec.GetReplicationStatusRequestObject) (apiSpec.GetReplicationStatusResponseObject, error) {
+	status := handler.replication.Status()
+
+	response := apiSpec.GetReplicationStatus200JSONResponse{Role: apiSpec.ReplicationStatusRole(status.Role), Sequence: status.Sequence}
+	if status.Role == replicationservice.RoleFollower {
+		response.Leader = &status.Leader
+		response.Connected = &status.Connected
+		response.AppliedSequence = &status.AppliedSequence
+		response.LeaderSequence = &status.LeaderSequence
+		response.LagChanges = &status.LagChanges
+		response.LagSeconds = &status.LagSeconds
+		if status.LastError != "" {
+			response.LastError = &status.LastError
+		}
+	}
+	return response, nil
+}
 
-	// GetQueueEntryList gets the first n entries from the queue
-	GetQueueEntryList(c *gin.Context)
+// GetCluster implements the GetCluster method of the CacheHandlerInterface
+func (handler *cacheHandler) GetCluster(ctx context.Context, request apiSpec.GetClusterRequestObject) (apiSpec.GetClusterResponseObject, error) {
+	if handler.cluster == nil {
+		return apiSpec.GetCluster501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "cluster mode is disabled"}}, nil
+	}
+	membership := handler.cluster.Members()
+	return apiSpec.GetCluster200JSONResponse{Self: handler.cluster.Self(), Version: membership.Version, Nodes: membership.Nodes}, nil
+}
 
-	// GetSortedQueueEntries gets the first n entries from the queue sorted by key or value
-	GetSortedQueueEntries(c *gin.Context)
+// ChangeCluster implements the ChangeCluster method of the CacheHandlerInterface
+func (handler *cacheHandler) ChangeCluster(ctx context.Context, request apiSpec.ChangeClusterRequestObject) (apiSpec.ChangeClusterResponseObject, error) {
+	if handler.cluster == nil {
+		return apiSpec.ChangeCluster501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "cluster mode is disabled"}}, nil
+	}
+	membership, err := handler.cluster.ChangeMembers(ctx, request.Body.Nodes)
+	switch {
+	case errors.Is(err, clusterservice.ErrNotDelivered):
+		klog.ErrorS(err, "Error sending the cluster membership", "version", membership.Version)
+		return apiSpec.ChangeCluster502JSONResponse{Error: err.Error()}, nil
+	case err != nil:
+		return apiSpec.ChangeCluster400JSONResponse{BadRequestJSONResponse: badRequest(err.Error())}, nil
+	}
+	return apiSpec.ChangeCluster200JSONResponse{Self: handler.cluster.Self(), Version: membership.Version, Nodes: membership.Nodes}, nil
+}
 
-	// UpdateQueueValue updates the value of a given key in the queue
-	UpdateQueueValue(c *gin.Context)
+// GetRaftStatus implements the GetRaftStatus method of the CacheHandlerInterface
+func (handler *cacheHandler) GetRaftStatus(ctx context.Context, request apiSpec.GetRaftStatusRequestObject) (apiSpec.GetRaftStatusResponseObject, error) {
+	if handler.raft == nil {
+		return apiSpec.GetRaftStatus501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "raft mode is disabled"}}, nil
+	}
+	status := handler.raft.Status()
+
+	response := apiSpec.GetRaftStatus200JSONResponse{
+		Id:            status.ID,
+		Role:          apiSpec.RaftStatusRole(status.Role),
+		Term:          status.Term,
+		Nodes:         status.Nodes,
+		LastIndex:     status.LastIndex,
+		CommitIndex:   status.CommitIndex,
+		AppliedIndex:  status.AppliedIndex,
+		SnapshotIndex: status.SnapshotIndex,
+	}
+	if status.Leader != "" {
+		response.Leader = &status.Leader
+	}
+	return response, nil
+}
 
-	// SetMapValue sets a value in the map
-	SetMapValue(c *gin.Context)
+// ListMembers implements the ListMembers method of the CacheHandlerInterface
+func (handler *cacheHandler) ListMembers(ctx context.Context, request apiSpec.ListMembersRequestObject) (apiSpec.ListMembersResponseObject, error) {
+	if handler.gossip == nil {
+		return apiSpec.ListMembers501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "gossip membership is disabled"}}, nil
+	}
+	members := handler.gossip.Members()
+
+	response := apiSpec.ListMembers200JSONResponse{Self: handler.gossip.Self(), Members: make([]apiSpec.Member, 0, len(members))}
+	for _, member := range members {
+		apiMember := apiSpec.Member{
+			Name:        member.Name,
+			State:       apiSpec.MemberState(member.State),
+			Incarnation: member.Incarnation,
+			Since:       member.Since,
+		}
+		if len(member.Metadata) > 0 {
+			metadata := member.Metadata
+			apiMember.Metadata = &metadata
+		}
+		response.Members = append(response.Members, apiMember)
+	}
+	return response, nil
+}
 
-	// GetMapValue gets a value from the map
-	GetMapValue(c *gin.Context)
+// GetCRDTStatus implements the GetCRDTStatus method of the CacheHandlerInterface
+func (handler *cacheHandler) GetCRDTStatus(ctx context.Context, request apiSpec.GetCRDTStatusRequestObject) (apiSpec.GetCRDTStatusResponseObject, error) {
+	if handler.crdt == nil {
+		return apiSpec.GetCRDTStatus501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "active-active mode is disabled"}}, nil
+	}
+	status := handler.crdt.Status()
+
+	response := apiSpec.GetCRDTStatus200JSONResponse{
+		Self:      status.Self,
+		Replica:   status.Replica,
+		Clock:     status.Clock.String(),
+		Registers: status.Registers,
+		Counters:  status.Counters,
+		Sets:      status.Sets,
+		Peers:     make([]apiSpec.CRDTPeer, 0, len(status.Peers)),
+	}
+	for _, peer := range status.Peers {
+		apiPeer := apiSpec.CRDTPeer{Name: peer.Name}
+		if !peer.LastSync.IsZero() {
+			lastSync := peer.LastSync
+			apiPeer.LastSync = &lastSync
+		}
+		if peer.Error != "" {
+			message := peer.Error
+			apiPeer.Error = &message
+		}
+		response.Peers = append(response.Peers, apiPeer)
+	}
+	return response, nil
+}
 
-	// GetAllMapValues gets all the values from the map
-	GetAllMapValues(c *gin.Context)
+// GetInfo implements the GetInfo method of the CacheHandlerInterface
+func (handler *cacheHandler) GetInfo(ctx context.Context, request apiSpec.GetInfoRequestObject) (apiSpec.GetInfoResponseObject, error) {
+	info := handler.info.Info()
+	now := time.Now()
+
+	response := apiSpec.GetInfo200JSONResponse{
+		StartedAt:     info.StartedAt,
+		UptimeSeconds: info.Uptime.Seconds(),
+		Config:        info.Config,
+		Memory: apiSpec.MemoryInfo{
+			EntriesBytes: info.EntriesBytes,
+			HeapBytes:    info.HeapBytes,
+			SysBytes:     info.SysBytes,
+		},
+		Totals: apiSpec.TotalsInfo{
+			MapEntries:   info.MapEntries,
+			QueueEntries: info.QueueEntries,
+			Hits:         info.Counts.Hits,
+			Misses:       info.Counts.Misses,
+			HitRatio:     hitRatio(info.Counts),
+			Expirations:  info.Counts.Expirations,
+		},
+		Namespaces: make([]apiSpec.NamespaceStats, 0, len(info.Namespaces)),
+		Persistence: apiSpec.PersistenceInfo{
+			ChangeSequence: info.ChangeSequence,
+			OldestChange:   info.ChangeOldest,
+		},
+		Replication: handler.replicationInfo(),
+	}
+	if response.Config == nil {
+		response.Config = map[string]string{}
+	}
+	for _, namespace := range info.Namespaces {
+		stats := apiSpec.NamespaceStats{
+			Name:         namespace.Name,
+			MapEntries:   namespace.MapEntries,
+			MapBytes:     namespace.MapBytes,
+			QueueEntries: namespace.QueueEntries,
+			QueueBytes:   namespace.QueueBytes,
+			Hits:         namespace.Counts.Hits,
+			Misses:       namespace.Counts.Misses,
+			HitRatio:     hitRatio(namespace.Counts),
+			Expirations:  namespace.Counts.Expirations,
+		}
+		if !namespace.OldestQueueItem.IsZero() {
+			age := now.Sub(namespace.OldestQueueItem).Seconds()
+			stats.OldestQueueItemAgeSeconds = &age
+		}
+		response.Namespaces = append(response.Namespaces, stats)
+	}
+	if info.SpillDirectory != "" {
+		response.Persistence.SpillDirectory = &info.SpillDirectory
+	}
+	if info.RaftDirectory != "" {
+		response.Persistence.RaftDirectory = &info.RaftDirectory
+	}
+	return response, nil
+}
 
-	// GetMapEntryList gets the first n entries from the map
-	GetMapEntryList(c *gin.Context)
+// hitRatio returns the hit ratio of counts, nil before the first read
+func hitRatio(counts infoservice.Counts) *float64 {
+	ratio, ok := counts.HitRatio()
+	if !ok {
+		return nil
+	}
+	return &ratio
+}
 
-	// GetSortedMapEntries gets the first n entries from the map sorted by key or value
-	GetSortedMapEntries(c *gin.Context)
+// replicationInfo describes how the data of this node is shared with other nodes
+func (handler *cacheHandler) replicationInfo() apiSpec.ReplicationInfo {
+	switch {
+	case handler.raft != nil:
+		status := handler.raft.Status()
+		nodes := len(status.Nodes)
+		info := apiSpec.ReplicationInfo{Mode: apiSpec.ReplicationInfoModeRaft, Nodes: &nodes}
+		if status.Leader != "" {
+			info.Leader = &status.Leader
+		}
+		return info
+	case handler.cluster != nil:
+		nodes := len(handler.cluster.Members().Nodes)
+		return apiSpec.ReplicationInfo{Mode: apiSpec.ReplicationInfoModeCluster, Nodes: &nodes}
+	case handler.crdt != nil:
+		status := handler.crdt.Status()
+		nodes := len(status.Peers) + 1
+		info := apiSpec.ReplicationInfo{Mode: apiSpec.ReplicationInfoModeActiveActive, Nodes: &nodes}
+		for _, peer := range status.Peers {
+			if peer.Error != "" {
+				message := peer.Name + ": " + peer.Error
+				info.LastError = &message
+			}
+		}
+		return info
+	}
 
-	// UpdateMapEntry updates the value of a given key in the map
-	UpdateMapEntry(c *gin.Context)
+	status := handler.replication.Status()
+	if status.Role != replicationservice.RoleFollower {
+		return apiSpec.ReplicationInfo{Mode: apiSpec.ReplicationInfoModeLeader}
+	}
+	info := apiSpec.ReplicationInfo{
+		Mode:       apiSpec.ReplicationInfoModeFollower,
+		Leader:     &status.Leader,
+		Connected:  &status.Connected,
+		LagSeconds: &status.LagSeconds,
+	}
+	if status.LastError != "" {
+		info.LastError = &status.LastError
+	}
+	return info
+}
 
-	// GetListofMapValues gets the values from a given list of key of the map
-	GetListofMapValues(c *gin.Context)
+// GetSlowLog implements the GetSlowLog method of the CacheHandlerInterface
+func (handler *cacheHandler) GetSlowLog(ctx context.Context, request apiSpec.GetSlowLogRequestObject) (apiSpec.GetSlowLogResponseObject, error) {
+	entries := handler.slowLog.Entries(intValue(request.Params.Count))
 
-	// TODO: Implement SetCacheTimetoLive for queue and map
+	response := apiSpec.GetSlowLog200JSONResponse{
+		ThresholdSeconds: handler.slowLog.Threshold().Seconds(),
+		Length:           handler.slowLog.Len(),
+		Entries:          make([]apiSpec.SlowLogEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		apiEntry := apiSpec.SlowLogEntry{
+			Id:              entry.ID,
+			Timestamp:       entry.Timestamp,
+			DurationSeconds: entry.Duration.Seconds(),
+			Type:            apiSpec.SlowLogEntryType(entry.Type),
+			Namespace:       entry.Namespace,
+			Op:              entry.Op,
+		}
+		if entry.Key != "" {
+			key := entry.Key
+			apiEntry.Key = &key
+		}
+		if entry.Client != "" {
+			client := entry.Client
+			apiEntry.Client = &client
+		}
+		response.Entries = append(response.Entries, apiEntry)
+	}
+	return response, nil
 }
 
-type SetRequest struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+// ResetSlowLog implements the ResetSlowLog method of the CacheHandlerInterface
+func (handler *cacheHandler) ResetSlowLog(ctx context.Context, request apiSpec.ResetSlowLogRequestObject) (apiSpec.ResetSlowLogResponseObject, error) {
+	handler.slowLog.Reset()
+	return apiSpec.ResetSlowLog204Response{}, nil
 }
 
-type cacheHandler struct {
-	cacheMapService   mapservice.MapServiceInterface
-	cacheQueueService queueservice.QueueServiceInterface
+// GetHotKeys implements the GetHotKeys method of the CacheHandlerInterface
+func (handler *cacheHandler) GetHotKeys(ctx context.Context, request apiSpec.GetHotKeysRequestObject) (apiSpec.GetHotKeysResponseObject, error) {
+	if handler.hotKeys == nil {
+		return apiSpec.GetHotKeys501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "hot key tracking is disabled"}}, nil
+	}
+	keys := handler.hotKeys.Top(intValue(request.Params.Count))
+
+	response := apiSpec.GetHotKeys200JSONResponse{
+		Capacity: handler.hotKeys.Capacity(),
+		Keys:     make([]apiSpec.HotKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, apiSpec.HotKey{
+			Type:      apiSpec.HotKeyType(key.Type),
+			Namespace: key.Namespace,
+			Key:       key.Key,
+			KeyHash:   metricsservice.KeyHash(key.Key),
+			Count:     key.Count,
+		})
+	}
+	return response, nil
 }
 
-func NewCacheHandler(mapservice mapservice.MapServiceInterface, queueservice queueservice.QueueServiceInterface) CacheHandlerInterface {
-	return &cacheHandler{
-		cacheMapService:   mapservice,
-		cacheQueueService: queueservice,
+// ListNamespaces implements the ListNamespaces method of the CacheHandlerInterface
+func (handler *cacheHandler) ListNamespaces(ctx context.Context, request apiSpec.ListNamespacesRequestObject) (apiSpec.ListNamespacesResponseObject, error) {
+	namespaces := handler.namespaces.List()
+
+	response := apiSpec.ListNamespaces200JSONResponse{Namespaces: make([]apiSpec.NamespaceInfo, 0, len(namespaces))}
+	for _, namespace := range namespaces {
+		response.Namespaces = append(response.Namespaces, apiSpec.NamespaceInfo{
+			Name:         namespace.Name,
+			MapEntries:   namespace.Map.Len(),
+			QueueEntries: namespace.Queue.Len(),
+		})
 	}
+	return response, nil
 }
 
-// SetQueueValue implements the SetQueueValue method of the CacheHandlerInterface
-func (handler *cacheHandler) SetQueueValue(c *gin.Context) {
-	var setValue SetRequest
-	err := c.BindJSON(&setValue)
+// DeleteNamespace implements the DeleteNamespace method of the CacheHandlerInterface
+func (handler *cacheHandler) DeleteNamespace(ctx context.Context, request apiSpec.DeleteNamespaceRequestObject) (apiSpec.DeleteNamespaceResponseObject, error) {
+	if !handler.namespaces.Delete(ctx, request.Namespace) {
+		return apiSpec.DeleteNamespace404JSONResponse{NotFoundJSONResponse: notFound("namespace not found")}, nil
+	}
+	klog.InfoS("Namespace deleted", "namespace", request.Namespace)
+	return apiSpec.DeleteNamespace204Response{}, nil
+}
+
+// SetMapValue implements the SetMapValue method of the CacheHandlerInterface
+func (handler *cacheHandler) SetMapValue(ctx context.Context, request apiSpec.SetMapValueRequestObject) (apiSpec.SetMapValueResponseObject, error) {
+	if request.Body.Key == "" {
+		return apiSpec.SetMapValue400JSONResponse{BadRequestJSONResponse: badRequest("key is required")}, nil
+	}
+	mapService := handler.mapService(request.Params.Namespace)
+
+	// Store reports the writes that could not be committed in raft mode
+	entry := mapRepository.CacheEntry{Key: request.Body.Key, Value: request.Body.Value, TTL: time.Duration(intValue(request.Body.TimeToLive)) * time.Second}
+	if _, err := mapService.Store(ctx, entry, mapRepository.Always); err != nil {
+		return apiSpec.SetMapValue503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.SetMapValue200JSONResponse{Status: "value set sucessfully", Key: request.Body.Key}, nil
+}
+
+// GetMapValue implements the GetMapValue method of the CacheHandlerInterface
+func (handler *cacheHandler) GetMapValue(ctx context.Context, request apiSpec.GetMapValueRequestObject) (apiSpec.GetMapValueResponseObject, error) {
+	value, ok, err := handler.mapService(request.Params.Namespace).Get(ctx, request.Key)
+	if err != nil {
+		return apiSpec.GetMapValue503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	if !ok {
+		return apiSpec.GetMapValue404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.GetMapValue200JSONResponse{Key: request.Key, Value: value}, nil
+}
+
+// GetAllMapValues implements the GetAllMapValues method of the CacheHandlerInterface
+func (handler *cacheHandler) GetAllMapValues(ctx context.Context, request apiSpec.GetAllMapValuesRequestObject) (apiSpec.GetAllMapValuesResponseObject, error) {
+	entries, err := handler.mapService(request.Params.Namespace).All(ctx)
+	if err != nil {
+		return apiSpec.GetAllMapValues503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.GetAllMapValues200JSONResponse(mapEntryList(entries)), nil
+}
+
+// GetMapEntryList implements the GetMapEntryList method of the CacheHandlerInterface
+func (handler *cacheHandler) GetMapEntryList(ctx context.Context, request apiSpec.GetMapEntryListRequestObject) (apiSpec.GetMapEntryListResponseObject, error) {
+	if request.N < 1 {
+		return apiSpec.GetMapEntryList400JSONResponse{BadRequestJSONResponse: badRequest("n must be at least 1")}, nil
+	}
+	entries, err := handler.mapService(request.Params.Namespace).GetEntryList(ctx, request.N)
+	if err != nil {
+		return apiSpec.GetMapEntryList503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.GetMapEntryList200JSONResponse(mapEntryList(entries)), nil
+}
+
+// GetSortedMapEntries implements the GetSortedMapEntries method of the CacheHandlerInterface
+func (handler *cacheHandler) GetSortedMapEntries(ctx context.Context, request apiSpec.GetSortedMapEntriesRequestObject) (apiSpec.GetSortedMapEntriesResponseObject, error) {
+	if request.N < 1 {
+		return apiSpec.GetSortedMapEntries400JSONResponse{BadRequestJSONResponse: badRequest("n must be at least 1")}, nil
+	}
+	selector := mapservice.SortByValue
+	if request.SortBy == apiSpec.GetSortedMapEntriesParamsSortByKey {
+		selector = mapservice.SortByKey
+	}
+	entries, err := handler.mapService(request.Params.Namespace).GetSortedEntryList(ctx, selector, request.N)
+	if err != nil {
+		return apiSpec.GetSortedMapEntries503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.GetSortedMapEntries200JSONResponse(mapEntryList(entries)), nil
+}
+
+// UpdateMapEntry implements the UpdateMapEntry method of the CacheHandlerInterface
+func (handler *cacheHandler) UpdateMapEntry(ctx context.Context, request apiSpec.UpdateMapEntryRequestObject) (apiSpec.UpdateMapEntryResponseObject, error) {
+	mapService := handler.mapService(request.Params.Namespace)
+
+	var err error
+	if request.Body.TimeToLive == nil {
+		// the flags and the expiration are kept
+		_, err = mapService.Modify(ctx, request.Key, func(string) (string, error) { return request.Body.NewVal, nil })
+	} else {
+		err = handler.replaceMapEntry(ctx, mapService, request.Key, request.Body.NewVal, *request.Body.TimeToLive)
+	}
+	switch {
+	case errors.Is(err, mapRepository.ErrNotFound):
+		return apiSpec.UpdateMapEntry404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	case err != nil:
+		return apiSpec.UpdateMapEntry503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.UpdateMapEntry200JSONResponse{Status: "value updated sucessfully", Key: request.Key}, nil
+}
+
+// replaceMapEntry writes the value and the time to live of an existing key
+// in a single write keeping its flags, the write is retried if the key
+// changed since it was read
+func (handler *cacheHandler) replaceMapEntry(ctx context.Context, mapService mapservice.MapServiceInterface, key, value string, ttl int) error {
+	for {
+		entry, ok, err := mapService.GetEntry(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return mapRepository.ErrNotFound
+		}
+		entry.Value, entry.TTL, entry.ExpiresAt = value, time.Duration(ttl)*time.Second, time.Time{}
+		_, err = mapService.Store(ctx, entry, mapRepository.IfVersion)
+		if !errors.Is(err, mapRepository.ErrVersionMismatch) {
+			return err
+		}
+	}
+}
+
+// DeleteMapEntry implements the DeleteMapEntry method of the CacheHandlerInterface
+func (handler *cacheHandler) DeleteMapEntry(ctx context.Context, request apiSpec.DeleteMapEntryRequestObject) (apiSpec.DeleteMapEntryResponseObject, error) {
+	deleted, err := handler.mapService(request.Params.Namespace).Delete(ctx, request.Key)
+	if err != nil {
+		return apiSpec.DeleteMapEntry503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	if !deleted {
+		return apiSpec.DeleteMapEntry404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.DeleteMapEntry204Response{}, nil
+}
+
+// GetMapTimeToLive implements the GetMapTimeToLive method of the CacheHandlerInterface
+func (handler *cacheHandler) GetMapTimeToLive(ctx context.Context, request apiSpec.GetMapTimeToLiveRequestObject) (apiSpec.GetMapTimeToLiveResponseObject, error) {
+	ttl, ok, err := handler.mapService(request.Params.Namespace).TimeToLive(ctx, request.Key)
+	if err != nil {
+		return apiSpec.GetMapTimeToLive503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	if !ok {
+		return apiSpec.GetMapTimeToLive404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.GetMapTimeToLive200JSONResponse{Key: request.Key, TimeToLive: ttlSeconds(ttl)}, nil
+}
+
+// SetMapTimeToLive implements the SetMapTimeToLive method of the CacheHandlerInterface
+func (handler *cacheHandler) SetMapTimeToLive(ctx context.Context, request apiSpec.SetMapTimeToLiveRequestObject) (apiSpec.SetMapTimeToLiveResponseObject, error) {
+	if request.Body.TimeToLive < 0 {
+		return apiSpec.SetMapTimeToLive400JSONResponse{BadRequestJSONResponse: badRequest("time-to-live must not be negative")}, nil
+	}
+	mapService := handler.mapService(request.Params.Namespace)
+
+	found, err := mapService.Expire(ctx, request.Key, request.Body.TimeToLive)
+	if err != nil {
+		return apiSpec.SetMapTimeToLive503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	if !found {
+		return apiSpec.SetMapTimeToLive404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	ttl, _, err := mapService.TimeToLive(ctx, request.Key)
+	if err != nil {
+		return apiSpec.SetMapTimeToLive503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.SetMapTimeToLive200JSONResponse{Key: request.Key, TimeToLive: ttlSeconds(ttl)}, nil
+}
+
+// GetListofMapValues implements the GetListofMapValues method of the CacheHandlerInterface
+func (handler *cacheHandler) GetListofMapValues(ctx context.Context, request apiSpec.GetListofMapValuesRequestObject) (apiSpec.GetListofMapValuesResponseObject, error) {
+	if len(request.Params.Key) == 0 {
+		return apiSpec.GetListofMapValues400JSONResponse{BadRequestJSONResponse: badRequest("at least one key is required")}, nil
+	}
+	entries, err := handler.mapService(request.Params.Namespace).GetListofValues(ctx, request.Params.Key)
+	if err != nil {
+		return apiSpec.GetListofMapValues503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	return apiSpec.GetListofMapValues200JSONResponse(mapEntryList(entries)), nil
+}
+
+// GetAllMapMetadata implements the GetAllMapMetadata method of the CacheHandlerInterface
+func (handler *cacheHandler) GetAllMapMetadata(ctx context.Context, request apiSpec.GetAllMapMetadataRequestObject) (apiSpec.GetAllMapMetadataResponseObject, error) {
+	metadata, err := handler.mapService(request.Params.Namespace).AllMetadata(ctx)
 	if err != nil {
-		klog.ErrorS(err, "Error binding json")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return apiSpec.GetAllMapMetadata503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
 	}
-	key := handler.cacheQueueService.Set(setValue.Key, setValue.Value)
+	return apiSpec.GetAllMapMetadata200JSONResponse(mapMetadataList(metadata)), nil
+}
 
-	c.JSON(http.StatusOK, gin.H{"status": "value set sucessfully", "key": key})
+// GetMapMetadata implements the GetMapMetadata method of the CacheHandlerInterface
+func (handler *cacheHandler) GetMapMetadata(ctx context.Context, request apiSpec.GetMapMetadataRequestObject) (apiSpec.GetMapMetadataResponseObject, error) {
+	metadata, ok, err := handler.mapService(request.Params.Namespace).Metadata(ctx, request.Key)
+	if err != nil {
+		return apiSpec.GetMapMetadata503JSONResponse{UnavailableJSONResponse: unavailable(err)}, nil
+	}
+	if !ok {
+		return apiSpec.GetMapMetadata404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.GetMapMetadata200JSONResponse(apiMetadata(metadata)), nil
+}
+
+// SetQueueValue implements the SetQueueValue method of the CacheHandlerInterface
+func (handler *cacheHandler) SetQueueValue(ctx context.Context, request apiSpec.SetQueueValueRequestObject) (apiSpec.SetQueueValueResponseObject, error) {
+	if request.Body.Key == "" {
+		return apiSpec.SetQueueValue400JSONResponse{BadRequestJSONResponse: badRequest("key is required")}, nil
+	}
+	queueService := handler.queueService(request.Params.Namespace)
+
+	key := queueService.SetCacheTimetoLive(ctx, request.Body.Key, request.Body.Value, intValue(request.Body.TimeToLive))
+	return apiSpec.SetQueueValue200JSONResponse{Status: "value set sucessfully", Key: key}, nil
 }
 
 // GetQueueValue implements the GetQueueValue method of the CacheHandlerInterface
-func (handler *cacheHandler) GetQueueValue(c *gin.Context) {
-	key, value := handler.cacheQueueService.Get()
-	klog.Info("Key: ", key, " Value: ", value)
+func (handler *cacheHandler) GetQueueValue(ctx context.Context, request apiSpec.GetQueueValueRequestObject) (apiSpec.GetQueueValueResponseObject, error) {
+	entry, ok := handler.queueService(request.Params.Namespace).Get(ctx)
+	if !ok {
+		return apiSpec.GetQueueValue404JSONResponse{NotFoundJSONResponse: notFound("queue is empty")}, nil
+	}
+	return apiSpec.GetQueueValue200JSONResponse{Key: entry.Key, Value: entry.Value}, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{"key": key, "value": value})
+// PopQueueValue implements the PopQueueValue method of the CacheHandlerInterface
+func (handler *cacheHandler) PopQueueValue(ctx context.Context, request apiSpec.PopQueueValueRequestObject) (apiSpec.PopQueueValueResponseObject, error) {
+	entry, ok := handler.queueService(request.Params.Namespace).Pop(ctx)
+	if !ok {
+		return apiSpec.PopQueueValue404JSONResponse{NotFoundJSONResponse: notFound("queue is empty")}, nil
+	}
+	return apiSpec.PopQueueValue200JSONResponse{Key: entry.Key, Value: entry.Value}, nil
 }
 
 // GetAllQueueValues implements the GetAllQueueValues method of the CacheHandlerInterface
-func (handler *cacheHandler) GetAllQueueValues(c *gin.Context) {
-	values := handler.cacheQueueService.All()
-	klog.Info("Values: ", values)
-	c.JSON(http.StatusOK, gin.H{"values": values})
+func (handler *cacheHandler) GetAllQueueValues(ctx context.Context, request apiSpec.GetAllQueueValuesRequestObject) (apiSpec.GetAllQueueValuesResponseObject, error) {
+	entries := handler.queueService(request.Params.Namespace).All(ctx)
+	return apiSpec.GetAllQueueValues200JSONResponse(queueEntryList(entries)), nil
 }
 
 // GetQueueEntryList implements the GetQueueEntryList method of the CacheHandlerInterface
-func (handler *cacheHandler) GetQueueEntryList(c *gin.Context) {
-	n, err := strconv.Atoi(c.Param("n"))
-	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (handler *cacheHandler) GetQueueEntryList(ctx context.Context, request apiSpec.GetQueueEntryListRequestObject) (apiSpec.GetQueueEntryListResponseObject, error) {
+	if request.N < 1 {
+		return apiSpec.GetQueueEntryList400JSONResponse{BadRequestJSONResponse: badRequest("n must be at least 1")}, nil
 	}
-	list := handler.cacheQueueService.GetEntryList(n)
-	klog.Info("List: ", list)
-	c.JSON(http.StatusOK, gin.H{"list": list})
+	entries := handler.queueService(request.Params.Namespace).GetEntryList(ctx, request.N)
+	return apiSpec.GetQueueEntryList200JSONResponse(queueEntryList(entries)), nil
 }
 
 // GetSortedQueueEntries implements the GetSortedQueueEntries method of the CacheHandlerInterface
-func (handler *cacheHandler) GetSortedQueueEntries(c *gin.Context) {
-	selector, err := strconv.Atoi(c.Param("selector"))
-	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (handler *cacheHandler) GetSortedQueueEntries(ctx context.Context, request apiSpec.GetSortedQueueEntriesRequestObject) (apiSpec.GetSortedQueueEntriesResponseObject, error) {
+	if request.N < 1 {
+		return apiSpec.GetSortedQueueEntries400JSONResponse{BadRequestJSONResponse: badRequest("n must be at least 1")}, nil
 	}
-	n, err := strconv.Atoi(c.Param("n"))
-	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	selector := queueservice.SortByValue
+	if request.SortBy == apiSpec.GetSortedQueueEntriesParamsSortByKey {
+		selector = queueservice.SortByKey
 	}
-	list := handler.cacheQueueService.GetSortedEntries(selector, n)
-	klog.Info("List: ", list)
-	c.JSON(http.StatusOK, gin.H{"list": list})
+	entries := handler.queueService(request.Params.Namespace).GetSortedEntries(ctx, selector, request.N)
+	return apiSpec.GetSortedQueueEntries200JSONResponse(queueEntryList(entries)), nil
+}
+
+// GetAllQueueMetadata implements the GetAllQueueMetadata method of the CacheHandlerInterface
+func (handler *cacheHandler) GetAllQueueMetadata(ctx context.Context, request apiSpec.GetAllQueueMetadataRequestObject) (apiSpec.GetAllQueueMetadataResponseObject, error) {
+	metadata := handler.queueService(request.Params.Namespace).AllMetadata(ctx)
+	return apiSpec.GetAllQueueMetadata200JSONResponse(queueMetadataList(metadata)), nil
+}
+
+// GetQueueMetadata implements the GetQueueMetadata method of the CacheHandlerInterface
+func (handler *cacheHandler) GetQueueMetadata(ctx context.Context, request apiSpec.GetQueueMetadataRequestObject) (apiSpec.GetQueueMetadataResponseObject, error) {
+	metadata, ok := handler.queueService(request.Params.Namespace).Metadata(ctx, request.Key)
+	if !ok {
+		return apiSpec.GetQueueMetadata404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.GetQueueMetadata200JSONResponse(apiMetadata(mapRepository.Metadata(metadata))), nil
 }
 
 // UpdateQueueValue implements the UpdateQueueValue method of the CacheHandlerInterface
-func (handler *cacheHandler) UpdateQueueValue(c *gin.Context) {
-	key := c.Param("key")
-	newValue := c.Param("newValue")
+func (handler *cacheHandler) UpdateQueueValue(ctx context.Context, request apiSpec.UpdateQueueValueRequestObject) (apiSpec.UpdateQueueValueResponseObject, error) {
+	if request.Body.TimeToLive != nil {
+		return apiSpec.UpdateQueueValue400JSONResponse{BadRequestJSONResponse: badRequest("time-to-live of a queued entry can not be changed")}, nil
+	}
+	if !handler.queueService(request.Params.Namespace).UpdateValue(ctx, request.Key, request.Body.NewVal) {
+		return apiSpec.UpdateQueueValue404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.UpdateQueueValue200JSONResponse{Status: "value updated sucessfully", Key: request.Key}, nil
+}
 
-	responseKey := handler.cacheQueueService.UpdateValue(key, newValue)
-	klog.Info("Key:", responseKey)
-	c.JSON(http.StatusOK, gin.H{"key": responseKey})
+// DeleteQueueValue implements the DeleteQueueValue method of the CacheHandlerInterface
+func (handler *cacheHandler) DeleteQueueValue(ctx context.Context, request apiSpec.DeleteQueueValueRequestObject) (apiSpec.DeleteQueueValueResponseObject, error) {
+	if !handler.queueService(request.Params.Namespace).Delete(ctx, request.Key) {
+		return apiSpec.DeleteQueueValue404JSONResponse{NotFoundJSONResponse: notFound("key not found")}, nil
+	}
+	return apiSpec.DeleteQueueValue204Response{}, nil
 }
 
-// SetMapValue implements the SetMapValue method of the CacheHandlerInterface
-func (handler *cacheHandler) SetMapValue(c *gin.Context) {
-	var setValue SetRequest
-	err := c.BindJSON(&setValue)
-	if err != nil {
-		klog.ErrorS(err, "Error binding json")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// GetCounter implements the GetCounter method of the CacheHandlerInterface
+func (handler *cacheHandler) GetCounter(ctx context.Context, request apiSpec.GetCounterRequestObject) (apiSpec.GetCounterResponseObject, error) {
+	if handler.crdt == nil {
+		return apiSpec.GetCounter501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "counters require the active-active mode"}}, nil
+	}
+	value, ok := handler.crdt.Counter(namespaceName(request.Params.Namespace), request.Key)
+	if !ok {
+		return apiSpec.GetCounter404JSONResponse{NotFoundJSONResponse: notFound("counter not found")}, nil
 	}
-	key := handler.cacheMapService.Set(setValue.Key, setValue.Value)
-	klog.Info("Key: ", key)
-	c.JSON(http.StatusOK, gin.H{"status": "value set sucessfully", "key": key})
+	return apiSpec.GetCounter200JSONResponse{Key: request.Key, Value: value}, nil
 }
 
-// GetMapValue implements the GetMapValue method of the CacheHandlerInterface
-func (handler *cacheHandler) GetMapValue(c *gin.Context) {
-	key := c.Param("key")
-	value := handler.cacheMapService.Get(key)
+// IncrementCounter implements the IncrementCounter method of the CacheHandlerInterface
+func (handler *cacheHandler) IncrementCounter(ctx context.Context, request apiSpec.IncrementCounterRequestObject) (apiSpec.IncrementCounterResponseObject, error) {
+	if handler.crdt == nil {
+		return apiSpec.IncrementCounter501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "counters require the active-active mode"}}, nil
+	}
+	value := handler.crdt.Increment(namespaceName(request.Params.Namespace), request.Key, request.Body.Delta)
+	return apiSpec.IncrementCounter200JSONResponse{Key: request.Key, Value: value}, nil
+}
 
-	klog.Info("Key: ", key, " Value: ", value)
-	c.JSON(http.StatusOK, gin.H{"key": key, "value": value})
+// GetSet implements the GetSet method of the CacheHandlerInterface
+func (handler *cacheHandler) GetSet(ctx context.Context, request apiSpec.GetSetRequestObject) (apiSpec.GetSetResponseObject, error) {
+	if handler.crdt == nil {
+		return apiSpec.GetSet501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "sets require the active-active mode"}}, nil
+	}
+	elements, ok := handler.crdt.Set(namespaceName(request.Params.Namespace), request.Key)
+	if !ok {
+		return apiSpec.GetSet404JSONResponse{NotFoundJSONResponse: notFound("set not found")}, nil
+	}
+	return apiSpec.GetSet200JSONResponse{Key: request.Key, Elements: elements}, nil
 }
 
-// GetAllMapValues implements the GetAllMapValues method of the CacheHandlerInterface
-func (handler *cacheHandler) GetAllMapValues(c *gin.Context) {
-	values := handler.cacheMapService.All()
+// UpdateSet implements the UpdateSet method of the CacheHandlerInterface
+func (handler *cacheHandler) UpdateSet(ctx context.Context, request apiSpec.UpdateSetRequestObject) (apiSpec.UpdateSetResponseObject, error) {
+	if handler.crdt == nil {
+		return apiSpec.UpdateSet501JSONResponse{NotImplementedJSONResponse: apiSpec.NotImplementedJSONResponse{Error: "sets require the active-active mode"}}, nil
+	}
+	var add, remove []string
+	if request.Body.Add != nil {
+		add = *request.Body.Add
+	}
+	if request.Body.Remove != nil {
+		remove = *request.Body.Remove
+	}
+	elements := handler.crdt.UpdateSet(namespaceName(request.Params.Namespace), request.Key, add, remove)
+	return apiSpec.UpdateSet200JSONResponse{Key: request.Key, Elements: elements}, nil
+}
+
+// ListTopics implements the ListTopics method of the CacheHandlerInterface
+func (handler *cacheHandler) ListTopics(ctx context.Context, request apiSpec.ListTopicsRequestObject) (apiSpec.ListTopicsResponseObject, error) {
+	topics := handler.topics.List()
 
-	klog.Info("Values: ", values)
-	c.JSON(http.StatusOK, gin.H{"values": values})
+	response := apiSpec.ListTopics200JSONResponse{Topics: make([]apiSpec.TopicInfo, 0, len(topics))}
+	for _, topic := range topics {
+		response.Topics = append(response.Topics, apiSpec.TopicInfo{
+			Name:          topic.Name,
+			Subscriptions: subscriptionList(topic.Subscriptions),
+		})
+	}
+	return response, nil
 }
 
-// GetMapEntryList implements the GetMapEntryList method of the CacheHandlerInterface
-func (handler *cacheHandler) GetMapEntryList(c *gin.Context) {
-	n, err := strconv.Atoi(c.Param("n"))
-	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// PublishMessage implements the PublishMessage method of the CacheHandlerInterface
+func (handler *cacheHandler) PublishMessage(ctx context.Context, request apiSpec.PublishMessageRequestObject) (apiSpec.PublishMessageResponseObject, error) {
+	if request.Body.Key == "" {
+		return apiSpec.PublishMessage400JSONResponse{BadRequestJSONResponse: badRequest("key is required")}, nil
 	}
-	values := handler.cacheMapService.GetEntryList(n)
-	klog.Info("Values: ", values)
-	c.JSON(http.StatusOK, gin.H{"values": values})
+	delivered := handler.topics.Publish(ctx, request.Topic, request.Body.Key, request.Body.Value, intValue(request.Body.TimeToLive))
+	return apiSpec.PublishMessage200JSONResponse{Topic: request.Topic, Delivered: delivered}, nil
 }
 
-// GetSortedMapEntries implements the GetSortedMapEntries method of the CacheHandlerInterface
-func (handler *cacheHandler) GetSortedMapEntries(c *gin.Context) {
-	selector, err := strconv.Atoi(c.Param("selector"))
-	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// ListSubscriptions implements the ListSubscriptions method of the CacheHandlerInterface
+func (handler *cacheHandler) ListSubscriptions(ctx context.Context, request apiSpec.ListSubscriptionsRequestObject) (apiSpec.ListSubscriptionsResponseObject, error) {
+	subscriptions := handler.topics.Subscriptions(request.Topic)
+	return apiSpec.ListSubscriptions200JSONResponse{Subscriptions: subscriptionList(subscriptions)}, nil
+}
+
+// CreateSubscription implements the CreateSubscription method of the CacheHandlerInterface
+func (handler *cacheHandler) CreateSubscription(ctx context.Context, request apiSpec.CreateSubscriptionRequestObject) (apiSpec.CreateSubscriptionResponseObject, error) {
+	subscription, err := handler.topics.Subscribe(request.Topic, topicservice.Subscription{
+		Name:      stringValue(request.Body.Name),
+		Namespace: request.Body.Namespace,
+		Filter:    stringValue(request.Body.Filter),
+	})
+	if errors.Is(err, topicservice.ErrSubscriptionExists) {
+		return apiSpec.CreateSubscription409JSONResponse{ConflictJSONResponse: apiSpec.ConflictJSONResponse{Error: err.Error()}}, nil
 	}
-	n, err := strconv.Atoi(c.Param("n"))
 	if err != nil {
-		klog.ErrorS(err, "Error converting string to int")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return apiSpec.CreateSubscription400JSONResponse{BadRequestJSONResponse: badRequest(err.Error())}, nil
 	}
-	values := handler.cacheMapService.GetSortedEntryList(selector, n)
+	klog.InfoS("Subscription created", "topic", subscription.Topic, "subscription", subscription.Name, "namespace", subscription.Namespace)
+	return apiSpec.CreateSubscription201JSONResponse(apiSubscription(subscription)), nil
+}
 
-	klog.Info("Values: ", values)
-	c.JSON(http.StatusOK, gin.H{"values": values})
+// DeleteSubscription implements the DeleteSubscription method of the CacheHandlerInterface
+func (handler *cacheHandler) DeleteSubscription(ctx context.Context, request apiSpec.DeleteSubscriptionRequestObject) (apiSpec.DeleteSubscriptionResponseObject, error) {
+	if !handler.topics.Unsubscribe(request.Topic, request.Subscription) {
+		return apiSpec.DeleteSubscription404JSONResponse{NotFoundJSONResponse: notFound("subscription not found")}, nil
+	}
+	klog.InfoS("Subscription deleted", "topic", request.Topic, "subscription", request.Subscription)
+	return apiSpec.DeleteSubscription204Response{}, nil
 }
 
-// UpdateMapEntry implements the UpdateMapEntry method of the CacheHandlerInterface
-func (handler *cacheHandler) UpdateMapEntry(c *gin.Context) {
-	key := c.Param("key")
-	newValue := c.Param("newValue")
+// mapService returns the map of the requested namespace
+func (handler *cacheHandler) mapService(namespace *apiSpec.Namespace) mapservice.MapServiceInterface {
+	return handler.namespaces.Get(stringValue(namespace)).Map
+}
+
+// namespaceName returns the name of the requested namespace
+func namespaceName(namespace *apiSpec.Namespace) string {
+	if name := stringValue(namespace); name != "" {
+		return name
+	}
+	return namespaceservice.DefaultNamespace
+}
 
-	responseKey := handler.cacheMapService.UpdateCacheEntry(key, newValue)
+// queueService returns the queue of the requested namespace
+func (handler *cacheHandler) queueService(namespace *apiSpec.Namespace) queueservice.QueueServiceInterface {
+	return handler.namespaces.Get(stringValue(namespace)).Queue
+}
 
-	klog.Info("Key:", responseKey)
-	c.JSON(http.StatusOK, gin.H{"key": responseKey})
+func mapEntryList(entries []mapRepository.CacheEntry) apiSpec.GetEntryList {
+	list := apiSpec.GetEntryList{Entries: make([]apiSpec.CacheEntry, 0, len(entries))}
+	for _, entry := range entries {
+		list.Entries = append(list.Entries, apiEntry(entry.Key, entry.Value, entry.TTL))
+	}
+	return list
 }
 
-// GetListofMapValues implements the GetListofMapValues method of the CacheHandlerInterface
-func (handler *cacheHandler) GetListofMapValues(c *gin.Context) {
-	keys := c.QueryArray("keys")
-	values := handler.cacheMapService.GetListofValues(keys)
+func queueEntryList(entries []queueRepository.CacheEntry) apiSpec.GetEntryList {
+	list := apiSpec.GetEntryList{Entries: make([]apiSpec.CacheEntry, 0, len(entries))}
+	for _, entry := range entries {
+		list.Entries = append(list.Entries, apiEntry(entry.Key, entry.Value, entry.TTL))
+	}
+	return list
+}
+
+func mapMetadataList(metadata []mapRepository.Metadata) apiSpec.MetadataList {
+	list := apiSpec.MetadataList{Entries: make([]apiSpec.EntryMetadata, 0, len(metadata))}
+	for _, entry := range metadata {
+		list.Entries = append(list.Entries, apiMetadata(entry))
+	}
+	return list
+}
+
+func queueMetadataList(metadata []queueRepository.Metadata) apiSpec.MetadataList {
+	list := apiSpec.MetadataList{Entries: make([]apiSpec.EntryMetadata, 0, len(metadata))}
+	for _, entry := range metadata {
+		list.Entries = append(list.Entries, apiMetadata(mapRepository.Metadata(entry)))
+	}
+	return list
+}
+
+// apiMetadata converts the metadata of an entry, the metadata of the queue
+// entries has the same fields and is converted to the one of the map first
+func apiMetadata(metadata mapRepository.Metadata) apiSpec.EntryMetadata {
+	result := apiSpec.EntryMetadata{
+		Key:         metadata.Key,
+		CreatedAt:   metadata.CreatedAt,
+		UpdatedAt:   metadata.UpdatedAt,
+		AccessCount: metadata.Accesses,
+		Size:        metadata.Size,
+		Version:     metadata.Version,
+	}
+	if !metadata.LastAccess.IsZero() {
+		result.LastAccess = &metadata.LastAccess
+	}
+	if metadata.TTL > 0 {
+		seconds := metadata.TTL.Seconds()
+		result.TimeToLive = &seconds
+	}
+	return result
+}
+
+func subscriptionList(subscriptions []topicservice.Subscription) []apiSpec.Subscription {
+	list := make([]apiSpec.Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		list = append(list, apiSubscription(subscription))
+	}
+	return list
+}
+
+func apiSubscription(subscription topicservice.Subscription) apiSpec.Subscription {
+	result := apiSpec.Subscription{Name: subscription.Name, Topic: subscription.Topic, Namespace: subscription.Namespace}
+	if subscription.Filter != "" {
+		result.Filter = &subscription.Filter
+	}
+	return result
+}
+
+func apiEntry(key, value string, ttl time.Duration) apiSpec.CacheEntry {
+	entry := apiSpec.CacheEntry{Key: key, Value: value}
+	if ttl > 0 {
+		seconds := int(ttl / time.Second)
+		entry.TimeToLive = &seconds
+	}
+	return entry
+}
 
-	klog.Info("Values: ", values)
-	c.JSON(http.StatusOK, gin.H{"values": values})
+// ttlSeconds converts a remaining time to live to the API representation, -1 never expires
+func ttlSeconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return -1
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
+
+func badRequest(message string) apiSpec.BadRequestJSONResponse {
+	return apiSpec.BadRequestJSONResponse{Error: message}
+}
+
+func notFound(message string) apiSpec.NotFoundJSONResponse {
+	return apiSpec.NotFoundJSONResponse{Error: message}
+}
+
+func unavailable(err error) apiSpec.UnavailableJSONResponse {
+	return apiSpec.UnavailableJSONResponse{Error: err.Error()}
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
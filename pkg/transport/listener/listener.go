@@ -0,0 +1,85 @@
+// Package listener creates the listeners of the protocol frontends from
+// addresses given on the command line. An address is either a TCP address
+// such as ":8000" or a Unix domain socket path prefixed with "unix:".
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// UnixPrefix marks an address as the path of a Unix domain socket
+const UnixPrefix = "unix:"
+
+// Options configure the Unix domain sockets created by Listen
+type Options struct {
+	// SocketMode is the permission of the socket file
+	SocketMode fs.FileMode
+	// SocketGroup is the numeric id of the group owning the socket file, -1 keeps the default group
+	SocketGroup int
+}
+
+// Split splits a comma separated list of addresses, empty entries are ignored
+func Split(addresses string) []string {
+	var result []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+// Listen listens on a TCP address or on a Unix domain socket. A stale socket
+// file left behind by a previous process is removed, any other file at the
+// path is an error. The socket file is removed when the listener is closed.
+func Listen(address string, options Options) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, UnixPrefix)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("empty Unix domain socket path in %q", address)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := removeStale(path); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, options.SocketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if options.SocketGroup >= 0 {
+		if err := os.Lchown(path, -1, options.SocketGroup); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// removeStale removes a socket file nobody is listening on anymore
+func removeStale(path string) error {
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
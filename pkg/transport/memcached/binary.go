@@ -0,0 +1,323 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	repository "github.com/zelta-7/cache/pkg/repository/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+const (
+	requestMagic  = 0x80
+	responseMagic = 0x81
+	headerLength  = 24
+)
+
+// Opcodes of the binary protocol, the quiet variants only reply on failure
+// or, for the get commands, on a hit
+const (
+	opGet       = 0x00
+	opSet       = 0x01
+	opAdd       = 0x02
+	opReplace   = 0x03
+	opDelete    = 0x04
+	opIncrement = 0x05
+	opDecrement = 0x06
+	opQuit      = 0x07
+	opFlush     = 0x08
+	opGetQ      = 0x09
+	opNoop      = 0x0a
+	opVersion   = 0x0b
+	opGetK      = 0x0c
+	opGetKQ     = 0x0d
+	opSetQ      = 0x11
+	opAddQ      = 0x12
+	opReplaceQ  = 0x13
+	opDeleteQ   = 0x14
+	opIncrQ     = 0x15
+	opDecrQ     = 0x16
+	opQuitQ     = 0x17
+	opFlushQ    = 0x18
+	opTouch     = 0x1c
+)
+
+// Response statuses of the binary protocol
+const (
+	statusOK             = 0x0000
+	statusKeyNotFound    = 0x0001
+	statusKeyExists      = 0x0002
+	statusValueTooLarge  = 0x0003
+	statusInvalidArgs    = 0x0004
+	statusNotStored      = 0x0005
+	statusNonNumeric     = 0x0006
+	statusUnknownCommand = 0x0081
+	statusNotSupported   = 0x0083
+	// statusTemporaryFailure answers a write that could not be committed
+	statusTemporaryFailure = 0x0086
+	// statusNotMyVbucket answers a key owned by another cluster node
+	statusNotMyVbucket = 0x0007
+)
+
+// quietOpcodes maps the quiet opcodes to the opcode they are a variant of
+var quietOpcodes = map[byte]byte{
+	opGetQ:     opGet,
+	opGetKQ:    opGetK,
+	opSetQ:     opSet,
+	opAddQ:     opAdd,
+	opReplaceQ: opReplace,
+	opDeleteQ:  opDelete,
+	opIncrQ:    opIncrement,
+	opDecrQ:    opDecrement,
+	opQuitQ:    opQuit,
+	opFlushQ:   opFlush,
+}
+
+// request is a decoded binary request, opcode is the opcode sent by the
+// client and command the one it is a quiet variant of
+type request struct {
+	opcode  byte
+	command byte
+	quiet   bool
+	opaque  uint32
+	cas     uint64
+	extras  []byte
+	key     string
+	value   []byte
+}
+
+// response is a binary response, it is not sent when quiet is set
+type response struct {
+	status uint16
+	cas    uint64
+	extras []byte
+	key    string
+	value  []byte
+	quiet  bool
+}
+
+// serveBinary runs the binary protocol until the client quits or the connection fails
+func (s *server) serveBinary(ctx context.Context, reader *bufio.Reader, writer *bufio.Writer) error {
+	for {
+		req, err := readRequest(reader)
+		if err != nil {
+			return err
+		}
+
+		var res response
+		if req.command == opQuit {
+			res.quiet = req.quiet
+		} else {
+			res = s.runBinary(ctx, req)
+		}
+		if !res.quiet {
+			writeResponse(writer, req, res)
+		}
+		if req.command == opQuit {
+			return writer.Flush()
+		}
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readRequest(reader *bufio.Reader) (request, error) {
+	var header [headerLength]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return request{}, err
+	}
+	if header[0] != requestMagic {
+		return request{}, fmt.Errorf("invalid request magic 0x%02x", header[0])
+	}
+	keyLength := int(binary.BigEndian.Uint16(header[2:4]))
+	extrasLength := int(header[4])
+	bodyLength := int(binary.BigEndian.Uint32(header[8:12]))
+	if bodyLength < keyLength+extrasLength || bodyLength > maxValueLength+maxKeyLength+255 {
+		return request{}, fmt.Errorf("invalid request body length %d", bodyLength)
+	}
+	body := make([]byte, bodyLength)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return request{}, err
+	}
+
+	req := request{
+		opcode:  header[1],
+		command: header[1],
+		opaque:  binary.BigEndian.Uint32(header[12:16]),
+		cas:     binary.BigEndian.Uint64(header[16:24]),
+		extras:  body[:extrasLength],
+		key:     string(body[extrasLength : extrasLength+keyLength]),
+		value:   body[extrasLength+keyLength:],
+	}
+	if command, ok := quietOpcodes[req.opcode]; ok {
+		req.command = command
+		req.quiet = true
+	}
+	return req, nil
+}
+
+func writeResponse(writer *bufio.Writer, req request, res response) {
+	var header [headerLength]byte
+	header[0] = responseMagic
+	header[1] = req.opcode
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(res.key)))
+	header[4] = byte(len(res.extras))
+	binary.BigEndian.PutUint16(header[6:8], res.status)
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(res.extras)+len(res.key)+len(res.value)))
+	binary.BigEndian.PutUint32(header[12:16], req.opaque)
+	binary.BigEndian.PutUint64(header[16:24], res.cas)
+	writer.Write(header[:])
+	writer.Write(res.extras)
+	writer.WriteString(res.key)
+	writer.Write(res.value)
+}
+
+// failure is the response of a failed request, failures are sent even for quiet requests
+func failure(status uint16, message string) response {
+	return response{status: status, value: []byte(message)}
+}
+
+// runBinary runs a single request
+func (s *server) runBinary(ctx context.Context, req request) response {
+	if req.command != opNoop && req.command != opVersion && req.command != opFlush && (len(req.key) == 0 || len(req.key) > maxKeyLength) {
+		return failure(statusInvalidArgs, "Invalid arguments")
+	}
+	switch req.command {
+	case opSet, opAdd, opReplace, opDelete, opIncrement, opDecrement, opTouch, opFlush:
+		if s.namespaces.ReadOnly() {
+			return failure(statusNotSupported, namespaceservice.ErrReadOnly.Error())
+		}
+	}
+	if req.command != opNoop && req.command != opVersion && req.command != opFlush {
+		if err := s.namespaces.Route(s.namespace, req.key); err != nil {
+			return failure(statusNotMyVbucket, err.Error())
+		}
+	}
+
+	switch req.command {
+	case opGet, opGetK:
+		entries, err := s.cache().GetListofValues(ctx, []string{req.key})
+		if err != nil {
+			return failure(statusTemporaryFailure, err.Error())
+		}
+		if len(entries) == 0 {
+			res := failure(statusKeyNotFound, "Not found")
+			res.quiet = req.quiet
+			return res
+		}
+		entry := entries[0]
+		res := response{cas: entry.Version, extras: make([]byte, 4), value: []byte(entry.Value)}
+		binary.BigEndian.PutUint32(res.extras, entry.Flags)
+		if req.command == opGetK {
+			res.key = req.key
+		}
+		return res
+
+	case opSet, opAdd, opReplace:
+		if len(req.extras) != 8 {
+			return failure(statusInvalidArgs, "Invalid arguments")
+		}
+		if len(req.value) > maxValueLength {
+			return failure(statusValueTooLarge, "Too large")
+		}
+		condition := map[byte]repository.Condition{opSet: repository.Always, opAdd: repository.IfAbsent, opReplace: repository.IfPresent}[req.command]
+		if req.cas != 0 {
+			if req.command == opAdd {
+				return failure(statusInvalidArgs, "Invalid arguments")
+			}
+			condition = repository.IfVersion
+		}
+		entry := repository.CacheEntry{
+			Key:     req.key,
+			Value:   string(req.value),
+			Flags:   binary.BigEndian.Uint32(req.extras[0:4]),
+			Version: req.cas,
+		}
+		err := s.store(ctx, entry, int64(int32(binary.BigEndian.Uint32(req.extras[4:8]))), condition)
+		switch {
+		case err == nil:
+			// the value is stored, a read of its version that fails only leaves the CAS out
+			stored, _, _ := s.cache().GetEntry(ctx, req.key)
+			return response{cas: stored.Version, quiet: req.quiet}
+		case errors.Is(err, repository.ErrVersionMismatch), errors.Is(err, repository.ErrExists):
+			return failure(statusKeyExists, "Data exists for key.")
+		case errors.Is(err, repository.ErrNotFound):
+			return failure(statusKeyNotFound, "Not found")
+		default:
+			return failure(statusNotStored, "Not stored.")
+		}
+
+	case opDelete:
+		deleted, err := s.cache().Delete(ctx, req.key)
+		if err != nil {
+			return failure(statusTemporaryFailure, err.Error())
+		}
+		if !deleted {
+			return failure(statusKeyNotFound, "Not found")
+		}
+		return response{quiet: req.quiet}
+
+	case opIncrement, opDecrement:
+		if len(req.extras) != 20 {
+			return failure(statusInvalidArgs, "Invalid arguments")
+		}
+		delta := binary.BigEndian.Uint64(req.extras[0:8])
+		initial := binary.BigEndian.Uint64(req.extras[8:16])
+		exptime := binary.BigEndian.Uint32(req.extras[16:20])
+
+		value, err := s.incr(ctx, req.key, delta, req.command == opDecrement)
+		if errors.Is(err, repository.ErrNotFound) && exptime != 0xffffffff {
+			entry := repository.CacheEntry{Key: req.key, Value: fmt.Sprint(initial)}
+			value, err = initial, s.store(ctx, entry, int64(exptime), repository.IfAbsent)
+		}
+		switch {
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrExists):
+			return failure(statusKeyNotFound, "Not found")
+		case errors.Is(err, errNonNumeric):
+			return failure(statusNonNumeric, "Non-numeric server-side value for incr or decr")
+		case err != nil:
+			return failure(statusNotStored, "Not stored.")
+		}
+		entry, _, _ := s.cache().GetEntry(ctx, req.key)
+		res := response{cas: entry.Version, value: make([]byte, 8), quiet: req.quiet}
+		binary.BigEndian.PutUint64(res.value, value)
+		return res
+
+	case opTouch:
+		if len(req.extras) != 4 {
+			return failure(statusInvalidArgs, "Invalid arguments")
+		}
+		touched, err := s.touch(ctx, req.key, int64(int32(binary.BigEndian.Uint32(req.extras))))
+		if err != nil {
+			return failure(statusTemporaryFailure, err.Error())
+		}
+		if !touched {
+			return failure(statusKeyNotFound, "Not found")
+		}
+		return response{}
+
+	case opFlush:
+		var delay int64
+		if len(req.extras) == 4 {
+			delay = int64(binary.BigEndian.Uint32(req.extras))
+		}
+		s.flush(ctx, delay)
+		return response{quiet: req.quiet}
+
+	case opNoop:
+		return response{}
+
+	case opVersion:
+		return response{value: []byte(version)}
+
+	default:
+		return failure(statusUnknownCommand, "Unknown command")
+	}
+}
@@ -0,0 +1,256 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	repository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxKeyLength is the longest key memcached accepts
+	maxKeyLength = 250
+	// maxValueLength is the default item size limit of memcached
+	maxValueLength = 1 << 20
+	// relativeExpirationLimit is the largest exptime that is relative to
+	// the current time, larger values are unix timestamps
+	relativeExpirationLimit = 60 * 60 * 24 * 30
+	// version is reported by the version commands
+	version = "1.6.0-cache"
+)
+
+var errNonNumeric = errors.New("cannot increment or decrement non-numeric value")
+
+// ServerInterface serves the memcached text and binary protocols on top of the map service
+type ServerInterface interface {
+	// Serve accepts connections on listener until ctx is done
+	Serve(ctx context.Context, listener net.Listener) error
+
+	// Close cancels the flush delayed by a flush_all, it is called once the
+	// context given to Serve is done
+	Close()
+}
+
+type server struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	namespace  string
+
+	// flushTimer is the pending flush delayed by a flush_all, nil if none is
+	flushTimer *time.Timer
+	closed     bool
+	flushLock  sync.Mutex
+}
+
+// NewServer returns a server storing the items in the map of the given namespace
+func NewServer(namespaces namespaceservice.NamespaceServiceInterface, namespace string) ServerInterface {
+	return &server{
+		namespaces: namespaces,
+		namespace:  namespace,
+	}
+}
+
+// Serve implements the Serve method of the ServerInterface
+func (s *server) Serve(ctx context.Context, listener net.Listener) error {
+	var connections sync.WaitGroup
+	defer connections.Wait()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		s.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle serves a connection, the protocol is chosen by the first byte
+// the client sends as binary requests always start with the request magic
+func (s *server) handle(ctx context.Context, conn net.Conn) {
+	ctx = common.WithClient(ctx, conn.RemoteAddr().String())
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	if first[0] == requestMagic {
+		err = s.serveBinary(ctx, reader, writer)
+	} else {
+		err = s.serveText(ctx, reader, writer)
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		klog.V(2).InfoS("Error serving memcached connection", "remote", conn.RemoteAddr(), "err", err)
+	}
+}
+
+func (s *server) cache() mapservice.MapServiceInterface {
+	return s.namespaces.Get(s.namespace).Map
+}
+
+// expiration converts a memcached exptime to a time to live, expired is
+// true when the item must not be visible at all
+func expiration(exptime int64) (ttl time.Duration, expired bool) {
+	switch {
+	case exptime < 0:
+		return 0, true
+	case exptime == 0:
+		return 0, false
+	case exptime > relativeExpirationLimit:
+		exptime -= time.Now().Unix()
+		if exptime <= 0 {
+			return 0, true
+		}
+	}
+	return time.Duration(exptime) * time.Second, false
+}
+
+// store writes an item if the condition holds. An item with an exptime in
+// the past is not written, as in memcached it only replaces the item of its
+// key which is removed, and the reply is the one of the write.
+func (s *server) store(ctx context.Context, entry repository.CacheEntry, exptime int64, condition repository.Condition) error {
+	ttl, expired := expiration(exptime)
+	if !expired {
+		entry.TTL = ttl
+		_, err := s.cache().Store(ctx, entry, condition)
+		return err
+	}
+
+	current, ok, err := s.cache().GetEntry(ctx, entry.Key)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !ok && (condition == repository.IfPresent || condition == repository.IfVersion):
+		return repository.ErrNotFound
+	case !ok:
+		return nil
+	case condition == repository.IfAbsent:
+		return repository.ErrExists
+	case condition == repository.IfVersion && current.Version != entry.Version:
+		return repository.ErrVersionMismatch
+	}
+	// the item is removed only if it was not written meanwhile
+	deleted, err := s.cache().DeleteVersion(ctx, entry.Key, current.Version)
+	if err == nil && !deleted && condition == repository.IfVersion {
+		err = repository.ErrVersionMismatch
+	}
+	return err
+}
+
+// touch changes the expiration of an item and reports whether it exists
+func (s *server) touch(ctx context.Context, key string, exptime int64) (bool, error) {
+	ttl, expired := expiration(exptime)
+	if expired {
+		return s.cache().Delete(ctx, key)
+	}
+	return s.cache().Expire(ctx, key, int(ttl/time.Second))
+}
+
+// incr adds delta to an item holding a decimal number, incr wraps around
+// at 64 bits and decr stops at zero like memcached
+func (s *server) incr(ctx context.Context, key string, delta uint64, decr bool) (uint64, error) {
+	var result uint64
+	_, err := s.cache().Modify(ctx, key, func(value string) (string, error) {
+		current, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", errNonNumeric
+		}
+		switch {
+		case !decr:
+			result = current + delta
+		case delta > current:
+			result = 0
+		default:
+			result = current - delta
+		}
+		return strconv.FormatUint(result, 10), nil
+	})
+	return result, err
+}
+
+// flush removes every item after delay seconds, it replaces the flush
+// still pending from a previous flush_all
+func (s *server) flush(ctx context.Context, delay int64) {
+	s.flushLock.Lock()
+	defer s.flushLock.Unlock()
+
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+	if delay <= 0 {
+		s.cache().Flush(ctx)
+		return
+	}
+	if s.closed {
+		return
+	}
+	// the delayed flush outlives the connection, it keeps its client only
+	client := common.Client(ctx)
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Duration(delay)*time.Second, func() {
+		s.flushLock.Lock()
+		current := s.flushTimer == timer
+		if current {
+			s.flushTimer = nil
+		}
+		s.flushLock.Unlock()
+		if !current {
+			return
+		}
+		if s.namespaces.ReadOnly() {
+			klog.InfoS("Delayed memcached flush skipped on a read-only replica", "namespace", s.namespace)
+			return
+		}
+		s.cache().Flush(common.WithClient(context.Background(), client))
+	})
+	s.flushTimer = timer
+}
+
+// Close implements the Close method of the ServerInterface
+func (s *server) Close() {
+	s.flushLock.Lock()
+	defer s.flushLock.Unlock()
+
+	s.closed = true
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+}
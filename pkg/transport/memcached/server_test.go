@@ -0,0 +1,317 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// writes records the writes applied to the namespaces
+type writes struct {
+	ops  []string
+	lock sync.Mutex
+}
+
+// Observe implements the Observe method of the Observer interface
+func (w *writes) Observe(ctx context.Context, operation namespaceservice.Operation) {
+	if operation.Write && operation.OK {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		w.ops = append(w.ops, operation.Op+" "+operation.Key)
+	}
+}
+
+func (w *writes) take() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	ops := w.ops
+	w.ops = nil
+	return ops
+}
+
+// startServer serves the default namespace on a local port and returns a
+// connection to it and the writes applied
+func startServer(t *testing.T) (net.Conn, *writes) {
+	t.Helper()
+	observed := &writes{}
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{Observers: []namespaceservice.Observer{observed}})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		NewServer(namespaces, namespaceservice.DefaultNamespace).Serve(ctx, listener)
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-served
+	})
+	return conn, observed
+}
+
+func TestTextProtocol(t *testing.T) {
+	conn, _ := startServer(t)
+	reader := bufio.NewReader(conn)
+	// do sends the command and returns the lines of the reply up to the last one
+	do := func(command, last string) string {
+		t.Helper()
+		fmt.Fprintf(conn, "%s\r\n", command)
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading the reply to %q: %v", command, err)
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\r\n"))
+			if last == "" || strings.HasPrefix(lines[len(lines)-1], last) || strings.Contains(lines[len(lines)-1], "ERROR") {
+				return strings.Join(lines, "|")
+			}
+		}
+	}
+
+	for _, step := range []struct{ command, last, reply string }{
+		{"set a 5 0 1\r\nx", "", "STORED"},
+		{"set n 0 100 2\r\n10", "", "STORED"},
+		{"get a missing n", "END", "VALUE a 5 1|x|VALUE n 0 2|10|END"},
+		{"add a 0 0 1\r\ny", "", "NOT_STORED"},
+		{"replace missing 0 0 1\r\ny", "", "NOT_STORED"},
+		{"incr n 5", "", "15"},
+		{"decr n 20", "", "0"},
+		{"incr a 1", "", "CLIENT_ERROR cannot increment or decrement non-numeric value"},
+		{"incr missing 1", "", "NOT_FOUND"},
+		{"touch a 100", "", "TOUCHED"},
+		{"touch missing 100", "", "NOT_FOUND"},
+		{"set q 0 0 1 noreply\r\nz\r\nget q", "END", "VALUE q 0 1|z|END"},
+		{"delete q", "", "DELETED"},
+		{"delete q", "", "NOT_FOUND"},
+		{"version", "", "VERSION " + version},
+		{"unknown", "", "ERROR"},
+	} {
+		if reply := do(step.command, step.last); reply != step.reply {
+			t.Fatalf("%q replied %q, want %q", step.command, reply, step.reply)
+		}
+	}
+
+	reply := do("gets a", "END")
+	gets := strings.Fields(strings.TrimSuffix(reply, "|x|END"))
+	if len(gets) != 5 {
+		t.Fatalf("gets replied %q", reply)
+	}
+	cas, _ := strconv.ParseUint(gets[4], 10, 64)
+	if reply := do(fmt.Sprintf("cas a 0 0 1 %d\r\ny", cas+1), ""); reply != "EXISTS" {
+		t.Fatalf("cas with another version replied %q", reply)
+	}
+	if reply := do(fmt.Sprintf("cas a 0 0 1 %d\r\ny", cas), ""); reply != "STORED" {
+		t.Fatalf("cas with the version read replied %q", reply)
+	}
+	if reply := do("cas missing 0 0 1 1\r\ny", ""); reply != "NOT_FOUND" {
+		t.Fatalf("cas of a missing key replied %q", reply)
+	}
+}
+
+func TestExpiredItemsAreNotWritten(t *testing.T) {
+	conn, observed := startServer(t)
+	reader := bufio.NewReader(conn)
+	do := func(command string) string {
+		t.Helper()
+		fmt.Fprintf(conn, "%s\r\n", command)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the reply to %q: %v", command, err)
+		}
+		return strings.TrimSuffix(line, "\r\n")
+	}
+
+	past := time.Now().Add(-time.Hour).Unix()
+	for _, step := range []struct{ command, reply string }{
+		{"set a 0 -1 1\r\nx", "STORED"},
+		{fmt.Sprintf("set b 0 %d 1\r\nx", past), "STORED"},
+		{"add c 0 -1 1\r\nx", "STORED"},
+		{"replace d 0 -1 1\r\nx", "NOT_STORED"},
+	} {
+		if reply := do(step.command); reply != step.reply {
+			t.Fatalf("%q replied %q, want %q", step.command, reply, step.reply)
+		}
+	}
+	if ops := observed.take(); len(ops) != 0 {
+		t.Fatalf("the expired items were written: %v", ops)
+	}
+
+	// an expired item replaces the item of its key
+	do("set e 0 0 1\r\nx")
+	observed.take()
+	if reply := do("set e 0 -1 1\r\ny"); reply != "STORED" {
+		t.Fatalf("the expired set replied %q", reply)
+	}
+	if ops := observed.take(); len(ops) != 1 || strings.HasPrefix(ops[0], "set") {
+		t.Fatalf("the expired set wrote %v, want the item removed", ops)
+	}
+	if reply := do("get e"); reply != "END" {
+		t.Fatalf("get of the replaced item replied %q", reply)
+	}
+	if reply := do("add e 0 -1 1\r\ny"); reply != "STORED" {
+		t.Fatalf("add over the removed item replied %q", reply)
+	}
+}
+
+// binaryRequest is a request of the binary protocol
+type binaryRequest struct {
+	opcode byte
+	opaque uint32
+	cas    uint64
+	extras []byte
+	key    string
+	value  string
+}
+
+// binaryResponse is a decoded response of the binary protocol
+type binaryResponse struct {
+	opcode byte
+	status uint16
+	opaque uint32
+	cas    uint64
+	extras []byte
+	key    string
+	value  string
+}
+
+func (r binaryRequest) write(w io.Writer) error {
+	header := make([]byte, headerLength)
+	header[0] = requestMagic
+	header[1] = r.opcode
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(r.key)))
+	header[4] = byte(len(r.extras))
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(r.extras)+len(r.key)+len(r.value)))
+	binary.BigEndian.PutUint32(header[12:16], r.opaque)
+	binary.BigEndian.PutUint64(header[16:24], r.cas)
+	_, err := w.Write(append(append(append(header, r.extras...), r.key...), r.value...))
+	return err
+}
+
+func readResponse(r io.Reader) (binaryResponse, error) {
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return binaryResponse{}, err
+	}
+	if header[0] != responseMagic {
+		return binaryResponse{}, fmt.Errorf("invalid response magic 0x%02x", header[0])
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[8:12]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return binaryResponse{}, err
+	}
+	keyLength, extrasLength := int(binary.BigEndian.Uint16(header[2:4])), int(header[4])
+	return binaryResponse{
+		opcode: header[1],
+		status: binary.BigEndian.Uint16(header[6:8]),
+		opaque: binary.BigEndian.Uint32(header[12:16]),
+		cas:    binary.BigEndian.Uint64(header[16:24]),
+		extras: body[:extrasLength],
+		key:    string(body[extrasLength : extrasLength+keyLength]),
+		value:  string(body[extrasLength+keyLength:]),
+	}, nil
+}
+
+// storageExtras returns the extras of a storage request
+func storageExtras(flags uint32, exptime int32) []byte {
+	extras := make([]byte, 8)
+	binary.BigEndian.PutUint32(extras[0:4], flags)
+	binary.BigEndian.PutUint32(extras[4:8], uint32(exptime))
+	return extras
+}
+
+func TestBinaryProtocol(t *testing.T) {
+	conn, _ := startServer(t)
+	reader := bufio.NewReader(conn)
+	do := func(request binaryRequest) binaryResponse {
+		t.Helper()
+		if err := request.write(conn); err != nil {
+			t.Fatalf("sending opcode 0x%02x: %v", request.opcode, err)
+		}
+		response, err := readResponse(reader)
+		if err != nil {
+			t.Fatalf("reading the response to opcode 0x%02x: %v", request.opcode, err)
+		}
+		if response.opcode != request.opcode || response.opaque != request.opaque {
+			t.Fatalf("the response %+v does not match the request %+v", response, request)
+		}
+		return response
+	}
+
+	set := do(binaryRequest{opcode: opSet, opaque: 1, extras: storageExtras(7, 0), key: "a", value: "x"})
+	if set.status != statusOK || set.cas == 0 {
+		t.Fatalf("set responded %+v", set)
+	}
+	get := do(binaryRequest{opcode: opGetK, opaque: 2, key: "a"})
+	if get.status != statusOK || get.value != "x" || get.key != "a" || binary.BigEndian.Uint32(get.extras) != 7 || get.cas != set.cas {
+		t.Fatalf("getk responded %+v", get)
+	}
+	if res := do(binaryRequest{opcode: opAdd, opaque: 3, extras: storageExtras(0, 0), key: "a", value: "y"}); res.status != statusKeyExists {
+		t.Fatalf("add of an existing key responded %+v", res)
+	}
+	if res := do(binaryRequest{opcode: opSet, opaque: 4, cas: set.cas + 1, extras: storageExtras(0, 0), key: "a", value: "y"}); res.status != statusKeyExists {
+		t.Fatalf("set with another CAS responded %+v", res)
+	}
+	if res := do(binaryRequest{opcode: opReplace, opaque: 5, extras: storageExtras(0, 0), key: "missing", value: "y"}); res.status != statusKeyNotFound {
+		t.Fatalf("replace of a missing key responded %+v", res)
+	}
+
+	counter := make([]byte, 20)
+	binary.BigEndian.PutUint64(counter[0:8], 5)
+	binary.BigEndian.PutUint64(counter[8:16], 10)
+	for _, want := range []uint64{10, 15} {
+		res := do(binaryRequest{opcode: opIncrement, opaque: 6, extras: counter, key: "n"})
+		if res.status != statusOK || binary.BigEndian.Uint64([]byte(res.value)) != want {
+			t.Fatalf("increment responded %+v, want %d", res, want)
+		}
+	}
+
+	// an item already expired is not visible
+	if res := do(binaryRequest{opcode: opSet, opaque: 7, extras: storageExtras(0, -1), key: "a", value: "z"}); res.status != statusOK {
+		t.Fatalf("set of an expired item responded %+v", res)
+	}
+	if res := do(binaryRequest{opcode: opGet, opaque: 8, key: "a"}); res.status != statusKeyNotFound {
+		t.Fatalf("get of the expired item responded %+v", res)
+	}
+
+	// the quiet requests only respond on failure, the noop ends the batch
+	for _, request := range []binaryRequest{
+		{opcode: opSetQ, opaque: 9, extras: storageExtras(0, 0), key: "b", value: "1"},
+		{opcode: opGetQ, opaque: 10, key: "missing"},
+		{opcode: opAddQ, opaque: 11, extras: storageExtras(0, 0), key: "b", value: "2"},
+		{opcode: opNoop, opaque: 12},
+	} {
+		request.write(conn)
+	}
+	for _, want := range []struct {
+		opaque uint32
+		status uint16
+	}{{11, statusKeyExists}, {12, statusOK}} {
+		res, err := readResponse(reader)
+		if err != nil || res.opaque != want.opaque || res.status != want.status {
+			t.Fatalf("the batch of quiet requests responded %+v: %v, want status 0x%04x for request %d", res, err, want.status, want.opaque)
+		}
+	}
+	if res := do(binaryRequest{opcode: 0x7f, opaque: 13, key: "a"}); res.status != statusUnknownCommand {
+		t.Fatalf("an unknown opcode responded %+v", res)
+	}
+}
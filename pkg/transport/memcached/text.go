@@ -0,0 +1,265 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	repository "github.com/zelta-7/cache/pkg/repository/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// storageConditions maps the text storage commands to the condition of the write
+var storageConditions = map[string]repository.Condition{
+	"set":     repository.Always,
+	"add":     repository.IfAbsent,
+	"replace": repository.IfPresent,
+	"cas":     repository.IfVersion,
+}
+
+// serveText runs the text protocol until the client quits or the connection fails
+func (s *server) serveText(ctx context.Context, reader *bufio.Reader, writer *bufio.Writer) error {
+	for {
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			writer.WriteString("CLIENT_ERROR line too long\r\n")
+			return writer.Flush()
+		}
+		if err != nil {
+			return err
+		}
+
+		fields := strings.Fields(string(line))
+		if len(fields) > 0 {
+			if fields[0] == "quit" {
+				return writer.Flush()
+			}
+			if err := s.runText(ctx, reader, writer, fields); err != nil {
+				writer.Flush()
+				return err
+			}
+		}
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// runText runs a single command, an error is only returned when the connection can not be used anymore
+func (s *server) runText(ctx context.Context, reader *bufio.Reader, writer *bufio.Writer, fields []string) error {
+	command, args := fields[0], fields[1:]
+	noreply := len(args) > 0 && args[len(args)-1] == "noreply"
+	if noreply {
+		args = args[:len(args)-1]
+	}
+	reply := func(s string) {
+		if !noreply {
+			writer.WriteString(s + "\r\n")
+		}
+	}
+
+	switch command {
+	case "delete", "incr", "decr", "touch", "flush_all":
+		if s.namespaces.ReadOnly() {
+			reply("SERVER_ERROR " + namespaceservice.ErrReadOnly.Error())
+			return nil
+		}
+	}
+	// the keys owned by another cluster node are served by that node
+	switch command {
+	case "get", "gets", "delete", "incr", "decr", "touch":
+		keys := args
+		if command != "get" && command != "gets" && len(args) > 0 {
+			keys = args[:1]
+		}
+		if err := s.namespaces.Route(s.namespace, keys...); err != nil {
+			reply("SERVER_ERROR " + err.Error())
+			return nil
+		}
+	}
+
+	switch command {
+	case "get", "gets":
+		if len(args) == 0 {
+			writer.WriteString("ERROR\r\n")
+			return nil
+		}
+		// the entries are read like any other read so that their accesses are counted
+		entries, err := s.cache().GetListofValues(ctx, args)
+		if err != nil {
+			reply("SERVER_ERROR " + err.Error())
+			return nil
+		}
+		for _, entry := range entries {
+			writer.WriteString("VALUE " + entry.Key + " " + strconv.FormatUint(uint64(entry.Flags), 10) + " " + strconv.Itoa(len(entry.Value)))
+			if command == "gets" {
+				writer.WriteString(" " + strconv.FormatUint(entry.Version, 10))
+			}
+			writer.WriteString("\r\n" + entry.Value + "\r\n")
+		}
+		writer.WriteString("END\r\n")
+
+	case "set", "add", "replace", "cas":
+		return s.runTextStorage(ctx, reader, reply, command, args)
+
+	case "delete":
+		if len(args) < 1 || len(args) > 2 || (len(args) == 2 && args[1] != "0") {
+			reply("CLIENT_ERROR bad command line format")
+			return nil
+		}
+		deleted, err := s.cache().Delete(ctx, args[0])
+		switch {
+		case err != nil:
+			reply("SERVER_ERROR " + err.Error())
+		case deleted:
+			reply("DELETED")
+		default:
+			reply("NOT_FOUND")
+		}
+
+	case "incr", "decr":
+		if len(args) != 2 || !validKey(args[0]) {
+			reply("CLIENT_ERROR bad command line format")
+			return nil
+		}
+		delta, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			reply("CLIENT_ERROR invalid numeric delta argument")
+			return nil
+		}
+		value, err := s.incr(ctx, args[0], delta, command == "decr")
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			reply("NOT_FOUND")
+		case err != nil:
+			reply("CLIENT_ERROR " + err.Error())
+		default:
+			reply(strconv.FormatUint(value, 10))
+		}
+
+	case "touch":
+		if len(args) != 2 || !validKey(args[0]) {
+			reply("CLIENT_ERROR bad command line format")
+			return nil
+		}
+		exptime, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			reply("CLIENT_ERROR invalid exptime argument")
+			return nil
+		}
+		touched, err := s.touch(ctx, args[0], exptime)
+		switch {
+		case err != nil:
+			reply("SERVER_ERROR " + err.Error())
+		case touched:
+			reply("TOUCHED")
+		default:
+			reply("NOT_FOUND")
+		}
+
+	case "flush_all":
+		var delay int64
+		if len(args) > 0 {
+			var err error
+			if delay, err = strconv.ParseInt(args[0], 10, 64); err != nil || len(args) > 1 {
+				reply("CLIENT_ERROR bad command line format")
+				return nil
+			}
+		}
+		s.flush(ctx, delay)
+		reply("OK")
+
+	case "version":
+		writer.WriteString("VERSION " + version + "\r\n")
+
+	case "verbosity":
+		reply("OK")
+
+	default:
+		writer.WriteString("ERROR\r\n")
+	}
+	return nil
+}
+
+// runTextStorage reads the data block of a storage command and writes the item
+func (s *server) runTextStorage(ctx context.Context, reader *bufio.Reader, reply func(string), command string, args []string) error {
+	expected := 4
+	if command == "cas" {
+		expected = 5
+	}
+	if len(args) != expected {
+		reply("CLIENT_ERROR bad command line format")
+		return nil
+	}
+	flags, flagsErr := strconv.ParseUint(args[1], 10, 32)
+	exptime, exptimeErr := strconv.ParseInt(args[2], 10, 64)
+	length, lengthErr := strconv.Atoi(args[3])
+	if flagsErr != nil || exptimeErr != nil || lengthErr != nil || length < 0 || !validKey(args[0]) {
+		reply("CLIENT_ERROR bad command line format")
+		return errors.New("bad storage command line")
+	}
+	var casUnique uint64
+	if command == "cas" {
+		var err error
+		if casUnique, err = strconv.ParseUint(args[4], 10, 64); err != nil {
+			reply("CLIENT_ERROR bad command line format")
+			return errors.New("bad storage command line")
+		}
+	}
+
+	if length > maxValueLength {
+		if _, err := reader.Discard(length + 2); err != nil {
+			return err
+		}
+		reply("SERVER_ERROR object too large for cache")
+		return nil
+	}
+	data := make([]byte, length+2)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+	if data[length] != '\r' || data[length+1] != '\n' {
+		reply("CLIENT_ERROR bad data chunk")
+		return errors.New("bad data chunk")
+	}
+
+	if s.namespaces.ReadOnly() {
+		reply("SERVER_ERROR " + namespaceservice.ErrReadOnly.Error())
+		return nil
+	}
+	if err := s.namespaces.Route(s.namespace, args[0]); err != nil {
+		reply("SERVER_ERROR " + err.Error())
+		return nil
+	}
+	entry := repository.CacheEntry{Key: args[0], Value: string(data[:length]), Flags: uint32(flags), Version: casUnique}
+	err := s.store(ctx, entry, exptime, storageConditions[command])
+	switch {
+	case err == nil:
+		reply("STORED")
+	case errors.Is(err, repository.ErrVersionMismatch):
+		reply("EXISTS")
+	case errors.Is(err, repository.ErrNotFound) && command == "cas":
+		reply("NOT_FOUND")
+	default:
+		reply("NOT_STORED")
+	}
+	return nil
+}
+
+// validKey reports whether key can be used by a memcached client
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"github.com/gin-gonic/gin"
+	hotkeysservice "github.com/zelta-7/cache/pkg/service/hotkeys"
+	metricsservice "github.com/zelta-7/cache/pkg/service/metrics"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// metricsContentType is the content type of the Prometheus text format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// NewMetricsMiddleware returns a gin middleware recording the latency of
+// every request by route, the requests matching no route are recorded
+// under the route "unmatched"
+func NewMetricsMiddleware(metrics metricsservice.MetricsServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := metrics.StartRequest()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(c.Request.Method, route, c.Writer.Status())
+	}
+}
+
+// RegisterMetrics serves the metrics, the sizes of the namespaces and the
+// hot keys, unless hotKeys is nil, on /metrics in the Prometheus text format
+func RegisterMetrics(router gin.IRouter, namespaces namespaceservice.NamespaceServiceInterface, metrics metricsservice.MetricsServiceInterface, hotKeys hotkeysservice.HotKeysServiceInterface) {
+	router.GET("/metrics", func(c *gin.Context) {
+		var gauges []metricsservice.NamespaceGauges
+		for _, namespace := range namespaces.List() {
+			gauges = append(gauges, metricsservice.NamespaceGauges{
+				Namespace:    namespace.Name,
+				MapEntries:   namespace.Map.Len(),
+				MapBytes:     namespace.Map.Bytes(),
+				QueueEntries: namespace.Queue.Len(),
+				QueueBytes:   namespace.Queue.Bytes(),
+			})
+		}
+		var hot []metricsservice.HotKeyGauge
+		if hotKeys != nil {
+			for _, key := range hotKeys.Top(0) {
+				hot = append(hot, metricsservice.HotKeyGauge{Namespace: key.Namespace, Type: key.Type, Key: key.Key, Count: key.Count})
+			}
+		}
+		c.Header("Content-Type", metricsContentType)
+		if err := metrics.Write(c.Writer, gauges, hot); err != nil {
+			klog.V(2).InfoS("Error writing the metrics", "err", err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	monitorservice "github.com/zelta-7/cache/pkg/service/monitor"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// RegisterMonitor streams every operation received by the maps and the
+// queues as Server-Sent Events on /monitor, like the MONITOR command of
+// Redis. The namespace and prefix query parameters filter the operations
+// and redact=true removes their values. The operations a client is too slow
+// to receive are dropped and counted in a dropped event.
+func RegisterMonitor(router gin.IRouter, monitor monitorservice.MonitorServiceInterface) {
+	router.GET("/monitor", func(c *gin.Context) {
+		filter := monitorservice.Filter{Namespace: c.Query("namespace"), Prefix: c.Query("prefix")}
+		if filter.Namespace != "" {
+			if err := namespaceservice.ValidateName(filter.Namespace); err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+				return
+			}
+		}
+		if redact := c.Query("redact"); redact != "" {
+			var err error
+			if filter.Redact, err = strconv.ParseBool(redact); err != nil {
+				c.JSON(http.StatusBadRequest, apiSpec.Error{Error: "redact must be true or false"})
+				return
+			}
+		}
+
+		subscription := monitor.Subscribe(filter)
+		defer subscription.Cancel()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Render(-1, sse.Event{Event: "ready", Data: "ready"})
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+		var dropped uint64
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+					return
+				}
+			case command := <-subscription.Commands:
+				if total := subscription.Dropped(); total > dropped {
+					c.Render(-1, sse.Event{Event: "dropped", Data: strconv.FormatUint(total-dropped, 10)})
+					dropped = total
+				}
+				c.Render(-1, sse.Event{Event: command.Op, Data: command})
+			}
+			c.Writer.Flush()
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	raftservice "github.com/zelta-7/cache/pkg/service/raft"
+)
+
+// RegisterRaft serves the messages exchanged by the raft nodes, the votes,
+// the entries and snapshots of the leader, and the reads and writes
+// forwarded to the leader by the other nodes
+func RegisterRaft(router gin.IRouter, raft raftservice.RaftServiceInterface) {
+	router.POST(raftservice.VotePath, func(c *gin.Context) {
+		var request raftservice.VoteRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, raft.RequestVote(request))
+	})
+
+	router.POST(raftservice.AppendPath, func(c *gin.Context) {
+		var request raftservice.AppendRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, raft.AppendEntries(request))
+	})
+
+	router.POST(raftservice.SnapshotPath, func(c *gin.Context) {
+		var request raftservice.SnapshotRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, raft.InstallSnapshot(request))
+	})
+
+	router.POST(raftservice.ReadIndexPath, func(c *gin.Context) {
+		index, err := raft.ReadIndex(c.Request.Context())
+		if err != nil {
+			raftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, raftservice.ReadIndexResponse{Index: index})
+	})
+
+	router.POST(raftservice.ExecutePath, func(c *gin.Context) {
+		var operation raftservice.Operation
+		if err := c.ShouldBindJSON(&operation); err != nil {
+			c.JSON(http.StatusBadRequest, apiSpec.Error{Error: err.Error()})
+			return
+		}
+		result, err := raft.Execute(c.Request.Context(), operation)
+		if err != nil {
+			raftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+}
+
+// raftError answers a message the node could not handle
+func raftError(c *gin.Context, err error) {
+	status := http.StatusServiceUnavailable
+	if errors.Is(err, raftservice.ErrNotLeader) {
+		status = raftservice.StatusNotLeader
+	}
+	c.JSON(status, apiSpec.Error{Error: err.Error()})
+}
@@ -0,0 +1,25 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// NewReadOnlyMiddleware returns a gin middleware that rejects the requests
+// other than GET, HEAD and OPTIONS with a 403 while namespaces are read-only
+func NewReadOnlyMiddleware(namespaces namespaceservice.NamespaceServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if namespaces.ReadOnly() {
+				c.AbortWithStatusJSON(http.StatusForbidden, apiSpec.Error{Error: namespaceservice.ErrReadOnly.Error()})
+				return
+			}
+		}
+		c.Next()
+	}
+}
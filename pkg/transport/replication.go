@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	replicationservice "github.com/zelta-7/cache/pkg/service/replication"
+	"k8s.io/klog/v2"
+)
+
+// RegisterReplication serves the snapshot loaded by the followers on
+// /replication/snapshot as newline delimited JSON, the first line holds the
+// token resuming the change stream after the snapshot and the entries follow
+func RegisterReplication(router gin.IRouter, replication replicationservice.ReplicationServiceInterface) {
+	router.GET("/replication/snapshot", func(c *gin.Context) {
+		snapshot := replication.Snapshot(c.Request.Context())
+		klog.InfoS("Sending snapshot", "remote", c.Request.RemoteAddr, "sequence", snapshot.Sequence, "entries", len(snapshot.Items))
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Cache-Control", "no-cache")
+		c.Status(http.StatusOK)
+		encoder := json.NewEncoder(c.Writer)
+		if err := encoder.Encode(replicationservice.SnapshotHeader{Token: snapshot.Token, Sequence: snapshot.Sequence, Namespaces: snapshot.Namespaces}); err != nil {
+			return
+		}
+		for _, item := range snapshot.Items {
+			if err := encoder.Encode(item); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	changeservice "github.com/zelta-7/cache/pkg/service/changes"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	replicationservice "github.com/zelta-7/cache/pkg/service/replication"
+)
+
+// replicationNode is a leader or a follower serving the HTTP API on a local
+// port. The nodes run in the test process, cmd/main_test.go runs them as
+// separate processes.
+type replicationNode struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	server     *httptest.Server
+	cancel     context.CancelFunc
+}
+
+// startReplicationNode starts a leader if leader is empty and otherwise a follower of leader
+func startReplicationNode(t *testing.T, leader string) *replicationNode {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	changes, err := changeservice.NewChangeService(changeservice.Options{HistorySize: 100})
+	if err != nil {
+		t.Fatalf("creating the change log: %v", err)
+	}
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{Changes: changes})
+	replication := replicationservice.NewReplicationService(namespaces, changes, leader)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	RegisterChanges(router, changes)
+	RegisterReplication(router, replication)
+	handler := NewCacheHandler(namespaces, HandlerOptions{Replication: replication})
+	apiSpec.RegisterHandlers(router, apiSpec.NewStrictHandler(handler, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go replication.Run(ctx)
+	return &replicationNode{namespaces: namespaces, server: httptest.NewServer(router), cancel: cancel}
+}
+
+func (n *replicationNode) stop() {
+	n.cancel()
+	n.server.Close()
+}
+
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal(message)
+}
+
+// queue returns the key=value pairs of the queue of the default namespace in queue order
+func (n *replicationNode) queue() string {
+	var entries []string
+	for _, entry := range n.namespaces.Get("").Queue.All(context.Background()) {
+		entries = append(entries, entry.Key+"="+entry.Value)
+	}
+	return fmt.Sprint(entries)
+}
+
+func TestFollowerReplaysTheChangesOfTheLeader(t *testing.T) {
+	leader := startReplicationNode(t, "")
+	defer leader.stop()
+
+	ctx := context.Background()
+	leaderMap := leader.namespaces.Get("").Map
+	if _, err := leaderMap.Set(ctx, "before", "snapshot"); err != nil {
+		t.Fatalf("setting a key on the leader: %v", err)
+	}
+
+	follower := startReplicationNode(t, leader.server.URL)
+	defer follower.stop()
+	// loading the snapshot replaces the namespaces of the follower
+	followerMap := func() mapservice.MapServiceInterface {
+		return follower.namespaces.Get("").Map
+	}
+	eventually(t, "the follower did not load the snapshot of the leader", func() bool {
+		value, ok, _ := followerMap().Get(ctx, "before")
+		return ok && value == "snapshot"
+	})
+
+	if _, err := leaderMap.SetCacheTimetoLive(ctx, "after", "streamed", 60); err != nil {
+		t.Fatalf("setting a key on the leader: %v", err)
+	}
+	if _, err := leaderMap.Delete(ctx, "before"); err != nil {
+		t.Fatalf("deleting a key on the leader: %v", err)
+	}
+	eventually(t, "the follower did not apply the changes streamed by the leader", func() bool {
+		_, found, _ := followerMap().Get(ctx, "before")
+		value, ok, _ := followerMap().Get(ctx, "after")
+		ttl, _, _ := followerMap().TimeToLive(ctx, "after")
+		return !found && ok && value == "streamed" && ttl > 0
+	})
+}
+
+func TestFollowerKeepsTheExpirationAndTheFlagsOfTheLeader(t *testing.T) {
+	leader := startReplicationNode(t, "")
+	defer leader.stop()
+
+	ctx := context.Background()
+	leaderNamespace := leader.namespaces.Get("")
+	snapshotted := mapRepository.CacheEntry{Key: "snapshotted", Value: "1", TTL: time.Minute, Flags: 7}
+	if _, err := leaderNamespace.Map.Store(ctx, snapshotted, mapRepository.Always); err != nil {
+		t.Fatalf("storing a key on the leader: %v", err)
+	}
+	leaderNamespace.Queue.SetCacheTimetoLive(ctx, "queued", "1", 60)
+
+	follower := startReplicationNode(t, leader.server.URL)
+	defer follower.stop()
+	eventually(t, "the follower did not load the snapshot of the leader", func() bool {
+		_, ok, _ := follower.namespaces.Get("").Map.Get(ctx, "snapshotted")
+		return ok
+	})
+
+	streamed := mapRepository.CacheEntry{Key: "streamed", Value: "2", TTL: time.Minute, Flags: 9}
+	if _, err := leaderNamespace.Map.Store(ctx, streamed, mapRepository.Always); err != nil {
+		t.Fatalf("storing a key on the leader: %v", err)
+	}
+	if _, err := leaderNamespace.Map.Expire(ctx, "snapshotted", 120); err != nil {
+		t.Fatalf("expiring a key on the leader: %v", err)
+	}
+	leaderNamespace.Queue.SetCacheTimetoLive(ctx, "pushed", "2", 60)
+
+	entry := func(namespace *namespaceservice.Namespace, key string) mapRepository.CacheEntry {
+		entry, _, _ := namespace.Map.GetEntry(ctx, key)
+		return entry
+	}
+	eventually(t, "the follower did not apply the changes streamed by the leader", func() bool {
+		followerNamespace := follower.namespaces.Get("")
+		for _, key := range []string{"snapshotted", "streamed"} {
+			want, got := entry(leaderNamespace, key), entry(followerNamespace, key)
+			if got.Value != want.Value || !got.ExpiresAt.Equal(want.ExpiresAt) || got.Flags != want.Flags {
+				return false
+			}
+		}
+		want, got := leaderNamespace.Queue.All(ctx), followerNamespace.Queue.All(ctx)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if got[i].Key != want[i].Key || !got[i].ExpiresAt.Equal(want[i].ExpiresAt) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestFollowerRemovesTheEntryPoppedByTheLeader(t *testing.T) {
+	leader := startReplicationNode(t, "")
+	defer leader.stop()
+	follower := startReplicationNode(t, leader.server.URL)
+	defer follower.stop()
+
+	ctx := context.Background()
+	leaderQueue := leader.namespaces.Get("").Queue
+	leaderQueue.Set(ctx, "a", "1")
+	leaderQueue.Set(ctx, "b", "2")
+	leaderQueue.Set(ctx, "c", "3")
+	eventually(t, "the follower did not replicate the pushes", func() bool {
+		return follower.queue() == "[a=1 b=2 c=3]"
+	})
+
+	// the follower still holds at its front an entry the leader no longer
+	// has, as it does when the leader expired it first
+	follower.namespaces.Get("").Queue.Prepend(ctx, "stale", "0")
+	if entry, ok := leaderQueue.Pop(ctx); !ok || entry.Key != "a" {
+		t.Fatalf("leader popped %+v, %v, want a", entry, ok)
+	}
+	eventually(t, "the follower did not remove the entry popped by the leader", func() bool {
+		return follower.queue() == "[stale=0 b=2 c=3]"
+	})
+	if got := leader.queue(); got != "[b=2 c=3]" {
+		t.Errorf("leader queue is %s, want [b=2 c=3]", got)
+	}
+}
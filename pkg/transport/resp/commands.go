@@ -0,0 +1,551 @@
+package resp
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+)
+
+// command is a Redis command, a positive arity is the exact number of
+// arguments including the command name and a negative one the minimum
+type command struct {
+	arity int
+	run   func(ctx context.Context, s *server, c *conn, args []string)
+}
+
+// writeCommands are rejected while the namespaces are read-only
+var writeCommands = map[string]bool{
+	"SET":     true,
+	"DEL":     true,
+	"EXPIRE":  true,
+	"MSET":    true,
+	"FLUSHDB": true,
+	"LPUSH":   true,
+	"RPUSH":   true,
+	"LPOP":    true,
+}
+
+// mapKeys returns the map keys named by the arguments of a command, the
+// commands naming keys owned by another cluster node are rejected with
+// WRONGNODE while KEYS, SCAN, DBSIZE and FLUSHDB only see this node
+var mapKeys = map[string]func(args []string) []string{
+	"GET":    firstArgument,
+	"SET":    firstArgument,
+	"DEL":    allArguments,
+	"EXISTS": allArguments,
+	"EXPIRE": firstArgument,
+	"TTL":    firstArgument,
+	"PTTL":   firstArgument,
+	"MGET":   allArguments,
+	"MSET":   evenArguments,
+}
+
+func firstArgument(args []string) []string {
+	return args[:1]
+}
+
+func allArguments(args []string) []string {
+	return args
+}
+
+// evenArguments returns the keys of key value pairs
+func evenArguments(args []string) []string {
+	keys := make([]string, 0, (len(args)+1)/2)
+	for i := 0; i < len(args); i += 2 {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+var commands = map[string]command{
+	"PING":    {-1, ping},
+	"ECHO":    {2, echo},
+	"QUIT":    {-1, quit},
+	"HELLO":   {-1, hello},
+	"SELECT":  {2, selectNamespace},
+	"CLIENT":  {-2, client},
+	"COMMAND": {-1, commandInfo},
+	"GET":     {2, get},
+	"SET":     {-3, set},
+	"DEL":     {-2, del},
+	"EXISTS":  {-2, exists},
+	"EXPIRE":  {3, expire},
+	"TTL":     {2, ttl},
+	"PTTL":    {2, pttl},
+	"MGET":    {-2, mget},
+	"MSET":    {-3, mset},
+	"KEYS":    {2, keys},
+	"SCAN":    {-2, scan},
+	"DBSIZE":  {1, dbsize},
+	"FLUSHDB": {-1, flushdb},
+	"LPUSH":   {-3, lpush},
+	"RPUSH":   {-3, rpush},
+	"LPOP":    {-2, lpop},
+	"LLEN":    {2, llen},
+	"LRANGE":  {4, lrange},
+}
+
+func ping(ctx context.Context, s *server, c *conn, args []string) {
+	if len(args) > 0 {
+		c.writer.bulk(args[0])
+		return
+	}
+	c.writer.simple("PONG")
+}
+
+func echo(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.bulk(args[0])
+}
+
+func quit(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.simple("OK")
+	c.quit = true
+}
+
+// hello switches the protocol version, authentication is not supported
+func hello(ctx context.Context, s *server, c *conn, args []string) {
+	if len(args) > 0 {
+		proto, err := strconv.Atoi(args[0])
+		if err != nil || proto < 2 || proto > 3 {
+			c.writer.errorf("NOPROTO unsupported protocol version")
+			return
+		}
+		for i := 1; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "AUTH":
+				c.writer.errorf("ERR authentication is not supported")
+				return
+			case "SETNAME":
+				i++
+			}
+		}
+		c.writer.proto = proto
+	}
+
+	c.writer.mapHeader(7)
+	c.writer.bulk("server")
+	c.writer.bulk("cache")
+	c.writer.bulk("version")
+	c.writer.bulk("1.0")
+	c.writer.bulk("proto")
+	c.writer.integer(int64(c.writer.proto))
+	c.writer.bulk("id")
+	c.writer.integer(0)
+	c.writer.bulk("mode")
+	c.writer.bulk("standalone")
+	c.writer.bulk("role")
+	c.writer.bulk("master")
+	c.writer.bulk("modules")
+	c.writer.array(0)
+}
+
+// selectNamespace switches the namespace of the map commands, database 0 is the default namespace
+func selectNamespace(ctx context.Context, s *server, c *conn, args []string) {
+	name := args[0]
+	if name == "0" {
+		name = namespaceservice.DefaultNamespace
+	}
+	if err := namespaceservice.ValidateName(name); err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	c.namespace = name
+	c.writer.simple("OK")
+}
+
+// client accepts the CLIENT subcommands sent by client libraries on connect
+func client(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.simple("OK")
+}
+
+// commandInfo replies with no command documentation, which redis-cli accepts
+func commandInfo(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.array(0)
+}
+
+func get(ctx context.Context, s *server, c *conn, args []string) {
+	value, ok, err := s.namespaces.Get(c.namespace).Map.Get(ctx, args[0])
+	if err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	if !ok {
+		c.writer.null()
+		return
+	}
+	c.writer.bulk(value)
+}
+
+// set supports the EX and PX options, PX is rounded up to the second
+func set(ctx context.Context, s *server, c *conn, args []string) {
+	key, value := args[0], args[1]
+	ttl := 0
+	for i := 2; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		if (option != "EX" && option != "PX") || i+1 == len(args) {
+			c.writer.errorf("ERR syntax error")
+			return
+		}
+		i++
+		n, err := strconv.Atoi(args[i])
+		if err != nil || n <= 0 {
+			c.writer.errorf("ERR invalid expire time in 'set' command")
+			return
+		}
+		ttl = n
+		if option == "PX" {
+			ttl = (n + 999) / 1000
+		}
+	}
+	if _, err := s.namespaces.Get(c.namespace).Map.SetCacheTimetoLive(ctx, key, value, ttl); err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	c.writer.simple("OK")
+}
+
+// del removes the map keys and the lists named by the arguments
+func del(ctx context.Context, s *server, c *conn, args []string) {
+	namespace := s.namespaces.Get(c.namespace)
+	deleted := 0
+	for _, key := range args {
+		ok, err := namespace.Map.Delete(ctx, key)
+		if err != nil {
+			c.writer.errorf("ERR %s", err)
+			return
+		}
+		if namespace.Queue.Delete(ctx, key) {
+			ok = true
+		}
+		if ok {
+			deleted++
+		}
+	}
+	c.writer.integer(int64(deleted))
+}
+
+// exists counts the arguments naming a map key or a list
+func exists(ctx context.Context, s *server, c *conn, args []string) {
+	namespace := s.namespaces.Get(c.namespace)
+	found := 0
+	for _, key := range args {
+		_, ok, err := namespace.Map.Get(ctx, key)
+		if err != nil {
+			c.writer.errorf("ERR %s", err)
+			return
+		}
+		if ok || len(listEntries(ctx, namespace.Queue, key)) > 0 {
+			found++
+		}
+	}
+	c.writer.integer(int64(found))
+}
+
+// expire deletes the key when seconds is not positive, like Redis does
+func expire(ctx context.Context, s *server, c *conn, args []string) {
+	seconds, err := strconv.Atoi(args[1])
+	if err != nil {
+		c.writer.errorf("ERR value is not an integer or out of range")
+		return
+	}
+	mapService := s.namespaces.Get(c.namespace).Map
+	var ok bool
+	if seconds <= 0 {
+		ok, err = mapService.Delete(ctx, args[0])
+	} else {
+		ok, err = mapService.Expire(ctx, args[0], seconds)
+	}
+	if err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	if ok {
+		c.writer.integer(1)
+		return
+	}
+	c.writer.integer(0)
+}
+
+func ttl(ctx context.Context, s *server, c *conn, args []string) {
+	remainingTTL(ctx, s, c, args[0], time.Second)
+}
+
+func pttl(ctx context.Context, s *server, c *conn, args []string) {
+	remainingTTL(ctx, s, c, args[0], time.Millisecond)
+}
+
+// remainingTTL follows the Redis convention, -2 for a missing key and -1 for a key that never expires
+func remainingTTL(ctx context.Context, s *server, c *conn, key string, unit time.Duration) {
+	remaining, ok, err := s.namespaces.Get(c.namespace).Map.TimeToLive(ctx, key)
+	switch {
+	case err != nil:
+		c.writer.errorf("ERR %s", err)
+	case !ok:
+		c.writer.integer(-2)
+	case remaining == 0:
+		c.writer.integer(-1)
+	default:
+		c.writer.integer(int64((remaining + unit - 1) / unit))
+	}
+}
+
+// mget reads every key before replying so that a failed read replies with an error only
+func mget(ctx context.Context, s *server, c *conn, args []string) {
+	mapService := s.namespaces.Get(c.namespace).Map
+	values := make([]*string, len(args))
+	for i, key := range args {
+		value, ok, err := mapService.Get(ctx, key)
+		if err != nil {
+			c.writer.errorf("ERR %s", err)
+			return
+		}
+		if ok {
+			values[i] = &value
+		}
+	}
+	c.writer.array(len(values))
+	for _, value := range values {
+		if value != nil {
+			c.writer.bulk(*value)
+		} else {
+			c.writer.null()
+		}
+	}
+}
+
+func mset(ctx context.Context, s *server, c *conn, args []string) {
+	if len(args)%2 != 0 {
+		c.writer.errorf("ERR wrong number of arguments for 'mset' command")
+		return
+	}
+	mapService := s.namespaces.Get(c.namespace).Map
+	for i := 0; i < len(args); i += 2 {
+		if _, err := mapService.Set(ctx, args[i], args[i+1]); err != nil {
+			c.writer.errorf("ERR %s", err)
+			return
+		}
+	}
+	c.writer.simple("OK")
+}
+
+func keys(ctx context.Context, s *server, c *conn, args []string) {
+	found, err := matchingKeys(ctx, s, c, args[0])
+	if err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	c.writer.bulks(found)
+}
+
+// scan walks the keys in sorted order, the cursor is the hex encoded last
+// key walked so that the keys written or deleted between two calls do not
+// make the scan skip or repeat the other keys, 0 starts and ends the scan
+func scan(ctx context.Context, s *server, c *conn, args []string) {
+	var after string
+	if args[0] != "0" {
+		last, err := hex.DecodeString(args[0])
+		if err != nil || len(last) == 0 {
+			c.writer.errorf("ERR invalid cursor")
+			return
+		}
+		after = string(last)
+	}
+	var err error
+	pattern, count := "*", 10
+	for i := 1; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			c.writer.errorf("ERR syntax error")
+			return
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil || count < 1 {
+				c.writer.errorf("ERR syntax error")
+				return
+			}
+		default:
+			c.writer.errorf("ERR syntax error")
+			return
+		}
+	}
+
+	entries, err := s.namespaces.Get(c.namespace).Map.All(ctx)
+	if err != nil {
+		c.writer.errorf("ERR %s", err)
+		return
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	sort.Strings(keys)
+	first := sort.SearchStrings(keys, after)
+	if first < len(keys) && keys[first] == after {
+		first++
+	}
+	found := make([]string, 0, count)
+	last := first
+	for ; last < len(keys) && last < first+count; last++ {
+		if common.MatchPattern(pattern, keys[last]) {
+			found = append(found, keys[last])
+		}
+	}
+	next := "0"
+	if last < len(keys) {
+		next = hex.EncodeToString([]byte(keys[last-1]))
+	}
+
+	c.writer.array(2)
+	c.writer.bulk(next)
+	c.writer.bulks(found)
+}
+
+func matchingKeys(ctx context.Context, s *server, c *conn, pattern string) ([]string, error) {
+	entries, err := s.namespaces.Get(c.namespace).Map.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]string, 0)
+	for _, entry := range entries {
+		if common.MatchPattern(pattern, entry.Key) {
+			found = append(found, entry.Key)
+		}
+	}
+	return found, nil
+}
+
+func dbsize(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.integer(int64(s.namespaces.Get(c.namespace).Map.Len()))
+}
+
+func flushdb(ctx context.Context, s *server, c *conn, args []string) {
+	s.namespaces.Get(c.namespace).Map.Flush(ctx)
+	c.writer.simple("OK")
+}
+
+// lpush and the other list commands use the queue of the selected
+// namespace, the elements of a list are the entries whose key is the list name
+func lpush(ctx context.Context, s *server, c *conn, args []string) {
+	queue := s.namespaces.Get(c.namespace).Queue
+	for _, value := range args[1:] {
+		queue.Prepend(ctx, args[0], value)
+	}
+	c.writer.integer(int64(len(listEntries(ctx, queue, args[0]))))
+}
+
+func rpush(ctx context.Context, s *server, c *conn, args []string) {
+	queue := s.namespaces.Get(c.namespace).Queue
+	for _, value := range args[1:] {
+		queue.Set(ctx, args[0], value)
+	}
+	c.writer.integer(int64(len(listEntries(ctx, queue, args[0]))))
+}
+
+func lpop(ctx context.Context, s *server, c *conn, args []string) {
+	if len(args) > 2 {
+		c.writer.errorf("ERR wrong number of arguments for 'lpop' command")
+		return
+	}
+	queue := s.namespaces.Get(c.namespace).Queue
+
+	if len(args) == 1 {
+		value, ok := popList(ctx, queue, args[0])
+		if !ok {
+			c.writer.null()
+			return
+		}
+		c.writer.bulk(value)
+		return
+	}
+
+	count, err := strconv.Atoi(args[1])
+	if err != nil || count < 0 {
+		c.writer.errorf("ERR value is out of range, must be positive")
+		return
+	}
+	var values []string
+	for len(values) < count {
+		value, ok := popList(ctx, queue, args[0])
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		c.writer.nullArray()
+		return
+	}
+	c.writer.bulks(values)
+}
+
+func llen(ctx context.Context, s *server, c *conn, args []string) {
+	c.writer.integer(int64(len(listEntries(ctx, s.namespaces.Get(c.namespace).Queue, args[0]))))
+}
+
+func lrange(ctx context.Context, s *server, c *conn, args []string) {
+	start, err := strconv.Atoi(args[1])
+	if err != nil {
+		c.writer.errorf("ERR value is not an integer or out of range")
+		return
+	}
+	stop, err := strconv.Atoi(args[2])
+	if err != nil {
+		c.writer.errorf("ERR value is not an integer or out of range")
+		return
+	}
+
+	entries := listEntries(ctx, s.namespaces.Get(c.namespace).Queue, args[0])
+	if start < 0 {
+		start += len(entries)
+	}
+	if stop < 0 {
+		stop += len(entries)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= len(entries) {
+		stop = len(entries) - 1
+	}
+	values := make([]string, 0)
+	for i := start; i <= stop; i++ {
+		values = append(values, entries[i].Value)
+	}
+	c.writer.bulks(values)
+}
+
+// listEntries returns the elements of the list in the queue, in order
+func listEntries(ctx context.Context, queue queueservice.QueueServiceInterface, list string) []queueRepository.CacheEntry {
+	entries := make([]queueRepository.CacheEntry, 0)
+	for _, entry := range queue.All(ctx) {
+		if entry.Key == list {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// popList removes and returns the first element of the list, the first
+// entry is looked up again when another client removed it in between
+func popList(ctx context.Context, queue queueservice.QueueServiceInterface, list string) (string, bool) {
+	for {
+		entries := listEntries(ctx, queue, list)
+		if len(entries) == 0 {
+			return "", false
+		}
+		if queue.Remove(ctx, list, entries[0].Value) {
+			return entries[0].Value, true
+		}
+	}
+}
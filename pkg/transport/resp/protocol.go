@@ -0,0 +1,164 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxBulkLength bounds the size of a single argument
+	maxBulkLength = 64 << 20
+	// maxCommandLength bounds the size of the arguments of a single command
+	maxCommandLength = 128 << 20
+	// maxArguments bounds the number of arguments of a single command
+	maxArguments = 1 << 20
+	// maxLineLength bounds the size of an inline command or of the header of
+	// an array or a bulk string
+	maxLineLength = 64 << 10
+	// initialArguments is the capacity of the arguments of a command, they
+	// grow as they arrive rather than from the count sent by the client
+	initialArguments = 16
+)
+
+var errProtocol = errors.New("protocol error")
+
+// readCommand reads a command sent either as an array of bulk strings or as an inline command
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+	if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count > maxArguments {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	if count <= 0 {
+		return nil, nil
+	}
+	capacity := count
+	if capacity > initialArguments {
+		capacity = initialArguments
+	}
+	args := make([]string, 0, capacity)
+	total := 0
+	for i := 0; i < count; i++ {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%.1s'", errProtocol, line)
+		}
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 || length > maxBulkLength {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		if total += length; total > maxCommandLength {
+			return nil, fmt.Errorf("%w: command too long", errProtocol)
+		}
+		// the bulk string is buffered as it arrives, a client announcing a
+		// large length without sending it holds no memory
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, reader, int64(length)+2); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		bulk := data.Bytes()
+		if bulk[length] != '\r' || bulk[length+1] != '\n' {
+			return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", errProtocol)
+		}
+		args = append(args, string(bulk[:length]))
+	}
+	return args, nil
+}
+
+// readLine reads a line of at most maxLineLength bytes
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		if len(line)+len(fragment) > maxLineLength {
+			return "", fmt.Errorf("%w: line too long", errProtocol)
+		}
+		line = append(line, fragment...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return "", err
+		}
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// writer encodes replies in RESP2 or, once the client sent HELLO 3, in RESP3
+type writer struct {
+	*bufio.Writer
+	proto int
+}
+
+func (w *writer) simple(s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func (w *writer) errorf(format string, args ...interface{}) {
+	w.WriteString("-" + strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf(format, args...)) + "\r\n")
+}
+
+func (w *writer) integer(n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *writer) bulk(s string) {
+	w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (w *writer) null() {
+	if w.proto >= 3 {
+		w.WriteString("_\r\n")
+		return
+	}
+	w.WriteString("$-1\r\n")
+}
+
+func (w *writer) nullArray() {
+	if w.proto >= 3 {
+		w.WriteString("_\r\n")
+		return
+	}
+	w.WriteString("*-1\r\n")
+}
+
+func (w *writer) array(n int) {
+	w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// mapHeader starts a map of n pairs, RESP2 clients get a flat array instead
+func (w *writer) mapHeader(n int) {
+	if w.proto >= 3 {
+		w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.array(2 * n)
+}
+
+func (w *writer) bulks(values []string) {
+	w.array(len(values))
+	for _, value := range values {
+		w.bulk(value)
+	}
+}
@@ -0,0 +1,136 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/zelta-7/cache/common"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+// ServerInterface serves the Redis protocol on top of the cache services
+type ServerInterface interface {
+	// Serve accepts connections on listener until ctx is done
+	Serve(ctx context.Context, listener net.Listener) error
+}
+
+type server struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+}
+
+func NewServer(namespaces namespaceservice.NamespaceServiceInterface) ServerInterface {
+	return &server{
+		namespaces: namespaces,
+	}
+}
+
+// conn is the state of a single client connection
+type conn struct {
+	net.Conn
+	reader    *bufio.Reader
+	writer    *writer
+	namespace string
+	quit      bool
+}
+
+// Serve implements the Serve method of the ServerInterface
+func (s *server) Serve(ctx context.Context, listener net.Listener) error {
+	var connections sync.WaitGroup
+	defer connections.Wait()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			s.handle(ctx, netConn)
+		}()
+	}
+}
+
+// handle reads and runs the commands of a connection, replies are flushed
+// once every pipelined command that was already received has run
+func (s *server) handle(ctx context.Context, netConn net.Conn) {
+	ctx = common.WithClient(ctx, netConn.RemoteAddr().String())
+	c := &conn{
+		Conn:      netConn,
+		reader:    bufio.NewReader(netConn),
+		writer:    &writer{Writer: bufio.NewWriter(netConn), proto: 2},
+		namespace: namespaceservice.DefaultNamespace,
+	}
+	defer c.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	for !c.quit {
+		args, err := readCommand(c.reader)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				c.writer.errorf("ERR %s", err)
+				c.writer.Flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				klog.V(2).InfoS("Error reading RESP command", "remote", c.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		if len(args) > 0 {
+			s.dispatch(ctx, c, args)
+		}
+		if c.reader.Buffered() == 0 || c.quit {
+			if err := c.writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *server) dispatch(ctx context.Context, c *conn, args []string) {
+	name := strings.ToUpper(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		c.writer.errorf("ERR unknown command '%s'", args[0])
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		c.writer.errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+		return
+	}
+	if writeCommands[name] && s.namespaces.ReadOnly() {
+		c.writer.errorf("READONLY You can't write against a read only replica.")
+		return
+	}
+	if keys, ok := mapKeys[name]; ok {
+		if err := s.namespaces.Route(c.namespace, keys(args[1:])...); err != nil {
+			// MOVED names a hash slot and the RESP address of the owner,
+			// which the ring of the cluster does not have
+			c.writer.errorf("WRONGNODE %s", err)
+			return
+		}
+	}
+	cmd.run(ctx, s, c, args[1:])
+}
@@ -0,0 +1,214 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// testClient sends inline commands to a RESP server and reads its replies
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// startServer serves the namespaces on a local port and returns a connected client
+func startServer(t *testing.T, namespaces namespaceservice.NamespaceServiceInterface) *testClient {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		NewServer(namespaces).Serve(ctx, listener)
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-served
+	})
+	return &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// do sends the command and returns its reply formatted by reply
+func (c *testClient) do(command string) string {
+	c.t.Helper()
+	if _, err := fmt.Fprintf(c.conn, "%s\r\n", command); err != nil {
+		c.t.Fatalf("sending %q: %v", command, err)
+	}
+	reply, err := c.reply()
+	if err != nil {
+		c.t.Fatalf("reading the reply to %q: %v", command, err)
+	}
+	return reply
+}
+
+// reply reads a reply, arrays are formatted as [a b] and nulls as nil
+func (c *testClient) reply() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	switch line[0] {
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "nil", nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return "", err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "nil", nil
+		}
+		elements := make([]string, n)
+		for i := range elements {
+			if elements[i], err = c.reply(); err != nil {
+				return "", err
+			}
+		}
+		return "[" + strings.Join(elements, " ") + "]", nil
+	}
+	return line, nil
+}
+
+func TestMapCommands(t *testing.T) {
+	c := startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{}))
+
+	for _, step := range []struct{ command, reply string }{
+		{"PING", "+PONG"},
+		{"SET a 1", "+OK"},
+		{"SET b 2 EX 100", "+OK"},
+		{"GET a", "1"},
+		{"GET missing", "nil"},
+		{"MGET a missing b", "[1 nil 2]"},
+		{"TTL a", ":-1"},
+		{"TTL b", ":100"},
+		{"TTL missing", ":-2"},
+		{"EXISTS a b missing", ":2"},
+		{"DBSIZE", ":2"},
+		{"DEL a missing", ":1"},
+		{"KEYS *", "[b]"},
+		{"FLUSHDB", "+OK"},
+		{"DBSIZE", ":0"},
+		{"UNKNOWN", "-ERR unknown command 'UNKNOWN'"},
+	} {
+		if reply := c.do(step.command); reply != step.reply {
+			t.Fatalf("%s replied %q, want %q", step.command, reply, step.reply)
+		}
+	}
+}
+
+func TestListsAreKeysOfTheSelectedNamespace(t *testing.T) {
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	c := startServer(t, namespaces)
+
+	for _, step := range []struct{ command, reply string }{
+		{"RPUSH jobs b c", ":2"},
+		{"LPUSH jobs a", ":3"},
+		{"RPUSH other x", ":1"},
+		{"LRANGE jobs 0 -1", "[a b c]"},
+		{"LLEN jobs", ":3"},
+		{"LPOP jobs", "a"},
+		{"LPOP jobs 5", "[b c]"},
+		{"LPOP jobs", "nil"},
+		{"SELECT tenant", "+OK"},
+		{"LLEN other", ":0"},
+		{"RPUSH other y", ":1"},
+		{"EXISTS other", ":1"},
+		{"DEL other", ":1"},
+		{"LLEN other", ":0"},
+		{"SELECT 0", "+OK"},
+		{"LRANGE other 0 -1", "[x]"},
+	} {
+		if reply := c.do(step.command); reply != step.reply {
+			t.Fatalf("%s replied %q, want %q", step.command, reply, step.reply)
+		}
+	}
+	if names := namespaces.List(); len(names) != 2 {
+		t.Fatalf("the lists created %d namespaces, want the default one and the selected one", len(names))
+	}
+}
+
+func TestScanResumesAfterTheLastKey(t *testing.T) {
+	c := startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{}))
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		c.do("SET " + key + " 1")
+	}
+
+	first := c.do("SCAN 0 COUNT 2")
+	if !strings.HasSuffix(first, " [a b]]") {
+		t.Fatalf("the first SCAN replied %q, want the keys a and b", first)
+	}
+	cursor := strings.TrimPrefix(strings.TrimSuffix(first, " [a b]]"), "[")
+	// the keys written or deleted before the cursor do not shift the scan
+	c.do("DEL a")
+	c.do("SET 0 1")
+	if reply := c.do("SCAN " + cursor + " COUNT 2 MATCH *"); !strings.HasSuffix(reply, " [c d]]") {
+		t.Fatalf("the second SCAN replied %q, want the keys c and d", reply)
+	}
+	if reply := c.do("SCAN " + cursor + " COUNT 10"); reply != "[0 [c d e]]" {
+		t.Fatalf("the last SCAN replied %q, want the end of the scan", reply)
+	}
+	if reply := c.do("SCAN zz"); reply != "-ERR invalid cursor" {
+		t.Fatalf("SCAN with an invalid cursor replied %q", reply)
+	}
+}
+
+// router owns every key but remote
+type router struct{}
+
+func (router) Self() string { return "http://self" }
+
+func (router) Owner(namespace, key string) string {
+	if key == "remote" {
+		return "http://other"
+	}
+	return "http://self"
+}
+
+func TestKeysOfAnotherNodeAreRejected(t *testing.T) {
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	namespaces.SetRouter(router{})
+	c := startServer(t, namespaces)
+
+	if reply := c.do("SET local 1"); reply != "+OK" {
+		t.Fatalf("SET of a local key replied %q", reply)
+	}
+	if reply := c.do("MGET local remote"); reply != "-WRONGNODE key is owned by http://other" {
+		t.Fatalf("MGET of a remote key replied %q", reply)
+	}
+}
+
+func TestWritesAreRejectedWhileReadOnly(t *testing.T) {
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	namespaces.SetReadOnly(true)
+	c := startServer(t, namespaces)
+
+	if reply := c.do("RPUSH jobs a"); !strings.HasPrefix(reply, "-READONLY") {
+		t.Fatalf("RPUSH on a read-only node replied %q", reply)
+	}
+	if reply := c.do("GET a"); reply != "nil" {
+		t.Fatalf("GET on a read-only node replied %q", reply)
+	}
+}
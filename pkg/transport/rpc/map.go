@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/zelta-7/cache/api/grpc/cachepb"
+	"github.com/zelta-7/cache/common"
+	repository "github.com/zelta-7/cache/pkg/repository/map"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mapServer struct {
+	cachepb.UnimplementedMapServiceServer
+	namespaces namespaceservice.NamespaceServiceInterface
+}
+
+func NewMapServer(namespaces namespaceservice.NamespaceServiceInterface) cachepb.MapServiceServer {
+	return &mapServer{
+		namespaces: namespaces,
+	}
+}
+
+// mapService returns the map of the namespace, a FailedPrecondition naming
+// the owner is returned if one of the keys is owned by another cluster node
+func (m *mapServer) mapService(name string, keys ...string) (mapservice.MapServiceInterface, error) {
+	ns, err := namespace(m.namespaces, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.namespaces.Route(ns.Name, keys...); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return ns.Map, nil
+}
+
+// Set implements the Set method of the MapServiceServer
+func (m *mapServer) Set(ctx context.Context, request *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	if request.TimeToLive < 0 {
+		return nil, status.Error(codes.InvalidArgument, "time to live must not be negative")
+	}
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	key, err := service.SetCacheTimetoLive(ctx, request.Key, request.Value, int(request.TimeToLive))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &cachepb.SetResponse{Key: key, Status: "value set sucessfully"}, nil
+}
+
+// Get implements the Get method of the MapServiceServer
+func (m *mapServer) Get(ctx context.Context, request *cachepb.GetRequest) (*cachepb.Entry, error) {
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok, err := service.Get(ctx, request.Key)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.Entry{Key: request.Key, Value: value}, nil
+}
+
+// GetMany implements the GetMany method of the MapServiceServer
+func (m *mapServer) GetMany(ctx context.Context, request *cachepb.GetManyRequest) (*cachepb.GetManyResponse, error) {
+	service, err := m.mapService(request.Namespace, request.Keys...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := service.GetListofValues(ctx, request.Keys)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	response := &cachepb.GetManyResponse{}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, &cachepb.Entry{Key: entry.Key, Value: entry.Value})
+	}
+	return response, nil
+}
+
+// Update implements the Update method of the MapServiceServer
+func (m *mapServer) Update(ctx context.Context, request *cachepb.UpdateRequest) (*cachepb.SetResponse, error) {
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	found, err := service.UpdateCacheEntry(ctx, request.Key, request.Value)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !found {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.SetResponse{Key: request.Key, Status: "value updated sucessfully"}, nil
+}
+
+// Delete implements the Delete method of the MapServiceServer
+func (m *mapServer) Delete(ctx context.Context, request *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := service.Delete(ctx, request.Key)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !deleted {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.DeleteResponse{Key: request.Key}, nil
+}
+
+// GetTimeToLive implements the GetTimeToLive method of the MapServiceServer
+func (m *mapServer) GetTimeToLive(ctx context.Context, request *cachepb.GetTimeToLiveRequest) (*cachepb.TimeToLive, error) {
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	ttl, ok, err := service.TimeToLive(ctx, request.Key)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.TimeToLive{Key: request.Key, TimeToLive: ttlSeconds(ttl)}, nil
+}
+
+// SetTimeToLive implements the SetTimeToLive method of the MapServiceServer
+func (m *mapServer) SetTimeToLive(ctx context.Context, request *cachepb.SetTimeToLiveRequest) (*cachepb.TimeToLive, error) {
+	if request.TimeToLive < 0 {
+		return nil, status.Error(codes.InvalidArgument, "time to live must not be negative")
+	}
+	service, err := m.mapService(request.Namespace, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	found, err := service.Expire(ctx, request.Key, int(request.TimeToLive))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !found {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	ttl, _, err := service.TimeToLive(ctx, request.Key)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &cachepb.TimeToLive{Key: request.Key, TimeToLive: ttlSeconds(ttl)}, nil
+}
+
+// List implements the List method of the MapServiceServer
+func (m *mapServer) List(request *cachepb.ListRequest, stream cachepb.MapService_ListServer) error {
+	if request.N < 0 {
+		return status.Error(codes.InvalidArgument, "n must not be negative")
+	}
+	service, err := m.mapService(request.Namespace)
+	if err != nil {
+		return err
+	}
+	var entries []repository.CacheEntry
+	switch request.SortBy {
+	case cachepb.SortBy_SORT_BY_VALUE:
+		entries, err = service.GetSortedEntryList(stream.Context(), mapservice.SortByValue, int(request.N))
+	default:
+		entries, err = service.GetEntryList(stream.Context(), int(request.N))
+	}
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return sendMapEntries(stream, entries)
+}
+
+// Scan implements the Scan method of the MapServiceServer
+func (m *mapServer) Scan(request *cachepb.ScanRequest, stream cachepb.MapService_ScanServer) error {
+	service, err := m.mapService(request.Namespace)
+	if err != nil {
+		return err
+	}
+	entries, err := service.All(stream.Context())
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	matching := entries[:0]
+	for _, entry := range entries {
+		if request.Pattern == "" || common.MatchPattern(request.Pattern, entry.Key) {
+			matching = append(matching, entry)
+		}
+	}
+	return sendMapEntries(stream, matching)
+}
+
+// sendMapEntries sends the entries on a server stream, it stops early if the client went away
+func sendMapEntries(stream interface{ Send(*cachepb.Entry) error }, entries []repository.CacheEntry) error {
+	for _, entry := range entries {
+		if err := stream.Send(&cachepb.Entry{Key: entry.Key, Value: entry.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/zelta-7/cache/api/grpc/cachepb"
+	repository "github.com/zelta-7/cache/pkg/repository/queue"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// consumePollInterval is how often Consume checks an empty queue for new entries
+const consumePollInterval = 100 * time.Millisecond
+
+type queueServer struct {
+	cachepb.UnimplementedQueueServiceServer
+	namespaces namespaceservice.NamespaceServiceInterface
+}
+
+func NewQueueServer(namespaces namespaceservice.NamespaceServiceInterface) cachepb.QueueServiceServer {
+	return &queueServer{
+		namespaces: namespaces,
+	}
+}
+
+func (q *queueServer) queueService(name string) (queueservice.QueueServiceInterface, error) {
+	ns, err := namespace(q.namespaces, name)
+	if err != nil {
+		return nil, err
+	}
+	return ns.Queue, nil
+}
+
+// Push implements the Push method of the QueueServiceServer
+func (q *queueServer) Push(ctx context.Context, request *cachepb.PushRequest) (*cachepb.SetResponse, error) {
+	if request.TimeToLive < 0 {
+		return nil, status.Error(codes.InvalidArgument, "time to live must not be negative")
+	}
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	key := service.SetCacheTimetoLive(ctx, request.Key, request.Value, int(request.TimeToLive))
+	return &cachepb.SetResponse{Key: key, Status: "value set sucessfully"}, nil
+}
+
+// Peek implements the Peek method of the QueueServiceServer
+func (q *queueServer) Peek(ctx context.Context, request *cachepb.PeekRequest) (*cachepb.Entry, error) {
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := service.Get(ctx)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "queue is empty")
+	}
+	return &cachepb.Entry{Key: entry.Key, Value: entry.Value}, nil
+}
+
+// Pop implements the Pop method of the QueueServiceServer
+func (q *queueServer) Pop(ctx context.Context, request *cachepb.PopRequest) (*cachepb.Entry, error) {
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := service.Pop(ctx)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "queue is empty")
+	}
+	return &cachepb.Entry{Key: entry.Key, Value: entry.Value}, nil
+}
+
+// Update implements the Update method of the QueueServiceServer
+func (q *queueServer) Update(ctx context.Context, request *cachepb.UpdateRequest) (*cachepb.SetResponse, error) {
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !service.UpdateValue(ctx, request.Key, request.Value) {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.SetResponse{Key: request.Key, Status: "value updated sucessfully"}, nil
+}
+
+// Delete implements the Delete method of the QueueServiceServer
+func (q *queueServer) Delete(ctx context.Context, request *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !service.Delete(ctx, request.Key) {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	return &cachepb.DeleteResponse{Key: request.Key}, nil
+}
+
+// List implements the List method of the QueueServiceServer
+func (q *queueServer) List(request *cachepb.ListRequest, stream cachepb.QueueService_ListServer) error {
+	if request.N < 0 {
+		return status.Error(codes.InvalidArgument, "n must not be negative")
+	}
+	service, err := q.queueService(request.Namespace)
+	if err != nil {
+		return err
+	}
+	var entries []repository.CacheEntry
+	switch request.SortBy {
+	case cachepb.SortBy_SORT_BY_KEY:
+		entries = service.GetSortedEntries(stream.Context(), queueservice.SortByKey, int(request.N))
+	case cachepb.SortBy_SORT_BY_VALUE:
+		entries = service.GetSortedEntries(stream.Context(), queueservice.SortByValue, int(request.N))
+	default:
+		entries = service.GetEntryList(stream.Context(), int(request.N))
+	}
+	for _, entry := range entries {
+		if err := stream.Send(&cachepb.Entry{Key: entry.Key, Value: entry.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Consume implements the Consume method of the QueueServiceServer, the
+// stream ends when the client closes its side of it
+func (q *queueServer) Consume(stream cachepb.QueueService_ConsumeServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	service, err := q.queueService(first.Namespace)
+	if err != nil {
+		return err
+	}
+
+	credits := make(chan int64, 1)
+	credits <- first.Credit
+	received := make(chan error, 1)
+	go func() {
+		for {
+			request, err := stream.Recv()
+			if err != nil {
+				received <- err
+				return
+			}
+			select {
+			case credits <- request.Credit:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(consumePollInterval)
+	defer ticker.Stop()
+
+	var credit int64
+	for {
+		for credit > 0 {
+			// the node may have become read-only since the stream started
+			if q.namespaces.ReadOnly() {
+				return status.Error(codes.FailedPrecondition, namespaceservice.ErrReadOnly.Error())
+			}
+			entry, ok := service.Pop(ctx)
+			if !ok {
+				break
+			}
+			if err := stream.Send(&cachepb.Entry{Key: entry.Key, Value: entry.Value}); err != nil {
+				// the entry was not delivered, put it back for the next
+				// consumer with the expiration it had
+				service.Push(ctx, entry, true)
+				return err
+			}
+			credit--
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-received:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case more := <-credits:
+			if more < 0 {
+				return status.Error(codes.InvalidArgument, "credit must not be negative")
+			}
+			credit += more
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/zelta-7/cache/api/grpc/cachepb"
+	"github.com/zelta-7/cache/common"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// shutdownTimeout is how long Serve waits for the running calls once ctx is done
+const shutdownTimeout = 5 * time.Second
+
+// ServerInterface serves the gRPC API on top of the cache services
+type ServerInterface interface {
+	// Serve accepts connections on listener until ctx is done
+	Serve(ctx context.Context, listener net.Listener) error
+}
+
+// writeMethods are rejected with FailedPrecondition while the namespaces are read-only
+var writeMethods = map[string]bool{
+	cachepb.MapService_Set_FullMethodName:           true,
+	cachepb.MapService_Update_FullMethodName:        true,
+	cachepb.MapService_Delete_FullMethodName:        true,
+	cachepb.MapService_SetTimeToLive_FullMethodName: true,
+	cachepb.QueueService_Push_FullMethodName:        true,
+	cachepb.QueueService_Pop_FullMethodName:         true,
+	cachepb.QueueService_Update_FullMethodName:      true,
+	cachepb.QueueService_Delete_FullMethodName:      true,
+	cachepb.QueueService_Consume_FullMethodName:     true,
+}
+
+type server struct {
+	grpcServer *grpc.Server
+}
+
+func NewServer(namespaces namespaceservice.NamespaceServiceInterface) ServerInterface {
+	readOnly := func(method string) error {
+		if writeMethods[method] && namespaces.ReadOnly() {
+			return status.Error(codes.FailedPrecondition, namespaceservice.ErrReadOnly.Error())
+		}
+		return nil
+	}
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := readOnly(info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(withClient(ctx), req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := readOnly(info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, &clientStream{ServerStream: stream, ctx: withClient(stream.Context())})
+		}),
+	)
+	cachepb.RegisterMapServiceServer(grpcServer, NewMapServer(namespaces))
+	cachepb.RegisterQueueServiceServer(grpcServer, NewQueueServer(namespaces))
+	reflection.Register(grpcServer)
+	return &server{
+		grpcServer: grpcServer,
+	}
+}
+
+// withClient returns ctx carrying the address of the peer of the call
+func withClient(ctx context.Context) context.Context {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return common.WithClient(ctx, p.Addr.String())
+	}
+	return ctx
+}
+
+// clientStream is a stream whose context carries the address of its peer
+type clientStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements the Context method of the grpc.ServerStream interface
+func (s *clientStream) Context() context.Context {
+	return s.ctx
+}
+
+// Serve implements the Serve method of the ServerInterface
+func (s *server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		// streams such as Consume only end when the client closes them
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			s.grpcServer.Stop()
+		}
+	}()
+	return s.grpcServer.Serve(listener)
+}
+
+// namespace returns the namespace named by a request, the default namespace if name is empty
+func namespace(namespaces namespaceservice.NamespaceServiceInterface, name string) (*namespaceservice.Namespace, error) {
+	if name == "" {
+		return namespaces.Get(namespaceservice.DefaultNamespace), nil
+	}
+	if err := namespaceservice.ValidateName(name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return namespaces.Get(name), nil
+}
+
+// ttlSeconds converts a remaining time to live to seconds rounded up, -1 if it never expires
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return -1
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
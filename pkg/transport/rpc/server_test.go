@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zelta-7/cache/api/grpc/cachepb"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startServer serves the namespaces on a local port and returns a connection to it
+func startServer(t *testing.T, namespaces namespaceservice.NamespaceServiceInterface) *grpc.ClientConn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		NewServer(namespaces).Serve(ctx, listener)
+	}()
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-served
+	})
+	return conn
+}
+
+func TestMapService(t *testing.T) {
+	ctx := context.Background()
+	client := cachepb.NewMapServiceClient(startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{})))
+
+	if _, err := client.Set(ctx, &cachepb.SetRequest{Namespace: "tenant", Key: "a", Value: "1", TimeToLive: 100}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	client.Set(ctx, &cachepb.SetRequest{Namespace: "tenant", Key: "b", Value: "2"})
+	if entry, err := client.Get(ctx, &cachepb.GetRequest{Namespace: "tenant", Key: "a"}); err != nil || entry.Value != "1" {
+		t.Fatalf("Get returned %v: %v, want the value set", entry, err)
+	}
+	if _, err := client.Get(ctx, &cachepb.GetRequest{Key: "a"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Get in the default namespace returned %v, want NotFound", err)
+	}
+	if ttl, err := client.GetTimeToLive(ctx, &cachepb.GetTimeToLiveRequest{Namespace: "tenant", Key: "a"}); err != nil || ttl.TimeToLive != 100 {
+		t.Fatalf("GetTimeToLive returned %v: %v, want 100", ttl, err)
+	}
+	if ttl, err := client.GetTimeToLive(ctx, &cachepb.GetTimeToLiveRequest{Namespace: "tenant", Key: "b"}); err != nil || ttl.TimeToLive != -1 {
+		t.Fatalf("GetTimeToLive returned %v: %v, want -1", ttl, err)
+	}
+	many, err := client.GetMany(ctx, &cachepb.GetManyRequest{Namespace: "tenant", Keys: []string{"a", "missing", "b"}})
+	if err != nil || len(many.Entries) != 2 {
+		t.Fatalf("GetMany returned %v: %v, want the two entries found", many, err)
+	}
+	if _, err := client.Update(ctx, &cachepb.UpdateRequest{Namespace: "tenant", Key: "a", Value: "3"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := client.Update(ctx, &cachepb.UpdateRequest{Namespace: "tenant", Key: "missing", Value: "3"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Update of a missing key returned %v, want NotFound", err)
+	}
+
+	scan, err := client.Scan(ctx, &cachepb.ScanRequest{Namespace: "tenant", Pattern: "[ab]"})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	var values []string
+	for {
+		entry, err := scan.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("receiving the scan: %v", err)
+		}
+		values = append(values, entry.Key+"="+entry.Value)
+	}
+	if len(values) != 2 || values[0] != "a=3" || values[1] != "b=2" {
+		t.Fatalf("Scan streamed %v, want a=3 and b=2", values)
+	}
+
+	if _, err := client.Delete(ctx, &cachepb.DeleteRequest{Namespace: "tenant", Key: "a"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := client.Delete(ctx, &cachepb.DeleteRequest{Namespace: "tenant", Key: "a"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("a second Delete returned %v, want NotFound", err)
+	}
+	if _, err := client.Set(ctx, &cachepb.SetRequest{Namespace: "not valid", Key: "a"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Set in an invalid namespace returned %v, want InvalidArgument", err)
+	}
+}
+
+func TestConsumeSendsAsManyEntriesAsTheCredit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	client := cachepb.NewQueueServiceClient(startServer(t, namespaces))
+	for _, value := range []string{"1", "2", "3"} {
+		client.Push(ctx, &cachepb.PushRequest{Key: "jobs", Value: value})
+	}
+
+	stream, err := client.Consume(ctx)
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	stream.Send(&cachepb.ConsumeRequest{Credit: 2})
+	for _, want := range []string{"1", "2"} {
+		if entry, err := stream.Recv(); err != nil || entry.Value != want {
+			t.Fatalf("Consume sent %v: %v, want %s", entry, err, want)
+		}
+	}
+	// the third entry waits for more credit
+	time.Sleep(2 * consumePollInterval)
+	if entry, err := client.Peek(ctx, &cachepb.PeekRequest{}); err != nil || entry.Value != "3" {
+		t.Fatalf("Peek returned %v: %v, want the entry beyond the credit left in the queue", entry, err)
+	}
+
+	// the entries pushed afterwards are sent as they come
+	stream.Send(&cachepb.ConsumeRequest{Credit: 2})
+	client.Push(ctx, &cachepb.PushRequest{Key: "jobs", Value: "4"})
+	for _, want := range []string{"3", "4"} {
+		if entry, err := stream.Recv(); err != nil || entry.Value != want {
+			t.Fatalf("Consume sent %v: %v, want %s", entry, err, want)
+		}
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("the stream ended with %v once closed by the client", err)
+	}
+}
+
+func TestConsumeStopsOnceReadOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	client := cachepb.NewQueueServiceClient(startServer(t, namespaces))
+	client.Push(ctx, &cachepb.PushRequest{Key: "jobs", Value: "1"})
+
+	stream, err := client.Consume(ctx)
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	stream.Send(&cachepb.ConsumeRequest{})
+	// the stream started before the node became read-only
+	time.Sleep(2 * consumePollInterval)
+	namespaces.SetReadOnly(true)
+	stream.Send(&cachepb.ConsumeRequest{Credit: 1})
+	if _, err := stream.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Consume on a read-only node returned %v, want FailedPrecondition", err)
+	}
+	if entry, err := client.Peek(ctx, &cachepb.PeekRequest{}); err != nil || entry.Value != "1" {
+		t.Fatalf("Peek returned %v: %v, want the entry kept in the queue", entry, err)
+	}
+	if _, err := client.Pop(ctx, &cachepb.PopRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Pop on a read-only node returned %v, want FailedPrecondition", err)
+	}
+}
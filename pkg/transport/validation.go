@@ -0,0 +1,203 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	apiSpec "github.com/zelta-7/cache/api/http/server"
+	"k8s.io/klog/v2"
+)
+
+// ValidationOptions configures the middleware returned by NewValidationMiddleware
+type ValidationOptions struct {
+	// ValidateResponses checks the responses against the spec too, a response
+	// that does not match is replaced by a 500, meant for debugging only
+	ValidateResponses bool
+}
+
+// cloneSpec returns a deep copy of swagger
+func cloneSpec(swagger *openapi3.T) (*openapi3.T, error) {
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, err
+	}
+	return openapi3.NewLoader().LoadFromData(data)
+}
+
+// NewValidationMiddleware returns a gin middleware that rejects the requests
+// not matching the given spec with a 400, routes missing from the spec are let through
+func NewValidationMiddleware(swagger *openapi3.T, options ValidationOptions) (gin.HandlerFunc, error) {
+	// Routes are matched on the path only, the servers of the spec do not
+	// have to match the address the cache listens on. The router gets its
+	// own copy of the spec, the one given is served as is by RegisterDocs.
+	spec, err := cloneSpec(swagger)
+	if err != nil {
+		return nil, err
+	}
+	spec.Servers = nil
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			var routeError *routers.RouteError
+			if !errors.As(err, &routeError) {
+				klog.ErrorS(err, "Error finding the route of the request", "path", c.Request.URL.Path)
+			}
+			c.Next()
+			return
+		}
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), requestInput); err != nil {
+			details := validationDetails(err, "")
+			c.AbortWithStatusJSON(http.StatusBadRequest, apiSpec.Error{Error: "request does not match the API spec", Details: &details})
+			return
+		}
+
+		if !options.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 writer.status,
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options:                &openapi3filter.Options{MultiError: true, IncludeResponseStatus: true},
+		}
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), responseInput); err != nil {
+			klog.ErrorS(err, "Response does not match the API spec", "route", route.Path, "method", route.Method, "status", writer.status)
+			details := validationDetails(err, apiSpec.Response)
+			writer.Header().Del("Content-Length")
+			c.JSON(http.StatusInternalServerError, apiSpec.Error{Error: "response does not match the API spec", Details: &details})
+			return
+		}
+		writer.flush()
+	}, nil
+}
+
+// validationDetails flattens the errors returned by openapi3filter, in
+// overrides where the error is reported, which is needed for responses
+func validationDetails(err error, in apiSpec.ErrorDetailIn) []apiSpec.ErrorDetail {
+	if multiError, ok := err.(openapi3.MultiError); ok {
+		details := make([]apiSpec.ErrorDetail, 0, len(multiError))
+		for _, err := range multiError {
+			details = append(details, validationDetails(err, in)...)
+		}
+		return details
+	}
+
+	detail := apiSpec.ErrorDetail{In: in, Reason: err.Error()}
+	var requestError *openapi3filter.RequestError
+	if errors.As(err, &requestError) {
+		if requestError.Parameter != nil {
+			detail.In = apiSpec.ErrorDetailIn(requestError.Parameter.In)
+			detail.Name = &requestError.Parameter.Name
+		} else if requestError.RequestBody != nil {
+			detail.In = apiSpec.Body
+		}
+		if _, ok := requestError.Err.(openapi3.MultiError); ok {
+			details := validationDetails(requestError.Err, detail.In)
+			for i := range details {
+				if details[i].Name == nil {
+					details[i].Name = detail.Name
+				}
+			}
+			return details
+		}
+		if requestError.Reason != "" {
+			detail.Reason = requestError.Reason
+		}
+	}
+
+	var schemaError *openapi3.SchemaError
+	if errors.As(err, &schemaError) {
+		detail.Reason = schemaError.Reason
+		if pointer := schemaError.JSONPointer(); len(pointer) > 0 {
+			name := strings.Join(pointer, ".")
+			detail.Name = &name
+		}
+	}
+	if detail.In == "" {
+		detail.In = apiSpec.Body
+	}
+	return []apiSpec.ErrorDetail{detail}
+}
+
+// bufferedWriter holds the response back until it has been validated
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// flush writes the buffered response to the underlying writer
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+		klog.ErrorS(err, "Error writing the response")
+	}
+}
@@ -0,0 +1,426 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ErrClosed is returned by the client once the connection is closed
+var ErrClosed = errors.New("client closed")
+
+// ClientInterface is a client of the binary protocol. It is safe for
+// concurrent use, concurrent requests are pipelined on a single connection.
+type ClientInterface interface {
+	GetHealth(ctx context.Context) (string, error)
+	ListNamespaces(ctx context.Context) ([]NamespaceInfo, error)
+	DeleteNamespace(ctx context.Context, namespace string) error
+
+	SetMapValue(ctx context.Context, namespace, key, value string, ttl int) error
+	GetMapValue(ctx context.Context, namespace, key string) (string, error)
+	GetAllMapValues(ctx context.Context, namespace string) ([]Entry, error)
+	// UpdateMapEntry leaves the time to live unchanged when ttl is negative
+	UpdateMapEntry(ctx context.Context, namespace, key, value string, ttl int) error
+	DeleteMapEntry(ctx context.Context, namespace, key string) error
+	// GetMapTimeToLive returns the remaining time to live in seconds, -1 if the key never expires
+	GetMapTimeToLive(ctx context.Context, namespace, key string) (int, error)
+	SetMapTimeToLive(ctx context.Context, namespace, key string, ttl int) (int, error)
+	GetMapEntryList(ctx context.Context, namespace string, n int) ([]Entry, error)
+	GetSortedMapEntries(ctx context.Context, namespace string, sortBy, n int) ([]Entry, error)
+	GetListofMapValues(ctx context.Context, namespace string, keys []string) ([]Entry, error)
+
+	SetQueueValue(ctx context.Context, namespace, key, value string, ttl int) error
+	GetQueueValue(ctx context.Context, namespace string) (Entry, error)
+	PopQueueValue(ctx context.Context, namespace string) (Entry, error)
+	GetAllQueueValues(ctx context.Context, namespace string) ([]Entry, error)
+	GetQueueEntryList(ctx context.Context, namespace string, n int) ([]Entry, error)
+	GetSortedQueueEntries(ctx context.Context, namespace string, sortBy, n int) ([]Entry, error)
+	UpdateQueueValue(ctx context.Context, namespace, key, value string) error
+	DeleteQueueValue(ctx context.Context, namespace, key string) error
+
+	// Close closes the connection, pending requests fail with ErrClosed
+	Close() error
+}
+
+type client struct {
+	conn     net.Conn
+	requests chan frame
+
+	lock    sync.Mutex
+	nextID  uint32
+	pending map[uint32]chan frame
+	err     error
+	closed  chan struct{}
+}
+
+// Dial connects to a server, network is "tcp" or "unix"
+func Dial(ctx context.Context, network, address string) (ClientInterface, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a client sending its requests on conn
+func NewClient(conn net.Conn) ClientInterface {
+	c := &client{
+		conn:     conn,
+		requests: make(chan frame, 64),
+		pending:  make(map[uint32]chan frame),
+		closed:   make(chan struct{}),
+	}
+	go c.writeLoop()
+	go c.readLoop()
+	return c
+}
+
+// writeLoop writes the queued requests, the connection is flushed when no request is waiting
+func (c *client) writeLoop() {
+	writer := bufio.NewWriter(c.conn)
+	for {
+		select {
+		case <-c.closed:
+			return
+		case request := <-c.requests:
+			if err := writeFrame(writer, request); err != nil {
+				c.fail(err)
+				return
+			}
+			if len(c.requests) == 0 {
+				if err := writer.Flush(); err != nil {
+					c.fail(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// readLoop delivers the responses to the requests waiting for them
+func (c *client) readLoop() {
+	reader := bufio.NewReader(c.conn)
+	for {
+		response, err := readFrame(reader)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		c.lock.Lock()
+		waiting, ok := c.pending[response.id]
+		delete(c.pending, response.id)
+		c.lock.Unlock()
+		if ok {
+			waiting <- response
+		}
+	}
+}
+
+// fail closes the client and records the first error that stopped it
+func (c *client) fail(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.closed)
+	c.conn.Close()
+}
+
+// Close implements the Close method of the ClientInterface
+func (c *client) Close() error {
+	c.fail(ErrClosed)
+	return nil
+}
+
+// call sends a request and waits for its response, an error status is returned as an error
+func (c *client) call(ctx context.Context, opcode byte, request *encoder) (*decoder, error) {
+	waiting := make(chan frame, 1)
+
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return nil, ErrClosed
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = waiting
+	c.lock.Unlock()
+
+	forget := func() {
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+	}
+
+	select {
+	case c.requests <- frame{id: id, code: opcode, body: request.buf}:
+	case <-ctx.Done():
+		forget()
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, ErrClosed
+	}
+
+	select {
+	case response := <-waiting:
+		switch response.code {
+		case statusOK:
+			return &decoder{buf: response.body}, nil
+		case statusNotFound:
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, response.body)
+		case statusBadRequest:
+			return nil, fmt.Errorf("%w: %s", ErrBadRequest, response.body)
+		case statusReadOnly:
+			return nil, fmt.Errorf("%w: %s", ErrReadOnly, response.body)
+		case statusUnavailable:
+			return nil, fmt.Errorf("%w: %s", ErrUnavailable, response.body)
+		case statusMoved:
+			return nil, fmt.Errorf("%w: %s", ErrMoved, response.body)
+		default:
+			return nil, fmt.Errorf("server error: %s", response.body)
+		}
+	case <-ctx.Done():
+		forget()
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, ErrClosed
+	}
+}
+
+// callEntries runs an operation returning a list of entries
+func (c *client) callEntries(ctx context.Context, opcode byte, request *encoder) ([]Entry, error) {
+	response, err := c.call(ctx, opcode, request)
+	if err != nil {
+		return nil, err
+	}
+	entries := response.entries()
+	return entries, response.finish()
+}
+
+// callEntry runs an operation returning a single entry
+func (c *client) callEntry(ctx context.Context, opcode byte, request *encoder) (Entry, error) {
+	response, err := c.call(ctx, opcode, request)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := response.entry()
+	return entry, response.finish()
+}
+
+// callEmpty runs an operation returning nothing
+func (c *client) callEmpty(ctx context.Context, opcode byte, request *encoder) error {
+	response, err := c.call(ctx, opcode, request)
+	if err != nil {
+		return err
+	}
+	return response.finish()
+}
+
+// GetHealth implements the GetHealth method of the ClientInterface
+func (c *client) GetHealth(ctx context.Context) (string, error) {
+	response, err := c.call(ctx, opGetHealth, &encoder{})
+	if err != nil {
+		return "", err
+	}
+	status := response.string()
+	return status, response.finish()
+}
+
+// ListNamespaces implements the ListNamespaces method of the ClientInterface
+func (c *client) ListNamespaces(ctx context.Context) ([]NamespaceInfo, error) {
+	response, err := c.call(ctx, opListNamespaces, &encoder{})
+	if err != nil {
+		return nil, err
+	}
+	count := response.int()
+	if count < 0 || count > len(response.buf) {
+		return nil, errMalformed
+	}
+	namespaces := make([]NamespaceInfo, 0, count)
+	for i := 0; i < count; i++ {
+		namespaces = append(namespaces, NamespaceInfo{Name: response.string(), MapEntries: response.int(), QueueEntries: response.int()})
+	}
+	return namespaces, response.finish()
+}
+
+// DeleteNamespace implements the DeleteNamespace method of the ClientInterface
+func (c *client) DeleteNamespace(ctx context.Context, namespace string) error {
+	request := &encoder{}
+	request.string(namespace)
+	return c.callEmpty(ctx, opDeleteNamespace, request)
+}
+
+// SetMapValue implements the SetMapValue method of the ClientInterface
+func (c *client) SetMapValue(ctx context.Context, namespace, key, value string, ttl int) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	request.string(value)
+	request.int(ttl)
+	return c.callEmpty(ctx, opSetMapValue, request)
+}
+
+// GetMapValue implements the GetMapValue method of the ClientInterface
+func (c *client) GetMapValue(ctx context.Context, namespace, key string) (string, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	response, err := c.call(ctx, opGetMapValue, request)
+	if err != nil {
+		return "", err
+	}
+	value := response.string()
+	return value, response.finish()
+}
+
+// GetAllMapValues implements the GetAllMapValues method of the ClientInterface
+func (c *client) GetAllMapValues(ctx context.Context, namespace string) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	return c.callEntries(ctx, opGetAllMapValues, request)
+}
+
+// UpdateMapEntry implements the UpdateMapEntry method of the ClientInterface
+func (c *client) UpdateMapEntry(ctx context.Context, namespace, key, value string, ttl int) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	request.string(value)
+	request.int(ttl)
+	return c.callEmpty(ctx, opUpdateMapEntry, request)
+}
+
+// DeleteMapEntry implements the DeleteMapEntry method of the ClientInterface
+func (c *client) DeleteMapEntry(ctx context.Context, namespace, key string) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	return c.callEmpty(ctx, opDeleteMapEntry, request)
+}
+
+// GetMapTimeToLive implements the GetMapTimeToLive method of the ClientInterface
+func (c *client) GetMapTimeToLive(ctx context.Context, namespace, key string) (int, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	response, err := c.call(ctx, opGetMapTimeToLive, request)
+	if err != nil {
+		return 0, err
+	}
+	ttl := response.int()
+	return ttl, response.finish()
+}
+
+// SetMapTimeToLive implements the SetMapTimeToLive method of the ClientInterface
+func (c *client) SetMapTimeToLive(ctx context.Context, namespace, key string, ttl int) (int, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	request.int(ttl)
+	response, err := c.call(ctx, opSetMapTimeToLive, request)
+	if err != nil {
+		return 0, err
+	}
+	remaining := response.int()
+	return remaining, response.finish()
+}
+
+// GetMapEntryList implements the GetMapEntryList method of the ClientInterface
+func (c *client) GetMapEntryList(ctx context.Context, namespace string, n int) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.int(n)
+	return c.callEntries(ctx, opGetMapEntryList, request)
+}
+
+// GetSortedMapEntries implements the GetSortedMapEntries method of the ClientInterface
+func (c *client) GetSortedMapEntries(ctx context.Context, namespace string, sortBy, n int) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.int(sortBy)
+	request.int(n)
+	return c.callEntries(ctx, opGetSortedMapEntries, request)
+}
+
+// GetListofMapValues implements the GetListofMapValues method of the ClientInterface
+func (c *client) GetListofMapValues(ctx context.Context, namespace string, keys []string) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.int(len(keys))
+	for _, key := range keys {
+		request.string(key)
+	}
+	return c.callEntries(ctx, opGetListofMapValues, request)
+}
+
+// SetQueueValue implements the SetQueueValue method of the ClientInterface
+func (c *client) SetQueueValue(ctx context.Context, namespace, key, value string, ttl int) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	request.string(value)
+	request.int(ttl)
+	return c.callEmpty(ctx, opSetQueueValue, request)
+}
+
+// GetQueueValue implements the GetQueueValue method of the ClientInterface
+func (c *client) GetQueueValue(ctx context.Context, namespace string) (Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	return c.callEntry(ctx, opGetQueueValue, request)
+}
+
+// PopQueueValue implements the PopQueueValue method of the ClientInterface
+func (c *client) PopQueueValue(ctx context.Context, namespace string) (Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	return c.callEntry(ctx, opPopQueueValue, request)
+}
+
+// GetAllQueueValues implements the GetAllQueueValues method of the ClientInterface
+func (c *client) GetAllQueueValues(ctx context.Context, namespace string) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	return c.callEntries(ctx, opGetAllQueueValues, request)
+}
+
+// GetQueueEntryList implements the GetQueueEntryList method of the ClientInterface
+func (c *client) GetQueueEntryList(ctx context.Context, namespace string, n int) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.int(n)
+	return c.callEntries(ctx, opGetQueueEntryList, request)
+}
+
+// GetSortedQueueEntries implements the GetSortedQueueEntries method of the ClientInterface
+func (c *client) GetSortedQueueEntries(ctx context.Context, namespace string, sortBy, n int) ([]Entry, error) {
+	request := &encoder{}
+	request.string(namespace)
+	request.int(sortBy)
+	request.int(n)
+	return c.callEntries(ctx, opGetSortedQueueEntries, request)
+}
+
+// UpdateQueueValue implements the UpdateQueueValue method of the ClientInterface
+func (c *client) UpdateQueueValue(ctx context.Context, namespace, key, value string) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	request.string(value)
+	return c.callEmpty(ctx, opUpdateQueueValue, request)
+}
+
+// DeleteQueueValue implements the DeleteQueueValue method of the ClientInterface
+func (c *client) DeleteQueueValue(ctx context.Context, namespace, key string) error {
+	request := &encoder{}
+	request.string(namespace)
+	request.string(key)
+	return c.callEmpty(ctx, opDeleteQueueValue, request)
+}
@@ -0,0 +1,236 @@
+// Package wire implements a compact binary protocol exposing the operations
+// of the HTTP API. Every message is a frame made of a big endian uint32
+// length, a uint32 request id, a one byte opcode or status and a body.
+// Strings are encoded as a uvarint length followed by the bytes and
+// integers as varints. Clients may pipeline requests, responses carry the
+// id of the request they answer.
+package wire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// frameHeaderLength is the length of the request id and the opcode or status
+	frameHeaderLength = 5
+	// maxFrameLength bounds the length of a single frame
+	maxFrameLength = 64 << 20
+)
+
+// Opcodes, one for every operation of the CacheHandlerInterface
+const (
+	opGetHealth byte = iota + 1
+	opListNamespaces
+	opDeleteNamespace
+	opSetMapValue
+	opGetMapValue
+	opGetAllMapValues
+	opUpdateMapEntry
+	opDeleteMapEntry
+	opGetMapTimeToLive
+	opSetMapTimeToLive
+	opGetMapEntryList
+	opGetSortedMapEntries
+	opGetListofMapValues
+	opSetQueueValue
+	opGetQueueValue
+	opPopQueueValue
+	opGetAllQueueValues
+	opGetQueueEntryList
+	opGetSortedQueueEntries
+	opUpdateQueueValue
+	opDeleteQueueValue
+)
+
+// Response statuses, the body of a response with an error status is the error message
+const (
+	statusOK byte = iota
+	statusNotFound
+	statusBadRequest
+	statusInternalError
+	// statusReadOnly rejects a write sent to a read-only replica
+	statusReadOnly
+	// statusUnavailable answers an operation that could not be committed or made consistent
+	statusUnavailable
+	// statusMoved rejects a key owned by another cluster node, the body names the owner
+	statusMoved
+)
+
+// writeOperations are rejected with statusReadOnly while the namespaces are read-only
+var writeOperations = map[byte]bool{
+	opDeleteNamespace:  true,
+	opSetMapValue:      true,
+	opUpdateMapEntry:   true,
+	opDeleteMapEntry:   true,
+	opSetMapTimeToLive: true,
+	opSetQueueValue:    true,
+	opPopQueueValue:    true,
+	opUpdateQueueValue: true,
+	opDeleteQueueValue: true,
+}
+
+// Sort orders accepted by the sorted list operations
+const (
+	SortByKey = iota
+	SortByValue
+)
+
+var (
+	// ErrNotFound is returned by the client when the key, the queue entry or the namespace does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrBadRequest is returned by the client when the server rejected the arguments
+	ErrBadRequest = errors.New("bad request")
+	// ErrReadOnly is returned by the client when a write is sent to a read-only replica
+	ErrReadOnly = errors.New("read-only replica")
+	// ErrUnavailable is returned by the client when the server could not commit a write
+	ErrUnavailable = errors.New("unavailable")
+	// ErrMoved is returned by the client when the key is owned by another cluster node
+	ErrMoved = errors.New("moved")
+
+	errMalformed = errors.New("malformed message")
+)
+
+// Entry is a map or queue entry, TimeToLive is the time to live in seconds
+// the entry was stored with, 0 if it never expires
+type Entry struct {
+	Key        string
+	Value      string
+	TimeToLive int
+}
+
+// NamespaceInfo describes a namespace and the number of entries it holds
+type NamespaceInfo struct {
+	Name         string
+	MapEntries   int
+	QueueEntries int
+}
+
+// frame is a request or a response
+type frame struct {
+	id   uint32
+	code byte
+	body []byte
+}
+
+func readFrame(reader *bufio.Reader) (frame, error) {
+	var header [4 + frameHeaderLength]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return frame{}, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length < frameHeaderLength || length > maxFrameLength {
+		return frame{}, fmt.Errorf("%w: invalid frame length %d", errMalformed, length)
+	}
+	f := frame{
+		id:   binary.BigEndian.Uint32(header[4:8]),
+		code: header[8],
+		body: make([]byte, length-frameHeaderLength),
+	}
+	if _, err := io.ReadFull(reader, f.body); err != nil {
+		return frame{}, err
+	}
+	return f, nil
+}
+
+func writeFrame(writer *bufio.Writer, f frame) error {
+	var header [4 + frameHeaderLength]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(frameHeaderLength+len(f.body)))
+	binary.BigEndian.PutUint32(header[4:8], f.id)
+	header[8] = f.code
+	if _, err := writer.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := writer.Write(f.body)
+	return err
+}
+
+// encoder appends values to a frame body
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) string(s string) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) int(n int) {
+	e.buf = binary.AppendVarint(e.buf, int64(n))
+}
+
+func (e *encoder) entry(entry Entry) {
+	e.string(entry.Key)
+	e.string(entry.Value)
+	e.int(entry.TimeToLive)
+}
+
+func (e *encoder) entries(entries []Entry) {
+	e.int(len(entries))
+	for _, entry := range entries {
+		e.entry(entry)
+	}
+}
+
+// decoder reads values from a frame body, the first error is kept and
+// every following read returns a zero value
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) string() string {
+	if d.err != nil {
+		return ""
+	}
+	length, read := binary.Uvarint(d.buf)
+	if read <= 0 || length > uint64(len(d.buf)-read) {
+		d.err = errMalformed
+		return ""
+	}
+	d.buf = d.buf[read:]
+	s := string(d.buf[:length])
+	d.buf = d.buf[length:]
+	return s
+}
+
+func (d *decoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+	n, read := binary.Varint(d.buf)
+	if read <= 0 {
+		d.err = errMalformed
+		return 0
+	}
+	d.buf = d.buf[read:]
+	return int(n)
+}
+
+func (d *decoder) entry() Entry {
+	return Entry{Key: d.string(), Value: d.string(), TimeToLive: d.int()}
+}
+
+func (d *decoder) entries() []Entry {
+	count := d.int()
+	if count < 0 || count > len(d.buf) {
+		d.err = errMalformed
+		return nil
+	}
+	entries := make([]Entry, 0, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		entries = append(entries, d.entry())
+	}
+	return entries
+}
+
+// finish returns the first decoding error, or an error if bytes are left over
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.buf) > 0 {
+		d.err = errMalformed
+	}
+	return d.err
+}
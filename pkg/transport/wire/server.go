@@ -0,0 +1,640 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/zelta-7/cache/common"
+	mapRepository "github.com/zelta-7/cache/pkg/repository/map"
+	queueRepository "github.com/zelta-7/cache/pkg/repository/queue"
+	mapservice "github.com/zelta-7/cache/pkg/service/map"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	queueservice "github.com/zelta-7/cache/pkg/service/queue"
+	"k8s.io/klog/v2"
+)
+
+// ServerInterface serves the binary protocol on top of the cache services
+type ServerInterface interface {
+	// Serve accepts connections on listener until ctx is done
+	Serve(ctx context.Context, listener net.Listener) error
+}
+
+type server struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+}
+
+func NewServer(namespaces namespaceservice.NamespaceServiceInterface) ServerInterface {
+	return &server{
+		namespaces: namespaces,
+	}
+}
+
+// statusError is returned by an operation to reply with an error status
+type statusError struct {
+	status  byte
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+func notFound(message string) error {
+	return &statusError{status: statusNotFound, message: message}
+}
+
+func badRequest(message string) error {
+	return &statusError{status: statusBadRequest, message: message}
+}
+
+func unavailable(err error) error {
+	return &statusError{status: statusUnavailable, message: err.Error()}
+}
+
+func moved(err error) error {
+	return &statusError{status: statusMoved, message: err.Error()}
+}
+
+// operation decodes the arguments of a request, runs it and encodes the result
+type operation func(ctx context.Context, s *server, request *decoder, response *encoder) error
+
+var operations = map[byte]operation{
+	opGetHealth:             getHealth,
+	opListNamespaces:        listNamespaces,
+	opDeleteNamespace:       deleteNamespace,
+	opSetMapValue:           setMapValue,
+	opGetMapValue:           getMapValue,
+	opGetAllMapValues:       getAllMapValues,
+	opUpdateMapEntry:        updateMapEntry,
+	opDeleteMapEntry:        deleteMapEntry,
+	opGetMapTimeToLive:      getMapTimeToLive,
+	opSetMapTimeToLive:      setMapTimeToLive,
+	opGetMapEntryList:       getMapEntryList,
+	opGetSortedMapEntries:   getSortedMapEntries,
+	opGetListofMapValues:    getListofMapValues,
+	opSetQueueValue:         setQueueValue,
+	opGetQueueValue:         getQueueValue,
+	opPopQueueValue:         popQueueValue,
+	opGetAllQueueValues:     getAllQueueValues,
+	opGetQueueEntryList:     getQueueEntryList,
+	opGetSortedQueueEntries: getSortedQueueEntries,
+	opUpdateQueueValue:      updateQueueValue,
+	opDeleteQueueValue:      deleteQueueValue,
+}
+
+// Serve implements the Serve method of the ServerInterface
+func (s *server) Serve(ctx context.Context, listener net.Listener) error {
+	var connections sync.WaitGroup
+	defer connections.Wait()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle runs the requests of a connection in the order they are received,
+// responses are flushed once every pipelined request has been answered
+func (s *server) handle(ctx context.Context, conn net.Conn) {
+	ctx = common.WithClient(ctx, conn.RemoteAddr().String())
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		request, err := readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				klog.V(2).InfoS("Error reading binary protocol request", "remote", conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		if err := writeFrame(writer, s.run(ctx, request)); err != nil {
+			return
+		}
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *server) run(ctx context.Context, request frame) frame {
+	response := frame{id: request.id, code: statusOK}
+	op, ok := operations[request.code]
+	if !ok {
+		response.code = statusInternalError
+		response.body = []byte("unknown opcode")
+		return response
+	}
+	if writeOperations[request.code] && s.namespaces.ReadOnly() {
+		response.code = statusReadOnly
+		response.body = []byte(namespaceservice.ErrReadOnly.Error())
+		return response
+	}
+
+	result := &encoder{}
+	err := op(ctx, s, &decoder{buf: request.body}, result)
+	var statusErr *statusError
+	switch {
+	case err == nil:
+		response.body = result.buf
+	case errors.As(err, &statusErr):
+		response.code = statusErr.status
+		response.body = []byte(statusErr.message)
+	default:
+		response.code = statusInternalError
+		response.body = []byte(err.Error())
+	}
+	return response
+}
+
+// namespace returns the namespace named by a request, the default namespace if name is empty
+func (s *server) namespace(name string) (*namespaceservice.Namespace, error) {
+	if name == "" {
+		return s.namespaces.Get(namespaceservice.DefaultNamespace), nil
+	}
+	if err := namespaceservice.ValidateName(name); err != nil {
+		return nil, badRequest(err.Error())
+	}
+	return s.namespaces.Get(name), nil
+}
+
+// mapService returns the map of the namespace, statusMoved naming the owner
+// is returned if one of the keys is owned by another cluster node
+func (s *server) mapService(name string, keys ...string) (mapservice.MapServiceInterface, error) {
+	namespace, err := s.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.namespaces.Route(namespace.Name, keys...); err != nil {
+		return nil, moved(err)
+	}
+	return namespace.Map, nil
+}
+
+func (s *server) queueService(name string) (queueservice.QueueServiceInterface, error) {
+	namespace, err := s.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return namespace.Queue, nil
+}
+
+// finish checks that the whole request was decoded
+func finish(request *decoder) error {
+	if err := request.finish(); err != nil {
+		return badRequest("malformed request")
+	}
+	return nil
+}
+
+func getHealth(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	if err := finish(request); err != nil {
+		return err
+	}
+	response.string("ok")
+	return nil
+}
+
+func listNamespaces(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	if err := finish(request); err != nil {
+		return err
+	}
+	namespaces := s.namespaces.List()
+	response.int(len(namespaces))
+	for _, namespace := range namespaces {
+		response.string(namespace.Name)
+		response.int(namespace.Map.Len())
+		response.int(namespace.Queue.Len())
+	}
+	return nil
+}
+
+func deleteNamespace(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	name := request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if !s.namespaces.Delete(ctx, name) {
+		return notFound("namespace not found")
+	}
+	klog.InfoS("Namespace deleted", "namespace", name)
+	return nil
+}
+
+func setMapValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key, value, ttl := request.string(), request.string(), request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if ttl < 0 {
+		return badRequest("time-to-live must not be negative")
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	if _, err := service.SetCacheTimetoLive(ctx, key, value, ttl); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+func getMapValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key := request.string(), request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	value, ok, err := service.Get(ctx, key)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !ok {
+		return notFound("key not found")
+	}
+	response.string(value)
+	return nil
+}
+
+func getAllMapValues(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace := request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.mapService(namespace)
+	if err != nil {
+		return err
+	}
+	entries, err := service.All(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+	response.entries(mapEntries(entries))
+	return nil
+}
+
+// updateMapEntry leaves the time to live unchanged when it is negative
+func updateMapEntry(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key, value, ttl := request.string(), request.string(), request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	found, err := service.UpdateCacheEntry(ctx, key, value)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !found {
+		return notFound("key not found")
+	}
+	if ttl >= 0 {
+		if _, err := service.Expire(ctx, key, ttl); err != nil {
+			return unavailable(err)
+		}
+	}
+	return nil
+}
+
+func deleteMapEntry(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key := request.string(), request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	deleted, err := service.Delete(ctx, key)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !deleted {
+		return notFound("key not found")
+	}
+	return nil
+}
+
+func getMapTimeToLive(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key := request.string(), request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	ttl, ok, err := service.TimeToLive(ctx, key)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !ok {
+		return notFound("key not found")
+	}
+	response.int(ttlSeconds(ttl))
+	return nil
+}
+
+func setMapTimeToLive(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key, ttl := request.string(), request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if ttl < 0 {
+		return badRequest("time-to-live must not be negative")
+	}
+	service, err := s.mapService(namespace, key)
+	if err != nil {
+		return err
+	}
+	found, err := service.Expire(ctx, key, ttl)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !found {
+		return notFound("key not found")
+	}
+	remaining, _, err := service.TimeToLive(ctx, key)
+	if err != nil {
+		return unavailable(err)
+	}
+	response.int(ttlSeconds(remaining))
+	return nil
+}
+
+func getMapEntryList(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, n := request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if n < 1 {
+		return badRequest("n must be at least 1")
+	}
+	service, err := s.mapService(namespace)
+	if err != nil {
+		return err
+	}
+	entries, err := service.GetEntryList(ctx, n)
+	if err != nil {
+		return unavailable(err)
+	}
+	response.entries(mapEntries(entries))
+	return nil
+}
+
+func getSortedMapEntries(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, sortBy, n := request.string(), request.int(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if n < 1 {
+		return badRequest("n must be at least 1")
+	}
+	selector := mapservice.SortByValue
+	switch sortBy {
+	case SortByKey:
+		selector = mapservice.SortByKey
+	case SortByValue:
+	default:
+		return badRequest("invalid sort order")
+	}
+	service, err := s.mapService(namespace)
+	if err != nil {
+		return err
+	}
+	entries, err := service.GetSortedEntryList(ctx, selector, n)
+	if err != nil {
+		return unavailable(err)
+	}
+	response.entries(mapEntries(entries))
+	return nil
+}
+
+func getListofMapValues(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, count := request.string(), request.int()
+	if count < 0 || count > len(request.buf) {
+		return badRequest("malformed request")
+	}
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		keys = append(keys, request.string())
+	}
+	if err := finish(request); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return badRequest("at least one key is required")
+	}
+	service, err := s.mapService(namespace, keys...)
+	if err != nil {
+		return err
+	}
+	entries, err := service.GetListofValues(ctx, keys)
+	if err != nil {
+		return unavailable(err)
+	}
+	response.entries(mapEntries(entries))
+	return nil
+}
+
+func setQueueValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key, value, ttl := request.string(), request.string(), request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if key == "" {
+		return badRequest("key is required")
+	}
+	if ttl < 0 {
+		return badRequest("time-to-live must not be negative")
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	service.SetCacheTimetoLive(ctx, key, value, ttl)
+	return nil
+}
+
+func getQueueValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace := request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	entry, ok := service.Get(ctx)
+	if !ok {
+		return notFound("queue is empty")
+	}
+	response.entry(queueEntry(entry))
+	return nil
+}
+
+func popQueueValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace := request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	entry, ok := service.Pop(ctx)
+	if !ok {
+		return notFound("queue is empty")
+	}
+	response.entry(queueEntry(entry))
+	return nil
+}
+
+func getAllQueueValues(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace := request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	response.entries(queueEntries(service.All(ctx)))
+	return nil
+}
+
+func getQueueEntryList(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, n := request.string(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if n < 1 {
+		return badRequest("n must be at least 1")
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	response.entries(queueEntries(service.GetEntryList(ctx, n)))
+	return nil
+}
+
+func getSortedQueueEntries(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, sortBy, n := request.string(), request.int(), request.int()
+	if err := finish(request); err != nil {
+		return err
+	}
+	if n < 1 {
+		return badRequest("n must be at least 1")
+	}
+	selector := queueservice.SortByValue
+	switch sortBy {
+	case SortByKey:
+		selector = queueservice.SortByKey
+	case SortByValue:
+	default:
+		return badRequest("invalid sort order")
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	response.entries(queueEntries(service.GetSortedEntries(ctx, selector, n)))
+	return nil
+}
+
+func updateQueueValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key, value := request.string(), request.string(), request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	if !service.UpdateValue(ctx, key, value) {
+		return notFound("key not found")
+	}
+	return nil
+}
+
+func deleteQueueValue(ctx context.Context, s *server, request *decoder, response *encoder) error {
+	namespace, key := request.string(), request.string()
+	if err := finish(request); err != nil {
+		return err
+	}
+	service, err := s.queueService(namespace)
+	if err != nil {
+		return err
+	}
+	if !service.Delete(ctx, key) {
+		return notFound("key not found")
+	}
+	return nil
+}
+
+func mapEntries(entries []mapRepository.CacheEntry) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, Entry{Key: entry.Key, Value: entry.Value, TimeToLive: int(entry.TTL / time.Second)})
+	}
+	return result
+}
+
+func queueEntries(entries []queueRepository.CacheEntry) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, queueEntry(entry))
+	}
+	return result
+}
+
+func queueEntry(entry queueRepository.CacheEntry) Entry {
+	return Entry{Key: entry.Key, Value: entry.Value, TimeToLive: int(entry.TTL / time.Second)}
+}
+
+// ttlSeconds converts a remaining time to live to seconds rounded up, -1 if it never expires
+func ttlSeconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return -1
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
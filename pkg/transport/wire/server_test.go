@@ -0,0 +1,265 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// startServer serves the namespaces on a local port and returns its address
+func startServer(t *testing.T, namespaces namespaceservice.NamespaceServiceInterface) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		NewServer(namespaces).Serve(ctx, listener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+	return listener.Addr().String()
+}
+
+// dial returns a client of the server at address
+func dial(t *testing.T, address string) ClientInterface {
+	t.Helper()
+	client, err := Dial(context.Background(), "tcp", address)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestMapOperations(t *testing.T) {
+	ctx := context.Background()
+	client := dial(t, startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{})))
+
+	if status, err := client.GetHealth(ctx); err != nil || status == "" {
+		t.Fatalf("GetHealth returned %q: %v", status, err)
+	}
+	if err := client.SetMapValue(ctx, "tenant", "b", "2", 100); err != nil {
+		t.Fatalf("SetMapValue: %v", err)
+	}
+	client.SetMapValue(ctx, "tenant", "a", "3", 0)
+	if value, err := client.GetMapValue(ctx, "tenant", "b"); err != nil || value != "2" {
+		t.Fatalf("GetMapValue returned %q: %v, want 2", value, err)
+	}
+	if _, err := client.GetMapValue(ctx, "", "b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetMapValue in the default namespace returned %v, want ErrNotFound", err)
+	}
+	if ttl, err := client.GetMapTimeToLive(ctx, "tenant", "a"); err != nil || ttl != -1 {
+		t.Fatalf("GetMapTimeToLive returned %d: %v, want -1", ttl, err)
+	}
+
+	// a negative time to live leaves it unchanged
+	if err := client.UpdateMapEntry(ctx, "tenant", "b", "1", -1); err != nil {
+		t.Fatalf("UpdateMapEntry: %v", err)
+	}
+	if ttl, err := client.GetMapTimeToLive(ctx, "tenant", "b"); err != nil || ttl <= 0 || ttl > 100 {
+		t.Fatalf("GetMapTimeToLive returned %d: %v, want the time to live kept", ttl, err)
+	}
+	if err := client.UpdateMapEntry(ctx, "tenant", "missing", "1", -1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateMapEntry of a missing key returned %v, want ErrNotFound", err)
+	}
+
+	entries, err := client.GetSortedMapEntries(ctx, "tenant", SortByValue, 10)
+	if err != nil || fmt.Sprint(entries) != "[{b 1 100} {a 3 0}]" {
+		t.Fatalf("GetSortedMapEntries returned %v: %v, want the entries by value", entries, err)
+	}
+	entries, err = client.GetListofMapValues(ctx, "tenant", []string{"a", "missing"})
+	if err != nil || len(entries) != 1 || entries[0].Value != "3" {
+		t.Fatalf("GetListofMapValues returned %v: %v, want the entry found", entries, err)
+	}
+	namespaces, err := client.ListNamespaces(ctx)
+	if err != nil || fmt.Sprint(namespaces) != "[{default 0 0} {tenant 2 0}]" {
+		t.Fatalf("ListNamespaces returned %v: %v", namespaces, err)
+	}
+
+	if err := client.DeleteMapEntry(ctx, "tenant", "a"); err != nil {
+		t.Fatalf("DeleteMapEntry: %v", err)
+	}
+	if err := client.DeleteMapEntry(ctx, "tenant", "a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("a second DeleteMapEntry returned %v, want ErrNotFound", err)
+	}
+	if err := client.SetMapValue(ctx, "not valid", "a", "1", 0); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("SetMapValue in an invalid namespace returned %v, want ErrBadRequest", err)
+	}
+}
+
+func TestQueueOperations(t *testing.T) {
+	ctx := context.Background()
+	client := dial(t, startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{})))
+
+	for _, entry := range []Entry{{Key: "x", Value: "1"}, {Key: "y", Value: "2"}, {Key: "z", Value: "3"}} {
+		if err := client.SetQueueValue(ctx, "", entry.Key, entry.Value, 0); err != nil {
+			t.Fatalf("SetQueueValue: %v", err)
+		}
+	}
+	if entry, err := client.GetQueueValue(ctx, ""); err != nil || entry.Key != "x" {
+		t.Fatalf("GetQueueValue returned %v: %v, want the first entry", entry, err)
+	}
+	if err := client.UpdateQueueValue(ctx, "", "y", "4"); err != nil {
+		t.Fatalf("UpdateQueueValue: %v", err)
+	}
+	if err := client.DeleteQueueValue(ctx, "", "z"); err != nil {
+		t.Fatalf("DeleteQueueValue: %v", err)
+	}
+	if entries, err := client.GetAllQueueValues(ctx, ""); err != nil || fmt.Sprint(entries) != "[{x 1 0} {y 4 0}]" {
+		t.Fatalf("GetAllQueueValues returned %v: %v", entries, err)
+	}
+	for _, want := range []string{"x", "y"} {
+		if entry, err := client.PopQueueValue(ctx, ""); err != nil || entry.Key != want {
+			t.Fatalf("PopQueueValue returned %v: %v, want %s", entry, err, want)
+		}
+	}
+	if _, err := client.PopQueueValue(ctx, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("PopQueueValue of an empty queue returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestWritesToAReadOnlyNode(t *testing.T) {
+	ctx := context.Background()
+	namespaces := namespaceservice.NewNamespaceService(namespaceservice.Options{})
+	client := dial(t, startServer(t, namespaces))
+	client.SetMapValue(ctx, "", "a", "1", 0)
+	namespaces.SetReadOnly(true)
+
+	if err := client.SetMapValue(ctx, "", "a", "2", 0); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("SetMapValue on a read-only node returned %v, want ErrReadOnly", err)
+	}
+	if _, err := client.PopQueueValue(ctx, ""); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("PopQueueValue on a read-only node returned %v, want ErrReadOnly", err)
+	}
+	if value, err := client.GetMapValue(ctx, "", "a"); err != nil || value != "1" {
+		t.Fatalf("GetMapValue on a read-only node returned %q: %v, want 1", value, err)
+	}
+}
+
+func TestConcurrentRequestsArePipelined(t *testing.T) {
+	ctx := context.Background()
+	client := dial(t, startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{})))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, value := fmt.Sprintf("key-%d", i), fmt.Sprint(i)
+			if err := client.SetMapValue(ctx, "", key, value, 0); err != nil {
+				errs <- err
+				return
+			}
+			if got, err := client.GetMapValue(ctx, "", key); err != nil || got != value {
+				errs <- fmt.Errorf("GetMapValue of %s returned %q: %v, want %s", key, got, err, value)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestResponsesCarryTheRequestID(t *testing.T) {
+	conn, err := net.Dial("tcp", startServer(t, namespaceservice.NewNamespaceService(namespaceservice.Options{})))
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// the requests are pipelined in a single write, with ids in no particular order
+	set, get := &encoder{}, &encoder{}
+	set.string("")
+	set.string("a")
+	set.string("1")
+	set.int(0)
+	get.string("")
+	get.string("a")
+	requests := []frame{
+		{id: 42, code: opSetMapValue, body: set.buf},
+		{id: 7, code: opGetMapValue, body: get.buf},
+		{id: 7 << 20, code: 0xff},
+	}
+	writer := bufio.NewWriter(conn)
+	for _, request := range requests {
+		writeFrame(writer, request)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("sending the requests: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	for i, want := range []byte{statusOK, statusOK, statusInternalError} {
+		response, err := readFrame(reader)
+		if err != nil {
+			t.Fatalf("reading response %d: %v", i, err)
+		}
+		if response.id != requests[i].id || response.code != want {
+			t.Fatalf("response %d has the id %d and the status %d, want %d and %d", i, response.id, response.code, requests[i].id, want)
+		}
+	}
+}
+
+func TestClientMatchesTheResponsesOutOfOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	local, remote := net.Pipe()
+	client := NewClient(local)
+	defer client.Close()
+
+	// a server answering the requests in the reverse order
+	go func() {
+		reader, writer := bufio.NewReader(remote), bufio.NewWriter(remote)
+		var requests []frame
+		for len(requests) < 2 {
+			request, err := readFrame(reader)
+			if err != nil {
+				return
+			}
+			requests = append(requests, request)
+		}
+		for i := len(requests) - 1; i >= 0; i-- {
+			request := &decoder{buf: requests[i].body}
+			request.string()
+			response := &encoder{}
+			response.string("value of " + request.string())
+			writeFrame(writer, frame{id: requests[i].id, code: statusOK, body: response.buf})
+		}
+		writer.Flush()
+	}()
+
+	values := make(chan string, 2)
+	for _, key := range []string{"a", "b"} {
+		key := key
+		go func() {
+			value, err := client.GetMapValue(ctx, "", key)
+			if err != nil {
+				value = err.Error()
+			}
+			values <- key + ": " + value
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if value := <-values; value != "a: value of a" && value != "b: value of b" {
+			t.Fatalf("the client returned %q, want the response to its own request", value)
+		}
+	}
+}
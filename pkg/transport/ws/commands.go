@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"context"
+	"strings"
+
+	eventservice "github.com/zelta-7/cache/pkg/service/events"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// run runs a command and returns its result
+func (cn *conn) run(ctx context.Context, cmd command) reply {
+	if cmd.Namespace != "" {
+		if err := namespaceservice.ValidateName(cmd.Namespace); err != nil {
+			return failure(cmd, err.Error())
+		}
+	}
+
+	switch cmd.Op {
+	case "subscribe":
+		return cn.subscribe(cmd)
+	case "unsubscribe":
+		return cn.unsubscribe(cmd)
+	}
+
+	switch cmd.Op {
+	case "set", "delete", "push", "pop":
+		if cn.handler.namespaces.ReadOnly() {
+			return failure(cmd, namespaceservice.ErrReadOnly.Error())
+		}
+	}
+	switch cmd.Op {
+	case "get", "set", "delete":
+		if err := cn.handler.namespaces.Route(cmd.Namespace, cmd.Key); err != nil {
+			return failure(cmd, err.Error())
+		}
+	}
+
+	namespace := cn.handler.namespaces.Get(cmd.Namespace)
+	switch cmd.Op {
+	case "get":
+		if cmd.Key == "" {
+			return failure(cmd, "key is required")
+		}
+		value, ok, err := namespace.Map.Get(ctx, cmd.Key)
+		if err != nil {
+			return failure(cmd, err.Error())
+		}
+		if !ok {
+			return failure(cmd, "key not found")
+		}
+		return reply{ID: cmd.ID, Type: frameResult, Key: cmd.Key, Value: &value}
+
+	case "set":
+		if cmd.Key == "" {
+			return failure(cmd, "key is required")
+		}
+		if cmd.TimeToLive < 0 {
+			return failure(cmd, "time-to-live must not be negative")
+		}
+		if _, err := namespace.Map.SetCacheTimetoLive(ctx, cmd.Key, cmd.Value, cmd.TimeToLive); err != nil {
+			return failure(cmd, err.Error())
+		}
+		return reply{ID: cmd.ID, Type: frameResult, Key: cmd.Key}
+
+	case "delete":
+		deleted, err := namespace.Map.Delete(ctx, cmd.Key)
+		if err != nil {
+			return failure(cmd, err.Error())
+		}
+		if !deleted {
+			return failure(cmd, "key not found")
+		}
+		return reply{ID: cmd.ID, Type: frameResult, Key: cmd.Key}
+
+	case "push":
+		if cmd.Key == "" {
+			return failure(cmd, "key is required")
+		}
+		if cmd.TimeToLive < 0 {
+			return failure(cmd, "time-to-live must not be negative")
+		}
+		namespace.Queue.SetCacheTimetoLive(ctx, cmd.Key, cmd.Value, cmd.TimeToLive)
+		return reply{ID: cmd.ID, Type: frameResult, Key: cmd.Key}
+
+	case "pop":
+		entry, ok := namespace.Queue.Pop(ctx)
+		if !ok {
+			return failure(cmd, "queue is empty")
+		}
+		return reply{ID: cmd.ID, Type: frameResult, Key: entry.Key, Value: &entry.Value}
+
+	default:
+		return failure(cmd, "unknown op "+cmd.Op)
+	}
+}
+
+// subscribe forwards the events accepted by the filters of the command,
+// they are sent with the id of the command which is also used to unsubscribe
+func (cn *conn) subscribe(cmd command) reply {
+	if cmd.ID == "" {
+		return failure(cmd, "subscriptions require an id")
+	}
+	if cmd.Source != "" && cmd.Source != eventservice.SourceMap && cmd.Source != eventservice.SourceQueue {
+		return failure(cmd, "source must be map or queue")
+	}
+
+	cn.lock.Lock()
+	defer cn.lock.Unlock()
+
+	if _, ok := cn.subscriptions[cmd.ID]; ok {
+		return failure(cmd, "subscription id already in use")
+	}
+	events, cancel := cn.handler.events.Subscribe(func(event eventservice.Event) bool {
+		return (cmd.Namespace == "" || event.Namespace == cmd.Namespace) &&
+			(cmd.Source == "" || event.Source == cmd.Source) &&
+			strings.HasPrefix(event.Key, cmd.Prefix) &&
+			(len(cmd.Types) == 0 || contains(cmd.Types, event.Type))
+	})
+	sub := &subscription{cancel: cancel}
+	cn.subscriptions[cmd.ID] = sub
+
+	go func() {
+		for event := range events {
+			event := event
+			cn.send(reply{ID: cmd.ID, Type: frameEvent, Event: &event})
+		}
+		// the channel is also closed when the subscriber fell behind, the
+		// id may be in use by a new subscription once this one was cancelled
+		cn.lock.Lock()
+		active := cn.subscriptions[cmd.ID] == sub
+		if active {
+			delete(cn.subscriptions, cmd.ID)
+		}
+		cn.lock.Unlock()
+		if active {
+			cn.send(reply{ID: cmd.ID, Type: frameError, Error: "subscription dropped, the client fell behind"})
+		}
+	}()
+	return reply{ID: cmd.ID, Type: frameResult}
+}
+
+// unsubscribe stops the subscription created by the subscribe command with the same id
+func (cn *conn) unsubscribe(cmd command) reply {
+	cn.lock.Lock()
+	sub, ok := cn.subscriptions[cmd.ID]
+	delete(cn.subscriptions, cmd.ID)
+	cn.lock.Unlock()
+
+	if !ok {
+		return failure(cmd, "subscription not found")
+	}
+	sub.cancel()
+	return reply{ID: cmd.ID, Type: frameResult}
+}
+
+func failure(cmd command, message string) reply {
+	return reply{ID: cmd.ID, Type: frameError, Error: message}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+// Package ws serves a WebSocket API. Clients send JSON command frames and
+// receive a JSON frame with the result of every command, carrying the id of
+// the command, and the events of their subscriptions.
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	eventservice "github.com/zelta-7/cache/pkg/service/events"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// pingInterval is how often the server pings an idle client
+	pingInterval = 30 * time.Second
+	// readTimeout closes connections that did not answer a ping in time
+	readTimeout = 2 * pingInterval
+	// writeTimeout bounds the time spent writing a single frame
+	writeTimeout = 10 * time.Second
+	// maxFrameLength bounds the size of a command frame
+	maxFrameLength = 1 << 20
+	// outgoingBuffer is how many frames may wait for a slow client before it is disconnected
+	outgoingBuffer = 256
+)
+
+// Frame types sent by the server
+const (
+	frameResult = "result"
+	frameError  = "error"
+	frameEvent  = "event"
+)
+
+// Options configure the WebSocket endpoint
+type Options struct {
+	// AllowedOrigins lists the origins browsers may connect from, "*"
+	// allows every origin. Same origin connections are always allowed.
+	AllowedOrigins []string
+}
+
+// command is a frame sent by a client
+type command struct {
+	ID string `json:"id"`
+	Op string `json:"op"`
+	// Namespace defaults to the default namespace, except for subscribe
+	// where an empty namespace matches the events of every namespace
+	Namespace  string `json:"namespace,omitempty"`
+	Key        string `json:"key,omitempty"`
+	Value      string `json:"value,omitempty"`
+	TimeToLive int    `json:"time-to-live,omitempty"`
+	// Source, Prefix and Types filter the events of a subscription
+	Source string   `json:"source,omitempty"`
+	Prefix string   `json:"prefix,omitempty"`
+	Types  []string `json:"types,omitempty"`
+}
+
+// reply is a frame sent by the server, events carry the id of the subscribe command
+type reply struct {
+	ID    string              `json:"id,omitempty"`
+	Type  string              `json:"type"`
+	Key   string              `json:"key,omitempty"`
+	Value *string             `json:"value,omitempty"`
+	Error string              `json:"error,omitempty"`
+	Event *eventservice.Event `json:"event,omitempty"`
+}
+
+type handler struct {
+	namespaces namespaceservice.NamespaceServiceInterface
+	events     eventservice.EventServiceInterface
+	upgrader   websocket.Upgrader
+}
+
+// NewHandler returns the gin handler upgrading requests to WebSocket connections
+func NewHandler(namespaces namespaceservice.NamespaceServiceInterface, events eventservice.EventServiceInterface, options Options) gin.HandlerFunc {
+	h := &handler{
+		namespaces: namespaces,
+		events:     events,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(options.AllowedOrigins),
+		},
+	}
+	return h.serve
+}
+
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, o := range allowed {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// conn is the state of a single client connection
+type conn struct {
+	*websocket.Conn
+	handler  *handler
+	outgoing chan reply
+	done     chan struct{}
+	closing  sync.Once
+
+	lock          sync.Mutex
+	subscriptions map[string]*subscription
+}
+
+// subscription is a subscription of a connection, an id reused after an
+// unsubscribe is a new subscription
+type subscription struct {
+	cancel func()
+}
+
+func (h *handler) serve(c *gin.Context) {
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already replied with an error
+		return
+	}
+	ws.SetReadLimit(maxFrameLength)
+
+	cn := &conn{
+		Conn:          ws,
+		handler:       h,
+		outgoing:      make(chan reply, outgoingBuffer),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*subscription),
+	}
+	defer cn.close()
+
+	go cn.writeLoop()
+	cn.readLoop(c.Request.Context())
+}
+
+func (cn *conn) close() {
+	cn.closing.Do(func() {
+		close(cn.done)
+		cn.lock.Lock()
+		for id, sub := range cn.subscriptions {
+			sub.cancel()
+			delete(cn.subscriptions, id)
+		}
+		cn.lock.Unlock()
+		cn.Conn.Close()
+	})
+}
+
+// send queues a frame, a client that does not keep up is disconnected
+func (cn *conn) send(r reply) {
+	select {
+	case cn.outgoing <- r:
+	case <-cn.done:
+	default:
+		klog.V(2).InfoS("Closing slow WebSocket client", "remote", cn.RemoteAddr())
+		cn.close()
+	}
+}
+
+func (cn *conn) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cn.done:
+			return
+		case r := <-cn.outgoing:
+			cn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := cn.WriteJSON(r); err != nil {
+				cn.close()
+				return
+			}
+		case <-ticker.C:
+			if err := cn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				cn.close()
+				return
+			}
+		}
+	}
+}
+
+func (cn *conn) readLoop(ctx context.Context) {
+	cn.SetReadDeadline(time.Now().Add(readTimeout))
+	cn.SetPongHandler(func(string) error {
+		return cn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+
+	for {
+		_, data, err := cn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				klog.V(2).InfoS("Error reading WebSocket frame", "remote", cn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		cn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		var cmd command
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			cn.send(reply{Type: frameError, Error: "invalid command frame: " + err.Error()})
+			continue
+		}
+		cn.send(cn.run(ctx, cmd))
+	}
+}
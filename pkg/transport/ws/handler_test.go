@@ -0,0 +1,134 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	eventservice "github.com/zelta-7/cache/pkg/service/events"
+	namespaceservice "github.com/zelta-7/cache/pkg/service/namespace"
+)
+
+// testClient sends command frames to the WebSocket endpoint and reads its frames
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+// startServer serves the WebSocket endpoint on a local port and returns a connected client
+func startServer(t *testing.T, events eventservice.EventServiceInterface) *testClient {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", NewHandler(namespaceservice.NewNamespaceService(namespaceservice.Options{}), events, Options{}))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn}
+}
+
+// do sends the command and returns the next frame
+func (c *testClient) do(cmd command) reply {
+	c.t.Helper()
+	if err := c.conn.WriteJSON(cmd); err != nil {
+		c.t.Fatalf("sending %s: %v", cmd.Op, err)
+	}
+	return c.next()
+}
+
+// next returns the next frame
+func (c *testClient) next() reply {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var r reply
+	if err := c.conn.ReadJSON(&r); err != nil {
+		c.t.Fatalf("reading a frame: %v", err)
+	}
+	return r
+}
+
+func TestCommands(t *testing.T) {
+	c := startServer(t, eventservice.NewEventService(0))
+
+	if r := c.do(command{ID: "1", Op: "set", Key: "a", Value: "1"}); r.Type != frameResult {
+		t.Fatalf("set replied %+v", r)
+	}
+	if r := c.do(command{ID: "2", Op: "get", Key: "a"}); r.Type != frameResult || r.Value == nil || *r.Value != "1" {
+		t.Fatalf("get replied %+v", r)
+	}
+	if r := c.do(command{ID: "3", Op: "get", Key: "missing"}); r.Type != frameError || r.ID != "3" {
+		t.Fatalf("get of a missing key replied %+v", r)
+	}
+	if r := c.do(command{ID: "4", Op: "unknown"}); r.Error != "unknown op unknown" {
+		t.Fatalf("an unknown op replied %+v", r)
+	}
+}
+
+func TestSubscriptionsForwardTheEventsUntilUnsubscribed(t *testing.T) {
+	events := eventservice.NewEventService(0)
+	c := startServer(t, events)
+
+	if r := c.do(command{ID: "s", Op: "subscribe", Prefix: "user:"}); r.Type != frameResult {
+		t.Fatalf("subscribe replied %+v", r)
+	}
+	if r := c.do(command{ID: "s", Op: "subscribe"}); r.Error != "subscription id already in use" {
+		t.Fatalf("a second subscribe with the same id replied %+v", r)
+	}
+	events.Publish(eventservice.Event{Type: eventservice.EventSet, Source: eventservice.SourceMap, Key: "other"})
+	events.Publish(eventservice.Event{Type: eventservice.EventSet, Source: eventservice.SourceMap, Key: "user:1"})
+	if r := c.next(); r.ID != "s" || r.Type != frameEvent || r.Event.Key != "user:1" {
+		t.Fatalf("the subscription received %+v, want the event of user:1", r)
+	}
+
+	if r := c.do(command{ID: "s", Op: "unsubscribe"}); r.Type != frameResult {
+		t.Fatalf("unsubscribe replied %+v", r)
+	}
+	if r := c.do(command{ID: "s", Op: "subscribe", Prefix: "user:"}); r.Type != frameResult {
+		t.Fatalf("subscribe after unsubscribe replied %+v", r)
+	}
+	events.Publish(eventservice.Event{Type: eventservice.EventDelete, Source: eventservice.SourceMap, Key: "user:2"})
+	if r := c.next(); r.Type != frameEvent || r.Event.Key != "user:2" {
+		t.Fatalf("the new subscription received %+v, want the event of user:2", r)
+	}
+	if r := c.do(command{ID: "s", Op: "unsubscribe"}); r.Type != frameResult {
+		t.Fatalf("unsubscribe of the new subscription replied %+v", r)
+	}
+	if r := c.do(command{ID: "s", Op: "unsubscribe"}); r.Error != "subscription not found" {
+		t.Fatalf("a second unsubscribe replied %+v", r)
+	}
+}
+
+func TestEndOfACancelledSubscriptionKeepsTheReusedID(t *testing.T) {
+	events := eventservice.NewEventService(0)
+	cn := &conn{
+		handler:       &handler{events: events},
+		outgoing:      make(chan reply, outgoingBuffer),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*subscription),
+	}
+	cn.subscribe(command{ID: "s", Op: "subscribe"})
+	// the subscription is removed but its events end after the id is reused
+	cn.lock.Lock()
+	old := cn.subscriptions["s"]
+	delete(cn.subscriptions, "s")
+	cn.lock.Unlock()
+	cn.subscribe(command{ID: "s", Op: "subscribe"})
+	old.cancel()
+
+	select {
+	case r := <-cn.outgoing:
+		t.Fatalf("the end of the cancelled subscription sent %+v", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if r := cn.unsubscribe(command{ID: "s", Op: "unsubscribe"}); r.Type != frameResult {
+		t.Fatalf("the new subscription was dropped, unsubscribe replied %+v", r)
+	}
+}